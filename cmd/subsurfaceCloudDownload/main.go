@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/cloud"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/metrics"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -38,15 +50,20 @@ func readCloudConfig2Struct(configFile string) (cloud.CloudDownload, error) {
 	return config, nil
 }
 
-func runDownload(configFile, logConfig string) {
+//setupLogging configures the global logger using the log configuration file if given, otherwise logging to
+//the logs folder and to stdout unless logToStdout is false, e.g. when the output of the command is printed to stdout
+func setupLogging(logConfig string, logToStdout bool) error {
 	var cfg zap.Config
-	var cloudCnfg cloud.CloudDownload
 	logFileName := "log_" + cloud.TimeToStr(time.Now(), "2006-01-02T15_04_05") + ".json"
 	errorFileName := "log_errors_" + cloud.TimeToStr(time.Now(), "2006-01-02T15_04_05") + ".json"
 	if logConfig == "" {
 		//just make the logs dir if not exists
 		if err := os.MkdirAll("./logs", 0755); err != nil {
-			panic(err)
+			return err
+		}
+		outputPaths := []string{"./logs/" + logFileName}
+		if logToStdout {
+			outputPaths = append([]string{"stdout"}, outputPaths...)
 		}
 		cfg = zap.Config{
 
@@ -54,7 +71,7 @@ func runDownload(configFile, logConfig string) {
 			Development:      false,
 			Encoding:         "json",
 			EncoderConfig:    zap.NewProductionEncoderConfig(),
-			OutputPaths:      []string{"stdout", "./logs/" + logFileName},
+			OutputPaths:      outputPaths,
 			ErrorOutputPaths: []string{"stderr", "./logs/" + errorFileName},
 		}
 		//just encode the time in RFC3339 as the default is epoch from production config
@@ -62,19 +79,38 @@ func runDownload(configFile, logConfig string) {
 	} else {
 		//parse in the log configuration
 		if rawJSON, err := ioutil.ReadFile(logConfig); err != nil {
-			panic(err)
+			return err
 		} else {
 			if err := json.Unmarshal(rawJSON, &cfg); err != nil {
-				panic(err)
+				return err
 			}
 		}
 	}
 	logger, err := cfg.Build()
 	if err != nil {
-		panic(err)
+		return err
 	}
-
 	zap.ReplaceGlobals(logger)
+	return nil
+}
+
+func runDownload(configFile, logConfig, summaryFile, metricsAddr string) {
+	var err error
+	var cloudCnfg cloud.CloudDownload
+	if summaryFile == "" {
+		summaryFile = "./logs/summary_" + cloud.TimeToStr(time.Now(), "2006-01-02T15_04_05") + ".json"
+	}
+	if err = setupLogging(logConfig, true); err != nil {
+		panic(err)
+	}
+	if metricsAddr != "" {
+		server, err := startMetricsServer(metricsAddr)
+		if err != nil {
+			zap.S().Errorf("Failed in starting metrics server on:%s,error:%s", metricsAddr, err.Error())
+			return
+		}
+		defer server.Close()
+	}
 	//read the cloud config
 	if cloudCnfg, err = readCloudConfig2Struct(configFile); err != nil {
 		zap.S().Errorf("Failed in reading cloud configuration xml file:%s", err.Error())
@@ -84,7 +120,138 @@ func runDownload(configFile, logConfig string) {
 	//first check if we have any of the cloud params specified in the incoming xml file
 	//if so set them as environment variables
 	setEnvironments(cloudCnfg)
-	cloud.ProcessAndRunDownload(cloudCnfg)
+	ctx, cancel := cancelOnSignal()
+	defer cancel()
+	summary, errList := cloud.ProcessAndRunDownload(ctx, cloudCnfg)
+	for i := 0; i < len(errList); i++ {
+		zap.S().Errorf("Download error:%s", errList[i].Error())
+	}
+	if summary.Cancelled {
+		zap.S().Warnf("Download was cancelled, downloaded:%d files before stopping, cancelled:%d files",
+			summary.FilesDownloaded(), summary.FilesCancelled())
+	}
+	//the summary is written also for cancelled runs so that progress is not lost
+	if err = cloud.WriteDownloadSummary(summaryFile, summary); err != nil {
+		zap.S().Errorf("Failed in writing download summary to:%s,error:%s", summaryFile, err.Error())
+		return
+	}
+	zap.S().Infof("Wrote download summary to:%s, downloaded:%d, failed:%d",
+		summaryFile, summary.FilesDownloaded(), summary.FilesFailed())
+}
+
+//runListSources prints the fields, wellbores and report types found in the files visible to the client for the period,
+//the configuration file is optional and only used for the cloud parameters
+func runListSources(configFile, logConfig, dateFrom, dateTo string, useUploadedFrom, asJSON bool) {
+	var err error
+	var listing cloud.SourceListing
+	if err = setupLogging(logConfig, false); err != nil {
+		panic(err)
+	}
+	if configFile != "" {
+		var cloudCnfg cloud.CloudDownload
+		if cloudCnfg, err = readCloudConfig2Struct(configFile); err != nil {
+			zap.S().Errorf("Failed in reading cloud configuration xml file:%s", err.Error())
+			fmt.Fprintf(os.Stderr, "Failed in reading cloud configuration xml file:%s\n", err.Error())
+			os.Exit(1)
+		}
+		setEnvironments(cloudCnfg)
+	}
+	//default to the last week
+	if dateFrom == "" || dateTo == "" {
+		start, end := common.RollDays(7)
+		dateFrom = common.FormatTime2QueryDayString(start)
+		dateTo = common.FormatTime2QueryDayString(end)
+	}
+	ctx, cancel := cancelOnSignal()
+	defer cancel()
+	if listing, err = cloud.ListSources(ctx, dateFrom, dateTo, useUploadedFrom); err != nil {
+		zap.S().Errorf("Failed in listing sources:%s", err.Error())
+		fmt.Fprintf(os.Stderr, "Failed in listing sources:%s\n", err.Error())
+		os.Exit(1)
+	}
+	if asJSON {
+		data, _ := json.MarshalIndent(listing, "", "    ")
+		fmt.Println(string(data))
+		return
+	}
+	printSourceListing(os.Stdout, listing)
+}
+
+//printSourceListing writes the listing as a table
+func printSourceListing(out io.Writer, listing cloud.SourceListing) {
+	fmt.Fprintf(out, "Files found in period %s - %s: %d\n\n", listing.TimeFrom, listing.TimeTo, listing.Files)
+	var reportTypes []string
+	for reportType := range listing.ReportTypes {
+		reportTypes = append(reportTypes, reportType)
+	}
+	sort.Strings(reportTypes)
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REPORT TYPE\tFILES")
+	for i := 0; i < len(reportTypes); i++ {
+		fmt.Fprintf(writer, "%s\t%d\n", reportTypes[i], listing.ReportTypes[reportTypes[i]])
+	}
+	writer.Flush()
+	fmt.Fprintln(out)
+	writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KIND\tNAME\tREPORT TYPES\tFILES\tFIRST PERIOD\tLAST PERIOD")
+	for i := 0; i < len(listing.Sources); i++ {
+		source := listing.Sources[i]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\t%s\n", source.Kind, source.Name,
+			strings.Join(source.ReportTypes, ","), source.Files, source.FirstPeriod, source.LastPeriod)
+	}
+	writer.Flush()
+}
+
+//startMetricsServer serves the prometheus metrics on /metrics and a health check on /healthz
+//at the given address in the background, the listener is opened before returning so that a busy port is reported
+func startMetricsServer(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: metrics.NewServeMux(metrics.DefaultRegistry)}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			zap.S().Errorf("Metrics server stopped:%s", err.Error())
+		}
+	}()
+	zap.S().Infof("Serving metrics on:%s/metrics, health check on:%s/healthz", listener.Addr().String(),
+		listener.Addr().String())
+	return server, nil
+}
+
+//cancelOnSignal returns a context that is cancelled when the process receives SIGINT or SIGTERM,
+//a second signal will terminate the process straight away. The returned cancel function also stops
+//the signal notification so that signals after the run get their default behaviour
+func cancelOnSignal() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 2)
+	stopped := make(chan struct{})
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigs:
+			zap.S().Warnf("Received signal:%s, stopping download after the current file", sig.String())
+			cancel()
+		case <-stopped:
+			return
+		}
+		select {
+		case sig := <-sigs:
+			zap.S().Errorf("Received signal:%s again, terminating", sig.String())
+			os.Exit(1)
+		case <-stopped:
+			return
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(stopped)
+		})
+		cancel()
+	}
 }
 
 //setEnvironments just checks if we have recieved any configuration
@@ -124,7 +291,15 @@ func main() {
 
 	configFile := flag.String("configuration", "", "Path to the xml configuration file to use")
 	logConfig := flag.String("logconfiguration", "", "Path to the log configuration file")
+	summaryFile := flag.String("summary", "", "Path to the json file where the summary of the run is written, defaults to the logs folder")
 	showVersion := flag.Bool("version", false, "If specified will print out the version information and then exit")
+	listSources := flag.Bool("list-sources", false, "If specified will list the fields, wellbores and report types found in the files visible to the client and then exit")
+	dateFrom := flag.String("from", "", "Start date (yyyy-mm-dd) of the period used by -list-sources, defaults to the last 7 days")
+	dateTo := flag.String("to", "", "End date (yyyy-mm-dd) of the period used by -list-sources, defaults to the last 7 days")
+	useUploadedFrom := flag.Bool("useUploadedFrom", false, "If set -list-sources will use the created date of the files for the period instead of the reporting period")
+	asJSON := flag.Bool("json", false, "If set -list-sources will print the result as json")
+	metricsAddr := flag.String("metrics-addr", "", "Optional address, e.g. :9090, serving prometheus metrics on /metrics and a health check on /healthz while downloading")
+	timezone := flag.String("timezone", "UTC", "Time zone of the times in converted files and of xml times without a time zone, UTC (default) or an IANA name, e.g. Europe/Oslo")
 
 	flag.Parse()
 	if *showVersion {
@@ -132,8 +307,16 @@ func main() {
 		fmt.Println("Build time:", Build)
 		return
 	}
+	if err := common.SetTimezone(*timezone); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if *listSources {
+		runListSources(*configFile, *logConfig, *dateFrom, *dateTo, *useUploadedFrom, *asJSON)
+		return
+	}
 	if *configFile != "" {
-		runDownload(*configFile, *logConfig)
+		runDownload(*configFile, *logConfig, *summaryFile, *metricsAddr)
 	} else {
 		fmt.Println("Missing configuration parameter that should point to a valid configuration xml file")
 		flag.PrintDefaults()
@@ -7,12 +7,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"time"
 
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/ddrml"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/uom"
 	"go.uber.org/zap"
 )
 
@@ -22,8 +25,10 @@ var (
 )
 
 func processDDRFiles(inputFolder string, outputFile string, logFile string, moveFolder string,
-	outputFormat string, appendTime2Filename bool, oneFilePerSheet bool) {
+	outputFormat string, appendTime2Filename bool, oneFilePerSheet bool, excelStreaming bool, csvOptions common.CsvOptions,
+	transforms common.StreamTransforms) error {
 	var err error
+	transform := transforms.Transform()
 	var drillReports []ddrml.DrillReports
 	var files []string
 	start := time.Now()
@@ -31,7 +36,7 @@ func processDDRFiles(inputFolder string, outputFile string, logFile string, move
 	//just create the folders needed if not existing
 	if err = createNeededFolders(outputFile, logFile, moveFolder); err != nil {
 		fmt.Printf("Failed in created needed folder:%s", err.Error())
-		return
+		return err
 	}
 	//initialize the log file
 
@@ -56,24 +61,34 @@ func processDDRFiles(inputFolder string, outputFile string, logFile string, move
 		outputFile = common.AppendTimeAndDateToFile(outputFile)
 		zap.S().Infof("Append time 2 filename is true, generated new outputfile name:%s", outputFile)
 	}
-	if drillReports, err = ddrml.ReadDDRXMLFiles2Struct(inputFolder); err != nil {
+	//the streaming excel writer reads the files one at a time as it writes them
+	if excelStreaming && isExcelFormat(outputFormat) {
+		if files, err = common.GetXMLFiles(inputFolder); err != nil {
+			zap.S().Errorf("Failed in reading ddr xml files in folder:%s,error:%s", inputFolder, err.Error())
+			return err
+		}
+	} else if drillReports, err = ddrml.ReadDDRXMLFiles2Struct(inputFolder); err != nil {
 		zap.S().Errorf("Failed in processing ddr xml files in folder:%s,error:%s", inputFolder, err.Error())
-		return
+		return err
 	}
 	unmarshalTook := time.Since(unmarshalStart)
 	switch outputFormat {
 	case "excel":
-		err = outputDDRExcelData(outputFile, drillReports, oneFilePerSheet, appendTime2Filename)
+		err = outputDDRExcelData(outputFile, files, drillReports, oneFilePerSheet, appendTime2Filename, excelStreaming, transforms)
 	case "json":
-		err = outputDDRJSONData(outputFile, drillReports)
+		err = outputDDRJSONData(outputFile, drillReports, transform)
 	case "csv":
-		err = outputDDRCsvData(outputFile, drillReports)
+		err = outputDDRCsvData(outputFile, drillReports, csvOptions, transform)
+	case "parquet":
+		err = ddrml.BuildParquetFileForDrilling(outputFile, drillReports, transform)
+	case "sqlite":
+		err = ddrml.BuildSqliteFileForDrilling(outputFile, drillReports, transform)
 	default:
-		err = outputDDRExcelData(outputFile, drillReports, oneFilePerSheet, appendTime2Filename)
+		err = outputDDRExcelData(outputFile, files, drillReports, oneFilePerSheet, appendTime2Filename, excelStreaming, transforms)
 	}
 	if err != nil {
 		zap.S().Errorf("Failed in outputting DDR data to format:%s, error:%s", outputFormat, err.Error())
-		return
+		return err
 	}
 
 	totalTimeTook := time.Since(start)
@@ -83,16 +98,16 @@ func processDDRFiles(inputFolder string, outputFile string, logFile string, move
 		//make sure the destination folder exists
 		if err = common.CreateAllFolders(moveFolder); err != nil {
 			zap.S().Errorf("Failed in creating DDR move folder:%s", err.Error())
-			return
+			return err
 		}
 		//get the list of the files
-		if files, err = common.GetFilesWithExtension(inputFolder, "*.xml"); err != nil {
+		if files, err = common.GetXMLFiles(inputFolder); err != nil {
 			zap.S().Error("Failed in reading DDR file in folder:", err.Error())
-			return
+			return err
 		}
 		if err = common.MoveFiles(files, moveFolder); err != nil {
 			zap.S().Errorf("Failed in moving DDR files to folder:%s, error:%s", moveFolder, err.Error())
-			return
+			return err
 		}
 
 	}
@@ -100,17 +115,26 @@ func processDDRFiles(inputFolder string, outputFile string, logFile string, move
 	zap.S().Infof("XML DDR File parsing took:%s", unmarshalTook)
 	zap.S().Infof("Total time used:%s", totalTimeTook)
 
+	return nil
 }
 
-func outputDDRCsvData(outputFile string, drillReports []ddrml.DrillReports) error {
+func outputDDRCsvData(outputFile string, drillReports []ddrml.DrillReports, csvOptions common.CsvOptions, transform common.DataSetTransform) error {
 
-	return ddrml.BuildCsvFileForDrilling(outputFile, drillReports)
+	return ddrml.BuildCsvFileForDrillingWithOptions(outputFile, drillReports, csvOptions, transform)
 }
-func outputDDRExcelData(outputFile string, objects []ddrml.DrillReports,
-	oneFilePerSheet, appendTime2Filename bool) error {
+
+//outputDDRExcelData writes the excel file, the streaming writer reads the files one at a time, otherwise the objects
+//read from the files are written
+func outputDDRExcelData(outputFile string, files []string, objects []ddrml.DrillReports,
+	oneFilePerSheet, appendTime2Filename, excelStreaming bool, transforms common.StreamTransforms) error {
 	var err error
 	xlsStart := time.Now()
-	if err = ddrml.BuildXLSFileForDrilling(outputFile, objects, oneFilePerSheet, appendTime2Filename); err != nil {
+	if excelStreaming {
+		err = ddrml.BuildStreamXLSFileForDrilling(outputFile, files, oneFilePerSheet, transforms)
+	} else {
+		err = ddrml.BuildXLSFileForDrilling(outputFile, objects, oneFilePerSheet, appendTime2Filename, transforms.Transform())
+	}
+	if err != nil {
 		zap.S().Error("Error:", err.Error())
 		return err
 	}
@@ -120,6 +144,15 @@ func outputDDRExcelData(outputFile string, objects []ddrml.DrillReports,
 	return nil
 }
 
+//isExcelFormat returns true if the output format is written as excel, the default for other formats
+func isExcelFormat(outputFormat string) bool {
+	switch outputFormat {
+	case "json", "csv", "parquet", "sqlite":
+		return false
+	}
+	return true
+}
+
 func createNeededFolders(outputFile string, logFile string, moveFolder string) error {
 	var err error
 	outputFolder := common.GetFolderPathForFile(outputFile)
@@ -138,10 +171,10 @@ func createNeededFolders(outputFile string, logFile string, moveFolder string) e
 	return nil
 }
 
-func outputDDRJSONData(outputFile string, objects []ddrml.DrillReports) error {
+func outputDDRJSONData(outputFile string, objects []ddrml.DrillReports, transform common.DataSetTransform) error {
 	var err error
 	jsonStart := time.Now()
-	if err = ddrml.BuildJsonFileForDrilling(outputFile, objects); err != nil {
+	if err = ddrml.BuildJsonFileForDrilling(outputFile, objects, transform); err != nil {
 		zap.S().Error("Error:", err.Error())
 		return err
 	}
@@ -151,15 +184,38 @@ func outputDDRJSONData(outputFile string, objects []ddrml.DrillReports) error {
 	return nil
 }
 
+//notifyFailure sends a notification of the failed conversion to the webhook and/or email in the notify configuration
+func notifyFailure(notifyConfig string, folderPath string, outputFile string, err error) {
+	n := notify.NewNotification("subsurfaceCollabor8DDR2Format", "Conversion of DDRML files failed")
+	n.Details = []string{"XML folder:" + folderPath, "Output file:" + outputFile}
+	n.Failures = []string{err.Error()}
+	errorList := notify.SendWithConfigFile(context.Background(), notifyConfig, n)
+	for i := 0; i < len(errorList); i++ {
+		zap.S().Errorf("Failed in sending failure notification:%s", errorList[i].Error())
+	}
+}
+
 func main() {
 
 	outputFile := flag.String("OUTPUT_FILE", "", "Specifies name and path of result file, e.g. c:\\temp\\output_data.xlsx or c:\\temp\\output_data.json")
 	xmlFolder := flag.String("XML_FOLDER", "", "Specifies the path to the folder containing DDR xml files to process")
 	logFile := flag.String("LOG_FILE", "", "Path and file name to use for the logging file,e.g. C:\\temp\\processing_zap.S().txt")
 	moveFolder := flag.String("MOVE_FOLDER", "", "Specifies a path to a folder where xml files that has been processed should be moved to, if left empty files are not moved")
-	outputFormat := flag.String("OUTPUT_FORMAT", "excel", "Specifies output format to use either excel (default), csv or json")
+	outputFormat := flag.String("OUTPUT_FORMAT", "excel", "Specifies output format to use either excel (default), csv, json, parquet or sqlite")
 	appendTimeToFileName := flag.Bool("APPEND_TIME2FILENAME", false, "If set to true exection will always add a timestamp to the output file name")
 	oneFilePerSheet := flag.Bool("ONE_FILE_PER_SHEET", false, "If set and output is excel, the program will generate one excel file per sheet as output")
+	excelStreaming := flag.Bool("EXCEL_STREAMING", false, "If set and output is excel, rows are streamed to the excel file as they are written instead of building the workbook in memory, use for large conversions")
+	notifyConfig := flag.String("NOTIFY_CONFIG", "", "Optional path to a xml file with a notify section, e.g. the download configuration, used to send a notification if the conversion fails")
+	csvDelimiter := flag.String("CSV_DELIMITER", ";", "Delimiter to use if output is csv, a single character or tab")
+	csvBOM := flag.Bool("CSV_BOM", false, "If set and output is csv, a utf-8 byte order mark is written first so that excel detects the encoding")
+	csvLineEnding := flag.String("CSV_LINE_ENDING", "lf", "Line endings to use if output is csv, either lf (default) or crlf")
+	datasets := flag.String("DATASETS", "", "Comma separated names of the datasets to output, e.g. REPORT_INFO,ACTIVITIES, * and ? can be used as wildcards, all datasets if empty")
+	filter := flag.String("FILTER", "", "Expression selecting the rows to output, e.g. NameWellbore == \"25/11-G-1\" and ReportDTimStart >= 2020-01-01, only applied to datasets having the columns used")
+	columns := flag.String("COLUMNS", "", "Comma separated columns to output in the given order, a column can be renamed as <column>=<new name>, all columns if empty")
+	sortColumns := flag.String("SORT", "", "Comma separated columns to sort the rows on as <column> [asc|desc]")
+	join := flag.String("JOIN", "", "Comma separated report level datasets, e.g. REPORT_FILE_INFO,REPORT_INFO, whose columns are added to the rows of all other datasets from the same report, joined on DataUUID")
+	targetUnits := flag.String("TARGET_UNITS", "", "Converts the values of all value and unit of measure column pairs to SI, field or the units given in a json file, e.g. c:\\temp\\units.json, values are output as reported if empty")
+	timezone := flag.String("TIMEZONE", "UTC", "Time zone of the output times and of xml times without a time zone, UTC (default) or an IANA name, e.g. Europe/Oslo")
 	showVersion := flag.Bool("version", false, "If specified will print out the version information and then exit")
 
 	flag.Parse()
@@ -168,9 +224,40 @@ func main() {
 		fmt.Println("Build time:", Build)
 		return
 	}
+	if err := common.SetTimezone(*timezone); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	csvOptions := common.CsvOptions{Delimiter: *csvDelimiter, BOM: *csvBOM, LineEnding: *csvLineEnding}
+	if err := common.VerifyCsvOptions(csvOptions); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	query, err := common.ParseDataSetQuery(*datasets, *filter, *columns, *sortColumns)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	converter, err := uom.NewConverter(*targetUnits)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	//the report columns are joined first and the units converted so that the query can use them, the rows are
+	//sorted when all rows of a dataset are built
+	rowsQuery, sortQuery := query.Split()
+	transforms := common.StreamTransforms{
+		Rows:    common.ChainTransforms(common.ParseJoinTransform(*join), converter.Transform(), rowsQuery.Transform()),
+		DataSet: sortQuery.Transform(),
+	}
 	if *outputFile != "" && *xmlFolder != "" && *logFile != "" {
-		processDDRFiles(*xmlFolder, *outputFile, *logFile, *moveFolder, *outputFormat,
-			*appendTimeToFileName, *oneFilePerSheet)
+		err = processDDRFiles(*xmlFolder, *outputFile, *logFile, *moveFolder, *outputFormat,
+			*appendTimeToFileName, *oneFilePerSheet, *excelStreaming, csvOptions, transforms)
+		//the filter and columns are checked against the datasets of all files once converted
+		query.WarnUnmatched()
+		if err != nil && *notifyConfig != "" {
+			notifyFailure(*notifyConfig, *xmlFolder, *outputFile, err)
+		}
 	} else {
 		fmt.Println("Missing required input parameters...")
 		flag.PrintDefaults()
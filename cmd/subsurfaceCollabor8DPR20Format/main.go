@@ -8,9 +8,12 @@ package main
 
 import (
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/uom"
 
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/dpr20"
 
+	"context"
 	"flag"
 	"fmt"
 	"time"
@@ -24,8 +27,10 @@ var (
 )
 
 func processXMLFiles(folderPath string, outputFile string, logFile string, moveFolder string,
-	outputFormat string, appendTime2Filename bool, oneFilePerSheet bool) {
+	outputFormat string, appendTime2Filename bool, oneFilePerSheet bool, excelStreaming bool, csvOptions common.CsvOptions,
+	transforms common.StreamTransforms) error {
 	var err error
+	transform := transforms.Transform()
 	var objects []dpr20.Objects
 	var files []string
 	start := time.Now()
@@ -33,7 +38,7 @@ func processXMLFiles(folderPath string, outputFile string, logFile string, moveF
 	//just create the folders needed if not existing
 	if err = createNeededFolders(outputFile, logFile, moveFolder); err != nil {
 		fmt.Printf("Failed in created needed folder:%s", err.Error())
-		return
+		return err
 	}
 
 	cfg := zap.Config{
@@ -57,25 +62,35 @@ func processXMLFiles(folderPath string, outputFile string, logFile string, moveF
 		outputFile = common.AppendTimeAndDateToFile(outputFile)
 		zap.S().Infof("Append time 2 filename is true, generated new outputfile name:%s", outputFile)
 	}
-	//read the xml files in the folder to structs
-	if objects, err = dpr20.ReadProdXMLFiles2Struct(folderPath); err != nil {
+	//read the xml files in the folder to structs, the streaming excel writer reads the files one at a time as it
+	//writes them
+	if excelStreaming && isExcelFormat(outputFormat) {
+		if files, err = common.GetXMLFiles(folderPath); err != nil {
+			zap.S().Errorf("Failed in reading xml files in folder:%s, error:%s", folderPath, err.Error())
+			return err
+		}
+	} else if objects, err = dpr20.ReadProdXMLFiles2Struct(folderPath); err != nil {
 		zap.S().Errorf("Failed in reading xml files in folder:%s, error:%s", folderPath, err.Error())
-		return
+		return err
 	}
 	unmarshalTook := time.Since(unmarshalStart)
 	switch outputFormat {
 	case "excel":
-		err = outputExcelData(outputFile, objects, oneFilePerSheet, appendTime2Filename)
+		err = outputExcelData(outputFile, files, objects, oneFilePerSheet, appendTime2Filename, excelStreaming, transforms)
 	case "json":
-		err = outputJSONData(outputFile, objects)
+		err = outputJSONData(outputFile, objects, transform)
 	case "csv":
-		err = dpr20.BuildCSVFileForProduction(outputFile, objects, ";")
+		err = dpr20.BuildCSVFileForProductionWithOptions(outputFile, objects, csvOptions, transform)
+	case "parquet":
+		err = dpr20.BuildParquetFileForProduction(outputFile, objects, transform)
+	case "sqlite":
+		err = dpr20.BuildSqliteFileForProduction(outputFile, objects, transform)
 	default:
-		err = outputExcelData(outputFile, objects, oneFilePerSheet, appendTime2Filename)
+		err = outputExcelData(outputFile, files, objects, oneFilePerSheet, appendTime2Filename, excelStreaming, transforms)
 	}
 	if err != nil {
 		zap.S().Errorf("Failed in outputting data to format:%s, error:%s", outputFormat, err.Error())
-		return
+		return err
 	}
 
 	totalTimeTook := time.Since(start)
@@ -85,22 +100,32 @@ func processXMLFiles(folderPath string, outputFile string, logFile string, moveF
 		//make sure the destination folder exists
 		if err = common.CreateAllFolders(moveFolder); err != nil {
 			zap.S().Errorf("Failed in creating move folder:%s", err.Error())
-			return
+			return err
 		}
 		//get the list of the files
-		if files, err = common.GetFilesWithExtension(folderPath, "*.xml"); err != nil {
+		if files, err = common.GetXMLFiles(folderPath); err != nil {
 			zap.S().Error("Failed in reading file in folder:", err.Error())
-			return
+			return err
 		}
 		if err = common.MoveFiles(files, moveFolder); err != nil {
 			zap.S().Errorf("Failed in moving files to folder:%s, error:%s", moveFolder, err.Error())
-			return
+			return err
 		}
 
 	}
 	zap.S().Info("Finished processing results to:", outputFile)
 	zap.S().Infof("XML File parsing took:%s", unmarshalTook)
 	zap.S().Infof("Total time used:%s", totalTimeTook)
+	return nil
+}
+
+//isExcelFormat returns true if the output format is written as excel, the default for other formats
+func isExcelFormat(outputFormat string) bool {
+	switch outputFormat {
+	case "json", "csv", "parquet", "sqlite":
+		return false
+	}
+	return true
 }
 
 func createNeededFolders(outputFile string, logFile string, moveFolder string) error {
@@ -121,10 +146,10 @@ func createNeededFolders(outputFile string, logFile string, moveFolder string) e
 	return nil
 }
 
-func outputJSONData(outputFile string, objects []dpr20.Objects) error {
+func outputJSONData(outputFile string, objects []dpr20.Objects, transform common.DataSetTransform) error {
 	var err error
 	jsonStart := time.Now()
-	if err = dpr20.BuildJsonFileForProduction(outputFile, objects); err != nil {
+	if err = dpr20.BuildJsonFileForProduction(outputFile, objects, transform); err != nil {
 		zap.S().Error("Error:", err.Error())
 		return err
 	}
@@ -134,11 +159,18 @@ func outputJSONData(outputFile string, objects []dpr20.Objects) error {
 	return nil
 }
 
-func outputExcelData(outputFile string, objects []dpr20.Objects,
-	oneFilePerSheet bool, appendTime2Filename bool) error {
+//outputExcelData writes the excel file, the streaming writer reads the files one at a time, otherwise the objects
+//read from the files are written
+func outputExcelData(outputFile string, files []string, objects []dpr20.Objects,
+	oneFilePerSheet bool, appendTime2Filename bool, excelStreaming bool, transforms common.StreamTransforms) error {
 	var err error
 	xlsStart := time.Now()
-	if err = dpr20.BuildXLSFileForProduction(outputFile, objects, oneFilePerSheet, appendTime2Filename); err != nil {
+	if excelStreaming {
+		err = dpr20.BuildStreamXLSFileForProduction(outputFile, files, oneFilePerSheet, transforms)
+	} else {
+		err = dpr20.BuildXLSFileForProduction(outputFile, objects, oneFilePerSheet, appendTime2Filename, transforms.Transform())
+	}
+	if err != nil {
 		zap.S().Error("Error:", err.Error())
 		return err
 	}
@@ -148,6 +180,17 @@ func outputExcelData(outputFile string, objects []dpr20.Objects,
 	return nil
 }
 
+//notifyFailure sends a notification of the failed conversion to the webhook and/or email in the notify configuration
+func notifyFailure(notifyConfig string, folderPath string, outputFile string, err error) {
+	n := notify.NewNotification("subsurfaceCollabor8DPR20Format", "Conversion of DPR 2.0 files failed")
+	n.Details = []string{"XML folder:" + folderPath, "Output file:" + outputFile}
+	n.Failures = []string{err.Error()}
+	errorList := notify.SendWithConfigFile(context.Background(), notifyConfig, n)
+	for i := 0; i < len(errorList); i++ {
+		zap.S().Errorf("Failed in sending failure notification:%s", errorList[i].Error())
+	}
+}
+
 func main() {
 	showVersion := flag.Bool("version", false, "If specified will print out the version information and then exit")
 
@@ -155,9 +198,22 @@ func main() {
 	xmlFolder := flag.String("XML_FOLDER", "", "Specifies the path to the folder containing MPMRL Government xml files to process")
 	logFile := flag.String("LOG_FILE", "", "Path and file name to use for the logging file,e.g. C:\\temp\\processing_zap.S().txt")
 	moveFolder := flag.String("MOVE_FOLDER", "", "Specifies a path to a folder where xml files that has been processed should be moved to, if left empty files are not moved")
-	outputFormat := flag.String("OUTPUT_FORMAT", "excel", "Specifies output format to use either excel (default),csv or json")
+	outputFormat := flag.String("OUTPUT_FORMAT", "excel", "Specifies output format to use either excel (default),csv,json,parquet or sqlite")
 	appendTimeToFileName := flag.Bool("APPEND_TIME2FILENAME", false, "If set to true exection will always add a timestamp to the output file name")
 	oneFilePerSheet := flag.Bool("ONE_FILE_PER_SHEET", false, "If set and output is excel, the program will generate one excel file per sheet as output")
+	excelStreaming := flag.Bool("EXCEL_STREAMING", false, "If set and output is excel, rows are streamed to the excel file as they are written instead of building the workbook in memory, use for large conversions")
+	notifyConfig := flag.String("NOTIFY_CONFIG", "", "Optional path to a xml file with a notify section, e.g. the download configuration, used to send a notification if the conversion fails")
+	csvDelimiter := flag.String("CSV_DELIMITER", ";", "Delimiter to use if output is csv, a single character or tab")
+	csvBOM := flag.Bool("CSV_BOM", false, "If set and output is csv, a utf-8 byte order mark is written first so that excel detects the encoding")
+	csvLineEnding := flag.String("CSV_LINE_ENDING", "lf", "Line endings to use if output is csv, either lf (default) or crlf")
+	datasets := flag.String("DATASETS", "", "Comma separated names of the datasets to output, e.g. DOCUMENT_INFO,FACILITIES, * and ? can be used as wildcards, all datasets if empty")
+	filter := flag.String("FILTER", "", "Expression selecting the rows to output, e.g. DocumentName == \"DPR_2020-01-01\" and DocumentDate >= 2020-01-01, only applied to datasets having the columns used")
+	columns := flag.String("COLUMNS", "", "Comma separated columns to output in the given order, a column can be renamed as <column>=<new name>, all columns if empty")
+	sortColumns := flag.String("SORT", "", "Comma separated columns to sort the rows on as <column> [asc|desc]")
+	join := flag.String("JOIN", "", "Comma separated report level datasets, e.g. REPORT_FILE_INFO,DOCUMENT_INFO, whose columns are added to the rows of all other datasets from the same report, joined on DataUUID")
+	targetUnits := flag.String("TARGET_UNITS", "", "Converts the values of all value and unit of measure column pairs to SI, field or the units given in a json file, e.g. c:\\temp\\units.json, values are output as reported if empty")
+	timezone := flag.String("TIMEZONE", "UTC", "Time zone of the output times and of xml times without a time zone, UTC (default) or an IANA name, e.g. Europe/Oslo")
+	pivot := flag.String("PIVOT", "", "Pivots the volumes to one row per period and one column per facility, flow and product, e.g. G-1_production_oil_Sm3, given as default or as ; separated <key>=<value> pairs with the keys rows, columns, values, aggregate, fill and step, e.g. values=Volume;fill=zero")
 
 	flag.Parse()
 	if *showVersion {
@@ -165,8 +221,46 @@ func main() {
 		fmt.Println("Build time:", Build)
 		return
 	}
+	if err := common.SetTimezone(*timezone); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	csvOptions := common.CsvOptions{Delimiter: *csvDelimiter, BOM: *csvBOM, LineEnding: *csvLineEnding}
+	if err := common.VerifyCsvOptions(csvOptions); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	query, err := common.ParseDataSetQuery(*datasets, *filter, *columns, *sortColumns)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	volumePivot, err := common.ParsePivot(*pivot)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	converter, err := uom.NewConverter(*targetUnits)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	//the report columns are joined first and the units converted so that the query can use them, and the pivot
+	//is last so that it is applied to the selected datasets and rows in the target units. The rows are sorted and
+	//pivoted when all rows of a dataset are built
+	rowsQuery, sortQuery := query.Split()
+	transforms := common.StreamTransforms{
+		Rows:    common.ChainTransforms(common.ParseJoinTransform(*join), converter.Transform(), rowsQuery.Transform()),
+		DataSet: common.ChainTransforms(sortQuery.Transform(), volumePivot.Transform()),
+	}
 	if *outputFile != "" && *xmlFolder != "" && *logFile != "" {
-		processXMLFiles(*xmlFolder, *outputFile, *logFile, *moveFolder, *outputFormat, *appendTimeToFileName, *oneFilePerSheet)
+		err = processXMLFiles(*xmlFolder, *outputFile, *logFile, *moveFolder, *outputFormat,
+			*appendTimeToFileName, *oneFilePerSheet, *excelStreaming, csvOptions, transforms)
+		//the filter and columns are checked against the datasets of all files once converted
+		query.WarnUnmatched()
+		if err != nil && *notifyConfig != "" {
+			notifyFailure(*notifyConfig, *xmlFolder, *outputFile, err)
+		}
 	} else {
 		fmt.Println("Missing required input parameters...")
 		flag.PrintDefaults()
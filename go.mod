@@ -4,8 +4,13 @@ go 1.13
 
 require (
 	github.com/go-resty/resty/v2 v2.2.0
+	github.com/prometheus/client_golang v1.12.2
+	github.com/prometheus/client_model v0.2.0
 	github.com/satori/go.uuid v1.2.0
 	github.com/tealeg/xlsx v1.0.5
+	github.com/xitongsys/parquet-go v1.5.4
+	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
 	go.uber.org/zap v1.14.1
-	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e
+	golang.org/x/net v0.0.0-20210525063256-abc453219eb5
+	modernc.org/sqlite v1.14.6
 )
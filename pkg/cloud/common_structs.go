@@ -1,6 +1,10 @@
 package cloud
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
+)
 
 //globals pointing to enviroinment variables to look for
 var AzureClientIdEnvName = "AzureClientId"
@@ -21,6 +25,7 @@ type CloudDownload struct {
 	MPRGovs     []CloudProductionConfig `xml:"mprmlGov"`
 	MPRPartners []CloudProductionConfig `xml:"mprmlPartner"`
 	DDRMLS      []CloudDDRMLConfig      `xml:"ddrml"`
+	Notify      notify.Config           `xml:"notify"` //webhook and/or email notified when the run fails
 }
 
 type CloudConfig struct {
@@ -42,6 +47,7 @@ type CloudProductionConfig struct {
 	UseUploadedFrom bool   `xml:"useUploadedFrom"`
 	LogFile         string `xml:"logFile"`
 	Common          CloudCommonConfig
+	Convert         CloudConvertConfig `xml:"convert"`
 }
 
 type CloudDDRMLConfig struct {
@@ -51,6 +57,7 @@ type CloudDDRMLConfig struct {
 	UseUploadedFrom bool   `xml:"useUploadedFrom"`
 	LogFile         string `xml:"logFile"`
 	Common          CloudCommonConfig
+	Convert         CloudConvertConfig `xml:"convert"`
 }
 
 type CloudCommonConfig struct {
@@ -58,4 +65,28 @@ type CloudCommonConfig struct {
 	Format           string   `xml:"format"`
 	OutputFolder     string   `xml:"outputFolder"`
 	FileOutputPrefix string   `xml:"fileOutputPrefix"`
+	LinkMode         string   `xml:"linkMode"`         //copy (default), hardlink or symlink
+	PathTemplate     string   `xml:"pathTemplate"`     //go template for the path of each file below the output folder
+	VersionPolicy    string   `xml:"versionPolicy"`    //latest or history, by default every version is stored as a separate file
+	QuarantineFolder string   `xml:"quarantineFolder"` //xml files failing the integrity check, defaults to outputFolder/quarantine
+	Compress         string   `xml:"compress"`         //gzip stores xml files compressed as .xml.gz
+}
+
+//CloudConvertConfig configures converting the newly downloaded xml files of a block
+//to another format once the download has finished, conversion is skipped if no output file is set
+type CloudConvertConfig struct {
+	Format              string `xml:"format"` //excel (default), csv, json, parquet or sqlite
+	OutputFile          string `xml:"outputFile"`
+	OneFilePerSheet     bool   `xml:"oneFilePerSheet"`
+	AppendTime2Filename bool   `xml:"appendTime2Filename"`
+	ExcelStreaming      bool   `xml:"excelStreaming"` //excel only, streams rows to the file for large conversions
+	CsvDelimiter        string `xml:"csvDelimiter"`   //csv only, single character or tab, defaults to ;
+	CsvBOM              bool   `xml:"csvBOM"`         //csv only, writes a utf-8 byte order mark for excel
+	CsvLineEnding       string `xml:"csvLineEnding"`  //csv only, lf (default) or crlf
+	Datasets            string `xml:"datasets"`       //comma separated datasets to output, all if empty
+	Filter              string `xml:"filter"`         //filter expression selecting the rows to output
+	Columns             string `xml:"columns"`        //comma separated columns to output as <column> or <column>=<new name>
+	Sort                string `xml:"sort"`           //comma separated columns to sort the rows on as <column> [asc|desc]
+	Join                string `xml:"join"`           //comma separated report datasets whose columns are added to the other datasets
+	TargetUnits         string `xml:"targetUnits"`    //SI, field or a json file with the units to convert values to
 }
@@ -0,0 +1,240 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/ddrml"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/dpr10"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/dpr20"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/mprml"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/uom"
+)
+
+//supported output formats for the convert section
+var convertFormats = []string{"excel", "csv", "json", "parquet", "sqlite"}
+
+//Enabled returns true if the convert section has been configured
+func (cnvCnfg CloudConvertConfig) Enabled() bool {
+	return cnvCnfg.OutputFile != ""
+}
+
+//csvOptions returns the options used when converting to csv
+func (cnvCnfg CloudConvertConfig) csvOptions() common.CsvOptions {
+	options := common.DefaultCsvOptions()
+	if cnvCnfg.CsvDelimiter != "" {
+		options.Delimiter = cnvCnfg.CsvDelimiter
+	}
+	if cnvCnfg.CsvLineEnding != "" {
+		options.LineEnding = cnvCnfg.CsvLineEnding
+	}
+	options.BOM = cnvCnfg.CsvBOM
+	return options
+}
+
+//transforms returns the transforms joining the report datasets to the other datasets, converting the units and
+//selecting the datasets, rows and columns to output, split in the transforms of the rows and the sorting. The
+//query is returned to warn of a filter or columns not matching any dataset once converted
+func (cnvCnfg CloudConvertConfig) transforms() (common.StreamTransforms, common.DataSetQuery, error) {
+	query, err := common.ParseDataSetQuery(cnvCnfg.Datasets, cnvCnfg.Filter, cnvCnfg.Columns, cnvCnfg.Sort)
+	if err != nil {
+		return common.StreamTransforms{}, query, err
+	}
+	converter, err := uom.NewConverter(cnvCnfg.TargetUnits)
+	if err != nil {
+		return common.StreamTransforms{}, query, err
+	}
+	rows, sort := query.Split()
+	return common.StreamTransforms{
+		Rows:    common.ChainTransforms(common.ParseJoinTransform(cnvCnfg.Join), converter.Transform(), rows.Transform()),
+		DataSet: sort.Transform(),
+	}, query, nil
+}
+
+//verifyConvertConfig checks that the convert section of a block can be used with the block format
+func verifyConvertConfig(cnvCnfg CloudConvertConfig, downloadFormat string) error {
+	if !cnvCnfg.Enabled() {
+		return nil
+	}
+	if strings.ToLower(downloadFormat) != "xml" {
+		return fmt.Errorf("Convert is only supported when downloading xml, format set to:%s", downloadFormat)
+	}
+	if err := common.VerifyCsvOptions(cnvCnfg.csvOptions()); err != nil {
+		return err
+	}
+	if _, _, err := cnvCnfg.transforms(); err != nil {
+		return err
+	}
+	if cnvCnfg.Format == "" {
+		return nil
+	}
+	for i := 0; i < len(convertFormats); i++ {
+		if strings.ToLower(cnvCnfg.Format) == convertFormats[i] {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unsupported convert format:%s, supported formats:%s", cnvCnfg.Format,
+		strings.Join(convertFormats, ","))
+}
+
+//convertConfigForReportType returns the convert configuration to use for one of several report types
+//downloaded by the same block, the report type is added to the output file name so the outputs do not overwrite each other
+func convertConfigForReportType(cnvCnfg CloudConvertConfig, reportType string) CloudConvertConfig {
+	if !cnvCnfg.Enabled() {
+		return cnvCnfg
+	}
+	folder := common.GetFolderPathForFile(cnvCnfg.OutputFile)
+	fileName, ext := common.GetFileNameAndExtension(cnvCnfg.OutputFile)
+	cnvCnfg.OutputFile = folder + string(os.PathSeparator) + fileName + "_" + reportType + ext
+	return cnvCnfg
+}
+
+//ConvertDownloadedFiles runs the converter matching the report type (DPR10, DPR20, MPRMLGov, MPRMLPartner or DDRML)
+//on the given xml files and writes the result to the output file in the convert configuration
+func ConvertDownloadedFiles(ctx context.Context, reportType string, files []string, cnvCnfg CloudConvertConfig) error {
+	var err error
+	log := LoggerFromContext(ctx)
+	if len(files) == 0 {
+		log.Infof("No new files downloaded for report type:%s, skipping conversion", reportType)
+		return nil
+	}
+	outputFile := cnvCnfg.OutputFile
+	format := strings.ToLower(cnvCnfg.Format)
+	if format == "" {
+		format = "excel"
+	}
+	if err = common.CreateAllFolders(common.GetFolderPathForFile(outputFile)); err != nil {
+		return err
+	}
+	//the time is appended to the file name here only, the excel builders are not asked to append it again
+	if cnvCnfg.AppendTime2Filename {
+		outputFile = common.AppendTimeAndDateToFile(outputFile)
+	}
+	transforms, query, err := cnvCnfg.transforms()
+	if err != nil {
+		return err
+	}
+	log.Infof("Converting %d downloaded files of report type:%s to format:%s, output:%s",
+		len(files), reportType, format, outputFile)
+	if format == "excel" && cnvCnfg.ExcelStreaming {
+		err = streamDownloadedFiles(reportType, files, outputFile, cnvCnfg.OneFilePerSheet, transforms)
+	} else {
+		err = convertDownloadedFiles(reportType, files, outputFile, format, cnvCnfg, transforms.Transform())
+	}
+	query.WarnUnmatched()
+	if err != nil {
+		return fmt.Errorf("Failed in converting report type:%s to format:%s, error:%s", reportType, format, err.Error())
+	}
+	log.Infof("Finished converting report type:%s to:%s", reportType, outputFile)
+	return nil
+}
+
+//streamDownloadedFiles writes the xml files to excel with the streaming writer, reading one file at a time
+func streamDownloadedFiles(reportType string, files []string, outputFile string, oneFilePerSheet bool,
+	transforms common.StreamTransforms) error {
+	switch strings.ToLower(reportType) {
+	case "dpr10":
+		return dpr10.BuildStreamXLSFileForProduction(outputFile, files, oneFilePerSheet, transforms)
+	case "dpr20":
+		return dpr20.BuildStreamXLSFileForProduction(outputFile, files, oneFilePerSheet, transforms)
+	case "mprmlgov", "mprmlpartner":
+		return mprml.BuildStreamXLSFileForProduction(outputFile, files, oneFilePerSheet, transforms)
+	case "ddrml":
+		return ddrml.BuildStreamXLSFileForDrilling(outputFile, files, oneFilePerSheet, transforms)
+	}
+	return fmt.Errorf("No converter available for report type:%s", reportType)
+}
+
+//convertDownloadedFiles reads all the xml files and writes them to the output format
+func convertDownloadedFiles(reportType string, files []string, outputFile string, format string,
+	cnvCnfg CloudConvertConfig, transform common.DataSetTransform) error {
+	var err error
+	switch strings.ToLower(reportType) {
+	case "dpr10":
+		var objects []dpr10.WITSMLComposite
+		if objects, err = dpr10.ReadProdXMLFileList2Struct(files); err != nil {
+			return err
+		}
+		switch format {
+		case "csv":
+			err = dpr10.BuildCSVFileForProductionWithOptions(outputFile, objects, cnvCnfg.csvOptions(), transform)
+		case "json":
+			err = dpr10.BuildJsonFileForProduction(outputFile, objects, transform)
+		case "parquet":
+			err = dpr10.BuildParquetFileForProduction(outputFile, objects, transform)
+		case "sqlite":
+			err = dpr10.BuildSqliteFileForProduction(outputFile, objects, transform)
+		default:
+			err = dpr10.BuildXLSFileForProduction(outputFile, objects, cnvCnfg.OneFilePerSheet, false, transform)
+		}
+	case "dpr20":
+		var objects []dpr20.Objects
+		if objects, err = dpr20.ReadProdXMLFileList2Struct(files); err != nil {
+			return err
+		}
+		switch format {
+		case "csv":
+			err = dpr20.BuildCSVFileForProductionWithOptions(outputFile, objects, cnvCnfg.csvOptions(), transform)
+		case "json":
+			err = dpr20.BuildJsonFileForProduction(outputFile, objects, transform)
+		case "parquet":
+			err = dpr20.BuildParquetFileForProduction(outputFile, objects, transform)
+		case "sqlite":
+			err = dpr20.BuildSqliteFileForProduction(outputFile, objects, transform)
+		default:
+			err = dpr20.BuildXLSFileForProduction(outputFile, objects, cnvCnfg.OneFilePerSheet, false, transform)
+		}
+	case "mprmlgov", "mprmlpartner":
+		var objects []mprml.Objects
+		if objects, err = mprml.ReadProdXMLFileList2Struct(files); err != nil {
+			return err
+		}
+		switch format {
+		case "csv":
+			err = mprml.BuildCSVFileForProductionWithOptions(outputFile, objects, cnvCnfg.csvOptions(), transform)
+		case "json":
+			err = mprml.BuildJsonFileForProduction(outputFile, objects, transform)
+		case "parquet":
+			err = mprml.BuildParquetFileForProduction(outputFile, objects, transform)
+		case "sqlite":
+			err = mprml.BuildSqliteFileForProduction(outputFile, objects, transform)
+		default:
+			err = mprml.BuildXLSFileForProduction(outputFile, objects, cnvCnfg.OneFilePerSheet, false, transform)
+		}
+	case "ddrml":
+		var dReports []ddrml.DrillReports
+		if dReports, err = ddrml.ReadDDRXMLFileList2Struct(files); err != nil {
+			return err
+		}
+		switch format {
+		case "csv":
+			err = ddrml.BuildCsvFileForDrillingWithOptions(outputFile, dReports, cnvCnfg.csvOptions(), transform)
+		case "json":
+			err = ddrml.BuildJsonFileForDrilling(outputFile, dReports, transform)
+		case "parquet":
+			err = ddrml.BuildParquetFileForDrilling(outputFile, dReports, transform)
+		case "sqlite":
+			err = ddrml.BuildSqliteFileForDrilling(outputFile, dReports, transform)
+		default:
+			err = ddrml.BuildXLSFileForDrilling(outputFile, dReports, cnvCnfg.OneFilePerSheet, false, transform)
+		}
+	default:
+		return fmt.Errorf("No converter available for report type:%s", reportType)
+	}
+	return err
+}
+
+//convertedInputFiles returns one file path per downloaded file object to use as converter input,
+//files written to several locations, e.g. ddrml files covering several wellbores, are only included once
+func convertedInputFiles(downloaded []DownloadedFile) []string {
+	var files []string
+	for i := 0; i < len(downloaded); i++ {
+		if len(downloaded[i].Paths) > 0 {
+			files = append(files, downloaded[i].Paths[0])
+		}
+	}
+	return files
+}
@@ -0,0 +1,230 @@
+package cloud
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestVerifyConvertConfig(t *testing.T) {
+	if err := verifyConvertConfig(CloudConvertConfig{}, "pdf"); err != nil {
+		t.Errorf("No convert section should not give an error, got:%s", err.Error())
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.xlsx"}, "pdf"); err == nil {
+		t.Errorf("Convert of pdf downloads should give an error")
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.xml", Format: "xml"}, "xml"); err == nil {
+		t.Errorf("Convert to unsupported format should give an error")
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.csv", Format: "CSV"}, "XML"); err != nil {
+		t.Errorf("Convert to csv from xml should be valid, got:%s", err.Error())
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.csv", Format: "csv", CsvDelimiter: ";;"}, "XML"); err == nil {
+		t.Errorf("Invalid csv delimiter should give an error")
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.csv", Filter: "NameWellbore =="}, "XML"); err == nil {
+		t.Errorf("Invalid filter should give an error")
+	}
+	if err := verifyConvertConfig(CloudConvertConfig{OutputFile: "out.csv", TargetUnits: "imperial"}, "XML"); err == nil {
+		t.Errorf("Unknown target units should give an error")
+	}
+}
+
+func TestConvertConfigForReportType(t *testing.T) {
+	cnvCnfg := convertConfigForReportType(CloudConvertConfig{OutputFile: "out/production.xlsx"}, "DPR10")
+	if cnvCnfg.OutputFile != filepath.Join("out", "production_DPR10.xlsx") {
+		t.Errorf("Expected report type in output file name, got:%s", cnvCnfg.OutputFile)
+	}
+	if cnvCnfg = convertConfigForReportType(CloudConvertConfig{}, "DPR10"); cnvCnfg.OutputFile != "" {
+		t.Errorf("Output file should stay empty when convert is not set, got:%s", cnvCnfg.OutputFile)
+	}
+}
+
+func TestConvertDownloadedDDRMLFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "convert")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	xmlFile := filepath.Join(folder, "ddr.xml")
+	ddrml := `<?xml version="1.0" encoding="utf-8"?>
+<drillReports><drillReport uid="1" uidWell="w1" uidWellbore="wb1"><nameWell>NO 1/1-1</nameWell><nameWellbore>NO 1/1-1</nameWellbore></drillReport></drillReports>`
+	if err = ioutil.WriteFile(xmlFile, []byte(ddrml), 0644); err != nil {
+		t.Fatalf("Failed in writing test file:%s", err.Error())
+	}
+	outputFile := filepath.Join(folder, "converted", "ddr.json")
+	cnvCnfg := CloudConvertConfig{Format: "json", OutputFile: outputFile}
+	if err = ConvertDownloadedFiles(context.Background(), "DDRML", []string{xmlFile}, cnvCnfg); err != nil {
+		t.Fatalf("Failed in converting downloaded files:%s", err.Error())
+	}
+	if _, err = os.Stat(outputFile); err != nil {
+		t.Errorf("Converted output file not found:%s", err.Error())
+	}
+	//parquet gives one file per dataset carrying the xml file as source in the metadata
+	parquetFile := filepath.Join(folder, "converted", "ddr.parquet")
+	if err = ConvertDownloadedFiles(context.Background(), "DDRML", []string{xmlFile},
+		CloudConvertConfig{Format: "parquet", OutputFile: parquetFile}); err != nil {
+		t.Fatalf("Failed in converting downloaded files to parquet:%s", err.Error())
+	}
+	parquetFiles, _ := filepath.Glob(filepath.Join(folder, "converted", "ddr_*.parquet"))
+	if len(parquetFiles) == 0 {
+		t.Fatalf("No parquet files written")
+	}
+	if data, _ := ioutil.ReadFile(parquetFiles[0]); !strings.HasPrefix(string(data), "PAR1") ||
+		!strings.Contains(string(data), xmlFile) {
+		t.Errorf("Expected parquet file with source file in metadata:%s", parquetFiles[0])
+	}
+	//sqlite converting the same report again replaces its rows
+	dbFile := filepath.Join(folder, "converted", "ddr.db")
+	for i := 0; i < 2; i++ {
+		if err = ConvertDownloadedFiles(context.Background(), "DDRML", []string{xmlFile},
+			CloudConvertConfig{Format: "sqlite", OutputFile: dbFile}); err != nil {
+			t.Fatalf("Failed in converting downloaded files to sqlite:%s", err.Error())
+		}
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite database:%s", err.Error())
+	}
+	defer db.Close()
+	var count int
+	if err = db.QueryRow("SELECT COUNT(*) FROM REPORT_FILE_INFO").Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected one report file info row after converting twice, got:%d,%v", count, err)
+	}
+	//streamed excel has all the datasets as sheets after the index sheet
+	xlsxFile := filepath.Join(folder, "converted", "ddr.xlsx")
+	if err = ConvertDownloadedFiles(context.Background(), "DDRML", []string{xmlFile},
+		CloudConvertConfig{Format: "excel", OutputFile: xlsxFile, ExcelStreaming: true}); err != nil {
+		t.Fatalf("Failed in converting downloaded files to streamed excel:%s", err.Error())
+	}
+	workbook, err := xlsx.OpenFile(xlsxFile)
+	if err != nil {
+		t.Fatalf("Failed in opening streamed excel file:%s", err.Error())
+	}
+	if len(workbook.Sheets) < 2 || workbook.Sheets[1].Name != "REPORT_FILE_INFO" ||
+		workbook.Sheets[1].Cell(1, 2).Value != xmlFile {
+		t.Errorf("Expected report file info sheet with the converted file, got %d sheets", len(workbook.Sheets))
+	}
+	//the streamed datasets are selected, filtered and projected one at a time
+	selectedFile := filepath.Join(folder, "converted", "ddr_selected.xlsx")
+	if err = ConvertDownloadedFiles(context.Background(), "DDRML", []string{xmlFile},
+		CloudConvertConfig{Format: "excel", OutputFile: selectedFile, ExcelStreaming: true, Datasets: "report_*",
+			Filter: `FileName != "other.xml"`, Columns: "FileName=File"}); err != nil {
+		t.Fatalf("Failed in converting downloaded files with a query:%s", err.Error())
+	}
+	if workbook, err = xlsx.OpenFile(selectedFile); err != nil {
+		t.Fatalf("Failed in opening selected excel file:%s", err.Error())
+	}
+	if len(workbook.Sheets) != 2 || workbook.Sheets[1].Name != "REPORT_FILE_INFO" ||
+		workbook.Sheets[1].Cell(0, 0).Value != "File" || workbook.Sheets[1].Cell(1, 0).Value != "ddr.xml" {
+		t.Errorf("Expected only the projected report file info sheet, got %d sheets", len(workbook.Sheets))
+	}
+	if err = ConvertDownloadedFiles(context.Background(), "UNKNOWN", []string{xmlFile}, cnvCnfg); err == nil {
+		t.Errorf("Convert of unknown report type should give an error")
+	}
+}
+
+//TestConvertCompressedDownloads will test that files stored gzip compressed are downloaded as .xml.gz
+//and can be converted without decompressing them first
+func TestConvertCompressedDownloads(t *testing.T) {
+	ddrml := `<?xml version="1.0" encoding="utf-8"?>
+<drillReports><drillReport uid="1" uidWell="w1" uidWellbore="wb1"><nameWell>NO 1/1-1</nameWell><nameWellbore>NO 1/1-1</nameWellbore></drillReport></drillReports>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(ddrml))
+	}))
+	defer server.Close()
+	folder, err := ioutil.TempDir("", "compress")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	files := []FileObject{{FileName: "ddr.xml", FileReference: "1", ReportType: 3, Created: "2020-03-01T10:44:51.526Z",
+		Sources: []DataSource{{Kind: "wellbore", Name: "NO 1/1-1"}}}}
+	storage := StorageOptions{Format: "XML", OutputFolder: folder, FilePrefix: "TEST", Compress: "gzip",
+		VersionPolicy: VersionPolicyHistory}
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key", storage)
+	if len(errorList) != 0 || len(downloaded) != 1 {
+		t.Fatalf("Expected one file downloaded, got:%v,%v", downloaded, errorList)
+	}
+	path := downloaded[0].Paths[0]
+	if !strings.HasSuffix(path, "_v1.xml.gz") {
+		t.Errorf("Expected compressed file with version suffix, got:%s", path)
+	}
+	if data, _ := ioutil.ReadFile(path); strings.Contains(string(data), "drillReports") {
+		t.Errorf("Expected file to be stored compressed")
+	}
+	outputFile := filepath.Join(folder, "converted", "ddr.json")
+	if err = ConvertDownloadedFiles(context.Background(), "DDRML", convertedInputFiles(downloaded),
+		CloudConvertConfig{Format: "json", OutputFile: outputFile}); err != nil {
+		t.Fatalf("Failed in converting compressed files:%s", err.Error())
+	}
+	if data, _ := ioutil.ReadFile(outputFile); !strings.Contains(string(data), "NO 1/1-1") {
+		t.Errorf("Expected converted data from compressed file, got:%s", string(data))
+	}
+}
+
+func TestVerifyCompress(t *testing.T) {
+	if err := verifyCompress("", "PDF"); err != nil {
+		t.Errorf("No compression should be valid, got:%s", err.Error())
+	}
+	if err := verifyCompress("GZIP", "xml"); err != nil {
+		t.Errorf("Gzip of xml should be valid, got:%s", err.Error())
+	}
+	if err := verifyCompress("gzip", "PDF"); err == nil {
+		t.Errorf("Gzip of pdf should give an error")
+	}
+	if err := verifyCompress("zip", "XML"); err == nil {
+		t.Errorf("Unsupported compression should give an error")
+	}
+}
+
+//TestRunQueryConvertsDownloadedFilesOnFailure will test that the files that did download are converted when
+//another file of the block fails
+func TestRunQueryConvertsDownloadedFilesOnFailure(t *testing.T) {
+	ddrml := `<?xml version="1.0" encoding="utf-8"?>
+<drillReports><drillReport uid="1" uidWell="w1" uidWellbore="wb1"><nameWell>NO 1/1-1</nameWell><nameWellbore>NO 1/1-1</nameWellbore></drillReport></drillReports>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"data":{"files":[
+				{"fileName":"a.xml","fileReferenceId":"1","created":"2020-03-01T10:00:00Z","reportType":3,
+				"metadata":{"periodStart":"2020-02-29","periodEnd":"2020-03-01","reportId":"1"},
+				"sources":[{"kind":"wellbore","name":"NO 1/1-1"}]},
+				{"fileName":"b.xml","fileReferenceId":"2","created":"2020-03-02T10:00:00Z","reportType":3,
+				"metadata":{"periodStart":"2020-03-01","periodEnd":"2020-03-02","reportId":"2"}}]}}`))
+		case r.URL.Path == "/files/1":
+			w.Write([]byte(ddrml))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	folder, err := ioutil.TempDir("", "convertfailure")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	outputFile := filepath.Join(folder, "converted", "ddr.json")
+	fQuery := FileQuery{TimeFrom: "2020-03-01", TimeTo: "2020-03-02", Field: "TEST", FileType: "XML",
+		ReportType: "DDRML", OutputLocation: folder, Convert: CloudConvertConfig{Format: "json", OutputFile: outputFile,
+			AppendTime2Filename: true}}
+	if _, err = RunQueryAndDownloadFiles(context.Background(), fQuery, "token", "key", server.URL,
+		server.URL+"/files"); err == nil {
+		t.Fatalf("Expected download of second file to fail")
+	}
+	converted, _ := filepath.Glob(filepath.Join(folder, "converted", "ddr_*.json"))
+	if len(converted) != 1 {
+		t.Fatalf("Expected the downloaded file converted once with the time in the name, got:%v", converted)
+	}
+	if data, _ := ioutil.ReadFile(converted[0]); !strings.Contains(string(data), "NO 1/1-1") {
+		t.Errorf("Expected converted data from the downloaded file, got:%s", string(data))
+	}
+}
@@ -1,6 +1,9 @@
 package cloud
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 var downloadDPR10XmlDataConfigDateFrom = `<subsurface>
 <dpr>
@@ -252,8 +255,8 @@ func TestBuildDPR10QueryNoUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of dpr 10 config:%s", err.Error())
 	}
-	fQueryDPR10 := createDPR10Query(cConfig.DPRS[0], "token")
-	fQueryDPR20 := createDPR20Query(cConfig.DPRS[0], "token")
+	fQueryDPR10 := createFileQuery(newProductionBlock("dpr", cConfig.DPRS[0]), testReportType("DPR10"), true)
+	fQueryDPR20 := createFileQuery(newProductionBlock("dpr", cConfig.DPRS[0]), testReportType("DPR20"), true)
 	if queryDPR10, err = BuildQueryForAssetUsingPeriod(fQueryDPR10); err != nil {
 		t.Errorf("Failed in creating dpr10 query:%s", err.Error())
 	}
@@ -273,8 +276,8 @@ func TestBuildDPR10QueryUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of dpr 10 config:%s", err.Error())
 	}
-	fQueryDPR10 := createDPR10Query(cConfig.DPRS[0], "token")
-	fQueryDPR20 := createDPR20Query(cConfig.DPRS[0], "token")
+	fQueryDPR10 := createFileQuery(newProductionBlock("dpr", cConfig.DPRS[0]), testReportType("DPR10"), true)
+	fQueryDPR20 := createFileQuery(newProductionBlock("dpr", cConfig.DPRS[0]), testReportType("DPR20"), true)
 	if queryDPR10, err = BuildQueryForAssetUsingCreated(fQueryDPR10); err != nil {
 		t.Errorf("Failed in creating dpr10 query:%s", err.Error())
 	}
@@ -297,7 +300,7 @@ func TestDownloadDPR10FromConfiguration(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of dpr 10 config:%s", err.Error())
 	}
-	if errors = ProcessAndRunDownload(cConfig); len(errors) > 0 {
+	if _, errors = ProcessAndRunDownload(context.Background(), cConfig); len(errors) > 0 {
 		for i := 0; i < len(errors); i++ {
 			t.Errorf("Failed in download:%s", errors[i].Error())
 		}
@@ -318,7 +321,7 @@ func TestDownloadDPR10FromConfigurationCreated(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of dpr 10 config:%s", err.Error())
 	}
-	if errors = ProcessAndRunDownload(cConfig); len(errors) > 0 {
+	if _, errors = ProcessAndRunDownload(context.Background(), cConfig); len(errors) > 0 {
 		for i := 0; i < len(errors); i++ {
 			t.Errorf("Failed in download:%s", errors[i].Error())
 		}
@@ -336,7 +339,7 @@ func TestBuildMPRMLGovQueryNoUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of mprmlgov config:%s", err.Error())
 	}
-	fQueryMPRMLGov := createMPRMLGovQuery(cConfig.MPRGovs[0], "token")
+	fQueryMPRMLGov := createFileQuery(newProductionBlock("mprmlGov", cConfig.MPRGovs[0]), testReportType("MPRMLGov"), false)
 	if queryMPRMLGov, err = BuildQueryForAssetUsingPeriod(fQueryMPRMLGov); err != nil {
 		t.Errorf("Failed in   creating mprmlgov query:%s", err.Error())
 	}
@@ -353,7 +356,7 @@ func TestBuildMPRMLGovQueryUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of mprmlgov config:%s", err.Error())
 	}
-	fQueryMPRMLGov := createMPRMLGovQuery(cConfig.MPRGovs[0], "token")
+	fQueryMPRMLGov := createFileQuery(newProductionBlock("mprmlGov", cConfig.MPRGovs[0]), testReportType("MPRMLGov"), false)
 	if queryMPRMLGov, err = BuildQueryForAssetUsingCreated(fQueryMPRMLGov); err != nil {
 		t.Errorf("Failed in creating mprmlgov query:%s", err.Error())
 	}
@@ -371,7 +374,7 @@ func TestDownloadMPRMLGovFromConfigurationUsingPeriod(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of mprml gov config:%s", err.Error())
 	}
-	if errors = ProcessAndRunDownload(cConfig); len(errors) > 0 {
+	if _, errors = ProcessAndRunDownload(context.Background(), cConfig); len(errors) > 0 {
 		for i := 0; i < len(errors); i++ {
 			t.Errorf("Failed in download:%s", errors[i].Error())
 		}
@@ -390,7 +393,7 @@ func TestDownloadMPRMLGovFromConfigurationUsingUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of mprml gov config:%s", err.Error())
 	}
-	if errors = ProcessAndRunDownload(cConfig); len(errors) > 0 {
+	if _, errors = ProcessAndRunDownload(context.Background(), cConfig); len(errors) > 0 {
 		for i := 0; i < len(errors); i++ {
 			t.Errorf("Failed in download:%s", errors[i].Error())
 		}
@@ -408,7 +411,7 @@ func TestBuildDDRMLQueryNoUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of ddrml config:%s", err.Error())
 	}
-	fQueryDDRML := createDDRMLQuery(cConfig.DDRMLS[0], "token")
+	fQueryDDRML := createFileQuery(newDDRMLBlock(cConfig.DDRMLS[0]), testReportType("DDRML"), false)
 	if queryDDRML, err = BuildQueryForAssetUsingPeriod(fQueryDDRML); err != nil {
 		t.Errorf("Failed in creating ddrml query:%s", err.Error())
 	}
@@ -425,7 +428,7 @@ func TestBuildDDRMLQueryUploadedFrom(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of ddrml config:%s", err.Error())
 	}
-	fQueryDDRML := createDDRMLQuery(cConfig.DDRMLS[0], "token")
+	fQueryDDRML := createFileQuery(newDDRMLBlock(cConfig.DDRMLS[0]), testReportType("DDRML"), false)
 	if queryDDRML, err = BuildQueryForAssetUsingPeriod(fQueryDDRML); err != nil {
 		t.Errorf("Failed in creating ddrml query:%s", err.Error())
 	}
@@ -445,7 +448,7 @@ func TestDownloadDDRMLFromConfigurationForPeriod(t *testing.T) {
 	if cConfig, err = CloudConfigArrayToStruct(data); err != nil {
 		t.Errorf("Failed in uinmarshal of ddrml config:%s", err.Error())
 	}
-	if errors = ProcessAndRunDownload(cConfig); len(errors) > 0 {
+	if _, errors = ProcessAndRunDownload(context.Background(), cConfig); len(errors) > 0 {
 		for i := 0; i < len(errors); i++ {
 			t.Errorf("Failed in download:%s", errors[i].Error())
 		}
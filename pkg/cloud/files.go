@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
 	"github.com/go-resty/resty/v2"
 	"go.uber.org/zap"
 )
@@ -31,7 +33,7 @@ func GenerateFileName(fObj FileObject, filePrefix, format string) string {
 	baseFileName = SafeEncodeNameForWinFiles(baseFileName)
 	reportType := MapReportType(fObj.ReportType)
 	sourceString := ""
-	if strings.ToLower(reportType) == "ddrml" {
+	if isStoredPerWellbore(fObj) {
 		for i := 0; i < len(fObj.Sources); i++ {
 			sourceString = sourceString + "_" + SafeEncodeNameForWinFiles(fObj.Sources[i].Name)
 		}
@@ -54,68 +56,146 @@ func SafeEncodeNameForWinFiles(name string) string {
 	return name
 }
 
+//MapReportType returns the name of the report type with the given api id
 func MapReportType(reportType int) string {
-	switch reportType {
-	case 1:
-		return "DPR10"
-	case 2:
-		return "DPR20"
-	case 3:
-		return "DDRML"
-	case 4:
-		return "MPRMLGov"
-	case 5:
-		return "MPRMLPartner"
-	default:
-		return "NONE_REPORTTYPE"
+	if definition, exists := ReportTypeById(reportType); exists {
+		return definition.Name
 	}
+	return "NONE_REPORTTYPE"
 }
 
 //DownloadFiles downloads a set of files from a array of fileobjects, it will call download file function for each entry
-// and dowload the data to the outputfolder, will return an array of possible errors
-//assetName will be used to prefix the files
-//addTimeStampInName will add a unix timestamp to the outputfile name identifying when the file was downloaded
-func DownloadFiles(files []FileObject, fileURL, token, subscriptionKey, format,
-	outputFolder, filePrefix string, addTimeStampInName bool) []error {
+// and dowload the data to the outputfolder, will return the files written and an array of possible errors
+//the storage options control the format, output folder, file prefix, how files stored in several locations are linked
+//and how resubmitted versions of the same report are kept
+//if the context is cancelled the remaining files are returned as cancelled and the cancellation is returned as an error
+func DownloadFiles(ctx context.Context, files []FileObject, fileURL, token, subscriptionKey string,
+	storage StorageOptions) ([]DownloadedFile, []error) {
 	var errorsEncountered []error
+	var downloaded []DownloadedFile
+	var index *VersionIndex
+	var newestCreated map[string]string
+	log := LoggerFromContext(ctx)
+	versionPolicy := strings.ToLower(storage.VersionPolicy)
+	if versionPolicy != "" {
+		var err error
+		if index, err = LoadVersionIndex(storage.OutputFolder); err != nil {
+			errorMsg := fmt.Sprintf("Failed in loading version index from:%s,error:%s", storage.OutputFolder, err.Error())
+			log.Error(errorMsg)
+			errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+			return downloaded, errorsEncountered
+		}
+		//oldest first so that versions are numbered in the order they were submitted
+		files = sortFilesByCreated(files)
+		newestCreated = newestCreatedByReport(files)
+	}
 	for i := 0; i < len(files); i++ {
-		zap.S().Debugf("Processing file:" + files[i].FileName)
+		if ctx.Err() != nil {
+			errorMsg := fmt.Sprintf("Download cancelled, skipping remaining %d of %d files:%s",
+				len(files)-i, len(files), ctx.Err().Error())
+			log.Warn(errorMsg)
+			errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+			for ; i < len(files); i++ {
+				downloaded = append(downloaded, DownloadedFile{File: files[i], Cancelled: true})
+			}
+			return downloaded, errorsEncountered
+		}
+		log.Debugf("Processing file:" + files[i].FileName)
+		//with the latest policy only the newest version, stored or among the files found, is downloaded
+		if index != nil && versionPolicy == VersionPolicyLatest && (index.IsOlderThanCurrent(files[i]) ||
+			createdBefore(files[i].Created, newestCreated[reportVersionKey(files[i])])) {
+			log.Infof("Skipping file with referenceId:%s,fileName:%s,created:%s, a newer version is already stored",
+				files[i].FileReference, files[i].FileName, files[i].Created)
+			downloaded = append(downloaded, DownloadedFile{File: files[i], Skipped: true})
+			continue
+		}
 
-		if fileData, err := DownloadFile(files[i].FileReference,
-			fileURL, token, subscriptionKey, format); err != nil {
+		if fileData, err := DownloadFile(ctx, files[i].FileReference,
+			fileURL, token, subscriptionKey, storage.Format); err != nil {
+			//a download stopped by the cancellation is counted as cancelled and not as failed
+			if ctx.Err() != nil {
+				errorMsg := fmt.Sprintf("Download cancelled for file with referenceId:%s,fileName:%s,error:%s",
+					files[i].FileReference, files[i].FileName, err.Error())
+				log.Warn(errorMsg)
+				errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+				downloaded = append(downloaded, DownloadedFile{File: files[i], Cancelled: true})
+				continue
+			}
 			errorMsg := fmt.Sprintf("Failed in download of file with referenceId:%s,fileName:%s,format:%s,error:%s",
-				files[i].FileReference, files[i].FileName, format, err.Error())
-			zap.S().Error(errorMsg)
+				files[i].FileReference, files[i].FileName, storage.Format, err.Error())
+			log.Error(errorMsg)
 			errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
 		} else {
-			//we have the file now write it to disk.
-			outputFiles := BuildOutputPathForReportType(files[i], filePrefix, outputFolder, format)
-			//need to handle several paths and create the folders if needed
-			for x := 0; x < len(outputFiles); x++ {
-				//just check that the folder exists
-				if !FileOrFolderExists(filepath.Dir(outputFiles[x])) {
-					//folder does not exist just create it
-					if err = os.MkdirAll(filepath.Dir(outputFiles[x]), os.ModePerm); err != nil {
-						errorMsg := fmt.Sprintf("Failed in creating folder for storage with path:%s,error:%s", outputFiles[x], err.Error())
-						zap.S().Error(errorMsg)
-						errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
-						//just abort operation
-						return errorsEncountered
+			//check xml files before storing them, files failing the check are moved to quarantine
+			if strings.EqualFold(storage.Format, "xml") {
+				if err = CheckXMLIntegrity(fileData, MapReportType(files[i].ReportType)); err != nil {
+					errorMsg := fmt.Sprintf("Integrity check failed for file with referenceId:%s,fileName:%s,error:%s",
+						files[i].FileReference, files[i].FileName, err.Error())
+					if quarantinePath, qErr := QuarantineFile(ctx, files[i], fileData, err, storage); qErr != nil {
+						errorMsg = errorMsg + fmt.Sprintf(", failed in storing file in quarantine:%s", qErr.Error())
+					} else {
+						errorMsg = errorMsg + fmt.Sprintf(", file stored in quarantine:%s", quarantinePath)
 					}
+					log.Error(errorMsg)
+					errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+					continue
 				}
-				if err := common.Write2File(outputFiles[x], fileData); err != nil {
-					errorMsg := fmt.Sprintf("Failed in write of file with referenceId:%s,fileName:%s,outputLocation:%s,error:%s",
-						files[i].FileReference, files[i].FileName, outputFiles[x], err.Error())
-					zap.S().Error(errorMsg)
+			}
+			//we have the file now write it to disk.
+			if isStoredPerWellbore(files[i]) && len(files[i].Sources) == 0 && storage.PathTemplate == "" {
+				log.Warnf("No wellbore sources found for file with referenceId:%s,fileName:%s, storing it in output folder:%s",
+					files[i].FileReference, files[i].FileName, storage.OutputFolder)
+			}
+			outputFiles, err := BuildOutputPaths(files[i], storage)
+			if err != nil {
+				errorMsg := fmt.Sprintf("Failed in building output path for file with referenceId:%s,fileName:%s,error:%s",
+					files[i].FileReference, files[i].FileName, err.Error())
+				log.Error(errorMsg)
+				errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+				continue
+			}
+			dFile := DownloadedFile{File: files[i], Bytes: len(fileData)}
+			if storage.compressed() {
+				if fileData, err = common.GzipData(fileData); err != nil {
+					errorMsg := fmt.Sprintf("Failed in compressing file with referenceId:%s,fileName:%s,error:%s",
+						files[i].FileReference, files[i].FileName, err.Error())
+					log.Error(errorMsg)
 					errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
-				} else {
-					zap.S().Infof("Wrote file to:%s", outputFiles[x])
+					continue
+				}
+			}
+			if index != nil {
+				dFile.Version = index.NextVersion(files[i])
+				if versionPolicy == VersionPolicyHistory {
+					for x := 0; x < len(outputFiles); x++ {
+						outputFiles[x] = addVersionSuffix(outputFiles[x], dFile.Version)
+					}
+				}
+			}
+			//need to handle several paths, only written once when linking
+			written, writeErrors := writeFileToPaths(ctx, fileData, outputFiles, storage.LinkMode)
+			for x := 0; x < len(writeErrors); x++ {
+				errorMsg := fmt.Sprintf("Failed in storing file with referenceId:%s,fileName:%s,error:%s",
+					files[i].FileReference, files[i].FileName, writeErrors[x].Error())
+				log.Error(errorMsg)
+				errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+			}
+			dFile.Paths = written
+			if len(dFile.Paths) > 0 {
+				downloaded = append(downloaded, dFile)
+				if index != nil {
+					if err = storeVersion(ctx, index, dFile, storage); err != nil {
+						errorMsg := fmt.Sprintf("Failed in updating version index for file with referenceId:%s,fileName:%s,error:%s",
+							files[i].FileReference, files[i].FileName, err.Error())
+						log.Error(errorMsg)
+						errorsEncountered = append(errorsEncountered, errors.New(errorMsg))
+					}
 				}
 			}
 		}
 
 	}
-	return errorsEncountered
+	return downloaded, errorsEncountered
 }
 
 func FileOrFolderExists(path string) bool {
@@ -128,34 +208,20 @@ func FileOrFolderExists(path string) bool {
 
 //VerifyCloudDownloadConfig will check the configuration data for errors
 func VerifyCloudDownloadConfig(downloadConfig CloudDownload) error {
-	//first check that no rolldays are greater than 10
-	for i := 0; i < len(downloadConfig.DPRS); i++ {
-		if downloadConfig.DPRS[i].RollDays > MaxRollDays {
-			return fmt.Errorf("Invalid number of rolldays specified:%d, allowed:%d",
-				downloadConfig.DPRS[i].RollDays,
-				MaxRollDays)
-		}
-		//check the days
-		if downloadConfig.DPRS[i].DateFrom != "" && downloadConfig.DPRS[i].DateTo != "" {
-			if days, err := DaysBetween(downloadConfig.DPRS[i].DateFrom, downloadConfig.DPRS[i].DateTo); err != nil {
-				return err
-			} else {
-				if days > MaxNumberOfDaysPeriod {
-					return fmt.Errorf("Max number of days for a given period exceeded:%.2f, max set to:%.2f",
-						days, MaxNumberOfDaysPeriod)
-				}
-			}
-		}
+	if err := notify.Verify(downloadConfig.Notify); err != nil {
+		return err
 	}
-	for i := 0; i < len(downloadConfig.DDRMLS); i++ {
-		if downloadConfig.DDRMLS[i].RollDays > MaxRollDays {
+	blocks := downloadConfig.blocks()
+	for i := 0; i < len(blocks); i++ {
+		//first check that no rolldays are greater than the max
+		if blocks[i].RollDays > MaxRollDays {
 			return fmt.Errorf("Invalid number of rolldays specified:%d, allowed:%d",
-				downloadConfig.DDRMLS[i].RollDays,
+				blocks[i].RollDays,
 				MaxRollDays)
 		}
 		//check the days
-		if downloadConfig.DDRMLS[i].DateFrom != "" && downloadConfig.DDRMLS[i].DateTo != "" {
-			if days, err := DaysBetween(downloadConfig.DDRMLS[i].DateFrom, downloadConfig.DDRMLS[i].DateTo); err != nil {
+		if blocks[i].DateFrom != "" && blocks[i].DateTo != "" {
+			if days, err := DaysBetween(blocks[i].DateFrom, blocks[i].DateTo); err != nil {
 				return err
 			} else {
 				if days > MaxNumberOfDaysPeriod {
@@ -164,71 +230,62 @@ func VerifyCloudDownloadConfig(downloadConfig CloudDownload) error {
 				}
 			}
 		}
-	}
-	for i := 0; i < len(downloadConfig.MPRGovs); i++ {
-		if downloadConfig.MPRGovs[i].RollDays > MaxRollDays {
-			return fmt.Errorf("Invalid number of rolldays specified:%d, allowed:%d",
-				downloadConfig.MPRGovs[i].RollDays,
-				MaxRollDays)
-
+		if err := verifyFormat(blocks[i].Element, blocks[i].Common.Format); err != nil {
+			return err
 		}
-		//check the days
-		if downloadConfig.MPRGovs[i].DateFrom != "" && downloadConfig.MPRGovs[i].DateTo != "" {
-			if days, err := DaysBetween(downloadConfig.MPRGovs[i].DateFrom,
-				downloadConfig.MPRGovs[i].DateTo); err != nil {
-				return err
-			} else {
-				if days > MaxNumberOfDaysPeriod {
-					return fmt.Errorf("Max number of days for a given period exceeded:%.2f, max set to:%.2f",
-						days, MaxNumberOfDaysPeriod)
-				}
-			}
+		if err := verifyConvertConfig(blocks[i].Convert, blocks[i].Common.Format); err != nil {
+			return err
 		}
-		if strings.ToLower(downloadConfig.MPRGovs[i].Common.Format) == "pdf" {
-			return fmt.Errorf("Unsupported format specified for MPRML Gov data, pdf not supported")
+		if err := verifyLinkMode(blocks[i].Common.LinkMode); err != nil {
+			return err
 		}
-
-	}
-	for i := 0; i < len(downloadConfig.MPRPartners); i++ {
-		if downloadConfig.MPRPartners[i].RollDays > MaxRollDays {
-			return fmt.Errorf("Invalid number of rolldays specified:%d, allowed:%d",
-				downloadConfig.MPRPartners[i].RollDays,
-				MaxRollDays)
+		if err := verifyPathTemplate(blocks[i].Common.PathTemplate); err != nil {
+			return err
 		}
-		//check the days
-		if downloadConfig.MPRPartners[i].DateFrom != "" && downloadConfig.MPRPartners[i].DateTo != "" {
-			if days, err := DaysBetween(downloadConfig.MPRPartners[i].DateFrom,
-				downloadConfig.MPRPartners[i].DateTo); err != nil {
-				return err
-			} else {
-				if days > MaxNumberOfDaysPeriod {
-					return fmt.Errorf("Max number of days for a given period exceeded:%.2f, max set to:%.2f",
-						days, MaxNumberOfDaysPeriod)
-				}
-			}
+		if err := verifyVersionPolicy(blocks[i].Common.VersionPolicy); err != nil {
+			return err
 		}
-		if strings.ToLower(downloadConfig.MPRPartners[i].Common.Format) == "pdf" {
-			return fmt.Errorf("Unsupported format specified for MPRML Partner data, pdf not supported")
+		if err := verifyCompress(blocks[i].Common.Compress, blocks[i].Common.Format); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 //ProcessAndRunDownload will process and query for a set of files as defined in the download config
-//any errors will be tracked on a file basis and returned
-func ProcessAndRunDownload(downloadConfig CloudDownload) []error {
+//any errors will be tracked on a file basis and returned together with a summary of the run.
+//If the context is cancelled the run stops after the file currently being written and
+//the summary returned holds what was downloaded up to that point
+func ProcessAndRunDownload(ctx context.Context, downloadConfig CloudDownload) (DownloadSummary, []error) {
 	var err error
 	var errList []error
 	var token string
 	var subscriptionKey, graphQLUrl, fileDownloadUrl string
 	var fQueries []FileQuery
+	var blockLoggerClosers []func()
+	summary := DownloadSummary{Started: time.Now()}
+	//make sure that the summary is always closed with the errors found
+	finish := func() (DownloadSummary, []error) {
+		summary.Finished = time.Now()
+		summary.Cancelled = ctx.Err() != nil
+		for i := 0; i < len(errList); i++ {
+			summary.Errors = append(summary.Errors, errList[i].Error())
+		}
+		recordRunMetrics(summary, errList)
+		if len(errList) > 0 {
+			notifyDownloadFailures(downloadConfig.Notify, summary)
+		}
+		return summary, errList
+	}
 
 	if err = VerifyCloudDownloadConfig(downloadConfig); err != nil {
-		return append(errList, err)
+		errList = append(errList, err)
+		return finish()
 	}
-	if token, err = Authenticate(); err != nil {
+	if token, err = Authenticate(ctx); err != nil {
 		zap.S().Errorf("Failed in getting token:%s", err.Error())
-		return []error{err}
+		errList = append(errList, err)
+		return finish()
 	}
 	zap.S().Debugf("Got token:%s", token)
 	//get the subscription key from the environment variables
@@ -238,167 +295,137 @@ func ProcessAndRunDownload(downloadConfig CloudDownload) []error {
 	if subscriptionKey == "" {
 		errorMsg := fmt.Sprintf("Unable to find subscription key in environment variable:%s", AzureSubscriptionKeyEnvName)
 		zap.S().Errorf(errorMsg)
-		return []error{errors.New(errorMsg)}
+		errList = append(errList, errors.New(errorMsg))
+		return finish()
 	}
 	if graphQLUrl == "" {
 		errorMsg := fmt.Sprintf("Unable to locate environment variable for the graphqlurl:%s",
 			AzureGraphUrlEnvName)
 		zap.S().Errorf(errorMsg)
-		return []error{errors.New(errorMsg)}
+		errList = append(errList, errors.New(errorMsg))
+		return finish()
 	}
 	if fileDownloadUrl == "" {
 		errorMsg := fmt.Sprintf("Unable to locate environment variable for the filedownloadurl:%s",
 			AzureFileDownloadUrlEnvName)
 		zap.S().Errorf(errorMsg)
-		return []error{errors.New(errorMsg)}
-	}
-	//loop through all of the entities and process them one by one
-	//first dprs
-	zap.S().Debugf("Processing DPR's:%d", len(downloadConfig.DPRS))
-	for i := 0; i < len(downloadConfig.DPRS); i++ {
-		fQueries = append(fQueries,
-			createDPR10Query(downloadConfig.DPRS[i], token))
-		fQueries = append(fQueries,
-			createDPR20Query(downloadConfig.DPRS[i], token))
-	}
-	zap.S().Debugf("Processing MPRML Govs:%d", len(downloadConfig.MPRGovs))
-	for x := 0; x < len(downloadConfig.MPRGovs); x++ {
-		//make sure that we do not have queries for pdfs here
-		if strings.ToLower(downloadConfig.MPRGovs[x].Common.Format) == "pdf" {
-			errList = append(errList, errors.New("PDFS are not supported for MPRML Government reports"))
-		} else {
-			fQueries = append(fQueries, createMPRMLGovQuery(downloadConfig.MPRGovs[x], token))
-		}
-	}
-	zap.S().Debugf("Processing MPRML Partners:%d", len(downloadConfig.MPRPartners))
-	for y := 0; y < len(downloadConfig.MPRPartners); y++ {
-		if strings.ToLower(downloadConfig.MPRPartners[y].Common.Format) == "pdf" {
-			errList = append(errList, errors.New("PDFS are not supported for MPRML Partner reports"))
-		} else {
-			fQueries = append(fQueries, createMPRMLPartnerQuery(downloadConfig.MPRPartners[y], token))
+		errList = append(errList, errors.New(errorMsg))
+		return finish()
+	}
+	//one query per report type of each block in the configuration
+	fQueries = downloadConfig.fileQueries()
+	zap.S().Debugf("Processing number of queries:%d", len(fQueries))
+	//blocks configured with their own log file get a child logger writing to it,
+	//queries from the same block share the logger
+	blockLoggers := make(map[string]*zap.SugaredLogger)
+	defer func() {
+		for _, closeLogger := range blockLoggerClosers {
+			closeLogger()
 		}
-	}
-	zap.S().Debugf("Processing ddrmls:%s", len(downloadConfig.DDRMLS))
-	for z := 0; z < len(downloadConfig.DDRMLS); z++ {
-		fQueries = append(fQueries, createDDRMLQuery(downloadConfig.DDRMLS[z], token))
-	}
+	}()
 	//now run all of the queries one by one
 	for a := 0; a < len(fQueries); a++ {
-		err = RunQueryAndDownloadFiles(fQueries[a], token, subscriptionKey, graphQLUrl,
+		if ctx.Err() != nil {
+			zap.S().Warnf("Download run cancelled, skipping %d remaining queries", len(fQueries)-a)
+			errList = append(errList, fmt.Errorf("Download run cancelled before all queries were run:%s", ctx.Err().Error()))
+			break
+		}
+		queryCtx := ctx
+		if fQueries[a].LogFile != "" {
+			if _, exists := blockLoggers[fQueries[a].LogFile]; !exists {
+				logger, closeLogger, err := NewBlockLogger(fQueries[a].LogFile)
+				if err != nil {
+					zap.S().Errorf("Failed in creating log file:%s, logging to global log only,error:%s",
+						fQueries[a].LogFile, err.Error())
+					logger = zap.S()
+				} else {
+					blockLoggerClosers = append(blockLoggerClosers, closeLogger)
+				}
+				blockLoggers[fQueries[a].LogFile] = logger
+			}
+			queryCtx = ContextWithLogger(ctx, blockLoggers[fQueries[a].LogFile])
+		}
+		qSummary, err := RunQueryAndDownloadFiles(queryCtx, fQueries[a], token, subscriptionKey, graphQLUrl,
 			fileDownloadUrl)
+		summary.Queries = append(summary.Queries, qSummary)
 		if err != nil {
 			errList = append(errList, err)
 		}
 	}
-	return errList
+	return finish()
 }
 
 //RunQueryAndDownloadFiles will take an filequery object and run a graphql query for the specified files
 //and using the file result it will try to download each file locally using the filedownloadurl and the file reference
-func RunQueryAndDownloadFiles(fQuery FileQuery, token, subscriptionKey, graphQLUrl,
-	fileDownloadUrl string) error {
+//returns a summary of the files found and downloaded for the query
+func RunQueryAndDownloadFiles(ctx context.Context, fQuery FileQuery, token, subscriptionKey, graphQLUrl,
+	fileDownloadUrl string) (QuerySummary, error) {
 	var dObj DataObject
 	var err error
 	var query []byte
+	log := LoggerFromContext(ctx)
+	qSummary := newQuerySummary(fQuery)
 	if fQuery.UseUploadedFrom {
 		if query, err = BuildQueryForAssetUsingCreated(fQuery); err != nil {
-			zap.S().Errorf("Failed in generation of asset query:%s", err.Error())
-			return err
+			log.Errorf("Failed in generation of asset query:%s", err.Error())
+			qSummary.Errors = append(qSummary.Errors, err.Error())
+			return qSummary, err
 		}
 	} else {
 
 		if query, err = BuildQueryForAssetUsingPeriod(fQuery); err != nil {
-			zap.S().Errorf("Failed in generation of asset query:%s", err.Error())
-			return err
+			log.Errorf("Failed in generation of asset query:%s", err.Error())
+			qSummary.Errors = append(qSummary.Errors, err.Error())
+			return qSummary, err
 		}
 	}
-	zap.S().Infof("Running file query for, field:%s,fileType:%s, reportType:%s,timeRange:%s-%s, useUploadedFrom:%s",
+	log.Infof("Running file query for, field:%s,fileType:%s, reportType:%s,timeRange:%s-%s, useUploadedFrom:%t",
 		fQuery.Field,
 		fQuery.FileType, fQuery.ReportType, fQuery.TimeFrom,
 		fQuery.TimeTo, fQuery.UseUploadedFrom)
-	zap.S().Debugf("Generated query:%s", query)
-	if dObj, _, err = RunGraphQueryForFiles(token, graphQLUrl,
+	log.Debugf("Generated query:%s", query)
+	if dObj, _, err = RunGraphQueryForFiles(ctx, token, graphQLUrl,
 		subscriptionKey, query); err != nil {
 		errorMsg := fmt.Sprintf("RunGraphQL query for files failed:%s", err.Error())
-		zap.S().Errorf(errorMsg)
-		return errors.New(errorMsg)
-	}
-	zap.S().Infof("Got number of files:%d", len(dObj.Files))
-	if errorList := DownloadFiles(dObj.Files, fileDownloadUrl,
-		token, subscriptionKey, strings.ToUpper(fQuery.FileType),
-		fQuery.OutputLocation, fQuery.OutputPrefix, false); len(errorList) > 0 {
-		for i := 0; i < len(errorList); i++ {
-			zap.S().Errorf(errorList[i].Error())
-
+		log.Errorf(errorMsg)
+		recordQueryMetrics(fQuery, qSummary, nil, err)
+		qSummary.Errors = append(qSummary.Errors, errorMsg)
+		return qSummary, errors.New(errorMsg)
+	}
+	log.Infof("Got number of files:%d", len(dObj.Files))
+	qSummary.FilesFound = len(dObj.Files)
+	downloaded, errorList := DownloadFiles(ctx, dObj.Files, fileDownloadUrl,
+		token, subscriptionKey, fQuery.storageOptions())
+	qSummary.addDownloads(downloaded, errorList)
+	if len(errorList) > 0 {
+		recordQueryMetrics(fQuery, qSummary, downloaded, errorList[0])
+	} else {
+		recordQueryMetrics(fQuery, qSummary, downloaded, nil)
+	}
+	for i := 0; i < len(errorList); i++ {
+		log.Errorf(errorList[i].Error())
+	}
+	//the files that did download are converted even if others failed, unless the run was cancelled
+	if fQuery.Convert.Enabled() {
+		if ctx.Err() != nil {
+			log.Warnf("Skipping conversion of report type:%s as the download was cancelled", fQuery.ReportType)
+		} else if err = ConvertDownloadedFiles(ctx, fQuery.ReportType, convertedInputFiles(downloaded),
+			fQuery.Convert); err != nil {
+			log.Errorf(err.Error())
+			qSummary.Errors = append(qSummary.Errors, err.Error())
+			return qSummary, err
 		}
-		return errors.New("Failed in download of cloud files, please check logs...")
 	}
-	return nil
-}
-
-//createDPR10Query creates a DPR 1.0 query object
-func createDPR10Query(dprCnfg CloudProductionConfig, token string) FileQuery {
-
-	fQuery := buildFileQuery(dprCnfg.RollDays, dprCnfg.DateFrom, dprCnfg.DateTo)
-	fQuery.Field = dprCnfg.FieldName
-	fQuery.ReportType = "DPR10"
-	fQuery.FileType = dprCnfg.Common.Format
-	fQuery.UseUploadedFrom = dprCnfg.UseUploadedFrom
-	fQuery.OutputLocation = dprCnfg.Common.OutputFolder
-	fQuery.OutputPrefix = dprCnfg.Common.FileOutputPrefix
-	return fQuery
-
-}
-
-func createDPR20Query(dprCnfg CloudProductionConfig, token string) FileQuery {
-
-	fQuery := buildFileQuery(dprCnfg.RollDays, dprCnfg.DateFrom, dprCnfg.DateTo)
-	fQuery.Field = dprCnfg.FieldName
-	fQuery.ReportType = "DPR20"
-	fQuery.FileType = dprCnfg.Common.Format
-	fQuery.UseUploadedFrom = dprCnfg.UseUploadedFrom
-	fQuery.OutputLocation = dprCnfg.Common.OutputFolder
-	fQuery.OutputPrefix = dprCnfg.Common.FileOutputPrefix
-	return fQuery
-
-}
-
-func createMPRMLGovQuery(mpmrmlCnfg CloudProductionConfig, token string) FileQuery {
-
-	fQuery := buildFileQuery(mpmrmlCnfg.RollDays, mpmrmlCnfg.DateFrom, mpmrmlCnfg.DateTo)
-	fQuery.Field = mpmrmlCnfg.FieldName
-	fQuery.ReportType = "MPRMLGov"
-	fQuery.FileType = mpmrmlCnfg.Common.Format
-	fQuery.UseUploadedFrom = mpmrmlCnfg.UseUploadedFrom
-	fQuery.OutputLocation = mpmrmlCnfg.Common.OutputFolder
-	fQuery.OutputPrefix = mpmrmlCnfg.Common.FileOutputPrefix
-	return fQuery
-
-}
-
-func createMPRMLPartnerQuery(mpmrmlCnfg CloudProductionConfig, token string) FileQuery {
-
-	fQuery := buildFileQuery(mpmrmlCnfg.RollDays, mpmrmlCnfg.DateFrom, mpmrmlCnfg.DateTo)
-	fQuery.Field = mpmrmlCnfg.FieldName
-	fQuery.ReportType = "MPRMLPartner"
-	fQuery.FileType = mpmrmlCnfg.Common.Format
-	fQuery.UseUploadedFrom = mpmrmlCnfg.UseUploadedFrom
-	fQuery.OutputLocation = mpmrmlCnfg.Common.OutputFolder
-	fQuery.OutputPrefix = mpmrmlCnfg.Common.FileOutputPrefix
-	return fQuery
-
+	if len(errorList) > 0 {
+		return qSummary, errors.New("Failed in download of cloud files, please check logs...")
+	}
+	return qSummary, nil
 }
 
-func createDDRMLQuery(ddrmlConfig CloudDDRMLConfig, token string) FileQuery {
-
-	fQuery := buildFileQuery(ddrmlConfig.RollDays, ddrmlConfig.DateFrom, ddrmlConfig.DateTo)
-	fQuery.ReportType = "DDRML"
-	fQuery.FileType = ddrmlConfig.Common.Format
-	fQuery.UseUploadedFrom = ddrmlConfig.UseUploadedFrom
-	fQuery.OutputLocation = ddrmlConfig.Common.OutputFolder
-	fQuery.OutputPrefix = ddrmlConfig.Common.FileOutputPrefix
-	return fQuery
-
+//isStoredPerWellbore returns true if the file object is of a report type stored once per wellbore, e.g. ddrml
+func isStoredPerWellbore(fObj FileObject) bool {
+	definition, exists := ReportTypeById(fObj.ReportType)
+	return exists && definition.PerWellbore
 }
 
 //BuildOutputPathForReportType will build the output folder path based on the report type
@@ -409,8 +436,9 @@ func BuildOutputPathForReportType(fObj FileObject, filePrefix, outputFolder, for
 	outputFileName := GenerateFileName(fObj, filePrefix, format)
 	outputFolder = outputFolder + string(filepath.Separator)
 
-	//if the report type is a ddrml report we need to generate a subfolder for the wellbore itself which is part of the file
-	if strings.Contains(strings.ToLower(MapReportType(fObj.ReportType)), "ddrml") {
+	//if the report type is a ddrml report we need to generate a subfolder for the wellbore itself which is part of the file,
+	//files without any sources are stored directly in the output folder
+	if isStoredPerWellbore(fObj) && len(fObj.Sources) > 0 {
 		//loop through all of the sources to separate out the data
 		for i := 0; i < len(fObj.Sources); i++ {
 			paths = append(paths, outputFolder+SafeEncodeNameForWinFiles(fObj.Sources[i].Name)+string(filepath.Separator)+outputFileName)
@@ -439,7 +467,8 @@ func DownloadFilesToZip(fileReferences []FileObject, fileURL, token, subscriptio
 
 	//add the required headers
 	if fileURL == "" {
-		return data, errors.New("Missing fileurl...")
+		err = errors.New("Missing fileurl...")
+		return data, err
 	}
 	headers := make(map[string]string)
 	headers["Content-Type"] = "application/json"
@@ -480,11 +509,16 @@ func DownloadFilesToZip(fileReferences []FileObject, fileURL, token, subscriptio
 
 //DownloadFile downloads a single file using the given fileReference id,
 // fileURL for Azure, token from oauth2, subscription key for service and format to download (pdf or xml)
-func DownloadFile(fileReference, fileURL, token, subscriptionKey, format string) ([]byte, error) {
+//the request is aborted if the given context is cancelled
+func DownloadFile(ctx context.Context, fileReference, fileURL, token, subscriptionKey, format string) ([]byte, error) {
 	var resp *resty.Response
 	var err error
 	var data []byte
-	zap.S().Debugf("Downloading file with reference id:%s, format:%s, from url:%s",
+	defer func(start time.Time) {
+		observeDuration(fileDownloadDurationMetric, start, err)
+	}(time.Now())
+	log := LoggerFromContext(ctx)
+	log.Debugf("Downloading file with reference id:%s, format:%s, from url:%s",
 		fileReference, format, fileURL)
 	//add the required headers
 	if fileURL == "" {
@@ -495,17 +529,18 @@ func DownloadFile(fileReference, fileURL, token, subscriptionKey, format string)
 	headers["Ocp-Apim-Subscription-Key"] = subscriptionKey
 	client := resty.New()
 	client.SetTimeout(time.Duration(1 * time.Minute))
-	if ce := zap.S().Desugar().Check(zap.DebugLevel, "debugging"); ce != nil {
+	if ce := log.Desugar().Check(zap.DebugLevel, "debugging"); ce != nil {
 		client.SetDebug(true)
 	}
 	if resp, err = client.R().
+		SetContext(ctx).
 		SetQueryParams(map[string]string{
 			"format": strings.ToLower(format),
 		}).
 		SetHeaders(headers).
 		SetAuthToken(token).
 		Get(fileURL + "/" + fileReference); err != nil {
-		zap.S().Errorf("Failed in get of file, referenceId:%s, format:%s,error:%s",
+		log.Errorf("Failed in get of file, referenceId:%s, format:%s,error:%s",
 			fileReference, format, err.Error())
 		return data, err
 	}
@@ -514,8 +549,9 @@ func DownloadFile(fileReference, fileURL, token, subscriptionKey, format string)
 		//log the error
 		errorMsg := fmt.Sprintf("Failed in get of file server responded with http error > 400, referenceId:%s, format:%s,httpStatusCode:%d,httpStatus:%s,body:%s",
 			fileReference, format, resp.StatusCode(), resp.Status(), string(resp.Body()))
-		zap.S().Errorf(errorMsg)
-		return data, errors.New(errorMsg)
+		log.Errorf(errorMsg)
+		err = errors.New(errorMsg)
+		return data, err
 	}
 	//return the body, it is already closed by the resty library and copied to new array
 
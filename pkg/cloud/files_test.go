@@ -1,14 +1,19 @@
 package cloud
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
 func TestFailDownloadWithInvalidToken(t *testing.T) {
 	token := "XXXXX"
-	if result, err := DownloadFile("XXXX",
+	if result, err := DownloadFile(context.Background(), "XXXX",
 		os.Getenv("AzureFileDownloadUrl"), token, os.Getenv(AzureSubscriptionKeyEnvName), "xml"); err == nil {
 		t.Errorf("Download of file should fail without subscription key:got body,%s", string(result))
 	} else {
@@ -26,7 +31,7 @@ func TestFailDownloadWithInvalidFileId(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in getting token, got error back instead of token:%s", err.Error())
 	}
-	if result, err := DownloadFile("XXXX",
+	if result, err := DownloadFile(context.Background(), "XXXX",
 		os.Getenv("AzureFileDownloadUrl"), token, os.Getenv(AzureSubscriptionKeyEnvName), "xml"); err == nil {
 		t.Errorf("Download of file should fail with 400 bad request:got body,%s", string(result))
 	} else {
@@ -45,7 +50,7 @@ func TestFailDownloadWithInvalidFormat(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in getting token, got error back instead of token:%s", err.Error())
 	}
-	if result, err := DownloadFile(AzureFileIDToTest,
+	if result, err := DownloadFile(context.Background(), AzureFileIDToTest,
 		os.Getenv("AzureFileDownloadUrl"), token, os.Getenv(AzureSubscriptionKeyEnvName), "XML"); err == nil {
 		t.Errorf("Download of file should fail with 400 bad request:got body,%s", string(result))
 	} else {
@@ -64,7 +69,7 @@ func TestDownloadXMLFile(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in getting token, got error back instead of token:%s", err.Error())
 	}
-	if result, err := DownloadFile(AzureFileIDToTest,
+	if result, err := DownloadFile(context.Background(), AzureFileIDToTest,
 		os.Getenv("AzureFileDownloadUrl"), token, os.Getenv(AzureSubscriptionKeyEnvName), "xml"); err != nil {
 		t.Errorf("Download of file should not fail:%s", err.Error())
 	} else {
@@ -83,7 +88,7 @@ func TestDownloadPDFFile(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in getting token, got error back instead of token:%s", err.Error())
 	}
-	if result, err := DownloadFile(AzureFileIDToTest,
+	if result, err := DownloadFile(context.Background(), AzureFileIDToTest,
 		os.Getenv("AzureFileDownloadUrl"), token, os.Getenv(AzureSubscriptionKeyEnvName), "pdf"); err != nil {
 		t.Errorf("Download of file should not fail:%s", err.Error())
 	} else {
@@ -116,13 +121,13 @@ func TestQueryAndDownloadXMLFiles(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files, got error back instead of token:%s", err.Error())
 	}
-	if dObj, _, err = RunGraphQueryForFiles(token, os.Getenv(AzureGraphUrlEnvName), os.Getenv(AzureSubscriptionKeyEnvName), query); err != nil {
+	if dObj, _, err = RunGraphQueryForFiles(context.Background(), token, os.Getenv(AzureGraphUrlEnvName), os.Getenv(AzureSubscriptionKeyEnvName), query); err != nil {
 		t.Errorf("Resty post for files should not fail, failed with:%s", err.Error())
 		return
 	}
 	t.Logf("Got number of files:%d", len(dObj.Files))
-	if errorList := DownloadFiles(dObj.Files, os.Getenv("AzureFileDownloadUrl"),
-		token, os.Getenv(AzureSubscriptionKeyEnvName), "XML", LocalStorageLocation, "ASGARD", false); len(errorList) > 0 {
+	if _, errorList := DownloadFiles(context.Background(), dObj.Files, os.Getenv("AzureFileDownloadUrl"),
+		token, os.Getenv(AzureSubscriptionKeyEnvName), StorageOptions{Format: "XML", OutputFolder: LocalStorageLocation, FilePrefix: "ASGARD"}); len(errorList) > 0 {
 		//download should not fail check errors
 		for i := 0; i < len(errorList); i++ {
 			t.Error(err.Error())
@@ -153,7 +158,7 @@ func TestQueryAndDownloadPDFFiles(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files, got error back instead of token:%s", err.Error())
 	}
-	if dObj, _, err = RunGraphQueryForFiles(token,
+	if dObj, _, err = RunGraphQueryForFiles(context.Background(), token,
 		os.Getenv(AzureGraphUrlEnvName),
 		os.Getenv(AzureSubscriptionKeyEnvName),
 		query); err != nil {
@@ -161,10 +166,10 @@ func TestQueryAndDownloadPDFFiles(t *testing.T) {
 		return
 	}
 	t.Logf("Got number of files:%d", len(dObj.Files))
-	if errorList := DownloadFiles(dObj.Files,
+	if _, errorList := DownloadFiles(context.Background(), dObj.Files,
 		os.Getenv("AzureFileDownloadUrl"),
 		token, os.Getenv(AzureSubscriptionKeyEnvName),
-		"PDF", LocalStorageLocation, "ASGARD", false); len(errorList) > 0 {
+		StorageOptions{Format: "PDF", OutputFolder: LocalStorageLocation, FilePrefix: "ASGARD"}); len(errorList) > 0 {
 		//download should not fail check errors
 		for i := 0; i < len(errorList); i++ {
 			t.Error(errorList[i].Error())
@@ -223,13 +228,13 @@ func TestQueryAndDownloadDDRMLPDFFiles(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files, got error back instead of token:%s", err.Error())
 	}
-	if dObj, _, err = RunGraphQueryForFiles(token, os.Getenv(AzureGraphUrlEnvName), os.Getenv(AzureSubscriptionKeyEnvName), query); err != nil {
+	if dObj, _, err = RunGraphQueryForFiles(context.Background(), token, os.Getenv(AzureGraphUrlEnvName), os.Getenv(AzureSubscriptionKeyEnvName), query); err != nil {
 		t.Errorf("Resty post for files should not fail, failed with:%s", err.Error())
 		return
 	}
 	t.Logf("Got number of files:%d", len(dObj.Files))
-	if errorList := DownloadFiles(dObj.Files, os.Getenv("AzureFileDownloadUrl"),
-		token, os.Getenv(AzureSubscriptionKeyEnvName), "PDF", LocalStorageLocation, "ASGARD", false); len(errorList) > 0 {
+	if _, errorList := DownloadFiles(context.Background(), dObj.Files, os.Getenv("AzureFileDownloadUrl"),
+		token, os.Getenv(AzureSubscriptionKeyEnvName), StorageOptions{Format: "PDF", OutputFolder: LocalStorageLocation, FilePrefix: "ASGARD"}); len(errorList) > 0 {
 		//download should not fail check errors
 		for i := 0; i < len(errorList); i++ {
 			t.Error(errorList[i].Error())
@@ -238,3 +243,145 @@ func TestQueryAndDownloadDDRMLPDFFiles(t *testing.T) {
 	}
 
 }
+
+//TestDownloadFilesCancelled will test that a cancelled context stops the download
+//and that no files are written to the output folder
+func TestDownloadFilesCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<drillReports></drillReports>"))
+	}))
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "cancelled")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	files := []FileObject{{FileName: "a.xml", FileReference: "1", ReportType: 1},
+		{FileName: "b.xml", FileReference: "2", ReportType: 1}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	downloaded, errorList := DownloadFiles(ctx, files, server.URL, "token", "key",
+		StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST"})
+	if len(downloaded) != 2 || !downloaded[0].Cancelled || !downloaded[1].Cancelled || len(downloaded[0].Paths) != 0 {
+		t.Errorf("Expected both files returned as cancelled without paths, got:%v", downloaded)
+	}
+	if len(errorList) == 0 {
+		t.Errorf("Expected cancellation error back but got none")
+	}
+	//cancelled files are counted separately from the failed files
+	qSummary := QuerySummary{FilesFound: len(files)}
+	qSummary.addDownloads(downloaded, errorList)
+	if qSummary.FilesCancelled != 2 || qSummary.FilesFailed != 0 || qSummary.FilesDownloaded != 0 {
+		t.Errorf("Expected 2 cancelled and no failed files, got cancelled:%d,failed:%d", qSummary.FilesCancelled,
+			qSummary.FilesFailed)
+	}
+	if written, _ := filepath.Glob(filepath.Join(outputFolder, "*")); len(written) != 0 {
+		t.Errorf("Expected no files written after cancel, got:%v", written)
+	}
+}
+
+//TestDownloadFilesWithContext will test downloading files against a local file server
+func TestDownloadFilesWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<objects></objects>"))
+	}))
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	files := []FileObject{{FileName: "a.xml", FileReference: "1", ReportType: 2,
+		Created: "2020-03-01T10:44:51.526Z"}}
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key",
+		StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST"})
+	if len(errorList) > 0 {
+		t.Fatalf("Download should not fail:%s", errorList[0].Error())
+	}
+	if len(downloaded) != 1 || len(downloaded[0].Paths) != 1 {
+		t.Fatalf("Expected one downloaded file, got:%v", downloaded)
+	}
+	if !FileOrFolderExists(downloaded[0].Paths[0]) || FileOrFolderExists(downloaded[0].Paths[0]+".part") {
+		t.Errorf("Expected file to be written without temporary file left:%s", downloaded[0].Paths[0])
+	}
+}
+
+//TestDownloadDDRMLFilesLinkModes will test that a ddrml file covering several wellbores is written to every
+//wellbore folder using the given link mode
+func TestDownloadDDRMLFilesLinkModes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<drillReports></drillReports>"))
+	}))
+	defer server.Close()
+	files := []FileObject{{FileName: "rig.xml", FileReference: "1", ReportType: 3,
+		Sources: []DataSource{{Name: "NO 1/1-1"}, {Name: "NO 1/1-2"}}}}
+	for _, linkMode := range []string{"", LinkModeCopy, LinkModeHardlink, LinkModeSymlink} {
+		outputFolder, err := ioutil.TempDir("", "linkmode")
+		if err != nil {
+			t.Fatalf("Failed in creating temp folder:%s", err.Error())
+		}
+		defer os.RemoveAll(outputFolder)
+		downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key",
+			StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST", LinkMode: linkMode})
+		if len(errorList) > 0 {
+			t.Fatalf("Download with linkMode:%s should not fail:%s", linkMode, errorList[0].Error())
+		}
+		if len(downloaded) != 1 || len(downloaded[0].Paths) != 2 {
+			t.Fatalf("Expected one file written to two wellbore folders with linkMode:%s, got:%v", linkMode, downloaded)
+		}
+		first, _ := os.Stat(downloaded[0].Paths[0])
+		second, err := os.Lstat(downloaded[0].Paths[1])
+		if err != nil {
+			t.Fatalf("Expected file in second wellbore folder with linkMode:%s:%s", linkMode, err.Error())
+		}
+		switch linkMode {
+		case LinkModeHardlink:
+			if !os.SameFile(first, second) {
+				t.Errorf("Expected hard linked files to be the same file")
+			}
+		case LinkModeSymlink:
+			if second.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("Expected a symbolic link in second wellbore folder")
+			}
+		default:
+			if os.SameFile(first, second) || second.Mode()&os.ModeSymlink != 0 {
+				t.Errorf("Expected separate copies with linkMode:%s", linkMode)
+			}
+		}
+		if data, err := ioutil.ReadFile(downloaded[0].Paths[1]); err != nil || string(data) != "<drillReports></drillReports>" {
+			t.Errorf("Expected file content to be readable through second wellbore folder with linkMode:%s", linkMode)
+		}
+	}
+}
+
+//TestDownloadDDRMLFileWithoutSources will test that ddrml files with no sources are written to the output folder
+func TestDownloadDDRMLFileWithoutSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<drillReports></drillReports>"))
+	}))
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "nosources")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	files := []FileObject{{FileName: "rig.xml", FileReference: "1", ReportType: 3}}
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key",
+		StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST"})
+	if len(errorList) > 0 {
+		t.Fatalf("Download should not fail:%s", errorList[0].Error())
+	}
+	if len(downloaded) != 1 || len(downloaded[0].Paths) != 1 ||
+		filepath.Dir(downloaded[0].Paths[0]) != filepath.Clean(outputFolder) {
+		t.Errorf("Expected file without sources in output folder root, got:%v", downloaded)
+	}
+}
+
+func TestVerifyLinkMode(t *testing.T) {
+	if err := verifyLinkMode("HardLink"); err != nil {
+		t.Errorf("Hardlink should be a valid link mode:%s", err.Error())
+	}
+	if err := verifyLinkMode("junction"); err == nil {
+		t.Errorf("Unknown link mode should give an error")
+	}
+}
@@ -0,0 +1,87 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//QuarantineFolderName is the folder below the output folder used for files failing the integrity check
+//when no quarantineFolder is configured
+var QuarantineFolderName = "quarantine"
+
+//ExpectedRootElement returns the root element an xml file of the report type must have, empty if unknown
+func ExpectedRootElement(reportType string) string {
+	definition, _ := ReportTypeByName(reportType)
+	return definition.RootElement
+}
+
+//CheckXMLIntegrity checks that the data is a well formed xml document with the root element expected for the report type,
+//this catches e.g. html error pages or truncated files returned with http status ok
+func CheckXMLIntegrity(data []byte, reportType string) error {
+	rootElement := ""
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	//the encoding is only relevant for reading the content, not for checking the structure
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("File is not well formed xml:%s", err.Error())
+		}
+		if element, ok := token.(xml.StartElement); ok && rootElement == "" {
+			rootElement = element.Name.Local
+			expected := ExpectedRootElement(reportType)
+			if expected != "" && rootElement != expected {
+				return fmt.Errorf("Unexpected root element:%s for report type:%s, expected:%s",
+					rootElement, reportType, expected)
+			}
+		}
+	}
+	if rootElement == "" {
+		return fmt.Errorf("File does not contain any xml elements")
+	}
+	return nil
+}
+
+//quarantineFolder returns the folder to store files failing the integrity check in
+func (storage StorageOptions) quarantineFolder() string {
+	if storage.QuarantineFolder != "" {
+		return storage.QuarantineFolder
+	}
+	return filepath.Join(storage.OutputFolder, QuarantineFolderName)
+}
+
+//QuarantineFile stores a file failing the integrity check in the quarantine folder, together with a
+//.reason.txt file recording the file details and why it failed. Returns the path of the quarantined file
+func QuarantineFile(ctx context.Context, fObj FileObject, data []byte, reason error, storage StorageOptions) (string, error) {
+	var err error
+	log := LoggerFromContext(ctx)
+	folder := storage.quarantineFolder()
+	if err = os.MkdirAll(folder, os.ModePerm); err != nil {
+		return "", err
+	}
+	path := filepath.Join(folder, GenerateFileName(fObj, storage.FilePrefix, storage.Format))
+	if err = common.Write2FileAtomic(path, data); err != nil {
+		return "", err
+	}
+	reasonText := fmt.Sprintf("time:%s\nfileReferenceId:%s\nfileName:%s\ncreated:%s\nreportType:%s\nperiod:%s-%s\nbytes:%d\nreason:%s\n",
+		time.Now().Format(time.RFC3339), fObj.FileReference, fObj.FileName, fObj.Created, MapReportType(fObj.ReportType),
+		fObj.MetaData.PeriodStart, fObj.MetaData.PeriodEnd, len(data), reason.Error())
+	if err = common.Write2FileAtomic(path+".reason.txt", []byte(reasonText)); err != nil {
+		return path, err
+	}
+	log.Warnf("Quarantined file with referenceId:%s,fileName:%s to:%s, reason:%s",
+		fObj.FileReference, fObj.FileName, path, reason.Error())
+	return path, nil
+}
@@ -0,0 +1,64 @@
+package cloud
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckXMLIntegrity(t *testing.T) {
+	if err := CheckXMLIntegrity([]byte(`<?xml version="1.0" encoding="utf-8"?><drillReports><drillReport/></drillReports>`), "DDRML"); err != nil {
+		t.Errorf("Valid ddrml should pass the integrity check:%s", err.Error())
+	}
+	if err := CheckXMLIntegrity([]byte(`<?xml version="1.0" encoding="ISO-8859-1"?><WITSMLComposite></WITSMLComposite>`), "DPR10"); err != nil {
+		t.Errorf("Valid dpr 1.0 should pass the integrity check:%s", err.Error())
+	}
+	if err := CheckXMLIntegrity([]byte(`<objects><object>`), "DPR20"); err == nil {
+		t.Errorf("Truncated xml should fail the integrity check")
+	}
+	if err := CheckXMLIntegrity([]byte(`<objects></objects>`), "DDRML"); err == nil {
+		t.Errorf("Wrong root element should fail the integrity check")
+	}
+	if err := CheckXMLIntegrity([]byte(`<!DOCTYPE html><html><body><h1>Error</h1><br></body></html>`), "MPRMLGov"); err == nil {
+		t.Errorf("Html error page should fail the integrity check")
+	}
+	if err := CheckXMLIntegrity([]byte(""), "DPR20"); err == nil {
+		t.Errorf("Empty file should fail the integrity check")
+	}
+}
+
+//TestDownloadFilesQuarantine will test that a file failing the integrity check is stored in the quarantine folder
+//together with the reason and not in the output folder
+func TestDownloadFilesQuarantine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>Service unavailable</body></html>"))
+	}))
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "quarantine")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	files := []FileObject{{FileName: "a.xml", FileReference: "1", ReportType: 2, Created: "2020-03-01T10:44:51.526Z"}}
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key",
+		StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST"})
+	if len(downloaded) != 0 || len(errorList) != 1 {
+		t.Fatalf("Expected file to fail integrity check, got downloaded:%v errors:%v", downloaded, errorList)
+	}
+	quarantined, _ := filepath.Glob(filepath.Join(outputFolder, QuarantineFolderName, "*.xml"))
+	if len(quarantined) != 1 {
+		t.Fatalf("Expected one file in quarantine, got:%v", quarantined)
+	}
+	reason, err := ioutil.ReadFile(quarantined[0] + ".reason.txt")
+	if err != nil || !strings.Contains(string(reason), "Unexpected root element:html") {
+		t.Errorf("Expected reason file with root element error, got:%s", string(reason))
+	}
+	if stored, _ := filepath.Glob(filepath.Join(outputFolder, "*.xml")); len(stored) != 0 {
+		t.Errorf("Expected no files in output folder, got:%v", stored)
+	}
+}
@@ -0,0 +1,56 @@
+package cloud
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerContextKey struct{}
+
+//ContextWithLogger returns a copy of the context carrying the given logger,
+//functions in this package taking a context will log through it
+func ContextWithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+//LoggerFromContext returns the logger carried by the context or the global logger if none is set
+func LoggerFromContext(ctx context.Context) *zap.SugaredLogger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*zap.SugaredLogger); ok && logger != nil {
+		return logger
+	}
+	return zap.S()
+}
+
+//NewBlockLogger creates a child of the global logger that also writes everything to the given log file,
+//meaning that entries still end up in the global log as before. The returned function flushes and closes the file
+func NewBlockLogger(logFile string) (*zap.SugaredLogger, func(), error) {
+	var err error
+	var sink zapcore.WriteSyncer
+	var closeSink func()
+	if err = os.MkdirAll(filepath.Dir(logFile), os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+	if sink, closeSink, err = zap.Open(logFile); err != nil {
+		return nil, nil, err
+	}
+	//follow the global logger and include debug entries only if it does
+	level := zapcore.InfoLevel
+	if zap.L().Core().Enabled(zapcore.DebugLevel) {
+		level = zapcore.DebugLevel
+	}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+	fileCore := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, level)
+	logger := zap.L().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, fileCore)
+	})).Sugar()
+	closeLogger := func() {
+		logger.Sync()
+		closeSink()
+	}
+	return logger, closeLogger, nil
+}
@@ -0,0 +1,41 @@
+package cloud
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//TestBlockLoggerWritesToOwnFile will test that a block logger carried in the context
+//writes its entries to its own log file
+func TestBlockLoggerWritesToOwnFile(t *testing.T) {
+	logFolder, err := ioutil.TempDir("", "blocklog")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(logFolder)
+	logFile := filepath.Join(logFolder, "sub", "sverdrup.json")
+	logger, closeLogger, err := NewBlockLogger(logFile)
+	if err != nil {
+		t.Fatalf("Failed in creating block logger:%s", err.Error())
+	}
+	ctx := ContextWithLogger(context.Background(), logger)
+	LoggerFromContext(ctx).Infof("Processing field:%s", "JOHAN SVERDRUP")
+	closeLogger()
+	data, err := ioutil.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed in reading block log file:%s", err.Error())
+	}
+	if !strings.Contains(string(data), "JOHAN SVERDRUP") {
+		t.Errorf("Block log file is missing the logged entry, got:%s", string(data))
+	}
+}
+
+func TestLoggerFromContextFallsBackToGlobal(t *testing.T) {
+	if LoggerFromContext(context.Background()) == nil {
+		t.Errorf("Expected the global logger back when no logger is set in the context")
+	}
+}
@@ -0,0 +1,73 @@
+package cloud
+
+import (
+	"time"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/metrics"
+)
+
+//metrics for the download runs, registered in the default registry served with -metrics-addr
+var (
+	filesDownloadedMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_files_downloaded_total",
+		"Number of files downloaded and stored per report type", "report_type")
+	filesFailedMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_files_failed_total",
+		"Number of files found that failed to be downloaded or stored per report type", "report_type")
+	filesCancelledMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_files_cancelled_total",
+		"Number of files found that were not downloaded as the run was cancelled per report type", "report_type")
+	filesSkippedMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_files_skipped_total",
+		"Number of files skipped as a newer version is already stored per report type", "report_type")
+	bytesDownloadedMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_downloaded_bytes_total",
+		"Number of bytes downloaded per report type", "report_type")
+	graphQueryDurationMetric = metrics.DefaultRegistry.NewHistogramVec("subsurface_graphql_query_duration_seconds",
+		"Duration of the graphql queries for files", metrics.DefaultBuckets, "result")
+	fileDownloadDurationMetric = metrics.DefaultRegistry.NewHistogramVec("subsurface_file_download_duration_seconds",
+		"Duration of the download of single files", metrics.DefaultBuckets, "result")
+	tokenRequestsMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_token_requests_total",
+		"Number of tokens requested from the token url", "result")
+	blockLastSuccessMetric = metrics.DefaultRegistry.NewGaugeVec("subsurface_block_last_success_timestamp_seconds",
+		"Unix time of the last run where all files of the block and report type were downloaded", "block", "report_type")
+	runsMetric = metrics.DefaultRegistry.NewCounterVec("subsurface_download_runs_total",
+		"Number of download runs by result, success, failed or cancelled", "result")
+)
+
+//resultLabel returns the result label value for an operation
+func resultLabel(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "success"
+}
+
+//observeDuration adds the time since start to the histogram labelled with the result
+func observeDuration(histogram *metrics.HistogramVec, start time.Time, err error) {
+	histogram.ObserveSince(start, resultLabel(err))
+}
+
+//recordQueryMetrics records the files downloaded, failed, cancelled and skipped for a query and the time of the last
+//successful run of the block
+func recordQueryMetrics(fQuery FileQuery, qSummary QuerySummary, downloaded []DownloadedFile, err error) {
+	bytes := 0
+	for i := 0; i < len(downloaded); i++ {
+		bytes = bytes + downloaded[i].Bytes
+	}
+	filesDownloadedMetric.Add(float64(qSummary.FilesDownloaded), fQuery.ReportType)
+	filesFailedMetric.Add(float64(qSummary.FilesFailed), fQuery.ReportType)
+	filesCancelledMetric.Add(float64(qSummary.FilesCancelled), fQuery.ReportType)
+	filesSkippedMetric.Add(float64(qSummary.FilesSkipped), fQuery.ReportType)
+	bytesDownloadedMetric.Add(float64(bytes), fQuery.ReportType)
+	if err == nil {
+		blockLastSuccessMetric.SetToCurrentTime(fQuery.Block, fQuery.ReportType)
+	}
+}
+
+//recordRunMetrics records the result of a complete download run
+func recordRunMetrics(summary DownloadSummary, errList []error) {
+	switch {
+	case summary.Cancelled:
+		runsMetric.Inc("cancelled")
+	case len(errList) > 0:
+		runsMetric.Inc("failed")
+	default:
+		runsMetric.Inc("success")
+	}
+}
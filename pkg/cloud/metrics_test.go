@@ -0,0 +1,74 @@
+package cloud
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+//TestRunQueryMetrics will test that the files downloaded, failed and bytes are counted per report type and
+//that the last success time is only set for the block when all files were downloaded
+func TestRunQueryMetrics(t *testing.T) {
+	fileData := `<?xml version="1.0" encoding="utf-8"?><objects><object/></objects>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"data":{"files":[
+				{"fileName":"a.xml","fileReferenceId":"1","created":"2020-03-01T10:00:00Z","reportType":2,
+				"metadata":{"periodStart":"2020-02-29","periodEnd":"2020-03-01","reportId":"1"}},
+				{"fileName":"b.xml","fileReferenceId":"2","created":"2020-03-02T10:00:00Z","reportType":2,
+				"metadata":{"periodStart":"2020-03-01","periodEnd":"2020-03-02","reportId":"2"}}]}}`))
+		case r.URL.Path == "/files/1":
+			w.Write([]byte(fileData))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	fQuery := FileQuery{TimeFrom: "2020-03-01", TimeTo: "2020-03-02", Field: "TEST", FileType: "XML",
+		ReportType: "DPR20", OutputLocation: outputFolder, Block: "dpr:METRICS_TEST"}
+	downloadedBefore := filesDownloadedMetric.Value("DPR20")
+	failedBefore := filesFailedMetric.Value("DPR20")
+	bytesBefore := bytesDownloadedMetric.Value("DPR20")
+	if _, err = RunQueryAndDownloadFiles(context.Background(), fQuery, "token", "key", server.URL,
+		server.URL+"/files"); err == nil {
+		t.Fatalf("Expected download of second file to fail")
+	}
+	if delta := filesDownloadedMetric.Value("DPR20") - downloadedBefore; delta != 1 {
+		t.Errorf("Expected 1 file downloaded, got:%f", delta)
+	}
+	if delta := filesFailedMetric.Value("DPR20") - failedBefore; delta != 1 {
+		t.Errorf("Expected 1 file failed, got:%f", delta)
+	}
+	if delta := bytesDownloadedMetric.Value("DPR20") - bytesBefore; delta != float64(len(fileData)) {
+		t.Errorf("Expected %d bytes downloaded, got:%f", len(fileData), delta)
+	}
+	if blockLastSuccessMetric.Value("dpr:METRICS_TEST", "DPR20") != 0 {
+		t.Errorf("Last success time should not be set for a block with failed downloads")
+	}
+	//only the first file found this time
+	fQuery.TimeFrom = "2020-02-29"
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"data":{"files":[{"fileName":"a.xml","fileReferenceId":"1","created":"2020-03-01T10:00:00Z",
+				"reportType":2,"metadata":{"periodStart":"2020-02-29","periodEnd":"2020-03-01","reportId":"1"}}]}}`))
+			return
+		}
+		w.Write([]byte(fileData))
+	})
+	if _, err = RunQueryAndDownloadFiles(context.Background(), fQuery, "token", "key", server.URL,
+		server.URL+"/files"); err != nil {
+		t.Fatalf("Expected download to succeed:%s", err.Error())
+	}
+	if blockLastSuccessMetric.Value("dpr:METRICS_TEST", "DPR20") == 0 {
+		t.Errorf("Last success time should be set for the block")
+	}
+}
@@ -0,0 +1,57 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
+	"go.uber.org/zap"
+)
+
+//NotifyProgramName is the program name used in notifications sent for download runs
+var NotifyProgramName = "subsurfaceCloudDownload"
+
+//notifyTimeout is the time allowed for sending a notification, a cancelled run is notified as well
+//so the notification does not use the context of the run
+var notifyTimeout = 1 * time.Minute
+
+//buildFailureNotification builds a notification of the failures in the download run, the errors of each query
+//are prefixed with the report type and field so that it is possible to see which block failed
+func buildFailureNotification(summary DownloadSummary) notify.Notification {
+	n := notify.NewNotification(NotifyProgramName, "Download of files from Collabor8 failed")
+	if summary.Cancelled {
+		n.Title = "Download of files from Collabor8 was cancelled"
+	}
+	n.Details = append(n.Details, fmt.Sprintf("Started:%s, finished:%s", summary.Started.Format(time.RFC3339),
+		summary.Finished.Format(time.RFC3339)))
+	n.Details = append(n.Details, fmt.Sprintf("Files downloaded:%d, failed:%d, cancelled:%d", summary.FilesDownloaded(),
+		summary.FilesFailed(), summary.FilesCancelled()))
+	for i := 0; i < len(summary.Queries); i++ {
+		for x := 0; x < len(summary.Queries[i].Errors); x++ {
+			n.Failures = append(n.Failures, fmt.Sprintf("%s %s:%s", summary.Queries[i].ReportType,
+				summary.Queries[i].Field, summary.Queries[i].Errors[x]))
+		}
+	}
+	//errors outside of the queries, e.g. configuration or authentication
+	if len(n.Failures) == 0 {
+		n.Failures = append(n.Failures, summary.Errors...)
+	}
+	return n
+}
+
+//notifyDownloadFailures sends a notification of the failures in the download run if notify is configured
+func notifyDownloadFailures(cnfg notify.Config, summary DownloadSummary) {
+	if !cnfg.Enabled() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	errorList := notify.Send(ctx, cnfg, buildFailureNotification(summary))
+	for i := 0; i < len(errorList); i++ {
+		zap.S().Errorf("Failed in sending failure notification:%s", errorList[i].Error())
+	}
+	if len(errorList) == 0 {
+		zap.S().Infof("Sent notification of download failures")
+	}
+}
@@ -0,0 +1,54 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/notify"
+)
+
+//TestProcessAndRunDownloadNotifiesFailure will test that a failing run posts the failures to the webhook
+func TestProcessAndRunDownloadNotifiesFailure(t *testing.T) {
+	payloads := make(chan notify.Notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notify.Notification
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &n); err != nil {
+			t.Errorf("Webhook payload is not json:%s", string(body))
+		}
+		payloads <- n
+	}))
+	defer server.Close()
+	downloadConfig := CloudDownload{
+		DPRS:   []CloudProductionConfig{{FieldName: "TEST", RollDays: MaxRollDays + 1, Common: CloudCommonConfig{Format: "XML"}}},
+		Notify: notify.Config{Webhook: notify.WebhookConfig{URL: server.URL}},
+	}
+	if _, errList := ProcessAndRunDownload(context.Background(), downloadConfig); len(errList) == 0 {
+		t.Fatalf("Expected invalid configuration to fail")
+	}
+	n := <-payloads
+	if n.Program != NotifyProgramName || len(n.Failures) != 1 ||
+		!strings.Contains(n.Failures[0], "Invalid number of rolldays") {
+		t.Errorf("Unexpected notification:%v", n)
+	}
+}
+
+func TestBuildFailureNotification(t *testing.T) {
+	summary := DownloadSummary{Queries: []QuerySummary{
+		{ReportType: "DPR20", Field: "TEST", FilesFound: 2, FilesDownloaded: 1, FilesFailed: 1,
+			Errors: []string{"Failed in download of file with referenceId:2"}},
+		{ReportType: "DPR10", Field: "TEST", FilesFound: 1, FilesDownloaded: 1}},
+		Errors: []string{"Failed in download of cloud files, please check logs..."}}
+	n := buildFailureNotification(summary)
+	if len(n.Failures) != 1 || n.Failures[0] != "DPR20 TEST:Failed in download of file with referenceId:2" {
+		t.Errorf("Expected query errors as failures, got:%v", n.Failures)
+	}
+	if !strings.Contains(strings.Join(n.Details, "\n"), "Files downloaded:2, failed:1") {
+		t.Errorf("Expected file counts in details, got:%v", n.Details)
+	}
+}
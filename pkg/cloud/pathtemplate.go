@@ -0,0 +1,157 @@
+package cloud
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//PathTemplateData is the data available in a pathTemplate, all fields of the file object and its metadata
+//can be used directly, e.g. {{.FileReference}} or {{.PeriodStart}}. Names are sanitised so they can not add folders
+type PathTemplateData struct {
+	FileObject
+	FileMetaData
+	Field          string //field name used in the query, empty for ddrml queries
+	Prefix         string //the fileOutputPrefix of the block
+	ReportTypeName string //report type as text e.g. DPR20 or DDRML
+	Source         string //name of the wellbore for ddrml files, one file is stored per wellbore
+	BaseName       string //file name without extension
+	Ext            string //file extension based on the format downloaded, xml or pdf
+}
+
+//functions available in path templates, dates can be given as RFC3339 timestamps or as yyyy-mm-dd
+var pathTemplateFuncs = template.FuncMap{
+	"year":  func(date string) string { return formatTemplateDate(date, "2006") },
+	"month": func(date string) string { return formatTemplateDate(date, "01") },
+	"day":   func(date string) string { return formatTemplateDate(date, "02") },
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+//formatTemplateDate parses the date and returns it formatted using the given layout, empty if the date can not be parsed
+func formatTemplateDate(date, layout string) string {
+	if timeObj, err := time.Parse(time.RFC3339, date); err == nil {
+		return timeObj.Format(layout)
+	}
+	if timeObj, err := time.Parse("2006-01-02", date); err == nil {
+		return timeObj.Format(layout)
+	}
+	return ""
+}
+
+//ParsePathTemplate parses a path template, the template uses / as folder separator on all platforms
+func ParsePathTemplate(pathTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("pathTemplate").Funcs(pathTemplateFuncs).Option("missingkey=error").Parse(pathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid pathTemplate:%s, error:%s", pathTemplate, err.Error())
+	}
+	return tmpl, nil
+}
+
+//verifyPathTemplate checks that the path template, if set, can be parsed
+func verifyPathTemplate(pathTemplate string) error {
+	if pathTemplate == "" {
+		return nil
+	}
+	_, err := ParsePathTemplate(pathTemplate)
+	return err
+}
+
+//newPathTemplateData builds the template data for a file object with all names sanitised
+func newPathTemplateData(fObj FileObject, storage StorageOptions) PathTemplateData {
+	baseFileName, _ := common.GetFileNameAndExtension(fObj.FileName)
+	fObj.FileName = SafeEncodeNameForWinFiles(fObj.FileName)
+	fObj.FileReference = SafeEncodeNameForWinFiles(fObj.FileReference)
+	var sources []DataSource
+	for i := 0; i < len(fObj.Sources); i++ {
+		source := fObj.Sources[i]
+		source.Name = SafeEncodeNameForWinFiles(source.Name)
+		sources = append(sources, source)
+	}
+	fObj.Sources = sources
+	metaData := fObj.MetaData
+	metaData.ReportId = SafeEncodeNameForWinFiles(metaData.ReportId)
+	metaData.ReportStatus = SafeEncodeNameForWinFiles(metaData.ReportStatus)
+	fObj.MetaData = metaData
+	return PathTemplateData{
+		FileObject:     fObj,
+		FileMetaData:   metaData,
+		Field:          SafeEncodeNameForWinFiles(storage.Field),
+		Prefix:         SafeEncodeNameForWinFiles(storage.FilePrefix),
+		ReportTypeName: MapReportType(fObj.ReportType),
+		BaseName:       SafeEncodeNameForWinFiles(baseFileName),
+		Ext:            strings.ToLower(storage.Format),
+	}
+}
+
+//BuildOutputPathsFromTemplate builds the output paths for a file object using the path template in the storage options,
+//ddrml files get one path per wellbore source unless the template gives the same path for all of them
+func BuildOutputPathsFromTemplate(fObj FileObject, storage StorageOptions) ([]string, error) {
+	var paths []string
+	tmpl, err := ParsePathTemplate(storage.PathTemplate)
+	if err != nil {
+		return paths, err
+	}
+	data := newPathTemplateData(fObj, storage)
+	sources := []string{""}
+	if len(data.Sources) > 0 {
+		sources = nil
+		for i := 0; i < len(data.Sources); i++ {
+			sources = append(sources, data.Sources[i].Name)
+		}
+	}
+	//only report types stored per wellbore, e.g. ddrml, get one path per source, other report types use the first source if any
+	if !isStoredPerWellbore(fObj) {
+		sources = sources[:1]
+	}
+	for i := 0; i < len(sources); i++ {
+		var path string
+		data.Source = sources[i]
+		if path, err = renderPathTemplate(tmpl, data, storage.OutputFolder); err != nil {
+			return nil, err
+		}
+		if !containsString(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+//renderPathTemplate executes the template and builds a path below the output folder, each folder and the file name
+//are sanitised and relative references to parent folders are rejected
+func renderPathTemplate(tmpl *template.Template, data PathTemplateData, outputFolder string) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Failed in executing pathTemplate for file:%s, error:%s", data.FileName, err.Error())
+	}
+	segments := []string{outputFolder}
+	for _, segment := range strings.Split(strings.TrimSpace(buf.String()), "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" || segment == "." {
+			continue
+		}
+		if segment == ".." {
+			return "", fmt.Errorf("pathTemplate gave path:%s for file:%s, references to parent folders are not allowed",
+				buf.String(), data.FileName)
+		}
+		segments = append(segments, SafeEncodeNameForWinFiles(segment))
+	}
+	if len(segments) == 1 {
+		return "", fmt.Errorf("pathTemplate gave an empty path for file:%s", data.FileName)
+	}
+	return filepath.Join(segments...), nil
+}
+
+func containsString(values []string, value string) bool {
+	for i := 0; i < len(values); i++ {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package cloud
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildOutputPathsFromTemplate(t *testing.T) {
+	fObj := FileObject{
+		FileName:      "DPR for JOHAN SVERDRUP.xml",
+		FileReference: "12345",
+		Created:       "2020-03-01T10:44:51.526Z",
+		ReportType:    2,
+		MetaData: FileMetaData{
+			FileType:    "XML",
+			PeriodStart: "2020-02-28",
+			PeriodEnd:   "2020-02-29",
+			ReportId:    "R:1",
+		},
+	}
+	storage := StorageOptions{Format: "XML", OutputFolder: "archive", Field: "JOHAN SVERDRUP",
+		PathTemplate: "{{.Field}}/{{.ReportTypeName}}/{{year .PeriodStart}}/{{month .PeriodStart}}/{{.PeriodStart}}_{{.ReportId}}.{{.Ext}}"}
+	paths, err := BuildOutputPaths(fObj, storage)
+	if err != nil {
+		t.Fatalf("Failed in building paths from template:%s", err.Error())
+	}
+	expected := filepath.Join("archive", "JOHAN SVERDRUP", "DPR20", "2020", "02", "2020-02-28_R_1.xml")
+	if len(paths) != 1 || paths[0] != expected {
+		t.Errorf("Expected path:%s, got:%v", expected, paths)
+	}
+}
+
+func TestBuildOutputPathsFromTemplateDDRMLSources(t *testing.T) {
+	fObj := FileObject{FileName: "rig.xml", FileReference: "1", ReportType: 3,
+		Created: "2020-03-01T10:44:51.526Z",
+		Sources: []DataSource{{Name: "NO 1/1-1"}, {Name: "NO 1/1-2"}}}
+	storage := StorageOptions{Format: "XML", OutputFolder: "out", PathTemplate: "{{.Source}}/{{year .Created}}/{{lower .BaseName}}.{{.Ext}}"}
+	paths, err := BuildOutputPaths(fObj, storage)
+	if err != nil {
+		t.Fatalf("Failed in building paths from template:%s", err.Error())
+	}
+	if len(paths) != 2 || paths[0] != filepath.Join("out", "NO 1_1-1", "2020", "rig.xml") {
+		t.Errorf("Expected one sanitised path per wellbore, got:%v", paths)
+	}
+	//a template not using the source should only give one path
+	storage.PathTemplate = "{{.ReportTypeName}}/{{.FileReference}}.{{.Ext}}"
+	if paths, err = BuildOutputPaths(fObj, storage); err != nil || len(paths) != 1 {
+		t.Errorf("Expected one path when template does not use the source, got:%v", paths)
+	}
+}
+
+func TestBuildOutputPathsFromTemplateRejectsParentFolders(t *testing.T) {
+	fObj := FileObject{FileName: "a.xml", FileReference: "1", ReportType: 1}
+	storage := StorageOptions{Format: "XML", OutputFolder: "out", PathTemplate: "../{{.FileName}}"}
+	if _, err := BuildOutputPaths(fObj, storage); err == nil {
+		t.Errorf("Expected error for template referencing parent folder")
+	}
+	storage.PathTemplate = "{{.Unknown}}"
+	if _, err := BuildOutputPaths(fObj, storage); err == nil {
+		t.Errorf("Expected error for template using unknown field")
+	}
+	if err := verifyPathTemplate("{{.FileName"); err == nil {
+		t.Errorf("Expected error for template that can not be parsed")
+	}
+}
@@ -2,10 +2,11 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"text/template"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"go.uber.org/zap"
@@ -25,14 +26,22 @@ type FileQueryConfig struct {
 }
 
 type FileQuery struct {
-	TimeFrom        string
-	TimeTo          string
-	Field           string
-	FileType        string
-	ReportType      string
-	UseUploadedFrom bool
-	OutputLocation  string
-	OutputPrefix    string
+	TimeFrom         string
+	TimeTo           string
+	Field            string
+	FileType         string
+	ReportType       string
+	UseUploadedFrom  bool
+	OutputLocation   string
+	OutputPrefix     string
+	LogFile          string
+	LinkMode         string
+	PathTemplate     string
+	VersionPolicy    string
+	QuarantineFolder string
+	Compress         string
+	Convert          CloudConvertConfig
+	Block            string //name of the configuration block the query was created from, used in metrics
 }
 
 type FileGraphResult struct {
@@ -120,16 +129,14 @@ var queryForXMLFilesForAssetUsingPeriod = `query{
 		  }`
 
 //BuildQueryForAssetUsingCreated builds a qraphql query for file using created to/from date
-//if the report type is either ddrml or dpr20 and the format is pdf it will ask for xml files instead
+//if the report type has its pdf generated from xml, e.g. ddrml or dpr20, it will ask for xml files instead
 //as the minio storage just stores xml files for these report types and the pdf report is generated on the fly.
 func BuildQueryForAssetUsingCreated(fQuery FileQuery) ([]byte, error) {
 
 	var tpl bytes.Buffer
-
-	if strings.ToLower(fQuery.ReportType) == "dpr20" || strings.ToLower(fQuery.ReportType) == "ddrml" {
-		//setting thje filetype to xml as it is a dpr20 or ddrml report
-		fQuery.FileType = "XML"
-
+	//report types with pdfs generated from the xml, e.g. dpr20 or ddrml, are queried as xml
+	if definition, exists := ReportTypeByName(fQuery.ReportType); exists {
+		fQuery.FileType = definition.QueryFileType(fQuery.FileType)
 	}
 	zap.S().Debugf("Building asset query:%s,%s,%s,%s", fQuery.Field, fQuery.FileType,
 		fQuery.TimeFrom, fQuery.TimeTo)
@@ -147,14 +154,13 @@ func BuildQueryForAssetUsingCreated(fQuery FileQuery) ([]byte, error) {
 }
 
 //BuildQueryForAssetUsingPeriod builds a qraphql query for file using period to/from date
-//if the report type is either ddrml or dpr20 and the format is pdf it will ask for xml files instead
+//if the report type has its pdf generated from xml, e.g. ddrml or dpr20, it will ask for xml files instead
 //as the minio storage just stores xml files for these report types and the pdf report is generated on the fly.
 func BuildQueryForAssetUsingPeriod(fQuery FileQuery) ([]byte, error) {
 	var tpl bytes.Buffer
-	if strings.ToLower(fQuery.ReportType) == "dpr20" || strings.ToLower(fQuery.ReportType) == "ddrml" {
-		//setting thje filetype to xml as it is a dpr20 or ddrml report
-		fQuery.FileType = "XML"
-
+	//report types with pdfs generated from the xml, e.g. dpr20 or ddrml, are queried as xml
+	if definition, exists := ReportTypeByName(fQuery.ReportType); exists {
+		fQuery.FileType = definition.QueryFileType(fQuery.FileType)
 	}
 	zap.S().Debugf("Building asset query:%s,%s,%s,%s", fQuery.Field, fQuery.FileType,
 		fQuery.TimeFrom, fQuery.TimeTo)
@@ -171,20 +177,26 @@ func BuildQueryForAssetUsingPeriod(fQuery FileQuery) ([]byte, error) {
 	return tpl.Bytes(), nil
 }
 
-func RunGraphQueryForFiles(token, url, subscriptionKey string, query []byte) (DataObject, interface{}, error) {
+//RunGraphQueryForFiles posts the given graphql query to the url and returns the files found,
+//the request is aborted if the given context is cancelled
+func RunGraphQueryForFiles(ctx context.Context, token, url, subscriptionKey string, query []byte) (DataObject, interface{}, error) {
 	var resp *resty.Response
 	var dObject DataObject
 	var fResult FileGraphResult
 	headers := make(map[string]string)
 
 	var err error
+	log := LoggerFromContext(ctx)
+	defer func(start time.Time) {
+		observeDuration(graphQueryDurationMetric, start, err)
+	}(time.Now())
 	//create the graphqlQueryObject
 	queryObj := GraphQuery{
 		Query: string(query),
 	}
 	payload, err := json.Marshal(queryObj)
 	if err != nil {
-		zap.S().Errorf("Failed in marshal of graphql object:%s", err.Error())
+		log.Errorf("Failed in marshal of graphql object:%s", err.Error())
 		return dObject, nil, err
 	}
 	//create the headers
@@ -195,24 +207,26 @@ func RunGraphQueryForFiles(token, url, subscriptionKey string, query []byte) (Da
 	//build the client
 	client := resty.New()
 	if resp, err = client.R().
+		SetContext(ctx).
 		SetHeaders(headers).
 		SetAuthToken(token).
 		SetBody(payload).
 		Post(url); err != nil {
-		zap.S().Errorf("Failed in rest request:%s", err.Error())
+		log.Errorf("Failed in rest request:%s", err.Error())
 		return dObject, nil, err
 
 	}
 
 	if resp.IsError() {
 
-		return dObject, resp.Error(), fmt.Errorf("Code:%d,Status:%s,Body:%s",
+		err = fmt.Errorf("Code:%d,Status:%s,Body:%s",
 			resp.StatusCode(), resp.Status(), string(resp.Body()))
+		return dObject, resp.Error(), err
 	} else {
-		zap.S().Debugf("Got response from server:%s", string(resp.Body()))
+		log.Debugf("Got response from server:%s", string(resp.Body()))
 		//unmarshal it
 		if err = json.Unmarshal(resp.Body(), &fResult); err != nil {
-			zap.S().Errorf("Failed in unmarshalling of response error:%s,got:%s", err.Error(), (resp.Body()))
+			log.Errorf("Failed in unmarshalling of response error:%s,got:%s", err.Error(), (resp.Body()))
 			return dObject, nil, err
 		}
 
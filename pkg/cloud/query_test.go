@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -120,7 +121,7 @@ func TestQueryForReportTypeWithAssetAndUsingPeriod(t *testing.T) {
 }
 
 func TestPostURLNotExisting(t *testing.T) {
-	if _, _, err := RunGraphQueryForFiles("token", "https://ccasda", "key",
+	if _, _, err := RunGraphQueryForFiles(context.Background(), "token", "https://ccasda", "key",
 		[]byte("This is my raw request, sent as-is")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
@@ -129,7 +130,7 @@ func TestPostURLNotExisting(t *testing.T) {
 }
 
 func TestPostURLThatIsNotCorrect(t *testing.T) {
-	if _, _, err := RunGraphQueryForFiles("token", "https://epimno.azure-api.net/test/graph22",
+	if _, _, err := RunGraphQueryForFiles(context.Background(), "token", "https://epimno.azure-api.net/test/graph22",
 		"key", []byte("This is my raw request, sent as-is")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
@@ -138,7 +139,7 @@ func TestPostURLThatIsNotCorrect(t *testing.T) {
 }
 
 func TestPostURLWithNoAuthAndSubscriptionKey(t *testing.T) {
-	if _, _, err := RunGraphQueryForFiles("token", os.Getenv("AzureGraphUrl"), "", []byte("This is my raw request, sent as-is")); err == nil {
+	if _, _, err := RunGraphQueryForFiles(context.Background(), "token", os.Getenv("AzureGraphUrl"), "", []byte("This is my raw request, sent as-is")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
 		t.Logf("Got error back as should with invalid url:%s", err.Error())
@@ -146,7 +147,7 @@ func TestPostURLWithNoAuthAndSubscriptionKey(t *testing.T) {
 }
 
 func TestPostURLWithNoAuth(t *testing.T) {
-	if _, _, err := RunGraphQueryForFiles("token", os.Getenv("AzureGraphUrl"),
+	if _, _, err := RunGraphQueryForFiles(context.Background(), "token", os.Getenv("AzureGraphUrl"),
 		os.Getenv("AzureSubscriptionKey"), []byte("This is my raw request, sent as-is")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
@@ -161,7 +162,7 @@ func TestGraphQueryWithNoBody(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files with empty body, got error back instead of token:%s", err.Error())
 	}
-	if _, _, err := RunGraphQueryForFiles(token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), []byte("")); err == nil {
+	if _, _, err := RunGraphQueryForFiles(context.Background(), token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), []byte("")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
 		t.Logf("Got error back as should with invalid url:%s", err.Error())
@@ -175,7 +176,7 @@ func TestGraphQueryWithEmptyQuery(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files with empty body, got error back instead of token:%s", err.Error())
 	}
-	if _, _, err := RunGraphQueryForFiles(token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"),
+	if _, _, err := RunGraphQueryForFiles(context.Background(), token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"),
 		[]byte("{}")); err == nil {
 		t.Errorf("Resty post call should fail with invalid url did not...")
 	} else {
@@ -201,7 +202,7 @@ func TestRunQueryForFilesUsingCreated(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files, got error back instead of token:%s", err.Error())
 	}
-	if _, _, err := RunGraphQueryForFiles(token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), query); err != nil {
+	if _, _, err := RunGraphQueryForFiles(context.Background(), token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), query); err != nil {
 		t.Errorf("Resty post for files should not fail, failed with:%s", err.Error())
 	} else {
 		t.Logf("Got ok response back")
@@ -227,7 +228,7 @@ func TestRunQueryForFilesUsingPeriod(t *testing.T) {
 	if token, err = GetValidToken(); err != nil {
 		t.Errorf("Failed in test of authentication in run query for files, got error back instead of token:%s", err.Error())
 	}
-	if _, _, err := RunGraphQueryForFiles(token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), query); err != nil {
+	if _, _, err := RunGraphQueryForFiles(context.Background(), token, os.Getenv("AzureGraphUrl"), os.Getenv("AzureSubscriptionKey"), query); err != nil {
 		t.Errorf("Resty post for files should not fail, failed with:%s", err.Error())
 	} else {
 		t.Logf("Got ok response back")
@@ -237,6 +238,6 @@ func TestRunQueryForFilesUsingPeriod(t *testing.T) {
 
 func GetValidToken() (string, error) {
 
-	return Authenticate()
+	return Authenticate(context.Background())
 
 }
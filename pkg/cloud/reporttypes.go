@@ -0,0 +1,181 @@
+package cloud
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ReportTypeDefinition describes a Collabor8 report type, adding a new report type is done by adding it to ReportTypes
+//and if it is downloaded through a new configuration element, adding the element to CloudDownload and blocks()
+type ReportTypeDefinition struct {
+	Id            int      //id of the report type used by the api in file objects
+	Name          string   //name of the report type used in queries e.g. DPR20
+	Formats       []string //formats that can be downloaded, in lower case
+	PDFFromXML    bool     //the pdf is generated on the fly from the xml file, queries must ask for xml files
+	ConfigElement string   //xml element of the download blocks in the configuration file
+	RootElement   string   //root element of the xml files
+	PerWellbore   bool     //files are stored once per wellbore source
+}
+
+//ReportTypes holds all report types supported, blocks downloading several report types
+//query for them in the order given here
+var ReportTypes = []ReportTypeDefinition{
+	{Id: 1, Name: "DPR10", Formats: []string{"xml", "pdf"}, ConfigElement: "dpr", RootElement: "WITSMLComposite"},
+	{Id: 2, Name: "DPR20", Formats: []string{"xml", "pdf"}, PDFFromXML: true, ConfigElement: "dpr", RootElement: "objects"},
+	{Id: 3, Name: "DDRML", Formats: []string{"xml", "pdf"}, PDFFromXML: true, ConfigElement: "ddrml",
+		RootElement: "drillReports", PerWellbore: true},
+	{Id: 4, Name: "MPRMLGov", Formats: []string{"xml"}, ConfigElement: "mprmlGov", RootElement: "objects"},
+	{Id: 5, Name: "MPRMLPartner", Formats: []string{"xml"}, ConfigElement: "mprmlPartner", RootElement: "objects"},
+}
+
+//ReportTypeById returns the report type with the given api id
+func ReportTypeById(id int) (ReportTypeDefinition, bool) {
+	for i := 0; i < len(ReportTypes); i++ {
+		if ReportTypes[i].Id == id {
+			return ReportTypes[i], true
+		}
+	}
+	return ReportTypeDefinition{}, false
+}
+
+//ReportTypeByName returns the report type with the given name, ignoring case
+func ReportTypeByName(name string) (ReportTypeDefinition, bool) {
+	for i := 0; i < len(ReportTypes); i++ {
+		if strings.EqualFold(ReportTypes[i].Name, name) {
+			return ReportTypes[i], true
+		}
+	}
+	return ReportTypeDefinition{}, false
+}
+
+//ReportTypesForConfigElement returns the report types downloaded by a configuration element
+func ReportTypesForConfigElement(element string) []ReportTypeDefinition {
+	var reportTypes []ReportTypeDefinition
+	for i := 0; i < len(ReportTypes); i++ {
+		if ReportTypes[i].ConfigElement == element {
+			reportTypes = append(reportTypes, ReportTypes[i])
+		}
+	}
+	return reportTypes
+}
+
+//SupportsFormat returns true if files of the report type can be downloaded in the format
+func (reportType ReportTypeDefinition) SupportsFormat(format string) bool {
+	for i := 0; i < len(reportType.Formats); i++ {
+		if strings.EqualFold(reportType.Formats[i], format) {
+			return true
+		}
+	}
+	return false
+}
+
+//QueryFileType returns the file type to query for when downloading the given format,
+//report types with pdfs generated from xml are always queried as xml
+func (reportType ReportTypeDefinition) QueryFileType(format string) string {
+	if reportType.PDFFromXML {
+		return "XML"
+	}
+	return format
+}
+
+//verifyFormat checks that all report types of the configuration element support the format
+func verifyFormat(element, format string) error {
+	reportTypes := ReportTypesForConfigElement(element)
+	for i := 0; i < len(reportTypes); i++ {
+		if !reportTypes[i].SupportsFormat(format) {
+			return fmt.Errorf("Unsupported format:%s specified for %s data, supported formats:%s",
+				format, reportTypes[i].Name, strings.Join(reportTypes[i].Formats, ","))
+		}
+	}
+	return nil
+}
+
+//downloadBlock is a download block from the configuration, the dpr, mprmlGov, mprmlPartner and ddrml
+//blocks are all turned into this so that they can be verified and queried for in the same way
+type downloadBlock struct {
+	Element         string
+	FieldName       string
+	DateFrom        string
+	DateTo          string
+	RollDays        int
+	UseUploadedFrom bool
+	LogFile         string
+	Common          CloudCommonConfig
+	Convert         CloudConvertConfig
+}
+
+func newProductionBlock(element string, cnfg CloudProductionConfig) downloadBlock {
+	return downloadBlock{Element: element, FieldName: cnfg.FieldName, DateFrom: cnfg.DateFrom, DateTo: cnfg.DateTo,
+		RollDays: cnfg.RollDays, UseUploadedFrom: cnfg.UseUploadedFrom, LogFile: cnfg.LogFile,
+		Common: cnfg.Common, Convert: cnfg.Convert}
+}
+
+func newDDRMLBlock(cnfg CloudDDRMLConfig) downloadBlock {
+	return downloadBlock{Element: "ddrml", DateFrom: cnfg.DateFrom, DateTo: cnfg.DateTo,
+		RollDays: cnfg.RollDays, UseUploadedFrom: cnfg.UseUploadedFrom, LogFile: cnfg.LogFile,
+		Common: cnfg.Common, Convert: cnfg.Convert}
+}
+
+//name returns the name of the block used to identify it, e.g. in metrics, the configuration element
+//followed by the field name if set
+func (block downloadBlock) name() string {
+	if block.FieldName == "" {
+		return block.Element
+	}
+	return block.Element + ":" + block.FieldName
+}
+
+//blocks returns all download blocks in the configuration in the order they are processed
+func (downloadConfig CloudDownload) blocks() []downloadBlock {
+	var blocks []downloadBlock
+	for i := 0; i < len(downloadConfig.DPRS); i++ {
+		blocks = append(blocks, newProductionBlock("dpr", downloadConfig.DPRS[i]))
+	}
+	for i := 0; i < len(downloadConfig.MPRGovs); i++ {
+		blocks = append(blocks, newProductionBlock("mprmlGov", downloadConfig.MPRGovs[i]))
+	}
+	for i := 0; i < len(downloadConfig.MPRPartners); i++ {
+		blocks = append(blocks, newProductionBlock("mprmlPartner", downloadConfig.MPRPartners[i]))
+	}
+	for i := 0; i < len(downloadConfig.DDRMLS); i++ {
+		blocks = append(blocks, newDDRMLBlock(downloadConfig.DDRMLS[i]))
+	}
+	return blocks
+}
+
+//fileQueries returns the queries to run for all blocks in the configuration, one per report type of each block
+func (downloadConfig CloudDownload) fileQueries() []FileQuery {
+	var fQueries []FileQuery
+	blocks := downloadConfig.blocks()
+	for i := 0; i < len(blocks); i++ {
+		reportTypes := ReportTypesForConfigElement(blocks[i].Element)
+		for x := 0; x < len(reportTypes); x++ {
+			fQueries = append(fQueries, createFileQuery(blocks[i], reportTypes[x], len(reportTypes) > 1))
+		}
+	}
+	return fQueries
+}
+
+//createFileQuery creates the query object for one report type of a download block, blocks downloading several
+//report types get the report type added to the convert output file so the outputs do not overwrite each other
+func createFileQuery(block downloadBlock, reportType ReportTypeDefinition, severalReportTypes bool) FileQuery {
+	fQuery := buildFileQuery(block.RollDays, block.DateFrom, block.DateTo)
+	fQuery.Field = block.FieldName
+	fQuery.ReportType = reportType.Name
+	fQuery.FileType = block.Common.Format
+	fQuery.UseUploadedFrom = block.UseUploadedFrom
+	fQuery.OutputLocation = block.Common.OutputFolder
+	fQuery.OutputPrefix = block.Common.FileOutputPrefix
+	fQuery.LinkMode = block.Common.LinkMode
+	fQuery.PathTemplate = block.Common.PathTemplate
+	fQuery.VersionPolicy = block.Common.VersionPolicy
+	fQuery.QuarantineFolder = block.Common.QuarantineFolder
+	fQuery.Compress = block.Common.Compress
+	fQuery.LogFile = block.LogFile
+	fQuery.Convert = block.Convert
+	fQuery.Block = block.name()
+	if severalReportTypes {
+		fQuery.Convert = convertConfigForReportType(block.Convert, reportType.Name)
+	}
+	return fQuery
+}
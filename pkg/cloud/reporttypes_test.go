@@ -0,0 +1,56 @@
+package cloud
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+//testReportType returns the report type definition with the given name
+func testReportType(name string) ReportTypeDefinition {
+	definition, _ := ReportTypeByName(name)
+	return definition
+}
+
+func TestMapReportTypeFromRegistry(t *testing.T) {
+	for i := 0; i < len(ReportTypes); i++ {
+		if name := MapReportType(ReportTypes[i].Id); name != ReportTypes[i].Name {
+			t.Errorf("Expected report type:%s for id:%d, got:%s", ReportTypes[i].Name, ReportTypes[i].Id, name)
+		}
+	}
+	if name := MapReportType(99); name != "NONE_REPORTTYPE" {
+		t.Errorf("Expected NONE_REPORTTYPE for unknown id, got:%s", name)
+	}
+}
+
+func TestReportTypeQueryFileType(t *testing.T) {
+	if fileType := testReportType("ddrml").QueryFileType("PDF"); fileType != "XML" {
+		t.Errorf("Expected ddrml pdfs to be queried as xml, got:%s", fileType)
+	}
+	if fileType := testReportType("DPR10").QueryFileType("PDF"); fileType != "PDF" {
+		t.Errorf("Expected dpr 1.0 pdfs to be queried as pdf, got:%s", fileType)
+	}
+}
+
+func TestFileQueriesFromRegistry(t *testing.T) {
+	cConfig := CloudDownload{
+		DPRS: []CloudProductionConfig{{FieldName: "JOHAN SVERDRUP", DateFrom: "2020-01-01", DateTo: "2020-01-02",
+			Common:  CloudCommonConfig{Format: "XML", OutputFolder: "out"},
+			Convert: CloudConvertConfig{OutputFile: filepath.Join("out", "dpr.xlsx")}}},
+		DDRMLS: []CloudDDRMLConfig{{DateFrom: "2020-01-01", DateTo: "2020-01-02", Common: CloudCommonConfig{Format: "PDF"}}},
+	}
+	fQueries := cConfig.fileQueries()
+	if len(fQueries) != 3 || fQueries[0].ReportType != "DPR10" || fQueries[1].ReportType != "DPR20" ||
+		fQueries[2].ReportType != "DDRML" {
+		t.Fatalf("Expected DPR10, DPR20 and DDRML queries, got:%+v", fQueries)
+	}
+	if fQueries[0].Convert.OutputFile != filepath.Join("out", "dpr_DPR10.xlsx") || fQueries[0].Field != "JOHAN SVERDRUP" {
+		t.Errorf("Unexpected dpr 1.0 query:%+v", fQueries[0])
+	}
+	if err := VerifyCloudDownloadConfig(cConfig); err != nil {
+		t.Errorf("Configuration should be valid:%s", err.Error())
+	}
+	cConfig.MPRGovs = []CloudProductionConfig{{FieldName: "GINA KROG", Common: CloudCommonConfig{Format: "PDF"}}}
+	if err := VerifyCloudDownloadConfig(cConfig); err == nil {
+		t.Errorf("Expected error for mprml pdf download")
+	}
+}
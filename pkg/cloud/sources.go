@@ -0,0 +1,120 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//SourceSummary holds a source, e.g. a field or wellbore, found in the files visible to the client
+type SourceSummary struct {
+	Kind         string   `json:"kind"`
+	Name         string   `json:"name"`
+	NamingSystem string   `json:"namingSystem,omitempty"`
+	ReportTypes  []string `json:"reportTypes"`
+	Files        int      `json:"files"`
+	FirstPeriod  string   `json:"firstPeriod,omitempty"`
+	LastPeriod   string   `json:"lastPeriod,omitempty"`
+}
+
+//SourceListing holds the sources and report types found in the files for a period
+type SourceListing struct {
+	TimeFrom    string          `json:"timeFrom"`
+	TimeTo      string          `json:"timeTo"`
+	Files       int             `json:"files"`
+	ReportTypes map[string]int  `json:"reportTypes"` //number of files per report type
+	Sources     []SourceSummary `json:"sources"`
+}
+
+//AggregateSources builds a listing of the sources and report types referenced by the files,
+//sources are sorted on kind and name
+func AggregateSources(files []FileObject) SourceListing {
+	listing := SourceListing{Files: len(files), ReportTypes: make(map[string]int)}
+	sources := make(map[string]*SourceSummary)
+	for i := 0; i < len(files); i++ {
+		reportType := MapReportType(files[i].ReportType)
+		listing.ReportTypes[reportType]++
+		for x := 0; x < len(files[i].Sources); x++ {
+			key := files[i].Sources[x].Kind + "|" + files[i].Sources[x].Name
+			source, exists := sources[key]
+			if !exists {
+				source = &SourceSummary{
+					Kind:         files[i].Sources[x].Kind,
+					Name:         files[i].Sources[x].Name,
+					NamingSystem: files[i].Sources[x].NamingSystem,
+				}
+				sources[key] = source
+			}
+			source.Files++
+			if !containsString(source.ReportTypes, reportType) {
+				source.ReportTypes = append(source.ReportTypes, reportType)
+			}
+			periodStart := files[i].MetaData.PeriodStart
+			if periodStart != "" && (source.FirstPeriod == "" || periodStart < source.FirstPeriod) {
+				source.FirstPeriod = periodStart
+			}
+			if periodStart != "" && periodStart > source.LastPeriod {
+				source.LastPeriod = periodStart
+			}
+		}
+	}
+	for _, source := range sources {
+		sort.Strings(source.ReportTypes)
+		listing.Sources = append(listing.Sources, *source)
+	}
+	sort.Slice(listing.Sources, func(i, j int) bool {
+		if listing.Sources[i].Kind != listing.Sources[j].Kind {
+			return listing.Sources[i].Kind < listing.Sources[j].Kind
+		}
+		return listing.Sources[i].Name < listing.Sources[j].Name
+	})
+	return listing
+}
+
+//ListSources queries for all xml files in the period visible to the client and returns the sources
+//(fields, wellbores etc.) and report types referenced by them. The period is limited to MaxNumberOfDaysPeriod days
+func ListSources(ctx context.Context, dateFrom, dateTo string, useUploadedFrom bool) (SourceListing, error) {
+	var err error
+	var token string
+	var query []byte
+	var dObj DataObject
+	listing := SourceListing{TimeFrom: dateFrom, TimeTo: dateTo}
+	log := LoggerFromContext(ctx)
+	if days, err := DaysBetween(dateFrom, dateTo); err != nil {
+		return listing, err
+	} else if days > MaxNumberOfDaysPeriod {
+		return listing, fmt.Errorf("Max number of days for a given period exceeded:%.2f, max set to:%.2f",
+			days, MaxNumberOfDaysPeriod)
+	}
+	subscriptionKey := os.Getenv(AzureSubscriptionKeyEnvName)
+	graphQLUrl := os.Getenv(AzureGraphUrlEnvName)
+	if subscriptionKey == "" {
+		return listing, fmt.Errorf("Unable to find subscription key in environment variable:%s", AzureSubscriptionKeyEnvName)
+	}
+	if graphQLUrl == "" {
+		return listing, fmt.Errorf("Unable to locate environment variable for the graphqlurl:%s", AzureGraphUrlEnvName)
+	}
+	if token, err = Authenticate(ctx); err != nil {
+		return listing, err
+	}
+	fQuery := FileQuery{TimeFrom: dateFrom, TimeTo: dateTo, FileType: "XML", UseUploadedFrom: useUploadedFrom}
+	if useUploadedFrom {
+		query, err = BuildQueryForAssetUsingCreated(fQuery)
+	} else {
+		query, err = BuildQueryForAssetUsingPeriod(fQuery)
+	}
+	if err != nil {
+		return listing, err
+	}
+	log.Infof("Listing sources for files in period:%s-%s, useUploadedFrom:%t", dateFrom, dateTo, useUploadedFrom)
+	if dObj, _, err = RunGraphQueryForFiles(ctx, token, graphQLUrl, subscriptionKey, query); err != nil {
+		return listing, errors.New(fmt.Sprintf("RunGraphQL query for files failed:%s", err.Error()))
+	}
+	log.Infof("Got number of files:%d", len(dObj.Files))
+	listing = AggregateSources(dObj.Files)
+	listing.TimeFrom = dateFrom
+	listing.TimeTo = dateTo
+	return listing, nil
+}
@@ -0,0 +1,38 @@
+package cloud
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAggregateSources(t *testing.T) {
+	files := []FileObject{
+		{ReportType: 2, MetaData: FileMetaData{PeriodStart: "2020-02-02"},
+			Sources: []DataSource{{Kind: "field", Name: "JOHAN SVERDRUP"}}},
+		{ReportType: 2, MetaData: FileMetaData{PeriodStart: "2020-02-01"},
+			Sources: []DataSource{{Kind: "field", Name: "JOHAN SVERDRUP"}}},
+		{ReportType: 3, MetaData: FileMetaData{PeriodStart: "2020-02-01"},
+			Sources: []DataSource{{Kind: "wellbore", Name: "NO 16/2-D-1 H"}, {Kind: "field", Name: "JOHAN SVERDRUP"}}},
+	}
+	listing := AggregateSources(files)
+	if listing.Files != 3 || listing.ReportTypes["DPR20"] != 2 || listing.ReportTypes["DDRML"] != 1 {
+		t.Errorf("Unexpected file and report type counts:%+v", listing)
+	}
+	if len(listing.Sources) != 2 {
+		t.Fatalf("Expected two sources, got:%+v", listing.Sources)
+	}
+	field := listing.Sources[0]
+	if field.Kind != "field" || field.Name != "JOHAN SVERDRUP" || field.Files != 3 ||
+		len(field.ReportTypes) != 2 || field.FirstPeriod != "2020-02-01" || field.LastPeriod != "2020-02-02" {
+		t.Errorf("Unexpected field source:%+v", field)
+	}
+	if listing.Sources[1].Kind != "wellbore" || listing.Sources[1].ReportTypes[0] != "DDRML" {
+		t.Errorf("Unexpected wellbore source:%+v", listing.Sources[1])
+	}
+}
+
+func TestListSourcesPeriodTooLong(t *testing.T) {
+	if _, err := ListSources(context.Background(), "2019-01-01", "2020-01-01", false); err == nil {
+		t.Errorf("Expected error for period longer than:%.0f days", MaxNumberOfDaysPeriod)
+	}
+}
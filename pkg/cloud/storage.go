@@ -0,0 +1,167 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//link modes controlling how a file stored in several locations, e.g. a ddrml file covering several wellbores, is written
+const (
+	LinkModeCopy     = "copy"     //write the full file to every location (default)
+	LinkModeHardlink = "hardlink" //write the file once and hard link the other locations to it
+	LinkModeSymlink  = "symlink"  //write the file once and create relative symbolic links to it
+)
+
+var linkModes = []string{LinkModeCopy, LinkModeHardlink, LinkModeSymlink}
+
+//CompressGzip stores downloaded xml files gzip compressed as .xml.gz
+const CompressGzip = "gzip"
+
+//StorageOptions controls how and where downloaded files are stored locally
+type StorageOptions struct {
+	Format           string //format downloaded, pdf or xml
+	OutputFolder     string
+	FilePrefix       string
+	LinkMode         string //copy (default), hardlink or symlink
+	PathTemplate     string //optional go template for the path of each file below the output folder
+	Field            string //field name used in the query, available in the path template
+	VersionPolicy    string //optional latest or history, how resubmitted versions of a report are kept
+	QuarantineFolder string //folder for xml files failing the integrity check, defaults to quarantine in the output folder
+	Compress         string //optional gzip, xml files are stored compressed with the .gz extension added
+}
+
+//storageOptions returns the storage options to use for the files found by the query
+func (fQuery FileQuery) storageOptions() StorageOptions {
+	return StorageOptions{
+		Format:           strings.ToUpper(fQuery.FileType),
+		OutputFolder:     fQuery.OutputLocation,
+		FilePrefix:       fQuery.OutputPrefix,
+		LinkMode:         fQuery.LinkMode,
+		PathTemplate:     fQuery.PathTemplate,
+		Field:            fQuery.Field,
+		VersionPolicy:    fQuery.VersionPolicy,
+		QuarantineFolder: fQuery.QuarantineFolder,
+		Compress:         fQuery.Compress,
+	}
+}
+
+//BuildOutputPaths builds the local paths to store a file object in, using the path template if set and
+//otherwise the default layout from BuildOutputPathForReportType
+//compressed files get the .gz extension added to the path
+func BuildOutputPaths(fObj FileObject, storage StorageOptions) ([]string, error) {
+	var paths []string
+	var err error
+	if storage.PathTemplate != "" {
+		if paths, err = BuildOutputPathsFromTemplate(fObj, storage); err != nil {
+			return paths, err
+		}
+	} else {
+		paths = BuildOutputPathForReportType(fObj, storage.FilePrefix, storage.OutputFolder, storage.Format)
+	}
+	if storage.compressed() {
+		for i := 0; i < len(paths); i++ {
+			paths[i] = paths[i] + common.GzipExtension
+		}
+	}
+	return paths, nil
+}
+
+//compressed returns true if files are stored gzip compressed
+func (storage StorageOptions) compressed() bool {
+	return strings.EqualFold(storage.Compress, CompressGzip)
+}
+
+//verifyCompress checks that compression is either not set or gzip, only xml files can be compressed
+func verifyCompress(compress, format string) error {
+	if compress == "" {
+		return nil
+	}
+	if !strings.EqualFold(compress, CompressGzip) {
+		return fmt.Errorf("Unsupported compress:%s, supported compression:%s", compress, CompressGzip)
+	}
+	if !strings.EqualFold(format, "xml") {
+		return fmt.Errorf("Compress:%s is only supported for the XML format, got format:%s", compress, format)
+	}
+	return nil
+}
+
+//verifyLinkMode checks that the link mode is empty or one of the supported link modes
+func verifyLinkMode(linkMode string) error {
+	if linkMode == "" {
+		return nil
+	}
+	for i := 0; i < len(linkModes); i++ {
+		if strings.ToLower(linkMode) == linkModes[i] {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unsupported linkMode:%s, supported modes:%s", linkMode, strings.Join(linkModes, ","))
+}
+
+//writeFileToPaths writes the data to the first path and depending on the link mode either copies or links
+//the remaining paths to it. If a link can not be created, e.g. hard links across volumes or symlinks without privileges on windows,
+//the file is copied instead. Returns the paths written and an error for every path that failed
+func writeFileToPaths(ctx context.Context, data []byte, paths []string, linkMode string) ([]string, []error) {
+	var written []string
+	var errorsEncountered []error
+	log := LoggerFromContext(ctx)
+	linkMode = strings.ToLower(linkMode)
+	for i := 0; i < len(paths); i++ {
+		if err := os.MkdirAll(filepath.Dir(paths[i]), os.ModePerm); err != nil {
+			errorsEncountered = append(errorsEncountered,
+				fmt.Errorf("Failed in creating folder for storage with path:%s,error:%s", paths[i], err.Error()))
+			continue
+		}
+		//the first file written is the one all links point to
+		if len(written) > 0 && (linkMode == LinkModeHardlink || linkMode == LinkModeSymlink) {
+			if err := linkFileAtomic(written[0], paths[i], linkMode); err != nil {
+				log.Warnf("Failed in creating %s from:%s to:%s, copying file instead, error:%s",
+					linkMode, paths[i], written[0], err.Error())
+			} else {
+				log.Infof("Linked file (%s) %s to:%s", linkMode, paths[i], written[0])
+				written = append(written, paths[i])
+				continue
+			}
+		}
+		//write through a temporary file so that an interrupted run never leaves a half-written file behind
+		if err := common.Write2FileAtomic(paths[i], data); err != nil {
+			errorsEncountered = append(errorsEncountered,
+				fmt.Errorf("Failed in write of file to outputLocation:%s,error:%s", paths[i], err.Error()))
+			continue
+		}
+		log.Infof("Wrote file to:%s", paths[i])
+		written = append(written, paths[i])
+	}
+	return written, errorsEncountered
+}
+
+//linkFileAtomic creates a hard or symbolic link at linkPath pointing to target, the link is created using
+//a temporary name and renamed in place so that an existing file at linkPath is replaced in one step
+func linkFileAtomic(target, linkPath, linkMode string) error {
+	var err error
+	tmpPath := linkPath + ".part"
+	os.Remove(tmpPath)
+	if linkMode == LinkModeSymlink {
+		var relTarget string
+		//use a relative target so the output folder can be moved as a whole
+		if relTarget, err = filepath.Rel(filepath.Dir(linkPath), target); err != nil {
+			return err
+		}
+		err = os.Symlink(relTarget, tmpPath)
+	} else {
+		err = os.Link(target, tmpPath)
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, linkPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
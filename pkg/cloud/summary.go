@@ -0,0 +1,121 @@
+package cloud
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//DownloadedFile holds the result of a single file that has been downloaded and written to disk
+//files skipped as a newer version is already stored and files not downloaded as the run was cancelled are also
+//included but have no paths
+type DownloadedFile struct {
+	File      FileObject `json:"file"`
+	Paths     []string   `json:"paths"`
+	Bytes     int        `json:"bytes"`
+	Version   int        `json:"version,omitempty"`
+	Skipped   bool       `json:"skipped,omitempty"`
+	Cancelled bool       `json:"cancelled,omitempty"`
+}
+
+//QuerySummary holds the outcome of running a single file query and downloading its files
+type QuerySummary struct {
+	ReportType      string   `json:"reportType"`
+	Field           string   `json:"field,omitempty"`
+	FileType        string   `json:"fileType"`
+	TimeFrom        string   `json:"timeFrom"`
+	TimeTo          string   `json:"timeTo"`
+	OutputLocation  string   `json:"outputLocation"`
+	FilesFound      int      `json:"filesFound"`
+	FilesDownloaded int      `json:"filesDownloaded"`
+	FilesFailed     int      `json:"filesFailed"`
+	FilesSkipped    int      `json:"filesSkipped,omitempty"`
+	FilesCancelled  int      `json:"filesCancelled,omitempty"`
+	Files           []string `json:"files,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+//DownloadSummary holds the outcome of a complete download run, a summary is also produced
+//for runs that are cancelled or fail part way through so that progress can be tracked
+type DownloadSummary struct {
+	Started   time.Time      `json:"started"`
+	Finished  time.Time      `json:"finished"`
+	Cancelled bool           `json:"cancelled"`
+	Queries   []QuerySummary `json:"queries"`
+	Errors    []string       `json:"errors,omitempty"`
+}
+
+//newQuerySummary creates a summary entry for the given query
+func newQuerySummary(fQuery FileQuery) QuerySummary {
+	return QuerySummary{
+		ReportType:     fQuery.ReportType,
+		Field:          fQuery.Field,
+		FileType:       fQuery.FileType,
+		TimeFrom:       fQuery.TimeFrom,
+		TimeTo:         fQuery.TimeTo,
+		OutputLocation: fQuery.OutputLocation,
+	}
+}
+
+//addDownloads adds the downloaded files and errors to the query summary
+func (qSummary *QuerySummary) addDownloads(downloaded []DownloadedFile, errorList []error) {
+	for i := 0; i < len(downloaded); i++ {
+		if downloaded[i].Skipped {
+			qSummary.FilesSkipped++
+			continue
+		}
+		if downloaded[i].Cancelled {
+			qSummary.FilesCancelled++
+			continue
+		}
+		qSummary.FilesDownloaded++
+		qSummary.Files = append(qSummary.Files, downloaded[i].Paths...)
+	}
+	qSummary.FilesFailed = qSummary.FilesFound - qSummary.FilesDownloaded - qSummary.FilesSkipped - qSummary.FilesCancelled
+	for i := 0; i < len(errorList); i++ {
+		qSummary.Errors = append(qSummary.Errors, errorList[i].Error())
+	}
+}
+
+//FilesDownloaded returns the total number of files downloaded in the run
+func (summary DownloadSummary) FilesDownloaded() int {
+	total := 0
+	for i := 0; i < len(summary.Queries); i++ {
+		total = total + summary.Queries[i].FilesDownloaded
+	}
+	return total
+}
+
+//FilesFailed returns the total number of files that failed to download in the run
+func (summary DownloadSummary) FilesFailed() int {
+	total := 0
+	for i := 0; i < len(summary.Queries); i++ {
+		total = total + summary.Queries[i].FilesFailed
+	}
+	return total
+}
+
+//FilesCancelled returns the total number of files not downloaded as the run was cancelled
+func (summary DownloadSummary) FilesCancelled() int {
+	total := 0
+	for i := 0; i < len(summary.Queries); i++ {
+		total = total + summary.Queries[i].FilesCancelled
+	}
+	return total
+}
+
+//WriteDownloadSummary writes the summary as json to the given path, creating the folder if needed
+func WriteDownloadSummary(path string, summary DownloadSummary) error {
+	var err error
+	var data []byte
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(summary, "", "    "); err != nil {
+		return err
+	}
+	return common.Write2FileAtomic(path, data)
+}
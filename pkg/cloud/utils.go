@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -37,8 +39,18 @@ func TimeToStr(timeObj time.Time, format string) string {
 	return timeObj.Format(format)
 }
 
-func Authenticate() (string, error) {
+//Authenticate requests a new access token from the Azure token url using the client credentials
+//found in the environment, the request is aborted if the given context is cancelled
+func Authenticate(ctx context.Context) (string, error) {
+	token, err := requestToken(ctx)
+	tokenRequestsMetric.Inc(resultLabel(err))
+	return token, err
+}
+
+//requestToken requests a new token from the token url using the client credentials
+func requestToken(ctx context.Context) (string, error) {
 
+	var req *http.Request
 	var resp *http.Response
 	var err error
 	var clientId, clientSecret, tokenUrl, resourceId string
@@ -59,10 +71,17 @@ func Authenticate() (string, error) {
 		"client_secret": {clientSecret},
 		"resource":      {resourceId},
 	}
-	if resp, err = http.PostForm(tokenUrl, formData); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl,
+		strings.NewReader(formData.Encode())); err != nil {
+		zap.S().Errorf("Error in creating authentication request:%s", err.Error())
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if resp, err = http.DefaultClient.Do(req); err != nil {
 		zap.S().Errorf("Error in authentication post:%s", err.Error())
 		return "", err
 	}
+	defer resp.Body.Close()
 	var result map[string]interface{}
 	json.NewDecoder(resp.Body).Decode(&result)
 	if resp.StatusCode == 200 {
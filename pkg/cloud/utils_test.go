@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"testing"
 )
 
@@ -33,7 +34,7 @@ func TestConvertRFC3339StrToOtherFormat(t *testing.T) {
 
 func TestSuccessAuthenticate(t *testing.T) {
 
-	if token, err := Authenticate(); err != nil {
+	if token, err := Authenticate(context.Background()); err != nil {
 		t.Errorf("Failed in test of authentication, got error back instead of token:%s", err.Error())
 	} else {
 		t.Logf("Got token:%s", token)
@@ -43,7 +44,7 @@ func TestSuccessAuthenticate(t *testing.T) {
 
 func TestFailureAuthenticate(t *testing.T) {
 
-	if _, err := Authenticate(); err == nil {
+	if _, err := Authenticate(context.Background()); err == nil {
 		t.Errorf("Failed in test of authentication, should get an error back but got nothing")
 	} else {
 		t.Logf("Got error:%s", err.Error())
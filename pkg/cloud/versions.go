@@ -0,0 +1,248 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//version policies controlling how resubmitted reports for the same report id and period are stored
+const (
+	VersionPolicyLatest  = "latest"  //keep only the newest created version, older copies are removed
+	VersionPolicyHistory = "history" //keep all versions with a _v<N> suffix, the index tells which one is current
+)
+
+var versionPolicies = []string{VersionPolicyLatest, VersionPolicyHistory}
+
+//VersionIndexFileName is the name of the index file stored in the output folder when a version policy is set
+var VersionIndexFileName = "versions_index.json"
+
+//ReportVersion is one downloaded version of a report
+type ReportVersion struct {
+	Version       int      `json:"version"`
+	FileReference string   `json:"fileReferenceId"`
+	Created       string   `json:"created"`
+	Paths         []string `json:"paths"`
+}
+
+//ReportVersions holds the versions downloaded for one report id and period
+type ReportVersions struct {
+	ReportType  string          `json:"reportType"`
+	ReportId    string          `json:"reportId"`
+	PeriodStart string          `json:"periodStart"`
+	PeriodEnd   string          `json:"periodEnd"`
+	Current     string          `json:"current"` //fileReferenceId of the current version
+	Versions    []ReportVersion `json:"versions"`
+}
+
+//VersionIndex keeps track of the versions downloaded to an output folder, keyed on report type, report id and period
+type VersionIndex struct {
+	Reports map[string]*ReportVersions `json:"reports"`
+}
+
+//verifyVersionPolicy checks that the version policy is empty or one of the supported policies
+func verifyVersionPolicy(versionPolicy string) error {
+	if versionPolicy == "" {
+		return nil
+	}
+	for i := 0; i < len(versionPolicies); i++ {
+		if strings.ToLower(versionPolicy) == versionPolicies[i] {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unsupported versionPolicy:%s, supported policies:%s", versionPolicy,
+		strings.Join(versionPolicies, ","))
+}
+
+//versionIndexPath returns the path of the version index file for an output folder
+func versionIndexPath(outputFolder string) string {
+	return filepath.Join(outputFolder, VersionIndexFileName)
+}
+
+//LoadVersionIndex reads the version index from the output folder, an empty index is returned if none exists
+func LoadVersionIndex(outputFolder string) (*VersionIndex, error) {
+	index := &VersionIndex{Reports: make(map[string]*ReportVersions)}
+	data, err := ioutil.ReadFile(versionIndexPath(outputFolder))
+	if os.IsNotExist(err) {
+		return index, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("Failed in reading version index:%s, error:%s", versionIndexPath(outputFolder), err.Error())
+	}
+	if index.Reports == nil {
+		index.Reports = make(map[string]*ReportVersions)
+	}
+	return index, nil
+}
+
+//Save writes the version index to the output folder
+func (index *VersionIndex) Save(outputFolder string) error {
+	var err error
+	var data []byte
+	if err = os.MkdirAll(outputFolder, os.ModePerm); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(index, "", "    "); err != nil {
+		return err
+	}
+	return common.Write2FileAtomic(versionIndexPath(outputFolder), data)
+}
+
+//reportVersionKey returns the key identifying all versions of the same report,
+//files without a report id use the wellbore sources instead
+func reportVersionKey(fObj FileObject) string {
+	reportId := fObj.MetaData.ReportId
+	if reportId == "" {
+		for i := 0; i < len(fObj.Sources); i++ {
+			reportId = reportId + "_" + fObj.Sources[i].Name
+		}
+	}
+	return MapReportType(fObj.ReportType) + "|" + reportId + "|" + fObj.MetaData.PeriodStart + "|" + fObj.MetaData.PeriodEnd
+}
+
+//IsOlderThanCurrent returns true if a newer version of the report than the file object has already been stored
+func (index *VersionIndex) IsOlderThanCurrent(fObj FileObject) bool {
+	versions, exists := index.Reports[reportVersionKey(fObj)]
+	if !exists {
+		return false
+	}
+	for i := 0; i < len(versions.Versions); i++ {
+		if versions.Versions[i].FileReference == versions.Current {
+			return createdBefore(fObj.Created, versions.Versions[i].Created)
+		}
+	}
+	return false
+}
+
+//newestCreatedByReport returns the newest created timestamp per report among the files
+func newestCreatedByReport(files []FileObject) map[string]string {
+	newest := make(map[string]string)
+	for i := 0; i < len(files); i++ {
+		key := reportVersionKey(files[i])
+		if created, exists := newest[key]; !exists || createdBefore(created, files[i].Created) {
+			newest[key] = files[i].Created
+		}
+	}
+	return newest
+}
+
+//NextVersion returns the version number to use for the file object, files already in the index keep their version
+func (index *VersionIndex) NextVersion(fObj FileObject) int {
+	versions, exists := index.Reports[reportVersionKey(fObj)]
+	if !exists {
+		return 1
+	}
+	maxVersion := 0
+	for i := 0; i < len(versions.Versions); i++ {
+		if versions.Versions[i].FileReference == fObj.FileReference {
+			return versions.Versions[i].Version
+		}
+		if versions.Versions[i].Version > maxVersion {
+			maxVersion = versions.Versions[i].Version
+		}
+	}
+	return maxVersion + 1
+}
+
+//AddVersion registers a stored version of the report and updates which version is current. With the latest policy
+//the earlier versions are dropped from the index and their paths, not reused by the new version, are returned for removal
+func (index *VersionIndex) AddVersion(fObj FileObject, version int, paths []string, versionPolicy string) []string {
+	var obsolete []string
+	key := reportVersionKey(fObj)
+	versions, exists := index.Reports[key]
+	if !exists {
+		versions = &ReportVersions{
+			ReportType:  MapReportType(fObj.ReportType),
+			ReportId:    fObj.MetaData.ReportId,
+			PeriodStart: fObj.MetaData.PeriodStart,
+			PeriodEnd:   fObj.MetaData.PeriodEnd,
+		}
+		index.Reports[key] = versions
+	}
+	newVersion := ReportVersion{Version: version, FileReference: fObj.FileReference, Created: fObj.Created, Paths: paths}
+	var kept []ReportVersion
+	for i := 0; i < len(versions.Versions); i++ {
+		if versions.Versions[i].FileReference == fObj.FileReference {
+			continue
+		}
+		if strings.ToLower(versionPolicy) == VersionPolicyLatest {
+			for x := 0; x < len(versions.Versions[i].Paths); x++ {
+				if !containsString(paths, versions.Versions[i].Paths[x]) {
+					obsolete = append(obsolete, versions.Versions[i].Paths[x])
+				}
+			}
+			continue
+		}
+		kept = append(kept, versions.Versions[i])
+	}
+	versions.Versions = append(kept, newVersion)
+	sort.Slice(versions.Versions, func(i, j int) bool {
+		return versions.Versions[i].Version < versions.Versions[j].Version
+	})
+	//the current version is the one created last
+	current := versions.Versions[0]
+	for i := 1; i < len(versions.Versions); i++ {
+		if !createdBefore(versions.Versions[i].Created, current.Created) {
+			current = versions.Versions[i]
+		}
+	}
+	versions.Current = current.FileReference
+	return obsolete
+}
+
+//storeVersion registers the downloaded file in the version index, removes files made obsolete by the
+//version policy and saves the index so that it is up to date even if the run is stopped
+func storeVersion(ctx context.Context, index *VersionIndex, dFile DownloadedFile, storage StorageOptions) error {
+	log := LoggerFromContext(ctx)
+	obsolete := index.AddVersion(dFile.File, dFile.Version, dFile.Paths, storage.VersionPolicy)
+	for i := 0; i < len(obsolete); i++ {
+		if err := os.Remove(obsolete[i]); err != nil && !os.IsNotExist(err) {
+			log.Warnf("Failed in removing older version:%s,error:%s", obsolete[i], err.Error())
+		} else {
+			log.Infof("Removed older version:%s", obsolete[i])
+		}
+	}
+	return index.Save(storage.OutputFolder)
+}
+
+//createdBefore compares two created timestamps, falling back to comparing the strings if they can not be parsed
+func createdBefore(created, other string) bool {
+	createdTime, err := StringRFC3339ToTime(created)
+	if err != nil {
+		return created < other
+	}
+	otherTime, err := StringRFC3339ToTime(other)
+	if err != nil {
+		return created < other
+	}
+	return createdTime.Before(otherTime)
+}
+
+//addVersionSuffix adds _v<version> to the file name of the path before the extension,
+//for compressed files before both extensions e.g. report_v2.xml.gz
+func addVersionSuffix(path string, version int) string {
+	ext := filepath.Ext(path)
+	if common.IsGzipFile(path) {
+		ext = filepath.Ext(strings.TrimSuffix(path, ext)) + ext
+	}
+	return strings.TrimSuffix(path, ext) + fmt.Sprintf("_v%d", version) + ext
+}
+
+//sortFilesByCreated sorts the files oldest first so that versions are numbered in the order they were submitted
+func sortFilesByCreated(files []FileObject) []FileObject {
+	sorted := make([]FileObject, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return createdBefore(sorted[i].Created, sorted[j].Created)
+	})
+	return sorted
+}
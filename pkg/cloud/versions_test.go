@@ -0,0 +1,112 @@
+package cloud
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+//versionTestFiles returns two versions of the same daily report, the second one resubmitted later
+func versionTestFiles() []FileObject {
+	metaData := FileMetaData{FileType: "XML", PeriodStart: "2020-02-28", PeriodEnd: "2020-02-29", ReportId: "R1"}
+	return []FileObject{
+		{FileName: "dpr.xml", FileReference: "2", ReportType: 2, Created: "2020-03-02T10:00:00Z", MetaData: metaData},
+		{FileName: "dpr.xml", FileReference: "1", ReportType: 2, Created: "2020-03-01T10:00:00Z", MetaData: metaData},
+	}
+}
+
+func newVersionTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<objects>" + r.URL.Query().Get("referenceId") + "</objects>"))
+	}))
+}
+
+func TestDownloadFilesVersionPolicyLatest(t *testing.T) {
+	server := newVersionTestServer()
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "latest")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	storage := StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST", VersionPolicy: "latest"}
+	files := versionTestFiles()
+	//first run only gets the first version, the second run the resubmitted one
+	if _, errorList := DownloadFiles(context.Background(), files[1:], server.URL, "token", "key", storage); len(errorList) > 0 {
+		t.Fatalf("Download should not fail:%s", errorList[0].Error())
+	}
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key", storage)
+	if len(errorList) > 0 {
+		t.Fatalf("Download should not fail:%s", errorList[0].Error())
+	}
+	var qSummary QuerySummary
+	qSummary.FilesFound = len(files)
+	qSummary.addDownloads(downloaded, errorList)
+	if qSummary.FilesDownloaded != 1 || qSummary.FilesSkipped != 1 || qSummary.FilesFailed != 0 {
+		t.Errorf("Expected one file downloaded and one skipped, got:%+v", qSummary)
+	}
+	written, _ := ioutil.ReadDir(outputFolder)
+	var xmlFiles []string
+	for i := 0; i < len(written); i++ {
+		if strings.HasSuffix(written[i].Name(), ".xml") {
+			xmlFiles = append(xmlFiles, written[i].Name())
+		}
+	}
+	if len(xmlFiles) != 1 || !strings.Contains(xmlFiles[0], "_2_") {
+		t.Errorf("Expected only the latest version to be kept, got:%v", xmlFiles)
+	}
+	index, err := LoadVersionIndex(outputFolder)
+	if err != nil {
+		t.Fatalf("Failed in loading version index:%s", err.Error())
+	}
+	versions := index.Reports[reportVersionKey(files[0])]
+	if versions == nil || versions.Current != "2" || len(versions.Versions) != 1 {
+		t.Errorf("Expected index with only the latest version as current, got:%+v", versions)
+	}
+}
+
+func TestDownloadFilesVersionPolicyHistory(t *testing.T) {
+	server := newVersionTestServer()
+	defer server.Close()
+	outputFolder, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(outputFolder)
+	storage := StorageOptions{Format: "XML", OutputFolder: outputFolder, FilePrefix: "TEST", VersionPolicy: "history"}
+	files := versionTestFiles()
+	downloaded, errorList := DownloadFiles(context.Background(), files, server.URL, "token", "key", storage)
+	if len(errorList) > 0 {
+		t.Fatalf("Download should not fail:%s", errorList[0].Error())
+	}
+	if len(downloaded) != 2 || downloaded[0].Version != 1 || !strings.HasSuffix(downloaded[0].Paths[0], "_v1.xml") ||
+		downloaded[1].Version != 2 || !strings.HasSuffix(downloaded[1].Paths[0], "_v2.xml") {
+		t.Fatalf("Expected two versions numbered in created order, got:%+v", downloaded)
+	}
+	//downloading again should keep the version numbers
+	if downloaded, errorList = DownloadFiles(context.Background(), files, server.URL, "token", "key", storage); len(errorList) > 0 ||
+		downloaded[1].Version != 2 {
+		t.Errorf("Expected version numbers to be kept on download of the same files, got:%+v", downloaded)
+	}
+	index, err := LoadVersionIndex(outputFolder)
+	if err != nil {
+		t.Fatalf("Failed in loading version index:%s", err.Error())
+	}
+	versions := index.Reports[reportVersionKey(files[0])]
+	if versions == nil || versions.Current != "2" || len(versions.Versions) != 2 {
+		t.Errorf("Expected index with both versions and the latest as current, got:%+v", versions)
+	}
+}
+
+func TestVerifyVersionPolicy(t *testing.T) {
+	if err := verifyVersionPolicy("History"); err != nil {
+		t.Errorf("History should be a valid version policy:%s", err.Error())
+	}
+	if err := verifyVersionPolicy("oldest"); err == nil {
+		t.Errorf("Unknown version policy should give an error")
+	}
+}
@@ -9,6 +9,7 @@ package common
 
 import (
 	"encoding/xml"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,9 @@ const Rolldays_default = -999
 
 const xsdDateTimeLayout = time.RFC3339
 const xsdDateLayout = "2006-01-02"
+
+//NullFloatValue was used for missing float values before columns could be null, values equal to it are still
+//treated as missing by the writers
 const NullFloatValue = -999.99
 const xsdDateTimeLayoutNoTimeZone = "2006-01-02T15:04:05"
 
@@ -56,7 +60,8 @@ func (c *xsdDouble) UnmarshalText(text []byte) error {
 func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	parse, _ := time.Parse(xsdDateTimeLayout, v)
+	//a time without time zone is read in the time zone set for the conversion, UTC by default
+	parse, _ := ParseXsdDateTime(v)
 	*c = xsdDateTime{parse}
 	return nil
 }
@@ -64,11 +69,53 @@ func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 func (c *xsdDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	parse, _ := time.Parse(xsdDateLayout, v)
+	parse, _ := ParseXsdDate(v)
 	*c = xsdDate{parse}
 	return nil
 }
 
+//Measure is a measure element of a report, a value with a unit of measure attribute, the value is missing
+//if the element is not in the xml or has no value
+type Measure struct {
+	Value   float64
+	Uom     string
+	present bool //set if the element has a value in the xml
+}
+
+//NewMeasure returns a measure with a value
+func NewMeasure(value float64, uom string) Measure {
+	return Measure{Value: value, Uom: uom, present: true}
+}
+
+//IsPresent returns true if the measure has a value
+func (m Measure) IsPresent() bool {
+	return m.present
+}
+
+//unmarshal function to handle measure parsing, the value is parsed as a xsd double and the uom attribute is
+//read as is, an element without a value is kept as a missing measure
+func (m *Measure) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var element struct {
+		Value string `xml:",chardata"`
+		Uom   string `xml:"uom,attr"`
+	}
+	if err := d.DecodeElement(&element, &start); err != nil {
+		return err
+	}
+	*m = Measure{Uom: element.Uom}
+	parse := strings.TrimSpace(element.Value)
+	if parse == "" {
+		return nil
+	}
+	number, err := strconv.ParseFloat(parse, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse value %s of measure %s: %v", parse, start.Name.Local, err)
+	}
+	m.Value = number
+	m.present = true
+	return nil
+}
+
 const datelayout_out = "2006-01-02T15_04_05"
 const datelayout_out_csv = "2006-01-02 15:04:05"
 
@@ -76,16 +123,20 @@ const daylayout_query = "2006-01-02"
 const monthlayout_query = "01"
 const yearlayout_query = "2006"
 
+//DataSet holds rows of values under the headers, the Schema declares the columns for validation and typing
+//in the output formats, datasets without a schema are typed from the values in the rows
 type DataSet struct {
 	Name        string
 	HeadersName []string
 	Rows        []RowData
+	Schema      Schema
 }
 
 type RowData struct {
 	Columns []ColumnData
 }
 
+//ColumnData holds one value of a row, IsNull is set together with the type flag for a missing value of the type
 type ColumnData struct {
 	IntVal        int       `json:",omitempty"`
 	FloatVal      float64   `json:",omitempty"`
@@ -96,11 +147,13 @@ type ColumnData struct {
 	IsFloat       bool      `json:",omitempty"`
 	IsStr         bool      `json:",omitempty"`
 	IsTime        bool      `json:",omitempty"`
+	IsNull        bool      `json:",omitempty"`
 }
 
 type DataSetJson struct {
 	Name        string
 	HeadersName []string
+	Schema      []ColumnSchema
 	Rows        []RowDataJson
 }
 
@@ -154,9 +207,47 @@ func (row *RowData) AddFloatValue(value float64) {
 	row.Columns = append(row.Columns, cData)
 }
 
+//AddTimeValue adds a time column in the time zone set by SetTimezone, the zero time is a missing time in the xml
+//and added as null
 func (row *RowData) AddTimeValue(value time.Time) {
 	cData := ColumnData{}
-	cData.TimeValue = value
+	cData.TimeValue = InTimezone(value)
 	cData.IsTime = true
+	cData.IsNull = value.IsZero()
 	row.Columns = append(row.Columns, cData)
 }
+
+//AddNullIntValue adds an int column without a value
+func (row *RowData) AddNullIntValue() {
+	row.Columns = append(row.Columns, ColumnData{IsInt: true, IsNull: true})
+}
+
+//AddNullFloatValue adds a float column without a value
+func (row *RowData) AddNullFloatValue() {
+	row.Columns = append(row.Columns, ColumnData{IsFloat: true, IsNull: true})
+}
+
+//AddNullStrValue adds a string column without a value
+func (row *RowData) AddNullStrValue() {
+	row.Columns = append(row.Columns, ColumnData{IsStr: true, IsNull: true})
+}
+
+//AddNullTimeValue adds a time column without a value
+func (row *RowData) AddNullTimeValue() {
+	row.Columns = append(row.Columns, ColumnData{IsTime: true, IsNull: true})
+}
+
+//AddMeasureValue adds the value and unit of measure columns of a measure, the value is null if the measure
+//has no value in the xml and the unit of measure is null if the measure has no uom attribute
+func (row *RowData) AddMeasureValue(measure Measure) {
+	if measure.IsPresent() {
+		row.AddFloatValue(measure.Value)
+	} else {
+		row.AddNullFloatValue()
+	}
+	if measure.Uom == "" {
+		row.AddNullStrValue()
+	} else {
+		row.AddStrValue(measure.Uom)
+	}
+}
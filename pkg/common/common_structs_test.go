@@ -0,0 +1,67 @@
+package common
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+//testMeasures is a report element with measures as they are read by the report formats
+type testMeasures struct {
+	XMLName  xml.Name `xml:"period"`
+	Volume   Measure  `xml:"volume"`
+	Mass     Measure  `xml:"mass"`
+	Density  Measure  `xml:"density"`
+	Temp     Measure  `xml:"temp"`
+	Pressure Measure  `xml:"pres"`
+}
+
+func TestMeasureUnmarshalXML(t *testing.T) {
+	//volume is missing, mass is empty, density has no value, temp has no uom
+	data := `<period><mass/><density uom="kg/m3"></density><temp> 20.5 </temp><pres uom="bar">12</pres></period>`
+	var measures testMeasures
+	if err := xml.Unmarshal([]byte(data), &measures); err != nil {
+		t.Fatalf("Failed in reading measures:%s", err.Error())
+	}
+	row := RowData{}
+	row.AddMeasureValue(measures.Volume)
+	row.AddMeasureValue(measures.Mass)
+	row.AddMeasureValue(measures.Density)
+	row.AddMeasureValue(measures.Temp)
+	row.AddMeasureValue(measures.Pressure)
+	cols := row.Columns
+	if len(cols) != 10 {
+		t.Fatalf("Expected a value and a uom column per measure, got:%d columns", len(cols))
+	}
+	if !cols[0].IsNull || !cols[1].IsNull || !cols[2].IsNull || !cols[3].IsNull {
+		t.Errorf("Expected missing and empty measures as null, got:%v", cols[:4])
+	}
+	if !cols[4].IsNull || !cols[4].IsFloat || cols[5].IsNull || cols[5].StrVal != "kg/m3" {
+		t.Errorf("Expected a measure without value as null with its uom, got:%v", cols[4:6])
+	}
+	if cols[6].IsNull || cols[6].FloatVal != 20.5 || !cols[7].IsNull || !cols[7].IsStr {
+		t.Errorf("Expected a measure without uom kept with a null uom, got:%v", cols[6:8])
+	}
+	if cols[8].IsNull || cols[8].FloatVal != 12 || cols[9].StrVal != "bar" {
+		t.Errorf("Expected a measure with value and uom kept, got:%v", cols[8:10])
+	}
+}
+
+func TestMeasureUnmarshalXMLZero(t *testing.T) {
+	var measures testMeasures
+	if err := xml.Unmarshal([]byte(`<period><volume uom="Sm3">0</volume></period>`), &measures); err != nil {
+		t.Fatalf("Failed in reading measures:%s", err.Error())
+	}
+	if !measures.Volume.IsPresent() || measures.Volume.Value != 0 {
+		t.Errorf("Expected a zero value as present, got:%v", measures.Volume)
+	}
+	if measures.Mass.IsPresent() {
+		t.Errorf("Expected a missing element as not present, got:%v", measures.Mass)
+	}
+}
+
+func TestMeasureUnmarshalXMLInvalid(t *testing.T) {
+	var measures testMeasures
+	if err := xml.Unmarshal([]byte(`<period><volume uom="Sm3">n/a</volume></period>`), &measures); err == nil {
+		t.Errorf("Expected an error for a value that is not a number")
+	}
+}
@@ -8,11 +8,12 @@ package common
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -29,34 +30,43 @@ func InitializeFolders(outputFolder string, logFile string) error {
 	return nil
 }
 
-//Converts a list of datasets to json
+//Converts a list of datasets to json, each dataset is written with the schema of its columns
 func DatasetsToJson(datasets []DataSet) ([]byte, error) {
 	var dSetsJson []DataSetJson
 	//just move everything over to a column valuebase json set
 	for x := 0; x < len(datasets); x++ {
-		dSetJson := DataSetJson{HeadersName: datasets[x].HeadersName, Name: datasets[x].Name}
+		if err := checkDataSet(datasets[x]); err != nil {
+			return nil, err
+		}
+		//the schema gives the types of the values, declared or found from the rows
+		schema := datasets[x].ColumnSchemas()
+		dSetJson := DataSetJson{HeadersName: datasets[x].HeadersName, Name: datasets[x].Name, Schema: schema}
 		//process row by row and add the header names
 		rows := []RowDataJson{}
 		for y := 0; y < len(datasets[x].Rows); y++ {
 			row := RowDataJson{}
 			for z := 0; z < len(datasets[x].Rows[y].Columns); z++ {
 				//check type of value
+				value := datasets[x].Rows[y].Columns[z]
+				if z < len(schema) {
+					value = typedColumn(value, schema[z].Type)
+				}
 				column := ColumnDataJson{Name: datasets[x].HeadersName[z]}
-				if datasets[x].Rows[y].Columns[z].IsFloat {
-					column.Value = datasets[x].Rows[y].Columns[z].FloatVal
-				} else if datasets[x].Rows[y].Columns[z].IsInt {
-					column.Value = datasets[x].Rows[y].Columns[z].IntVal
-				} else if datasets[x].Rows[y].Columns[z].IsStr {
-					column.Value = datasets[x].Rows[y].Columns[z].StrVal
-				} else if datasets[x].Rows[y].Columns[z].IsTime {
-					column.Value = datasets[x].Rows[y].Columns[z].TimeValue
-				} else if datasets[x].Rows[y].Columns[z].IsEmptyColumn {
-					column.Value = ""
+				if IsNullValue(value) {
+					//missing values are written as json null
+					column.Value = nil
+				} else if value.IsFloat {
+					column.Value = value.FloatVal
+				} else if value.IsInt {
+					column.Value = value.IntVal
+				} else if value.IsStr {
+					column.Value = value.StrVal
+				} else if value.IsTime {
+					column.Value = InTimezone(value.TimeValue)
 				} else {
 					column.Value = ""
 				}
 				row.Columns = append(row.Columns, column)
-				//row.Columns=append(row.Columns,ColumnDataJson{Name:datasets[x].HeadersName[z],Value:datasets[x].Rows[y].Columns[z].})
 			}
 			rows = append(rows, row)
 		}
@@ -77,67 +87,6 @@ func GetTimeZone(tObj *time.Time) string {
 	return zone
 }
 
-func DatasetsToCsv(datasets []DataSet, outputFile string, discriminator string) error {
-	var err error
-	var outputFolder, fileNameOnly, ext, dataOutFile string
-	for i := 0; i < len(datasets); i++ {
-		//get the outputfolder to store in
-		outputFolder = GetFolderPathForFile(outputFile)
-		//get the file name without extension
-		fileNameOnly, ext = GetFileNameAndExtension(outputFile)
-		//build the new filename using header name and removing
-		dataOutFile = outputFolder + string(os.PathSeparator) + fileNameOnly + "_" + datasets[i].Name + ext
-		if err = DatasetToCsv(datasets[i], dataOutFile, discriminator); err != nil {
-			return err
-		}
-		zap.S().Infof("Wrote csv data for dataset name:%s to location:%s", datasets[i].Name, dataOutFile)
-	}
-	return nil
-}
-
-func DatasetToCsv(dataset DataSet, outputFile string, discriminator string) error {
-	var dataBuffer bytes.Buffer
-	//first write the headers
-	for i := 0; i < len(dataset.HeadersName); i++ {
-		if i > 0 {
-			dataBuffer.WriteString(discriminator)
-		}
-		dataBuffer.WriteString(dataset.HeadersName[i])
-	}
-	//add the break line
-	dataBuffer.WriteString("\n")
-	//write the data
-	for x := 0; x < len(dataset.Rows); x++ {
-
-		for y := 0; y < len(dataset.Rows[x].Columns); y++ {
-			//now have the columne write it to the buffer
-			if y > 0 {
-				dataBuffer.WriteString(discriminator)
-			}
-			if dataset.Rows[x].Columns[y].IsEmptyColumn {
-				dataBuffer.WriteString("\"\"")
-			} else if dataset.Rows[x].Columns[y].IsFloat {
-				dataBuffer.WriteString(strconv.FormatFloat(dataset.Rows[x].Columns[y].FloatVal, 'f', -1, 64))
-
-			} else if dataset.Rows[x].Columns[y].IsInt {
-				dataBuffer.WriteString(strconv.Itoa(dataset.Rows[x].Columns[y].IntVal))
-
-			} else if dataset.Rows[x].Columns[y].IsTime {
-				dataBuffer.WriteString(dataset.Rows[x].Columns[y].TimeValue.Format(datelayout_out_csv))
-
-			} else {
-				//handle the string value
-				dataBuffer.WriteString("\"" + dataset.Rows[x].Columns[y].StrVal + "\"")
-			}
-
-		}
-		dataBuffer.WriteString("\n")
-	}
-	//write the databuffer data as a byte array
-	return Write2File(outputFile, dataBuffer.Bytes())
-
-}
-
 func CreateUUID() string {
 
 	u1 := uuid.NewV4()
@@ -218,6 +167,22 @@ func Write2File(filePath string, data []byte) error {
 
 }
 
+//Write2FileAtomic writes the data to a temporary file next to the given file path
+//and renames it in place when done, so that a reader never sees a half-written file
+func Write2FileAtomic(filePath string, data []byte) error {
+	var err error
+	tmpPath := filePath + ".part"
+	if err = Write2File(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
 func MoveFiles(files []string, move2Folder string) error {
 	var err error
 	for i := 0; i < len(files); i++ {
@@ -255,6 +220,81 @@ func GetFilesWithExtension(folder string, extension string) ([]string, error) {
 	return files, nil
 }
 
+//GzipExtension is added to the name of xml files stored gzip compressed, e.g. report.xml.gz
+const GzipExtension = ".gz"
+
+//IsGzipFile returns true if the file name has the gzip extension
+func IsGzipFile(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), GzipExtension)
+}
+
+//gzipFile closes both the gzip reader and the underlying file
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+//OpenXMLFile opens a xml file for reading, files with the .gz extension are decompressed transparently
+func OpenXMLFile(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !IsGzipFile(filePath) {
+		return file, nil
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipFile{Reader: reader, file: file}, nil
+}
+
+//ReadXMLFile reads a xml file, files with the .gz extension are decompressed transparently
+func ReadXMLFile(filePath string) ([]byte, error) {
+	if !IsGzipFile(filePath) {
+		return ReadFile(filePath)
+	}
+	reader, err := OpenXMLFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+//GetXMLFiles returns the xml files in the folder, both plain .xml and gzip compressed .xml.gz files
+func GetXMLFiles(folder string) ([]string, error) {
+	var files, compressed []string
+	var err error
+	if files, err = GetFilesWithExtension(folder, "*.xml"); err != nil {
+		return files, err
+	}
+	if compressed, err = GetFilesWithExtension(folder, "*.xml"+GzipExtension); err != nil {
+		return files, err
+	}
+	return append(files, compressed...), nil
+}
+
+//GzipData compresses the data using gzip
+func GzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func GetFolderPathForFile(filePath string) string {
 	return filepath.Dir(filePath)
 }
@@ -7,6 +7,11 @@
 package common
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -26,12 +31,21 @@ func TestParseTimeInUTC(t *testing.T) {
 	}
 }
 
+//setTestTimezone sets the time zone for a test and returns a function setting it back to UTC
+func setTestTimezone(t *testing.T, name string) func() {
+	if err := SetTimezone(name); err != nil {
+		t.Fatalf("Failed in setting time zone:%s, error:%s", name, err.Error())
+	}
+	return func() { SetTimezone("UTC") }
+}
+
 func TestParseNoTimezoneInDST(t *testing.T) {
+	defer setTestTimezone(t, "Europe/Oslo")()
 	var parse time.Time
 	var err error
 
 	verifyString := "2018-06-08T00:00:00+02:00"
-	if parse, err = time.ParseInLocation(xsdDateTimeLayoutNoTimeZone, timeVariant_NoTimeZoneDST, time.Now().Location()); err != nil {
+	if parse, err = ParseXsdDateTime(timeVariant_NoTimeZoneDST); err != nil {
 		t.Errorf("Failed in parsing time zone object:%s", err.Error())
 	}
 	if TimeToString(parse, xsdDateTimeLayout) != verifyString {
@@ -39,13 +53,141 @@ func TestParseNoTimezoneInDST(t *testing.T) {
 	}
 }
 func TestParseNoTimezoneInWinter(t *testing.T) {
+	defer setTestTimezone(t, "Europe/Oslo")()
 	var parse time.Time
 	var err error
 	verifyString := "2018-01-08T00:00:00+01:00"
-	if parse, err = time.ParseInLocation(xsdDateTimeLayoutNoTimeZone, timeVariant_NoTimeZoneWinter, time.Now().Location()); err != nil {
+	if parse, err = ParseXsdDateTime(timeVariant_NoTimeZoneWinter); err != nil {
 		t.Errorf("Failed in parsing time zone object:%s", err.Error())
 	}
 	if TimeToString(parse, xsdDateTimeLayout) != verifyString {
 		t.Errorf("Failed in parsing timezone in winter, expected:%s, got:%s", verifyString, TimeToString(parse, xsdDateTimeLayout))
 	}
 }
+
+func TestReadGzipXMLFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "compress")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	content := []byte(`<?xml version="1.0" encoding="utf-8"?><objects><object/></objects>`)
+	compressed, err := GzipData(content)
+	if err != nil {
+		t.Fatalf("Failed in compressing data:%s", err.Error())
+	}
+	ioutil.WriteFile(filepath.Join(folder, "a.xml"), content, 0644)
+	ioutil.WriteFile(filepath.Join(folder, "b.xml.gz"), compressed, 0644)
+	ioutil.WriteFile(filepath.Join(folder, "c.pdf"), content, 0644)
+	files, err := GetXMLFiles(folder)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected plain and compressed xml files, got:%v,%v", files, err)
+	}
+	for i := 0; i < len(files); i++ {
+		data, err := ReadXMLFile(files[i])
+		if err != nil {
+			t.Fatalf("Failed in reading file:%s,error:%s", files[i], err.Error())
+		}
+		if string(data) != string(content) {
+			t.Errorf("Unexpected content read from:%s, got:%s", files[i], string(data))
+		}
+	}
+	ioutil.WriteFile(filepath.Join(folder, "invalid.xml.gz"), content, 0644)
+	if _, err = ReadXMLFile(filepath.Join(folder, "invalid.xml.gz")); err == nil {
+		t.Errorf("Expected error reading a .gz file that is not gzip compressed")
+	}
+}
+
+//testRow holds the values of a row of a test dataset, each value is added as a column by its type, nil as an empty
+//column, a Measure as the value and unit of measure columns and ColumnData as it is for nulls
+type testRow []interface{}
+
+//null columns of each type for the test rows
+var (
+	testNullStr   = ColumnData{IsStr: true, IsNull: true}
+	testNullInt   = ColumnData{IsInt: true, IsNull: true}
+	testNullFloat = ColumnData{IsFloat: true, IsNull: true}
+	testNullTime  = ColumnData{IsTime: true, IsNull: true}
+)
+
+//testFixture holds the dataset used by the tests, the headers are taken from the schema if it has columns
+type testFixture struct {
+	name    string
+	headers []string
+	schema  Schema
+	rows    []testRow
+}
+
+//dataSet returns a new dataset of the fixture so a test can change it without changing the fixture
+func (fixture testFixture) dataSet() DataSet {
+	dataset := DataSet{Name: fixture.name, HeadersName: append([]string(nil), fixture.headers...)}
+	if len(fixture.schema.Columns) > 0 {
+		dataset = NewDataSet(fixture.name, fixture.schema)
+	}
+	for i := 0; i < len(fixture.rows); i++ {
+		row := RowData{}
+		for _, value := range fixture.rows[i] {
+			switch value := value.(type) {
+			case nil:
+				row.AddEmptyColumn()
+			case string:
+				row.AddStrValue(value)
+			case int:
+				row.AddIntValue(value)
+			case float64:
+				row.AddFloatValue(value)
+			case time.Time:
+				row.AddTimeValue(value)
+			case Measure:
+				row.AddMeasureValue(value)
+			case ColumnData:
+				row.Columns = append(row.Columns, value)
+			default:
+				panic(fmt.Sprintf("Unsupported value:%v of type:%T in test row", value, value))
+			}
+		}
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	return dataset
+}
+
+//testDataSets returns new datasets of the fixtures
+func testDataSets(fixtures ...testFixture) []DataSet {
+	datasets := make([]DataSet, len(fixtures))
+	for i := 0; i < len(fixtures); i++ {
+		datasets[i] = fixtures[i].dataSet()
+	}
+	return datasets
+}
+
+//nullFixture has a null value of every type and a missing measure
+var nullFixture = testFixture{name: "nulls",
+	headers: []string{"Well", "Count", "Volume", "VolumeUoM", "Start", "Depth", "DepthUoM"},
+	rows:    []testRow{{testNullStr, testNullInt, testNullFloat, testNullStr, time.Time{}, Measure{}}}}
+
+func TestDatasetsToJsonNulls(t *testing.T) {
+	data, err := DatasetsToJson([]DataSet{nullFixture.dataSet()})
+	if err != nil {
+		t.Fatalf("Failed in writing json:%s", err.Error())
+	}
+	var datasets []struct {
+		Rows []struct {
+			Columns []struct {
+				Name  string
+				Value interface{}
+			}
+		}
+	}
+	if err = json.Unmarshal(data, &datasets); err != nil {
+		t.Fatalf("Failed in reading written json:%s", err.Error())
+	}
+	columns := datasets[0].Rows[0].Columns
+	if len(columns) != 7 {
+		t.Fatalf("Expected 7 columns, got:%d", len(columns))
+	}
+	for i := 0; i < len(columns); i++ {
+		if columns[i].Value != nil {
+			t.Errorf("Expected null for column:%s, got:%v", columns[i].Name, columns[i].Value)
+		}
+	}
+}
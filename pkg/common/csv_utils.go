@@ -0,0 +1,165 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+//line endings supported when writing csv files
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
+)
+
+//utf8BOM is the utf-8 byte order mark, makes excel detect the encoding of csv files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+//CsvOptions controls the delimiter, byte order mark and line endings of csv files
+type CsvOptions struct {
+	Delimiter  string //single character, tab or \t for tab separated files, defaults to ;
+	BOM        bool   //write a utf-8 byte order mark first
+	LineEnding string //lf (default) or crlf
+}
+
+//DefaultCsvOptions returns the options used if nothing else is set, ; as delimiter, no bom and lf line endings
+func DefaultCsvOptions() CsvOptions {
+	return CsvOptions{Delimiter: ";", LineEnding: LineEndingLF}
+}
+
+//delimiter returns the delimiter as a rune, quotes and line breaks can not be used as delimiter
+func (options CsvOptions) delimiter() (rune, error) {
+	switch strings.ToLower(options.Delimiter) {
+	case "":
+		return ';', nil
+	case "tab", "\\t":
+		return '\t', nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(options.Delimiter)
+	if size != len(options.Delimiter) || delimiter == utf8.RuneError || delimiter == '"' ||
+		delimiter == '\r' || delimiter == '\n' {
+		return 0, fmt.Errorf("Invalid csv delimiter:%q, must be a single character other than quote or line break",
+			options.Delimiter)
+	}
+	return delimiter, nil
+}
+
+//VerifyCsvOptions checks the delimiter and line ending of the options
+func VerifyCsvOptions(options CsvOptions) error {
+	if _, err := options.delimiter(); err != nil {
+		return err
+	}
+	switch strings.ToLower(options.LineEnding) {
+	case "", LineEndingLF, LineEndingCRLF:
+		return nil
+	}
+	return fmt.Errorf("Unsupported csv line ending:%s, supported line endings:%s,%s", options.LineEnding,
+		LineEndingLF, LineEndingCRLF)
+}
+
+//DatasetsToCsv writes each dataset to its own csv file named after the output file and the dataset name
+//using the given delimiter
+func DatasetsToCsv(datasets []DataSet, outputFile string, discriminator string) error {
+	options := DefaultCsvOptions()
+	options.Delimiter = discriminator
+	return DatasetsToCsvWithOptions(datasets, outputFile, options)
+}
+
+//DatasetsToCsvWithOptions writes each dataset to its own csv file named after the output file and the dataset name
+func DatasetsToCsvWithOptions(datasets []DataSet, outputFile string, options CsvOptions) error {
+	var err error
+	var outputFolder, fileNameOnly, ext, dataOutFile string
+	if err = VerifyCsvOptions(options); err != nil {
+		return err
+	}
+	for i := 0; i < len(datasets); i++ {
+		//get the outputfolder to store in
+		outputFolder = GetFolderPathForFile(outputFile)
+		//get the file name without extension
+		fileNameOnly, ext = GetFileNameAndExtension(outputFile)
+		//build the new filename using header name and removing
+		dataOutFile = outputFolder + string(os.PathSeparator) + fileNameOnly + "_" + datasets[i].Name + ext
+		if err = DatasetToCsvWithOptions(datasets[i], dataOutFile, options); err != nil {
+			return err
+		}
+		zap.S().Infof("Wrote csv data for dataset name:%s to location:%s", datasets[i].Name, dataOutFile)
+	}
+	return nil
+}
+
+//DatasetToCsv writes the dataset to a csv file using the given delimiter
+func DatasetToCsv(dataset DataSet, outputFile string, discriminator string) error {
+	options := DefaultCsvOptions()
+	options.Delimiter = discriminator
+	return DatasetToCsvWithOptions(dataset, outputFile, options)
+}
+
+//DatasetToCsvWithOptions writes the dataset to a csv file
+func DatasetToCsvWithOptions(dataset DataSet, outputFile string, options CsvOptions) error {
+	var dataBuffer bytes.Buffer
+	if err := WriteDatasetCsv(&dataBuffer, dataset, options); err != nil {
+		return err
+	}
+	//write the databuffer data as a byte array
+	return Write2File(outputFile, dataBuffer.Bytes())
+}
+
+//WriteDatasetCsv writes the headers and rows of the dataset as csv, values containing the delimiter, quotes or
+//line breaks are quoted with embedded quotes doubled as described in RFC 4180. Datasets with a schema are
+//validated before written
+func WriteDatasetCsv(w io.Writer, dataset DataSet, options CsvOptions) error {
+	var err error
+	var delimiter rune
+	if err = VerifyCsvOptions(options); err != nil {
+		return err
+	}
+	if err = checkDataSet(dataset); err != nil {
+		return err
+	}
+	delimiter, _ = options.delimiter()
+	if options.BOM {
+		if _, err = w.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	writer.UseCRLF = strings.EqualFold(options.LineEnding, LineEndingCRLF)
+	//first write the headers
+	if err = writer.Write(dataset.HeadersName); err != nil {
+		return err
+	}
+	record := make([]string, 0)
+	for x := 0; x < len(dataset.Rows); x++ {
+		record = record[:0]
+		for y := 0; y < len(dataset.Rows[x].Columns); y++ {
+			record = append(record, csvValue(dataset.Rows[x].Columns[y]))
+		}
+		if err = writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//csvValue returns the text written to csv for the column
+func csvValue(column ColumnData) string {
+	if IsNullValue(column) {
+		return ""
+	} else if column.IsFloat {
+		return strconv.FormatFloat(column.FloatVal, 'f', -1, 64)
+	} else if column.IsInt {
+		return strconv.Itoa(column.IntVal)
+	} else if column.IsTime {
+		return InTimezone(column.TimeValue).Format(datelayout_out_csv)
+	}
+	return column.StrVal
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+//csvFixture has a comment needing quotes, a value of each type and an empty column
+var csvFixture = testFixture{name: "activity", headers: []string{"Comment", "Depth", "Count", "Start", "Empty"},
+	rows: []testRow{{"Drilled to \"TD\";\nPOOH", 2500.5, 3, time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC), nil}}}
+
+func TestWriteDatasetCsvQuoting(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDatasetCsv(&buf, csvFixture.dataSet(), DefaultCsvOptions()); err != nil {
+		t.Fatalf("Failed in writing csv:%s", err.Error())
+	}
+	expected := "Comment;Depth;Count;Start;Empty\n\"Drilled to \"\"TD\"\";\nPOOH\";2500.5;3;2020-03-01 06:00:00;\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected csv, got:%q, expected:%q", buf.String(), expected)
+	}
+	//must be possible to read back with the same values
+	reader := csv.NewReader(&buf)
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed in reading written csv:%s", err.Error())
+	}
+	if len(records) != 2 || records[1][0] != "Drilled to \"TD\";\nPOOH" {
+		t.Errorf("Unexpected records read back:%q", records)
+	}
+}
+
+func TestWriteDatasetCsvOptions(t *testing.T) {
+	var buf bytes.Buffer
+	options := CsvOptions{Delimiter: "tab", BOM: true, LineEnding: "CRLF"}
+	if err := WriteDatasetCsv(&buf, csvFixture.dataSet(), options); err != nil {
+		t.Fatalf("Failed in writing csv:%s", err.Error())
+	}
+	output := buf.String()
+	if !strings.HasPrefix(output, "\xEF\xBB\xBFComment\tDepth\tCount\tStart\tEmpty\r\n") {
+		t.Errorf("Expected bom, tab delimiter and crlf line ending, got:%q", output)
+	}
+	if !strings.HasSuffix(output, "\t2500.5\t3\t2020-03-01 06:00:00\t\r\n") {
+		t.Errorf("Expected crlf line ending, got:%q", output)
+	}
+}
+
+func TestVerifyCsvOptions(t *testing.T) {
+	for _, delimiter := range []string{"", ";", ",", "|", "tab", "\\t"} {
+		if err := VerifyCsvOptions(CsvOptions{Delimiter: delimiter}); err != nil {
+			t.Errorf("Delimiter:%q should be valid, got:%s", delimiter, err.Error())
+		}
+	}
+	for _, delimiter := range []string{"\"", "\n", ";;"} {
+		if err := VerifyCsvOptions(CsvOptions{Delimiter: delimiter}); err == nil {
+			t.Errorf("Delimiter:%q should be invalid", delimiter)
+		}
+	}
+	if err := VerifyCsvOptions(CsvOptions{LineEnding: "cr"}); err == nil {
+		t.Errorf("Line ending cr should be invalid")
+	}
+}
+
+func TestWriteDatasetCsvNulls(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDatasetCsv(&buf, nullFixture.dataSet(), DefaultCsvOptions()); err != nil {
+		t.Fatalf("Failed in writing csv:%s", err.Error())
+	}
+	expected := "Well;Count;Volume;VolumeUoM;Start;Depth;DepthUoM\n;;;;;;\n"
+	if buf.String() != expected {
+		t.Errorf("Expected nulls as empty fields, got:%q, expected:%q", buf.String(), expected)
+	}
+}
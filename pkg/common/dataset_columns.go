@@ -0,0 +1,76 @@
+package common
+
+import (
+	"strconv"
+)
+
+//IsNullValue returns true if the column has no value, null and empty columns and NullFloatValue are missing values
+func IsNullValue(column ColumnData) bool {
+	return column.IsNull || column.IsEmptyColumn || (column.IsFloat && column.FloatVal == NullFloatValue) ||
+		(!column.IsFloat && !column.IsInt && !column.IsTime && !column.IsStr)
+}
+
+//datasetColumnKind finds the type of the column at index from the values in the rows, ints and floats in the
+//same column gives float and any other mix of types gives string, columns with only nulls are strings
+func datasetColumnKind(dataset DataSet, index int) ColumnType {
+	var hasInt, hasFloat, hasTime, hasStr bool
+	for i := 0; i < len(dataset.Rows); i++ {
+		if index >= len(dataset.Rows[i].Columns) || IsNullValue(dataset.Rows[i].Columns[index]) {
+			continue
+		}
+		column := dataset.Rows[i].Columns[index]
+		if column.IsFloat {
+			hasFloat = true
+		} else if column.IsInt {
+			hasInt = true
+		} else if column.IsTime {
+			hasTime = true
+		} else {
+			hasStr = true
+		}
+	}
+	switch {
+	case hasStr || (hasTime && (hasInt || hasFloat)):
+		return ColumnTypeString
+	case hasTime:
+		return ColumnTypeTime
+	case hasFloat:
+		return ColumnTypeFloat
+	case hasInt:
+		return ColumnTypeInt
+	}
+	return ColumnTypeString
+}
+
+//datasetColumnHasNulls returns true if a row has a null or no value for the column at index
+func datasetColumnHasNulls(dataset DataSet, index int) bool {
+	for i := 0; i < len(dataset.Rows); i++ {
+		if index >= len(dataset.Rows[i].Columns) || IsNullValue(dataset.Rows[i].Columns[index]) {
+			return true
+		}
+	}
+	return false
+}
+
+//uniqueColumnNames returns the headers with empty names replaced by column_<number> and duplicated names,
+//or names in reserved, suffixed with _<count> as the typed output formats do not accept duplicated names
+func uniqueColumnNames(headers []string, reserved ...string) []string {
+	var columnNames []string
+	names := make(map[string]int)
+	for i := 0; i < len(reserved); i++ {
+		names[reserved[i]] = 1
+	}
+	for i := 0; i < len(headers); i++ {
+		name := headers[i]
+		if name == "" {
+			name = "column_" + strconv.Itoa(i+1)
+		}
+		for count := names[name]; count > 0; count = names[name] {
+			names[name] = count + 1
+			name = name + "_" + strconv.Itoa(count+1)
+		}
+		names[name]++
+		columnNames = append(columnNames, name)
+	}
+	return columnNames
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+//JoinKey is the column tying the datasets built from the same report together
+const JoinKey = "DataUUID"
+
+//JoinDataSets returns the dataset with the columns of the context dataset added to each row, the rows are matched
+//on the key column and rows without a matching context row get nulls. Only the first context row of each key is
+//used, so the dataset keeps its rows. Columns of the context already in the dataset are not added. A context
+//with one row is added to all rows of a dataset without the key column, for a context with several rows the
+//columns are added with nulls, so the columns of the dataset do not depend on the number of reports converted
+func JoinDataSets(dataset DataSet, context DataSet, key string) DataSet {
+	var columns []int
+	contextKey := columnIndex(context.HeadersName, key)
+	datasetKey := columnIndex(dataset.HeadersName, key)
+	for i := 0; i < len(context.HeadersName); i++ {
+		if i != contextKey && columnIndex(dataset.HeadersName, context.HeadersName[i]) < 0 {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) == 0 {
+		return dataset
+	}
+	var lookup func(row RowData) (RowData, bool)
+	if datasetKey >= 0 && contextKey >= 0 {
+		contextRows := make(map[string]RowData)
+		for i := 0; i < len(context.Rows); i++ {
+			value := csvValue(rowColumn(context.Rows[i], contextKey))
+			if _, found := contextRows[value]; !found {
+				contextRows[value] = context.Rows[i]
+			}
+		}
+		lookup = func(row RowData) (RowData, bool) {
+			contextRow, found := contextRows[csvValue(rowColumn(row, datasetKey))]
+			return contextRow, found
+		}
+	} else if len(context.Rows) == 1 {
+		lookup = func(row RowData) (RowData, bool) {
+			return context.Rows[0], true
+		}
+	} else {
+		zap.S().Debugf("Dataset:%s has no column:%s to join dataset:%s on, adding its columns as nulls", dataset.Name,
+			key, context.Name)
+		lookup = func(row RowData) (RowData, bool) {
+			return RowData{}, false
+		}
+	}
+	joined := DataSet{Name: dataset.Name, HeadersName: make([]string, 0, len(dataset.HeadersName)+len(columns))}
+	joined.HeadersName = append(joined.HeadersName, dataset.HeadersName...)
+	for i := 0; i < len(columns); i++ {
+		joined.HeadersName = append(joined.HeadersName, context.HeadersName[columns[i]])
+	}
+	//the schema is kept if both datasets declare one, the added columns are null for rows without context
+	if dataset.HasSchema() && context.HasSchema() {
+		joined.Schema = Schema{Columns: append([]ColumnSchema{}, dataset.Schema.Columns...)}
+		for i := 0; i < len(columns); i++ {
+			column := context.Schema.Columns[columns[i]]
+			column.Nullable = true
+			joined.Schema.Columns = append(joined.Schema.Columns, column)
+		}
+	}
+	joined.Rows = make([]RowData, len(dataset.Rows))
+	for x := 0; x < len(dataset.Rows); x++ {
+		row := RowData{Columns: make([]ColumnData, 0, len(dataset.Rows[x].Columns)+len(columns))}
+		row.Columns = append(row.Columns, dataset.Rows[x].Columns...)
+		contextRow, found := lookup(dataset.Rows[x])
+		for i := 0; i < len(columns); i++ {
+			if found {
+				row.Columns = append(row.Columns, rowColumn(contextRow, columns[i]))
+			} else {
+				row.Columns = append(row.Columns, ColumnData{IsEmptyColumn: true})
+			}
+		}
+		joined.Rows[x] = row
+	}
+	return joined
+}
+
+//ParseJoinTransform returns the join transform for the comma separated context dataset names, nil if no names
+//are given
+func ParseJoinTransform(contextNames string) DataSetTransform {
+	names := splitList(contextNames)
+	if len(names) == 0 {
+		return nil
+	}
+	return JoinTransform(names)
+}
+
+//JoinTransform returns a transform adding the columns of the named context datasets, e.g. REPORT_FILE_INFO and
+//DOCUMENT_INFO, to all other datasets joined on DataUUID. The context datasets are output as they are. The
+//transform remembers the context datasets it has seen, so when datasets are written one at a time the context
+//datasets must be written before the datasets they are joined to. The rows of a context dataset written in parts,
+//e.g. one part per report, are kept together so each report is joined to its context
+func JoinTransform(contextNames []string) DataSetTransform {
+	contexts := make([]*DataSet, len(contextNames))
+	contextIndex := func(name string) int {
+		for i := 0; i < len(contextNames); i++ {
+			if strings.EqualFold(contextNames[i], name) {
+				return i
+			}
+		}
+		return -1
+	}
+	return func(datasets []DataSet) ([]DataSet, error) {
+		for i := 0; i < len(datasets); i++ {
+			if index := contextIndex(datasets[i].Name); index >= 0 {
+				context := datasets[i]
+				if contexts[index] != nil && sameHeaders(contexts[index].HeadersName, context.HeadersName) {
+					context.Rows = append(contexts[index].Rows, context.Rows...)
+				} else {
+					context.Rows = append([]RowData{}, context.Rows...)
+				}
+				contexts[index] = &context
+			}
+		}
+		result := make([]DataSet, len(datasets))
+		for i := 0; i < len(datasets); i++ {
+			result[i] = datasets[i]
+			if contextIndex(datasets[i].Name) >= 0 {
+				continue
+			}
+			for x := 0; x < len(contexts); x++ {
+				if contexts[x] == nil {
+					zap.S().Warnf("Context dataset:%s not found for joining dataset:%s", contextNames[x], datasets[i].Name)
+					continue
+				}
+				result[i] = JoinDataSets(result[i], *contexts[x], JoinKey)
+			}
+		}
+		return result, nil
+	}
+}
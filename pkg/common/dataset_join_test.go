@@ -0,0 +1,89 @@
+package common
+
+import (
+	"testing"
+)
+
+//joinContextFixture has a duplicated key and a comment also in the detail dataset
+var joinContextFixture = testFixture{name: "REPORT_FILE_INFO", schema: Schema{Columns: []ColumnSchema{
+	StrColumn("DataUUID", "").Required(), StrColumn("FileName", ""), StrColumn("Comment", "")}},
+	rows: []testRow{{"uuid-1", "a.xml", "file a"}, {"uuid-2", "b.xml", "file b"}, {"uuid-1", "c.xml", "duplicated"}}}
+
+//joinDetailFixture has keys in another order than the context and a key missing in the context
+var joinDetailFixture = testFixture{name: "ACTIVITIES", schema: Schema{Columns: []ColumnSchema{
+	StrColumn("DataUUID", "").Required(), FloatColumn("Md", ""), StrColumn("Comment", "")}},
+	rows: []testRow{{"uuid-2", 100.0, "activity"}, {"uuid-1", 100.0, "activity"}, {"uuid-3", 100.0, "activity"}}}
+
+func TestJoinDataSets(t *testing.T) {
+	context, detail := joinContextFixture.dataSet(), joinDetailFixture.dataSet()
+	joined := JoinDataSets(detail, context, JoinKey)
+	//the key and the comment already in the dataset are not added
+	if len(joined.HeadersName) != 4 || joined.HeadersName[3] != "FileName" {
+		t.Fatalf("Expected the file name added to the headers, got:%v", joined.HeadersName)
+	}
+	expected := []string{"b.xml", "a.xml", ""}
+	for i := 0; i < len(expected); i++ {
+		if value := csvValue(joined.Rows[i].Columns[3]); value != expected[i] {
+			t.Errorf("Row:%d expected file name:%s, got:%s", i, expected[i], value)
+		}
+	}
+	if err := joined.Validate(); err != nil || !joined.Schema.Columns[3].Nullable {
+		t.Errorf("Expected valid joined schema with nullable context columns, got:%v", err)
+	}
+	//the rows of the dataset are not changed
+	if len(detail.Rows[0].Columns) != 3 || len(detail.HeadersName) != 3 {
+		t.Errorf("Expected the joined dataset to be left unchanged")
+	}
+	//a context with one row is added to datasets without the key
+	facilities := DataSet{Name: "FACILITIES", HeadersName: []string{"FacilityName"}}
+	facilities.Rows = append(facilities.Rows, RowData{Columns: []ColumnData{{StrVal: "A", IsStr: true}}})
+	//and with null columns for a context with several rows, so the headers do not depend on the number of rows
+	if joined = JoinDataSets(facilities, context, JoinKey); len(joined.HeadersName) != 3 ||
+		!IsNullValue(joined.Rows[0].Columns[1]) || !IsNullValue(joined.Rows[0].Columns[2]) {
+		t.Errorf("Expected null context columns for a context with several rows, got:%v", joined.Rows[0].Columns)
+	}
+	context.Rows = context.Rows[:1]
+	if joined = JoinDataSets(facilities, context, JoinKey); len(joined.HeadersName) != 3 ||
+		joined.Rows[0].Columns[1].StrVal != "a.xml" || joined.HasSchema() {
+		t.Errorf("Expected the single context row added without schema, got:%v", joined.HeadersName)
+	}
+}
+
+func TestJoinTransform(t *testing.T) {
+	context, detail := joinContextFixture.dataSet(), joinDetailFixture.dataSet()
+	if ParseJoinTransform(" , ") != nil {
+		t.Errorf("Expected no transform without context names")
+	}
+	transform := ParseJoinTransform("report_file_info")
+	//datasets written one at a time are joined to the context seen before
+	for _, dataset := range []DataSet{context, detail} {
+		datasets, err := transform([]DataSet{dataset})
+		if err != nil || len(datasets) != 1 {
+			t.Fatalf("Failed in joining dataset:%s, %v", dataset.Name, err)
+		}
+		if dataset.Name == "REPORT_FILE_INFO" && len(datasets[0].HeadersName) != 3 {
+			t.Errorf("Expected context dataset output as is, got:%v", datasets[0].HeadersName)
+		} else if dataset.Name == "ACTIVITIES" && len(datasets[0].HeadersName) != 4 {
+			t.Errorf("Expected context columns joined to the detail dataset, got:%v", datasets[0].HeadersName)
+		}
+	}
+	//the rows of a context written in parts, one part per report, are kept together
+	transform = ParseJoinTransform("REPORT_FILE_INFO")
+	for i := 0; i < 2; i++ {
+		part := context
+		part.Rows = context.Rows[i : i+1]
+		if _, err := transform([]DataSet{part}); err != nil {
+			t.Fatalf("Failed in joining context part:%d, %v", i, err)
+		}
+	}
+	if datasets, err := transform([]DataSet{detail}); err != nil || datasets[0].Rows[0].Columns[3].StrVal != "b.xml" ||
+		datasets[0].Rows[1].Columns[3].StrVal != "a.xml" {
+		t.Errorf("Expected the detail joined to the context rows of all parts, got:%v,%v", datasets, err)
+	}
+	//the join runs before the query so the context columns can be filtered on
+	query, _ := ParseDataSetQuery("ACTIVITIES", `FileName == "a.xml"`, "", "")
+	datasets, err := ChainTransforms(ParseJoinTransform("REPORT_FILE_INFO"), nil, query.Transform())([]DataSet{context, detail})
+	if err != nil || len(datasets) != 1 || len(datasets[0].Rows) != 1 || datasets[0].Rows[0].Columns[0].StrVal != "uuid-1" {
+		t.Errorf("Expected the activity of a.xml, got:%v,%v", datasets, err)
+	}
+}
@@ -0,0 +1,417 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//Fill values of a pivot for the cells without a value
+const (
+	PivotFillNull     = "null"
+	PivotFillZero     = "zero"
+	PivotFillPrevious = "previous"
+)
+
+//Steps of a pivot for adding the missing periods of a time row column
+const (
+	PivotStepAuto  = "auto"
+	PivotStepNone  = "none"
+	PivotStepHour  = "hour"
+	PivotStepDay   = "day"
+	PivotStepMonth = "month"
+)
+
+//pivotAggregates holds the functions combining the values of duplicated periods, given the count, sum, min, max,
+//first and last value of a cell
+var pivotAggregates = map[string]func(cell *pivotCell) float64{
+	"sum":   func(cell *pivotCell) float64 { return cell.sum },
+	"mean":  func(cell *pivotCell) float64 { return cell.sum / float64(cell.count) },
+	"min":   func(cell *pivotCell) float64 { return cell.min },
+	"max":   func(cell *pivotCell) float64 { return cell.max },
+	"first": func(cell *pivotCell) float64 { return cell.first },
+	"last":  func(cell *pivotCell) float64 { return cell.last },
+}
+
+//Pivot turns a long dataset with one row per facility, flow, product and period into a wide one with one row per
+//period and one column per facility, flow and product, e.g. G-1_production_oil_Sm3. The column values are joined with _ to name
+//the columns, followed by the value column if more than one is given and by the unit of measure found in the
+//<value>UoM column
+type Pivot struct {
+	Rows      []string //columns identifying the output rows, e.g. dateStart
+	Columns   []string //columns whose values name the output columns, e.g. FacilityName,FlowKind,ProductKind
+	Values    []string //number columns holding the values, e.g. VolumeStd
+	Aggregate string   //how values of duplicated periods are combined: sum, mean, min, max, first or last
+	Fill      string   //value of cells without a value: null, zero or previous
+	Step      string   //step of the periods added for missing periods: auto, none, hour, day or month
+}
+
+//DefaultPivot returns the pivot of the standard volumes of each facility, flow and product per start date. The
+//flow kind keeps e.g. the production and export of a product apart, and of the values of a resent report for
+//the same period the last one is kept
+func DefaultPivot() Pivot {
+	return Pivot{Rows: []string{"dateStart"}, Columns: []string{"FacilityName", "FlowKind", "ProductKind"},
+		Values: []string{"VolumeStd"}, Aggregate: "last", Fill: PivotFillNull, Step: PivotStepAuto}
+}
+
+//ParsePivot parses a pivot given as ; separated <key>=<value> pairs with the keys rows, columns, values,
+//aggregate, fill and step, e.g. columns=FacilityName,ProductKind;values=Volume;fill=zero. Keys left out are
+//taken from DefaultPivot and default gives the default pivot, nil is returned for an empty pivot
+func ParsePivot(spec string) (*Pivot, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	pivot := DefaultPivot()
+	if strings.EqualFold(spec, "default") {
+		return &pivot, nil
+	}
+	for _, part := range strings.Split(spec, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("Invalid pivot:%s, expected <key>=<value> but got:%s", spec, part)
+		}
+		key := strings.ToLower(strings.TrimSpace(keyValue[0]))
+		value := strings.TrimSpace(keyValue[1])
+		switch key {
+		case "rows":
+			pivot.Rows = splitList(value)
+		case "columns":
+			pivot.Columns = splitList(value)
+		case "values":
+			pivot.Values = splitList(value)
+		case "aggregate":
+			pivot.Aggregate = strings.ToLower(value)
+		case "fill":
+			pivot.Fill = strings.ToLower(value)
+		case "step":
+			pivot.Step = strings.ToLower(value)
+		default:
+			return nil, fmt.Errorf("Invalid pivot:%s, unknown key:%s, expected rows, columns, values, aggregate, fill or step",
+				spec, key)
+		}
+	}
+	if err := pivot.Verify(); err != nil {
+		return nil, err
+	}
+	return &pivot, nil
+}
+
+//Verify checks that the pivot has row, column and value columns and known aggregate, fill and step
+func (pivot Pivot) Verify() error {
+	if len(pivot.Rows) == 0 || len(pivot.Columns) == 0 || len(pivot.Values) == 0 {
+		return fmt.Errorf("Pivot requires rows, columns and values, got rows:%v columns:%v values:%v",
+			pivot.Rows, pivot.Columns, pivot.Values)
+	}
+	if _, found := pivotAggregates[pivot.Aggregate]; !found {
+		return fmt.Errorf("Invalid pivot aggregate:%s, expected sum, mean, min, max, first or last", pivot.Aggregate)
+	}
+	switch pivot.Fill {
+	case PivotFillNull, PivotFillZero, PivotFillPrevious:
+	default:
+		return fmt.Errorf("Invalid pivot fill:%s, expected null, zero or previous", pivot.Fill)
+	}
+	switch pivot.Step {
+	case PivotStepAuto, PivotStepNone, PivotStepHour, PivotStepDay, PivotStepMonth:
+	default:
+		return fmt.Errorf("Invalid pivot step:%s, expected auto, none, hour, day or month", pivot.Step)
+	}
+	return nil
+}
+
+//Transform returns the transform pivoting the datasets having the pivot columns, other datasets are output as
+//they are, nil for a nil pivot
+func (pivot *Pivot) Transform() DataSetTransform {
+	if pivot == nil {
+		return nil
+	}
+	return func(datasets []DataSet) ([]DataSet, error) {
+		result := make([]DataSet, len(datasets))
+		for i := 0; i < len(datasets); i++ {
+			pivoted, err := pivot.Apply(datasets[i])
+			if err != nil {
+				return nil, err
+			}
+			result[i] = pivoted
+		}
+		return result, nil
+	}
+}
+
+//pivotCell holds the values of the rows going into one cell of the pivot
+type pivotCell struct {
+	count                      int
+	sum, min, max, first, last float64
+}
+
+func (cell *pivotCell) add(value float64) {
+	if cell.count == 0 {
+		cell.min, cell.max, cell.first = value, value, value
+	}
+	cell.count++
+	cell.sum += value
+	cell.min = math.Min(cell.min, value)
+	cell.max = math.Max(cell.max, value)
+	cell.last = value
+}
+
+//pivotRow is one output row of the pivot with the values of the row columns and the cells by column name
+type pivotRow struct {
+	keys  []ColumnData
+	cells map[string]*pivotCell
+}
+
+//pivotKeyValue returns the text identifying a row or column value, times are compared as instants
+func pivotKeyValue(column ColumnData) string {
+	if column.IsTime && !IsNullValue(column) {
+		return column.TimeValue.UTC().Format(time.RFC3339Nano)
+	}
+	return csvValue(column)
+}
+
+//Apply returns the dataset pivoted, the rows are sorted on the row columns and values of rows with the same row
+//and column values are combined by the aggregate. Missing periods are added for a single time row column and
+//cells without a value are filled by the fill. A dataset without the pivot columns is returned as it is
+func (pivot Pivot) Apply(dataset DataSet) (DataSet, error) {
+	rowIndexes, rowsFound := pivotColumnIndexes(dataset.HeadersName, pivot.Rows)
+	columnIndexes, columnsFound := pivotColumnIndexes(dataset.HeadersName, pivot.Columns)
+	valueIndexes, valuesFound := pivotColumnIndexes(dataset.HeadersName, pivot.Values)
+	if !rowsFound || !columnsFound || !valuesFound {
+		zap.S().Debugf("Dataset:%s does not have the pivot columns, output as it is", dataset.Name)
+		return dataset, nil
+	}
+	unitIndexes := make([]int, len(valueIndexes))
+	for i := 0; i < len(valueIndexes); i++ {
+		unitIndexes[i] = columnIndex(dataset.HeadersName, dataset.HeadersName[valueIndexes[i]]+"UoM")
+	}
+	var rows []*pivotRow
+	rowsByKey := make(map[string]*pivotRow)
+	columnNames := make(map[string]ColumnSchema)
+	columnBases := make(map[string]bool)
+	nullColumns := make(map[string]ColumnSchema)
+	duplicates := 0
+	for x := 0; x < len(dataset.Rows); x++ {
+		keys := make([]ColumnData, len(rowIndexes))
+		keyValues := make([]string, len(rowIndexes))
+		for i := 0; i < len(rowIndexes); i++ {
+			keys[i] = rowColumn(dataset.Rows[x], rowIndexes[i])
+			keyValues[i] = pivotKeyValue(keys[i])
+		}
+		key := strings.Join(keyValues, "\x1f")
+		row, found := rowsByKey[key]
+		if !found {
+			row = &pivotRow{keys: keys, cells: make(map[string]*pivotCell)}
+			rowsByKey[key] = row
+			rows = append(rows, row)
+		}
+		var nameParts []string
+		for i := 0; i < len(columnIndexes); i++ {
+			if part := strings.TrimSpace(csvValue(rowColumn(dataset.Rows[x], columnIndexes[i]))); part != "" {
+				nameParts = append(nameParts, part)
+			}
+		}
+		for i := 0; i < len(valueIndexes); i++ {
+			valueName := dataset.HeadersName[valueIndexes[i]]
+			parts := append([]string{}, nameParts...)
+			if len(valueIndexes) > 1 {
+				parts = append(parts, valueName)
+			}
+			unit := ""
+			if unitIndexes[i] >= 0 {
+				unit = strings.TrimSpace(csvValue(rowColumn(dataset.Rows[x], unitIndexes[i])))
+			}
+			base := strings.Join(parts, "_")
+			description := valueName + " of " + strings.Join(nameParts, " ")
+			value := rowColumn(dataset.Rows[x], valueIndexes[i])
+			//a missing value without a unit only gives a column if no value of the facility and product has a unit
+			if IsNullValue(value) && unit == "" {
+				if _, found := nullColumns[base]; !found {
+					nullColumns[base] = FloatColumn(base, description)
+				}
+				continue
+			}
+			name := base
+			if unit != "" {
+				name = base + "_" + unit
+			}
+			if _, found := columnNames[name]; !found {
+				column := FloatColumn(name, description)
+				column.Unit = unit
+				columnNames[name] = column
+				columnBases[base] = true
+			}
+			if IsNullValue(value) {
+				continue
+			}
+			number, isNumber := columnNumber(value)
+			if !isNumber {
+				return dataset, fmt.Errorf("Dataset:%s row:%d column:%s has the value:%s which is not a number to pivot",
+					dataset.Name, x, valueName, csvValue(value))
+			}
+			cell, found := row.cells[name]
+			if !found {
+				cell = &pivotCell{}
+				row.cells[name] = cell
+			} else {
+				duplicates++
+			}
+			cell.add(number)
+		}
+	}
+	for base, column := range nullColumns {
+		if _, found := columnNames[base]; !found && !columnBases[base] {
+			columnNames[base] = column
+		}
+	}
+	if duplicates > 0 {
+		zap.S().Warnf("Dataset:%s has %d values for an already pivoted period and column, combined by:%s",
+			dataset.Name, duplicates, pivot.Aggregate)
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		for i := 0; i < len(rowIndexes); i++ {
+			aNull, bNull := IsNullValue(rows[a].keys[i]), IsNullValue(rows[b].keys[i])
+			if aNull || bNull {
+				if aNull != bNull {
+					return bNull
+				}
+				continue
+			}
+			if compared := compareColumnData(rows[a].keys[i], rows[b].keys[i]); compared != 0 {
+				return compared < 0
+			}
+		}
+		return false
+	})
+	if len(rowIndexes) == 1 {
+		rows = pivot.addMissingPeriods(dataset.Name, rows)
+	}
+	return pivot.build(dataset, rowIndexes, rows, columnNames), nil
+}
+
+//pivotColumnIndexes returns the indexes of the named columns, false if a column is not found
+func pivotColumnIndexes(headers []string, names []string) ([]int, bool) {
+	indexes := make([]int, len(names))
+	for i := 0; i < len(names); i++ {
+		if indexes[i] = columnIndex(headers, names[i]); indexes[i] < 0 {
+			return nil, false
+		}
+	}
+	return indexes, true
+}
+
+//build returns the pivoted dataset of the sorted rows with the row columns first and the value columns sorted by
+//name, the schema types the row columns as in the dataset and the value columns as nullable floats
+func (pivot Pivot) build(dataset DataSet, rowIndexes []int, rows []*pivotRow, columns map[string]ColumnSchema) DataSet {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	datasetSchema := dataset.ColumnSchemas()
+	schema := Schema{}
+	for i := 0; i < len(rowIndexes); i++ {
+		schema = schema.Append(datasetSchema[rowIndexes[i]])
+	}
+	headers := uniqueColumnNames(append(schema.Headers(), names...))
+	for i := 0; i < len(names); i++ {
+		column := columns[names[i]]
+		column.Name = headers[len(rowIndexes)+i]
+		schema = schema.Append(column)
+	}
+	pivoted := NewDataSet(dataset.Name, schema)
+	aggregate := pivotAggregates[pivot.Aggregate]
+	previous := make([]*float64, len(names))
+	for x := 0; x < len(rows); x++ {
+		row := RowData{Columns: append([]ColumnData{}, rows[x].keys...)}
+		for i := 0; i < len(names); i++ {
+			if cell, found := rows[x].cells[names[i]]; found {
+				value := aggregate(cell)
+				previous[i] = &value
+				row.AddFloatValue(value)
+			} else if pivot.Fill == PivotFillZero {
+				row.AddFloatValue(0)
+			} else if pivot.Fill == PivotFillPrevious && previous[i] != nil {
+				row.AddFloatValue(*previous[i])
+			} else {
+				row.AddNullFloatValue()
+			}
+		}
+		pivoted.Rows = append(pivoted.Rows, row)
+	}
+	zap.S().Debugf("Pivoted dataset:%s from %d rows to %d rows and %d value columns", dataset.Name,
+		len(dataset.Rows), len(pivoted.Rows), len(names))
+	return pivoted
+}
+
+//addMissingPeriods adds rows without values for the periods missing between the sorted time rows, the step is
+//found from the times if the pivot step is auto
+func (pivot Pivot) addMissingPeriods(datasetName string, rows []*pivotRow) []*pivotRow {
+	var times []time.Time
+	for i := 0; i < len(rows); i++ {
+		if !rows[i].keys[0].IsTime || IsNullValue(rows[i].keys[0]) {
+			return rows
+		}
+		//the periods follow the days and months of the time zone, e.g. days of 23 or 25 hours at daylight saving
+		times = append(times, InTimezone(rows[i].keys[0].TimeValue))
+	}
+	step := pivot.Step
+	if step == PivotStepAuto {
+		step = pivotTimeStep(times)
+	}
+	next, found := pivotNextTime[step]
+	if !found || len(rows) < 2 {
+		return rows
+	}
+	var filled []*pivotRow
+	for i := 0; i < len(rows); i++ {
+		filled = append(filled, rows[i])
+		if i == len(rows)-1 {
+			break
+		}
+		for period := next(times[i]); period.Before(times[i+1]); period = next(period) {
+			filled = append(filled, &pivotRow{keys: []ColumnData{{TimeValue: period, IsTime: true}}})
+		}
+	}
+	if added := len(filled) - len(rows); added > 0 {
+		zap.S().Infof("Dataset:%s was missing %d periods with step:%s, added without values", datasetName, added, step)
+	}
+	return filled
+}
+
+//pivotNextTime holds the functions giving the next period of each step
+var pivotNextTime = map[string]func(period time.Time) time.Time{
+	PivotStepHour:  func(period time.Time) time.Time { return period.Add(time.Hour) },
+	PivotStepDay:   func(period time.Time) time.Time { return period.AddDate(0, 0, 1) },
+	PivotStepMonth: func(period time.Time) time.Time { return period.AddDate(0, 1, 0) },
+}
+
+//pivotTimeStep returns the step of the sorted times, the first of month, day and hour where all times are at the
+//same point of the step and some times follow each other by one step, none if no step is found
+func pivotTimeStep(times []time.Time) string {
+	sameDay, sameTime, wholeHour := times[0].Day() <= 28, true, true
+	for i := 0; i < len(times); i++ {
+		sameDay = sameDay && times[i].Day() == times[0].Day()
+		sameTime = sameTime && times[i].Hour() == times[0].Hour() && times[i].Minute() == times[0].Minute() &&
+			times[i].Second() == times[0].Second() && times[i].Nanosecond() == times[0].Nanosecond()
+		wholeHour = wholeHour && times[i].Minute() == 0 && times[i].Second() == 0 && times[i].Nanosecond() == 0
+	}
+	aligned := map[string]bool{PivotStepMonth: sameDay && sameTime, PivotStepDay: sameTime, PivotStepHour: wholeHour}
+	for _, step := range []string{PivotStepMonth, PivotStepDay, PivotStepHour} {
+		if !aligned[step] {
+			continue
+		}
+		for i := 1; i < len(times); i++ {
+			if pivotNextTime[step](times[i-1]).Equal(times[i]) {
+				return step
+			}
+		}
+	}
+	return PivotStepNone
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+//pivotDay returns the day of january 2020 of a pivot row
+func pivotDay(day int) time.Time {
+	return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+//pivotFixture has a resent report for the same period, another flow of the product and periods without a value
+var pivotFixture = testFixture{name: "WELLBORE", headers: []string{"DataUUID", "FacilityName", "FlowKind",
+	"ProductKind", "dateStart", "VolumeStd", "VolumeStdUoM"},
+	rows: []testRow{
+		{"uuid", "G-2", "production", "oil", pivotDay(2), NewMeasure(20, "Sm3")},
+		{"uuid", "G-1", "production", "oil", pivotDay(1), NewMeasure(10, "Sm3")},
+		{"uuid", "G-1", "production", "gas", pivotDay(1), NewMeasure(1000, "Sm3")},
+		{"uuid", "G-1", "production", "oil", pivotDay(2), NewMeasure(11, "Sm3")},
+		{"uuid", "G-1", "production", "oil", pivotDay(2), NewMeasure(12, "Sm3")},
+		{"uuid", "G-1", "export", "oil", pivotDay(2), NewMeasure(5, "Sm3")},
+		{"uuid", "G-2", "production", "oil", pivotDay(4), testNullFloat, ""},
+		{"uuid", "G-3", "production", "oil", pivotDay(4), testNullFloat, ""},
+	}}
+
+func pivotValues(t *testing.T, dataset DataSet, column string) []string {
+	index := columnIndex(dataset.HeadersName, column)
+	if index < 0 {
+		t.Fatalf("Expected pivot column:%s, got:%v", column, dataset.HeadersName)
+	}
+	var values []string
+	for i := 0; i < len(dataset.Rows); i++ {
+		values = append(values, csvValue(dataset.Rows[i].Columns[index]))
+	}
+	return values
+}
+
+func expectValues(t *testing.T, name string, expected []string, values []string) {
+	if len(expected) != len(values) {
+		t.Errorf("%s expected:%v, got:%v", name, expected, values)
+		return
+	}
+	for i := 0; i < len(expected); i++ {
+		if expected[i] != values[i] {
+			t.Errorf("%s expected:%v, got:%v", name, expected, values)
+			return
+		}
+	}
+}
+
+func TestParsePivot(t *testing.T) {
+	invalid := []string{"rows", "rows=;values=Volume", "aggregate=median", "fill=last", "step=week", "unit=Sm3"}
+	for _, spec := range invalid {
+		if _, err := ParsePivot(spec); err == nil {
+			t.Errorf("Expected error for pivot:%s", spec)
+		}
+	}
+	if pivot, err := ParsePivot(" "); pivot != nil || err != nil || pivot.Transform() != nil {
+		t.Errorf("Expected no pivot for empty spec")
+	}
+	pivot, err := ParsePivot("Values=volume, volumeStd; fill=Zero")
+	if err != nil {
+		t.Fatalf("Failed in parsing pivot:%s", err.Error())
+	}
+	if len(pivot.Values) != 2 || pivot.Fill != PivotFillZero || pivot.Rows[0] != "dateStart" || pivot.Aggregate != "last" {
+		t.Errorf("Expected values and fill set and the rest defaulted, got:%+v", *pivot)
+	}
+}
+
+func TestPivotApply(t *testing.T) {
+	pivot := DefaultPivot()
+	pivoted, err := pivot.Apply(pivotFixture.dataSet())
+	if err != nil {
+		t.Fatalf("Failed in pivoting:%s", err.Error())
+	}
+	expectValues(t, "headers", []string{"dateStart", "G-1_export_oil_Sm3", "G-1_production_gas_Sm3",
+		"G-1_production_oil_Sm3", "G-2_production_oil_Sm3", "G-3_production_oil"}, pivoted.HeadersName)
+	if err = pivoted.Validate(); err != nil || pivoted.Schema.Columns[3].Unit != "Sm3" {
+		t.Errorf("Expected valid pivot with units in the schema, got:%v, %+v", err, pivoted.Schema.Columns)
+	}
+	//the missing 3rd is added, the resent report replaces the value and missing values are null
+	expectValues(t, "dates", []string{"2020-01-01 00:00:00", "2020-01-02 00:00:00", "2020-01-03 00:00:00",
+		"2020-01-04 00:00:00"}, pivotValues(t, pivoted, "dateStart"))
+	expectValues(t, "oil", []string{"10", "12", "", ""}, pivotValues(t, pivoted, "G-1_production_oil_Sm3"))
+	expectValues(t, "export", []string{"", "5", "", ""}, pivotValues(t, pivoted, "G-1_export_oil_Sm3"))
+	expectValues(t, "gas", []string{"1000", "", "", ""}, pivotValues(t, pivoted, "G-1_production_gas_Sm3"))
+	expectValues(t, "without values", []string{"", "", "", ""}, pivotValues(t, pivoted, "G-3_production_oil"))
+	pivot.Aggregate = "sum"
+	pivoted, _ = pivot.Apply(pivotFixture.dataSet())
+	expectValues(t, "sum oil", []string{"10", "23", "", ""}, pivotValues(t, pivoted, "G-1_production_oil_Sm3"))
+	pivot.Aggregate, pivot.Fill, pivot.Step = "first", PivotFillPrevious, PivotStepNone
+	pivoted, _ = pivot.Apply(pivotFixture.dataSet())
+	expectValues(t, "first oil", []string{"10", "11", "11"}, pivotValues(t, pivoted, "G-1_production_oil_Sm3"))
+	expectValues(t, "previous gas", []string{"1000", "1000", "1000"}, pivotValues(t, pivoted, "G-1_production_gas_Sm3"))
+	pivot.Fill = PivotFillZero
+	pivoted, _ = pivot.Apply(pivotFixture.dataSet())
+	expectValues(t, "zero G-2", []string{"0", "20", "0"}, pivotValues(t, pivoted, "G-2_production_oil_Sm3"))
+	//datasets without the pivot columns are output as they are
+	info := DataSet{Name: "DOCUMENT_INFO", HeadersName: []string{"DocumentName"}}
+	pivot = DefaultPivot()
+	datasets, err := pivot.Transform()([]DataSet{info})
+	if err != nil || len(datasets) != 1 || len(datasets[0].HeadersName) != 1 {
+		t.Errorf("Expected dataset without pivot columns output as it is, got:%v,%v", datasets, err)
+	}
+}
+
+func TestPivotTimeStep(t *testing.T) {
+	day := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2020, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		times []time.Time
+		step  string
+	}{
+		{[]time.Time{day(1, 1, 0), day(2, 1, 0), day(4, 1, 0)}, PivotStepMonth},
+		{[]time.Time{day(1, 1, 6), day(1, 2, 6), day(1, 5, 6)}, PivotStepDay},
+		{[]time.Time{day(1, 1, 0), day(1, 1, 1), day(1, 1, 6)}, PivotStepHour},
+		//weekly reports have no step between them and are not filled
+		{[]time.Time{day(1, 1, 0), day(1, 8, 0), day(1, 15, 0)}, PivotStepNone},
+	}
+	for _, test := range tests {
+		if step := pivotTimeStep(test.times); step != test.step {
+			t.Errorf("Expected step:%s for times:%v, got:%s", test.step, test.times, step)
+		}
+	}
+}
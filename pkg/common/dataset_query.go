@@ -0,0 +1,796 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+//DataSetTransform changes the datasets built from the reports before they are written, e.g. a query selecting
+//datasets, rows and columns. The output functions take a nil transform to write the datasets as built
+type DataSetTransform func(datasets []DataSet) ([]DataSet, error)
+
+//TransformDataSets applies the transform to the datasets, if any
+func TransformDataSets(datasets []DataSet, transform DataSetTransform) ([]DataSet, error) {
+	if transform == nil {
+		return datasets, nil
+	}
+	return transform(datasets)
+}
+
+//ChainTransforms returns a transform applying the transforms in order, nil transforms are skipped
+func ChainTransforms(transforms ...DataSetTransform) DataSetTransform {
+	var chain []DataSetTransform
+	for i := 0; i < len(transforms); i++ {
+		if transforms[i] != nil {
+			chain = append(chain, transforms[i])
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	return func(datasets []DataSet) ([]DataSet, error) {
+		var err error
+		for i := 0; i < len(chain) && err == nil; i++ {
+			datasets, err = chain[i](datasets)
+		}
+		return datasets, err
+	}
+}
+
+//StreamTransforms are the transforms of a conversion split for writing the rows as they are built. The row
+//transforms give the same rows whether applied to all rows of a dataset or to one part at a time, e.g. filtering,
+//selecting columns, converting units and joining report columns, the dataset transforms need all rows of a
+//dataset, e.g. sorting and pivoting
+type StreamTransforms struct {
+	Rows    DataSetTransform
+	DataSet DataSetTransform
+}
+
+//Transform returns the row and dataset transforms chained, for datasets built with all their rows
+func (transforms StreamTransforms) Transform() DataSetTransform {
+	return ChainTransforms(transforms.Rows, transforms.DataSet)
+}
+
+//ColumnSelection is a column to keep in the datasets, renamed if Rename is set
+type ColumnSelection struct {
+	Name   string
+	Rename string
+}
+
+//SortColumn is a column to sort the rows on
+type SortColumn struct {
+	Name       string
+	Descending bool
+}
+
+//DataSetQuery selects the datasets, rows and columns to output and the order of the rows. Dataset and column
+//names are matched ignoring case, dataset names may use the wildcards * and ?
+type DataSetQuery struct {
+	Datasets []string          //datasets to keep, all if empty
+	Filter   *Filter           //rows to keep, all if nil
+	Columns  []ColumnSelection //columns to keep in the given order, all if empty
+	Sort     []SortColumn      //columns to sort the rows on
+	matches  *queryMatches     //the filter and columns found in the datasets queried, shared by the parts of a split
+}
+
+//queryMatches records whether the filter of a query has been applied to a dataset and the selected columns found
+//in the datasets, so that a filter or columns not matching any dataset are warned of once
+type queryMatches struct {
+	queried  bool
+	filtered bool
+	columns  map[string]bool
+}
+
+//ParseDataSetQuery parses the comma separated dataset names, the filter expression, the comma separated columns
+//to keep as <column> or <column>=<new name> and the comma separated columns to sort on as <column> [asc|desc]
+func ParseDataSetQuery(datasets string, filter string, columns string, sortColumns string) (DataSetQuery, error) {
+	var err error
+	query := DataSetQuery{Datasets: splitList(datasets), matches: &queryMatches{columns: make(map[string]bool)}}
+	if strings.TrimSpace(filter) != "" {
+		if query.Filter, err = ParseFilter(filter); err != nil {
+			return query, err
+		}
+	}
+	for _, column := range splitList(columns) {
+		selection := ColumnSelection{Name: column}
+		if i := strings.Index(column, "="); i >= 0 {
+			selection = ColumnSelection{Name: strings.TrimSpace(column[:i]), Rename: strings.TrimSpace(column[i+1:])}
+		}
+		if selection.Name == "" {
+			return query, fmt.Errorf("Missing column name in column selection:%s", column)
+		}
+		query.Columns = append(query.Columns, selection)
+	}
+	for _, column := range splitList(sortColumns) {
+		fields := strings.Fields(column)
+		sortColumn := SortColumn{Name: fields[0]}
+		if len(fields) > 2 {
+			return query, fmt.Errorf("Invalid sort column:%s, expected <column> [asc|desc]", column)
+		} else if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				sortColumn.Descending = true
+			default:
+				return query, fmt.Errorf("Invalid sort order:%s, expected asc or desc", fields[1])
+			}
+		}
+		query.Sort = append(query.Sort, sortColumn)
+	}
+	for _, pattern := range query.Datasets {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return query, fmt.Errorf("Invalid dataset name:%s, error:%s", pattern, err.Error())
+		}
+	}
+	return query, nil
+}
+
+//splitList returns the trimmed non empty entries of the comma separated list
+func splitList(list string) []string {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+//IsEmpty returns true if the query keeps the datasets as they are
+func (query DataSetQuery) IsEmpty() bool {
+	return len(query.Datasets) == 0 && query.Filter == nil && len(query.Columns) == 0 && len(query.Sort) == 0
+}
+
+//Transform returns the query as a transform, nil if the query keeps the datasets as they are
+func (query DataSetQuery) Transform() DataSetTransform {
+	if query.IsEmpty() {
+		return nil
+	}
+	return query.Apply
+}
+
+//Split returns the part of the query selecting datasets, rows and columns and the part sorting the rows, which
+//needs all rows of a dataset. The columns are selected after sorting if the query sorts, as the sort columns
+//may not be selected
+func (query DataSetQuery) Split() (DataSetQuery, DataSetQuery) {
+	rows := DataSetQuery{Datasets: query.Datasets, Filter: query.Filter, Columns: query.Columns,
+		matches: query.matches}
+	if len(query.Sort) == 0 {
+		return rows, DataSetQuery{}
+	}
+	rows.Columns = nil
+	return rows, DataSetQuery{Columns: query.Columns, Sort: query.Sort, matches: query.matches}
+}
+
+//Apply selects the datasets, filters and sorts the rows and selects the columns of the datasets. The filter and
+//sort are only applied to datasets having their columns, datasets without any of the selected columns are left out
+func (query DataSetQuery) Apply(datasets []DataSet) ([]DataSet, error) {
+	var err error
+	var result []DataSet
+	for i := 0; i < len(datasets); i++ {
+		dataset := datasets[i]
+		if !query.keepsDataSet(dataset.Name) {
+			continue
+		}
+		query.match(dataset)
+		if query.Filter != nil {
+			if dataset, err = query.Filter.Apply(dataset); err != nil {
+				return nil, err
+			}
+		}
+		dataset = SortDataSet(dataset, query.Sort)
+		if len(query.Columns) > 0 {
+			var found bool
+			if dataset, found = SelectColumns(dataset, query.Columns); !found {
+				zap.S().Infof("Leaving out dataset:%s without any of the selected columns", dataset.Name)
+				continue
+			}
+		}
+		result = append(result, dataset)
+	}
+	return result, nil
+}
+
+//match records the filter and the selected columns found in the dataset
+func (query DataSetQuery) match(dataset DataSet) {
+	if query.matches == nil {
+		return
+	}
+	query.matches.queried = true
+	if query.Filter != nil && query.Filter.hasColumns(dataset) {
+		query.matches.filtered = true
+	}
+	for i := 0; i < len(query.Columns); i++ {
+		if columnIndex(dataset.HeadersName, query.Columns[i].Name) >= 0 {
+			query.matches.columns[strings.ToLower(query.Columns[i].Name)] = true
+		}
+	}
+}
+
+//WarnUnmatched logs a warning if the filter was not applied as no dataset has all its columns and for the selected
+//columns not found in any dataset, called when the conversion is done so each is warned of once also when the
+//datasets are queried one part at a time
+func (query DataSetQuery) WarnUnmatched() {
+	if query.matches == nil || !query.matches.queried {
+		return
+	}
+	if query.Filter != nil && !query.matches.filtered {
+		zap.S().Warnf("No dataset has all the columns:%s of the filter:%s, no rows were filtered",
+			strings.Join(query.Filter.Columns(), ","), query.Filter.String())
+	}
+	var unknown []string
+	for i := 0; i < len(query.Columns); i++ {
+		if !query.matches.columns[strings.ToLower(query.Columns[i].Name)] {
+			unknown = append(unknown, query.Columns[i].Name)
+		}
+	}
+	if len(unknown) > 0 {
+		zap.S().Warnf("Selected columns:%s are not found in any dataset and left out", strings.Join(unknown, ","))
+	}
+}
+
+//keepsDataSet returns true if the dataset name matches one of the dataset names of the query
+func (query DataSetQuery) keepsDataSet(name string) bool {
+	if len(query.Datasets) == 0 {
+		return true
+	}
+	for i := 0; i < len(query.Datasets); i++ {
+		if matched, _ := path.Match(strings.ToUpper(query.Datasets[i]), strings.ToUpper(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+//SelectDataSets returns the datasets with names matching one of the names, ignoring case and with the
+//wildcards * and ?
+func SelectDataSets(datasets []DataSet, names []string) []DataSet {
+	var result []DataSet
+	query := DataSetQuery{Datasets: names}
+	for i := 0; i < len(datasets); i++ {
+		if query.keepsDataSet(datasets[i].Name) {
+			result = append(result, datasets[i])
+		}
+	}
+	return result
+}
+
+//columnIndex returns the index of the header matching the name ignoring case, -1 if not found
+func columnIndex(headers []string, name string) int {
+	for i := 0; i < len(headers); i++ {
+		if headers[i] == name {
+			return i
+		}
+	}
+	for i := 0; i < len(headers); i++ {
+		if strings.EqualFold(headers[i], name) {
+			return i
+		}
+	}
+	return -1
+}
+
+//sameHeaders returns true if the headers are the same and in the same order
+func sameHeaders(headers []string, other []string) bool {
+	if len(headers) != len(other) {
+		return false
+	}
+	for i := 0; i < len(headers); i++ {
+		if headers[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//SelectColumns returns the dataset with the selected columns found in the dataset in the order selected, renamed
+//if given. Returns false if none of the columns are found
+func SelectColumns(dataset DataSet, columns []ColumnSelection) (DataSet, bool) {
+	var indexes []int
+	selected := DataSet{Name: dataset.Name}
+	for i := 0; i < len(columns); i++ {
+		index := columnIndex(dataset.HeadersName, columns[i].Name)
+		if index < 0 {
+			continue
+		}
+		name := dataset.HeadersName[index]
+		if columns[i].Rename != "" {
+			name = columns[i].Rename
+		}
+		indexes = append(indexes, index)
+		selected.HeadersName = append(selected.HeadersName, name)
+		if dataset.HasSchema() && index < len(dataset.Schema.Columns) {
+			column := dataset.Schema.Columns[index]
+			column.Name = name
+			selected.Schema.Columns = append(selected.Schema.Columns, column)
+		}
+	}
+	if len(indexes) == 0 {
+		return selected, false
+	}
+	for x := 0; x < len(dataset.Rows); x++ {
+		row := RowData{Columns: make([]ColumnData, len(indexes))}
+		for y := 0; y < len(indexes); y++ {
+			if indexes[y] < len(dataset.Rows[x].Columns) {
+				row.Columns[y] = dataset.Rows[x].Columns[indexes[y]]
+			} else {
+				row.Columns[y] = ColumnData{IsEmptyColumn: true}
+			}
+		}
+		selected.Rows = append(selected.Rows, row)
+	}
+	return selected, true
+}
+
+//SortDataSet returns the dataset with the rows sorted on the sort columns found in the dataset, missing values
+//are sorted last and rows with equal values keep their order
+func SortDataSet(dataset DataSet, columns []SortColumn) DataSet {
+	var indexes []int
+	var descending []bool
+	for i := 0; i < len(columns); i++ {
+		if index := columnIndex(dataset.HeadersName, columns[i].Name); index >= 0 {
+			indexes = append(indexes, index)
+			descending = append(descending, columns[i].Descending)
+		}
+	}
+	if len(indexes) == 0 {
+		return dataset
+	}
+	rows := make([]RowData, len(dataset.Rows))
+	copy(rows, dataset.Rows)
+	sort.SliceStable(rows, func(a, b int) bool {
+		for i := 0; i < len(indexes); i++ {
+			valueA, valueB := rowColumn(rows[a], indexes[i]), rowColumn(rows[b], indexes[i])
+			nullA, nullB := IsNullValue(valueA), IsNullValue(valueB)
+			if nullA || nullB {
+				if nullA == nullB {
+					continue
+				}
+				return nullB
+			}
+			c := compareColumnData(valueA, valueB)
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != descending[i]
+		}
+		return false
+	})
+	dataset.Rows = rows
+	return dataset
+}
+
+//rowColumn returns the column at index of the row, an empty column if the row is shorter
+func rowColumn(row RowData, index int) ColumnData {
+	if index < len(row.Columns) {
+		return row.Columns[index]
+	}
+	return ColumnData{IsEmptyColumn: true}
+}
+
+//columnNumber returns the int or float value of the column as a float, false if not a number
+func columnNumber(column ColumnData) (float64, bool) {
+	if column.IsFloat {
+		return column.FloatVal, true
+	} else if column.IsInt {
+		return float64(column.IntVal), true
+	}
+	return 0, false
+}
+
+//compareColumnData compares the values of two non null columns, numbers and times by value and other values by
+//their text, returns -1, 0 or 1
+func compareColumnData(a ColumnData, b ColumnData) int {
+	numberA, isNumberA := columnNumber(a)
+	numberB, isNumberB := columnNumber(b)
+	switch {
+	case isNumberA && isNumberB:
+		return compareFloats(numberA, numberB)
+	case a.IsTime && b.IsTime:
+		return compareTimes(a.TimeValue, b.TimeValue)
+	}
+	return strings.Compare(csvValue(a), csvValue(b))
+}
+
+func compareFloats(a float64, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a time.Time, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+//Filter is a parsed row filter expression. Comparisons are written as <column> <operator> <value> with the
+//operators ==, !=, <, <=, > and >=, combined with and, or, not and parentheses. Values are compared as numbers,
+//times or text after the type of the column, text values with spaces or operator characters must be quoted and
+//null matches missing values. Missing values are only matched by != and == null
+type Filter struct {
+	expression string
+	root       filterNode
+}
+
+//filterNode is a node of the parsed filter expression
+type filterNode interface {
+	//bind returns the function matching rows of the dataset with the columns typed by the schema
+	bind(dataset DataSet, schema []ColumnSchema) (func(row RowData) bool, error)
+	//columns adds the names of the columns used by the node
+	columns(names []string) []string
+}
+
+//ParseFilter parses the filter expression
+func ParseFilter(expression string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid filter:%s, error:%s", expression, err.Error())
+	}
+	if parser.position < len(tokens) {
+		return nil, fmt.Errorf("Invalid filter:%s, error:unexpected %s", expression, tokens[parser.position].text)
+	}
+	return &Filter{expression: expression, root: root}, nil
+}
+
+func (filter *Filter) String() string {
+	return filter.expression
+}
+
+//Columns returns the names of the columns used in the filter
+func (filter *Filter) Columns() []string {
+	return filter.root.columns(nil)
+}
+
+//Apply returns the dataset with the rows matching the filter, datasets without all the columns of the filter
+//are returned as they are
+func (filter *Filter) Apply(dataset DataSet) (DataSet, error) {
+	if !filter.hasColumns(dataset) {
+		zap.S().Debugf("Filter not applied to dataset:%s without all the columns:%s", dataset.Name,
+			strings.Join(filter.Columns(), ","))
+		return dataset, nil
+	}
+	match, err := filter.root.bind(dataset, dataset.ColumnSchemas())
+	if err != nil {
+		return dataset, fmt.Errorf("Failed in filtering dataset:%s, error:%s", dataset.Name, err.Error())
+	}
+	var rows []RowData
+	for i := 0; i < len(dataset.Rows); i++ {
+		if match(dataset.Rows[i]) {
+			rows = append(rows, dataset.Rows[i])
+		}
+	}
+	dataset.Rows = rows
+	return dataset, nil
+}
+
+//hasColumns returns true if the dataset has all the columns of the filter
+func (filter *Filter) hasColumns(dataset DataSet) bool {
+	columns := filter.Columns()
+	for i := 0; i < len(columns); i++ {
+		if columnIndex(dataset.HeadersName, columns[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type andNode struct{ left, right filterNode }
+type orNode struct{ left, right filterNode }
+type notNode struct{ node filterNode }
+
+//compareNode compares the value of a column with a value
+type compareNode struct {
+	column   string
+	operator string
+	value    string
+	isNull   bool //the value is the null keyword
+}
+
+func (node andNode) bind(dataset DataSet, schema []ColumnSchema) (func(row RowData) bool, error) {
+	left, right, err := bindBoth(node.left, node.right, dataset, schema)
+	if err != nil {
+		return nil, err
+	}
+	return func(row RowData) bool { return left(row) && right(row) }, nil
+}
+
+func (node andNode) columns(names []string) []string {
+	return node.right.columns(node.left.columns(names))
+}
+
+func (node orNode) bind(dataset DataSet, schema []ColumnSchema) (func(row RowData) bool, error) {
+	left, right, err := bindBoth(node.left, node.right, dataset, schema)
+	if err != nil {
+		return nil, err
+	}
+	return func(row RowData) bool { return left(row) || right(row) }, nil
+}
+
+func (node orNode) columns(names []string) []string {
+	return node.right.columns(node.left.columns(names))
+}
+
+func bindBoth(leftNode, rightNode filterNode, dataset DataSet, schema []ColumnSchema) (func(row RowData) bool,
+	func(row RowData) bool, error) {
+	left, err := leftNode.bind(dataset, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := rightNode.bind(dataset, schema)
+	return left, right, err
+}
+
+func (node notNode) bind(dataset DataSet, schema []ColumnSchema) (func(row RowData) bool, error) {
+	match, err := node.node.bind(dataset, schema)
+	if err != nil {
+		return nil, err
+	}
+	return func(row RowData) bool { return !match(row) }, nil
+}
+
+func (node notNode) columns(names []string) []string {
+	return node.node.columns(names)
+}
+
+func (node compareNode) columns(names []string) []string {
+	return append(names, node.column)
+}
+
+func (node compareNode) bind(dataset DataSet, schema []ColumnSchema) (func(row RowData) bool, error) {
+	index := columnIndex(dataset.HeadersName, node.column)
+	if index < 0 {
+		return nil, fmt.Errorf("Column:%s not found", node.column)
+	}
+	operator := node.operator
+	if node.isNull {
+		if operator != "==" && operator != "!=" {
+			return nil, fmt.Errorf("Only == and != can be used with null, got:%s", operator)
+		}
+		return func(row RowData) bool { return IsNullValue(rowColumn(row, index)) == (operator == "==") }, nil
+	}
+	var compare func(column ColumnData) (int, bool)
+	columnType := ColumnTypeString
+	if index < len(schema) {
+		columnType = schema[index].Type
+	}
+	switch columnType {
+	case ColumnTypeInt, ColumnTypeFloat:
+		number, err := strconv.ParseFloat(node.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Value:%s is not a number for column:%s", node.value, node.column)
+		}
+		compare = func(column ColumnData) (int, bool) {
+			value, ok := columnNumber(column)
+			return compareFloats(value, number), ok
+		}
+	case ColumnTypeTime:
+		t, err := ParseFilterTime(node.value)
+		if err != nil {
+			return nil, fmt.Errorf("Value:%s is not a time for column:%s", node.value, node.column)
+		}
+		compare = func(column ColumnData) (int, bool) {
+			return compareTimes(column.TimeValue, t), column.IsTime
+		}
+	default:
+		compare = func(column ColumnData) (int, bool) {
+			return strings.Compare(csvValue(column), node.value), true
+		}
+	}
+	return func(row RowData) bool {
+		column := rowColumn(row, index)
+		if IsNullValue(column) {
+			return operator == "!="
+		}
+		c, ok := compare(column)
+		if !ok {
+			return operator == "!="
+		}
+		switch operator {
+		case "==":
+			return c == 0
+		case "!=":
+			return c != 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c >= 0
+	}, nil
+}
+
+//filterTimeLayouts are the layouts accepted for times in filters, times without time zone are in the time zone
+//set by SetTimezone
+var filterTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05",
+	"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02", "2006-01"}
+
+//ParseFilterTime parses a time given in a filter, either a date, a date and time or a RFC3339 time
+func ParseFilterTime(value string) (time.Time, error) {
+	for i := 0; i < len(filterTimeLayouts); i++ {
+		if t, err := time.ParseInLocation(filterTimeLayouts[i], value, Timezone()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Unsupported time:%s, use e.g. 2020-01-01 or 2020-01-01T06:00:00Z", value)
+}
+
+//filterToken is a token of a filter expression
+type filterToken struct {
+	text   string
+	kind   int
+	quoted bool
+}
+
+//kinds of filter tokens
+const (
+	filterWord = iota
+	filterOperator
+	filterOpen
+	filterClose
+)
+
+//tokenizeFilter splits the expression in words, quoted values, comparison operators and parentheses
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			kind := filterOpen
+			if r == ')' {
+				kind = filterClose
+			}
+			tokens = append(tokens, filterToken{text: string(r), kind: kind})
+			i++
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				} else if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("Invalid filter:%s, error:missing closing quote", expression)
+			}
+			tokens = append(tokens, filterToken{text: value.String(), kind: filterWord, quoted: true})
+		case strings.ContainsRune("=!<>&|", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("=&|", runes[j]) {
+				j++
+			}
+			operator := string(runes[i:j])
+			switch operator {
+			case "=", "==", "!=", "<", "<=", ">", ">=":
+				if operator == "=" {
+					operator = "=="
+				}
+				tokens = append(tokens, filterToken{text: operator, kind: filterOperator})
+			case "&&":
+				tokens = append(tokens, filterToken{text: "and", kind: filterWord})
+			case "||":
+				tokens = append(tokens, filterToken{text: "or", kind: filterWord})
+			case "!":
+				tokens = append(tokens, filterToken{text: "not", kind: filterWord})
+			default:
+				return nil, fmt.Errorf("Invalid filter:%s, error:unknown operator %s", expression, operator)
+			}
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()\"'=!<>&|", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i:j]), kind: filterWord})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+//filterParser parses the tokens of a filter expression, not binds tighter than and, and tighter than or
+type filterParser struct {
+	tokens   []filterToken
+	position int
+}
+
+//keyword returns true and moves past the next token if it is the unquoted keyword
+func (parser *filterParser) keyword(keyword string) bool {
+	if parser.position < len(parser.tokens) {
+		token := parser.tokens[parser.position]
+		if token.kind == filterWord && !token.quoted && strings.EqualFold(token.text, keyword) {
+			parser.position++
+			return true
+		}
+	}
+	return false
+}
+
+func (parser *filterParser) parseOr() (filterNode, error) {
+	left, err := parser.parseAnd()
+	for err == nil && parser.keyword("or") {
+		var right filterNode
+		if right, err = parser.parseAnd(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (parser *filterParser) parseAnd() (filterNode, error) {
+	left, err := parser.parseNot()
+	for err == nil && parser.keyword("and") {
+		var right filterNode
+		if right, err = parser.parseNot(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (parser *filterParser) parseNot() (filterNode, error) {
+	if parser.keyword("not") {
+		node, err := parser.parseNot()
+		return notNode{node}, err
+	}
+	return parser.parsePrimary()
+}
+
+func (parser *filterParser) parsePrimary() (filterNode, error) {
+	if parser.position >= len(parser.tokens) {
+		return nil, errors.New("unexpected end of filter")
+	}
+	token := parser.tokens[parser.position]
+	if token.kind == filterOpen {
+		parser.position++
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.position >= len(parser.tokens) || parser.tokens[parser.position].kind != filterClose {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		parser.position++
+		return node, nil
+	}
+	if token.kind != filterWord || parser.position+2 >= len(parser.tokens) {
+		return nil, fmt.Errorf("expected <column> <operator> <value> at %s", token.text)
+	}
+	operator, value := parser.tokens[parser.position+1], parser.tokens[parser.position+2]
+	if operator.kind != filterOperator || value.kind != filterWord {
+		return nil, fmt.Errorf("expected <column> <operator> <value> at %s", token.text)
+	}
+	parser.position += 3
+	return compareNode{column: token.text, operator: operator.text, value: value.text,
+		isNull: !value.quoted && strings.EqualFold(value.text, "null")}, nil
+}
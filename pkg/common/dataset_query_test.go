@@ -0,0 +1,164 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+//queryFixtures has the report file info and the wells with a missing oil volume
+var queryFixtures = []testFixture{
+	{name: "REPORT_FILE_INFO", headers: []string{"FileName"}, rows: []testRow{{"a.xml"}}},
+	{name: "WELL_PROD", headers: []string{"NameWellbore", "ReportDTimStart", "Oil", "Comment"},
+		rows: []testRow{
+			{"25/11-G-1", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 10.0, "first"},
+			{"25/11-G-2", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), 20.0, "second"},
+			{"25/11-G-1", time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), testNullFloat, "missing"},
+			{"25/11-G-1", time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC), 5.0, "last"},
+		}},
+}
+
+func TestParseFilter(t *testing.T) {
+	invalid := []string{"Oil >", "Oil > 1 and", "(Oil > 1", "Oil => 1", "\"Oil > 1", "Oil < null"}
+	for _, expression := range invalid {
+		filter, err := ParseFilter(expression)
+		if err == nil && expression == "Oil < null" {
+			_, err = filter.Apply(testDataSets(queryFixtures...)[1])
+		}
+		if err == nil {
+			t.Errorf("Expected error for filter:%s", expression)
+		}
+	}
+	filter, err := ParseFilter("NameWellbore == \"25/11-G-1\" and (ReportDTimStart >= 2020-01-02 or not Oil != null)")
+	if err != nil {
+		t.Fatalf("Failed in parsing filter:%s", err.Error())
+	}
+	if columns := filter.Columns(); len(columns) != 3 {
+		t.Errorf("Expected 3 filter columns, got:%v", columns)
+	}
+}
+
+func TestDataSetQueryFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		comments []string
+	}{
+		{`NameWellbore == "25/11-G-1" and ReportDTimStart >= 2020-01-02`, []string{"missing", "last"}},
+		{`oil > 5 || Comment = second`, []string{"first", "second"}},
+		{`Oil == null`, []string{"missing"}},
+		{`Oil != 10`, []string{"second", "missing", "last"}},
+		{`not (Oil <= 10)`, []string{"second", "missing"}},
+		{`ReportDTimStart < 2020-01-02T12:00:00Z`, []string{"first", "second"}},
+	}
+	for _, test := range tests {
+		query, err := ParseDataSetQuery("", test.filter, "", "")
+		if err != nil {
+			t.Fatalf("Failed in parsing filter:%s, error:%s", test.filter, err.Error())
+		}
+		datasets, err := query.Apply(testDataSets(queryFixtures...))
+		if err != nil {
+			t.Fatalf("Failed in applying filter:%s, error:%s", test.filter, err.Error())
+		}
+		if len(datasets) != 2 || len(datasets[0].Rows) != 1 {
+			t.Errorf("Expected dataset without filter columns to be kept as is for filter:%s", test.filter)
+			continue
+		}
+		var comments []string
+		for _, row := range datasets[1].Rows {
+			comments = append(comments, row.Columns[3].StrVal)
+		}
+		if len(comments) != len(test.comments) {
+			t.Errorf("Filter:%s expected:%v, got:%v", test.filter, test.comments, comments)
+			continue
+		}
+		for i := 0; i < len(comments); i++ {
+			if comments[i] != test.comments[i] {
+				t.Errorf("Filter:%s expected:%v, got:%v", test.filter, test.comments, comments)
+				break
+			}
+		}
+	}
+	query, _ := ParseDataSetQuery("", "Oil > abc", "", "")
+	if _, err := query.Apply(testDataSets(queryFixtures...)); err == nil {
+		t.Errorf("Expected error comparing number column with text")
+	}
+}
+
+func TestDataSetQueryColumnsAndSort(t *testing.T) {
+	if _, err := ParseDataSetQuery("", "", "", "Oil up"); err == nil {
+		t.Errorf("Expected error for invalid sort order")
+	}
+	query, err := ParseDataSetQuery("well_*", "", "comment=Note, Oil, Missing", "Oil desc")
+	if err != nil {
+		t.Fatalf("Failed in parsing query:%s", err.Error())
+	}
+	datasets, err := query.Apply(testDataSets(queryFixtures...))
+	if err != nil {
+		t.Fatalf("Failed in applying query:%s", err.Error())
+	}
+	if len(datasets) != 1 || datasets[0].Name != "WELL_PROD" {
+		t.Fatalf("Expected only the WELL_PROD dataset, got:%d datasets", len(datasets))
+	}
+	dataset := datasets[0]
+	if len(dataset.HeadersName) != 2 || dataset.HeadersName[0] != "Note" || dataset.HeadersName[1] != "Oil" {
+		t.Errorf("Expected selected and renamed columns, got:%v", dataset.HeadersName)
+	}
+	expected := []string{"second", "first", "last", "missing"}
+	for i := 0; i < len(expected); i++ {
+		if len(dataset.Rows[i].Columns) != 2 || dataset.Rows[i].Columns[0].StrVal != expected[i] {
+			t.Errorf("Expected rows sorted on oil descending with nulls last:%v, got row %d:%v", expected, i,
+				dataset.Rows[i].Columns)
+		}
+	}
+	//datasets without any of the selected columns are left out
+	query, _ = ParseDataSetQuery("", "", "Oil", "")
+	if datasets, _ = query.Apply(testDataSets(queryFixtures...)); len(datasets) != 1 {
+		t.Errorf("Expected dataset without selected columns to be left out, got:%d datasets", len(datasets))
+	}
+	//the schema follows the selected columns
+	schemaDataset := schemaFixture.dataSet()
+	selected, found := SelectColumns(schemaDataset, []ColumnSelection{{Name: "Start"}, {Name: "Oil", Rename: "OilVolume"}})
+	if !found || selected.Validate() != nil || selected.Schema.Columns[1].Name != "OilVolume" ||
+		selected.Schema.Columns[1].Type != ColumnTypeFloat {
+		t.Errorf("Expected schema of selected columns, got:%+v", selected.Schema)
+	}
+}
+
+func TestDataSetQueryWarnUnmatched(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+	query, err := ParseDataSetQuery("", "Gas > 1", "Oil,Water", "Oil")
+	if err != nil {
+		t.Fatalf("Failed in parsing query:%s", err.Error())
+	}
+	//the datasets are queried one part at a time, the warnings are given once when done
+	rows, sort := query.Split()
+	transform := ChainTransforms(rows.Transform(), sort.Transform())
+	for i := 0; i < 3; i++ {
+		for _, dataset := range testDataSets(queryFixtures...) {
+			if _, err = transform([]DataSet{dataset}); err != nil {
+				t.Fatalf("Failed in applying query:%s", err.Error())
+			}
+		}
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warnings before the query is done, got:%d", logs.Len())
+	}
+	query.WarnUnmatched()
+	if logs.FilterMessageSnippet("Gas > 1").Len() != 1 || logs.FilterMessageSnippet("Water").Len() != 1 ||
+		logs.Len() != 2 {
+		t.Errorf("Expected one warning for the filter and one for the unknown column, got:%v", logs.All())
+	}
+	//nothing to warn of when the filter and columns are found
+	logs.TakeAll()
+	query, _ = ParseDataSetQuery("", "Oil > 1", "Oil", "")
+	if _, err = query.Apply(testDataSets(queryFixtures...)); err != nil {
+		t.Fatalf("Failed in applying query:%s", err.Error())
+	}
+	if query.WarnUnmatched(); logs.Len() != 0 {
+		t.Errorf("Expected no warnings for a matching query, got:%v", logs.All())
+	}
+}
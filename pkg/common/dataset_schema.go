@@ -0,0 +1,215 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+//ColumnType is the type of the values of a dataset column
+type ColumnType int
+
+//types of the values of a dataset column, int values are accepted in float columns
+const (
+	ColumnTypeString ColumnType = iota
+	ColumnTypeInt
+	ColumnTypeFloat
+	ColumnTypeTime
+)
+
+var columnTypeNames = []string{"string", "int", "float", "time"}
+
+func (columnType ColumnType) String() string {
+	if columnType < 0 || int(columnType) >= len(columnTypeNames) {
+		return fmt.Sprintf("ColumnType(%d)", int(columnType))
+	}
+	return columnTypeNames[columnType]
+}
+
+//MarshalText writes the type by its name in json
+func (columnType ColumnType) MarshalText() ([]byte, error) {
+	return []byte(columnType.String()), nil
+}
+
+//ColumnSchema describes a column of a dataset, Unit is the unit of measure of all values in the column when it
+//is fixed, values with a unit given per row have the unit in a <column>_UoM column
+type ColumnSchema struct {
+	Name        string
+	Type        ColumnType
+	Unit        string `json:",omitempty"`
+	Nullable    bool
+	Description string `json:",omitempty"`
+}
+
+//Schema describes the columns of a dataset in the order of the headers
+type Schema struct {
+	Columns []ColumnSchema
+}
+
+//maxValidationProblems is the number of problems listed in the error of an invalid dataset
+const maxValidationProblems = 10
+
+//StrColumn returns the schema of a nullable string column
+func StrColumn(name string, description string) ColumnSchema {
+	return ColumnSchema{Name: name, Type: ColumnTypeString, Nullable: true, Description: description}
+}
+
+//IntColumn returns the schema of a nullable int column
+func IntColumn(name string, description string) ColumnSchema {
+	return ColumnSchema{Name: name, Type: ColumnTypeInt, Nullable: true, Description: description}
+}
+
+//FloatColumn returns the schema of a nullable float column
+func FloatColumn(name string, description string) ColumnSchema {
+	return ColumnSchema{Name: name, Type: ColumnTypeFloat, Nullable: true, Description: description}
+}
+
+//TimeColumn returns the schema of a nullable time column
+func TimeColumn(name string, description string) ColumnSchema {
+	return ColumnSchema{Name: name, Type: ColumnTypeTime, Nullable: true, Description: description}
+}
+
+//UoMColumn returns the schema of the nullable unit of measure column of a value column whose unit is given per row
+func UoMColumn(name string, valueColumn string) ColumnSchema {
+	return StrColumn(name, "Unit of measure of "+valueColumn)
+}
+
+//Required returns the column schema with nulls not allowed
+func (column ColumnSchema) Required() ColumnSchema {
+	column.Nullable = false
+	return column
+}
+
+//Headers returns the names of the columns
+func (schema Schema) Headers() []string {
+	headers := make([]string, len(schema.Columns))
+	for i := 0; i < len(schema.Columns); i++ {
+		headers[i] = schema.Columns[i].Name
+	}
+	return headers
+}
+
+//Append returns a new schema with the columns added after the columns of the schema
+func (schema Schema) Append(columns ...ColumnSchema) Schema {
+	appended := make([]ColumnSchema, 0, len(schema.Columns)+len(columns))
+	appended = append(appended, schema.Columns...)
+	return Schema{Columns: append(appended, columns...)}
+}
+
+//NewDataSet creates an empty dataset with the schema and the headers of the schema
+func NewDataSet(name string, schema Schema) DataSet {
+	return DataSet{Name: name, HeadersName: schema.Headers(), Schema: schema}
+}
+
+//HasSchema returns true if the columns of the dataset are declared by a schema
+func (dataset DataSet) HasSchema() bool {
+	return len(dataset.Schema.Columns) > 0
+}
+
+//ColumnSchemas returns the declared schema of the columns, or for datasets without a schema the schema found from
+//the headers and the values in the rows
+func (dataset DataSet) ColumnSchemas() []ColumnSchema {
+	if dataset.HasSchema() {
+		return dataset.Schema.Columns
+	}
+	columns := make([]ColumnSchema, len(dataset.HeadersName))
+	for i := 0; i < len(dataset.HeadersName); i++ {
+		columns[i] = ColumnSchema{Name: dataset.HeadersName[i], Type: datasetColumnKind(dataset, i),
+			Nullable: datasetColumnHasNulls(dataset, i)}
+	}
+	return columns
+}
+
+//Validate checks that every row has one column per header and, for datasets with a schema, that the headers are
+//the schema columns, the values are of the column types and nulls are only found in nullable columns
+func (dataset DataSet) Validate() error {
+	var problems []string
+	columns := dataset.Schema.Columns
+	if dataset.HasSchema() {
+		if len(columns) != len(dataset.HeadersName) {
+			problems = append(problems, fmt.Sprintf("%d headers for %d schema columns", len(dataset.HeadersName),
+				len(columns)))
+		} else {
+			for i := 0; i < len(columns); i++ {
+				if columns[i].Name != dataset.HeadersName[i] {
+					problems = append(problems, fmt.Sprintf("header:%s at column:%d, schema column:%s",
+						dataset.HeadersName[i], i, columns[i].Name))
+				}
+			}
+		}
+	}
+	for x := 0; x < len(dataset.Rows) && len(problems) < maxValidationProblems; x++ {
+		row := dataset.Rows[x]
+		if len(row.Columns) != len(dataset.HeadersName) {
+			problems = append(problems, fmt.Sprintf("row:%d has %d columns for %d headers", x, len(row.Columns),
+				len(dataset.HeadersName)))
+		}
+		for y := 0; y < len(row.Columns) && y < len(columns) && len(problems) < maxValidationProblems; y++ {
+			if problem := columns[y].check(row.Columns[y]); problem != "" {
+				problems = append(problems, fmt.Sprintf("row:%d column:%s %s", x, columns[y].Name, problem))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Invalid dataset:%s, %s", dataset.Name, strings.Join(problems, ", "))
+}
+
+//check returns what is wrong with the value for the column, empty if the value is valid
+func (column ColumnSchema) check(value ColumnData) string {
+	if IsNullValue(value) {
+		if !column.Nullable {
+			return "is null but not nullable"
+		}
+		return ""
+	}
+	var valid bool
+	switch column.Type {
+	case ColumnTypeInt:
+		valid = value.IsInt
+	case ColumnTypeFloat:
+		valid = value.IsFloat || value.IsInt
+	case ColumnTypeTime:
+		valid = value.IsTime
+	default:
+		valid = value.IsStr
+	}
+	if valid {
+		return ""
+	}
+	return fmt.Sprintf("has a %s value, schema type:%s", columnDataType(value), column.Type)
+}
+
+//columnDataType returns the type of the value in the column data
+func columnDataType(value ColumnData) ColumnType {
+	switch {
+	case value.IsInt:
+		return ColumnTypeInt
+	case value.IsFloat:
+		return ColumnTypeFloat
+	case value.IsTime:
+		return ColumnTypeTime
+	}
+	return ColumnTypeString
+}
+
+//typedColumn returns the value converted to the column type, ints in float columns are written as floats
+func typedColumn(value ColumnData, columnType ColumnType) ColumnData {
+	if columnType == ColumnTypeFloat && value.IsInt && !IsNullValue(value) {
+		return ColumnData{FloatVal: float64(value.IntVal), IsFloat: true}
+	}
+	return value
+}
+
+//checkDataSet validates the dataset before it is written. Datasets with a schema are not written if invalid,
+//problems in datasets without a schema are logged as they were written before schemas were declared
+func checkDataSet(dataset DataSet) error {
+	err := dataset.Validate()
+	if err == nil || dataset.HasSchema() {
+		return err
+	}
+	zap.S().Warnf("Writing dataset without schema with problems:%s", err.Error())
+	return nil
+}
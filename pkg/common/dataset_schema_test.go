@@ -0,0 +1,103 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSchema = Schema{Columns: []ColumnSchema{
+	StrColumn("DataUUID", "Report identifier").Required(),
+	FloatColumn("Oil", "Oil volume"),
+	TimeColumn("Start", "Start of period"),
+}}
+
+//schemaFixture has an int in the float column and nulls in the nullable columns
+var schemaFixture = testFixture{name: "OIL_DAY", schema: testSchema,
+	rows: []testRow{{"uuid-1", 12, time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC)}, {"uuid-1", testNullFloat, testNullTime}}}
+
+func TestDataSetValidate(t *testing.T) {
+	dataset := schemaFixture.dataSet()
+	if err := dataset.Validate(); err != nil {
+		t.Fatalf("Expected valid dataset, got:%s", err.Error())
+	}
+	tests := []struct {
+		name    string
+		change  func(dataset *DataSet)
+		problem string
+	}{
+		{"missing column", func(dataset *DataSet) { dataset.Rows[0].Columns = dataset.Rows[0].Columns[:2] },
+			"row:0 has 2 columns for 3 headers"},
+		{"wrong type", func(dataset *DataSet) { dataset.Rows[1].Columns[1] = ColumnData{StrVal: "x", IsStr: true} },
+			"row:1 column:Oil has a string value, schema type:float"},
+		{"null in required", func(dataset *DataSet) { dataset.Rows[1].Columns[0] = ColumnData{IsStr: true, IsNull: true} },
+			"row:1 column:DataUUID is null but not nullable"},
+		{"header mismatch", func(dataset *DataSet) { dataset.HeadersName = []string{"DataUUID", "Gas", "Start"} },
+			"header:Gas at column:1, schema column:Oil"},
+	}
+	for _, test := range tests {
+		dataset := schemaFixture.dataSet()
+		test.change(&dataset)
+		err := dataset.Validate()
+		if err == nil || !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%s: expected error with:%s, got:%v", test.name, test.problem, err)
+		}
+	}
+	//only the column count is validated without a schema
+	dataset = DataSet{Name: "NO_SCHEMA", HeadersName: []string{"A", "B"}}
+	dataset.Rows = append(dataset.Rows, RowData{Columns: []ColumnData{{StrVal: "x", IsStr: true}}})
+	if err := dataset.Validate(); err == nil {
+		t.Errorf("Expected error for missing column in dataset without schema")
+	}
+	if err := WriteDatasetCsv(&bytes.Buffer{}, dataset, DefaultCsvOptions()); err != nil {
+		t.Errorf("Expected dataset without schema to be written, got:%s", err.Error())
+	}
+}
+
+func TestSchemaTyping(t *testing.T) {
+	dataset := schemaFixture.dataSet()
+	//the int value is written as a float in the declared float column
+	data, err := DatasetsToJson([]DataSet{dataset})
+	if err != nil {
+		t.Fatalf("Failed in converting to json:%s", err.Error())
+	}
+	var parsed []struct {
+		Schema []struct {
+			Name     string
+			Type     string
+			Nullable bool
+		}
+		Rows []struct {
+			Columns []struct {
+				Name  string
+				Value interface{}
+			}
+		}
+	}
+	if err = json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Failed in parsing json:%s", err.Error())
+	}
+	if len(parsed[0].Schema) != 3 || parsed[0].Schema[1].Type != "float" || parsed[0].Schema[0].Nullable {
+		t.Errorf("Expected schema in json, got:%+v", parsed[0].Schema)
+	}
+	if value, ok := parsed[0].Rows[0].Columns[1].Value.(float64); !ok || value != 12 {
+		t.Errorf("Expected oil value 12, got:%v", parsed[0].Rows[0].Columns[1].Value)
+	}
+	//the declared type is used even if the rows only hold ints
+	dataset.Rows = dataset.Rows[:1]
+	if columnType := dataset.ColumnSchemas()[1].Type; columnType != ColumnTypeFloat {
+		t.Errorf("Expected float column from schema, got:%s", columnType)
+	}
+	dataset.Schema = Schema{}
+	if columnType := dataset.ColumnSchemas()[1].Type; columnType != ColumnTypeInt {
+		t.Errorf("Expected int column found from rows, got:%s", columnType)
+	}
+	//invalid datasets with a schema are not written
+	dataset = schemaFixture.dataSet()
+	dataset.Rows[0].Columns[0] = ColumnData{IsStr: true, IsNull: true}
+	if err = WriteDatasetCsv(&bytes.Buffer{}, dataset, DefaultCsvOptions()); err == nil {
+		t.Errorf("Expected invalid dataset not to be written")
+	}
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//ExcelMaxSheetRows is the number of rows, including the header row, excel allows in a sheet. Datasets with more
+//rows are continued on sheets named <name>_2, <name>_3 and so on
+var ExcelMaxSheetRows = 1048576
+
+//excelMaxSheetNameLength is the number of characters excel allows in a sheet name
+const excelMaxSheetNameLength = 31
+
+//excelSheetNameReplacer replaces the characters excel does not allow in sheet names
+var excelSheetNameReplacer = strings.NewReplacer("\\", "_", "/", "_", "?", "_", "*", "_", "[", "(", "]", ")", ":", "_")
+
+//excelSheetPart is a sheet holding all or a part of the rows of a dataset
+type excelSheetPart struct {
+	name    string //name of the sheet
+	dataset string //name of the dataset
+	part    int    //1 for the first sheet of the dataset
+	first   int    //index of the first row of the dataset on the sheet
+	rows    int    //number of data rows on the sheet
+}
+
+//excelSheetNames hands out sheet names within the limits of excel that are unique in a workbook
+type excelSheetNames struct {
+	used map[string]bool
+}
+
+func newExcelSheetNames() *excelSheetNames {
+	return &excelSheetNames{used: make(map[string]bool)}
+}
+
+//name returns a unique sheet name for the part of the dataset, parts after the first get _<part> added. Names are
+//cleaned of characters excel does not allow and truncated to 31 characters, names already used in the workbook
+//get (<count>) added
+func (names *excelSheetNames) name(datasetName string, part int) string {
+	base := strings.Trim(excelSheetNameReplacer.Replace(datasetName), "'")
+	if base == "" {
+		base = "Sheet"
+	}
+	suffix := ""
+	if part > 1 {
+		suffix = "_" + strconv.Itoa(part)
+	}
+	name := truncateRunes(base, excelMaxSheetNameLength-len(suffix)) + suffix
+	for count := 2; names.used[strings.ToLower(name)]; count++ {
+		duplicate := suffix + " (" + strconv.Itoa(count) + ")"
+		name = truncateRunes(base, excelMaxSheetNameLength-len(duplicate)) + duplicate
+	}
+	names.used[strings.ToLower(name)] = true
+	return name
+}
+
+//truncateRunes returns the first count characters of the text
+func truncateRunes(text string, count int) string {
+	if utf8.RuneCountInString(text) <= count {
+		return text
+	}
+	return string([]rune(text)[:count])
+}
+
+//planExcelSheets splits the datasets on sheets within the row limit of excel and names the sheets
+func planExcelSheets(datasets []DataSet, names *excelSheetNames) []excelSheetPart {
+	var parts []excelSheetPart
+	maxRows := ExcelMaxSheetRows - 1
+	for i := 0; i < len(datasets); i++ {
+		first := 0
+		for part := 1; part == 1 || first < len(datasets[i].Rows); part++ {
+			rows := len(datasets[i].Rows) - first
+			if rows > maxRows {
+				rows = maxRows
+			}
+			parts = append(parts, excelSheetPart{name: names.name(datasets[i].Name, part), dataset: datasets[i].Name,
+				part: part, first: first, rows: rows})
+			first += rows
+		}
+	}
+	return parts
+}
+
+//indexSheetHeaders are the columns of the index sheet mapping the sheets to the datasets
+var indexSheetHeaders = []string{"Sheet", "Rows", "Dataset", "Part"}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestExcelSheetNames(t *testing.T) {
+	names := newExcelSheetNames()
+	long := "PRODUCTION_WELL_TEST_FACILITY_KIND_INJECTION"
+	tests := []struct {
+		dataset  string
+		part     int
+		expected string
+	}{
+		{"OIL", 1, "OIL"},
+		{"oil", 1, "oil (2)"},
+		{"OIL", 2, "OIL_2"},
+		{long, 1, long[:31]},
+		{long + "_GAS", 1, long[:27] + " (2)"},
+		{long, 2, long[:29] + "_2"},
+		{"A/B:C[1]", 1, "A_B_C(1)"},
+		{"'", 1, "Sheet"},
+	}
+	for i := 0; i < len(tests); i++ {
+		name := names.name(tests[i].dataset, tests[i].part)
+		if name != tests[i].expected {
+			t.Errorf("Expected sheet name:%s for dataset:%s part:%d, got:%s", tests[i].expected, tests[i].dataset,
+				tests[i].part, name)
+		}
+		if utf8.RuneCountInString(name) > excelMaxSheetNameLength {
+			t.Errorf("Sheet name too long:%s", name)
+		}
+	}
+}
+
+//checkSplitWorkbook checks that the rows of the split dataset are continued on the _2 and _3 sheets and that the
+//index sheet maps the sheets to the dataset
+func checkSplitWorkbook(t *testing.T, path string, dataset DataSet) {
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 4 || file.Sheets[0].Name != IndexSheetName {
+		t.Fatalf("Expected index sheet and three dataset sheets, got %d sheets", len(file.Sheets))
+	}
+	expected := []struct {
+		name  string
+		rows  int
+		first int
+	}{{dataset.Name[:31], 2, 0}, {dataset.Name[:29] + "_2", 2, 2}, {dataset.Name[:29] + "_3", 1, 4}}
+	for i := 0; i < len(expected); i++ {
+		sheet := file.Sheets[i+1]
+		if sheet.Name != expected[i].name || sheet.MaxRow != expected[i].rows+1 {
+			t.Errorf("Expected sheet:%s with %d rows, got:%s with %d rows", expected[i].name, expected[i].rows,
+				sheet.Name, sheet.MaxRow-1)
+			continue
+		}
+		if value, _ := sheet.Cell(1, 0).Int(); value != expected[i].first {
+			t.Errorf("Expected first row:%d on sheet:%s, got:%d", expected[i].first, sheet.Name, value)
+		}
+		index := file.Sheets[0]
+		if index.Cell(i+1, 0).Value != expected[i].name || index.Cell(i+1, 2).Value != dataset.Name {
+			t.Errorf("Expected index to map sheet:%s to dataset, got:%s,%s", expected[i].name,
+				index.Cell(i+1, 0).Value, index.Cell(i+1, 2).Value)
+		}
+		if part, _ := index.Cell(i+1, 3).Int(); part != i+1 {
+			t.Errorf("Expected part:%d on index for sheet:%s, got:%d", i+1, expected[i].name, part)
+		}
+	}
+}
+
+func TestWorkbookSplitSheets(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	defer func(maxRows int) { ExcelMaxSheetRows = maxRows }(ExcelMaxSheetRows)
+	ExcelMaxSheetRows = 3
+	//a long name and rows for two sheets and a part of a third
+	split := testFixture{name: "ACTIVITIES_FOR_ALL_THE_WELLBORES_OF_THE_FIELD", headers: []string{"RowNo"}}
+	for i := 0; i < 5; i++ {
+		split.rows = append(split.rows, testRow{i})
+	}
+	dataset := split.dataSet()
+	path := filepath.Join(folder, "drilling.xlsx")
+	if err = CreateWorkbookFromDataSet(path, []DataSet{dataset}, false, false); err != nil {
+		t.Fatalf("Failed in creating workbook:%s", err.Error())
+	}
+	checkSplitWorkbook(t, path, dataset)
+	streamPath := filepath.Join(folder, "drilling_stream.xlsx")
+	if err = CreateStreamWorkbookFromDataSet(streamPath, []DataSet{dataset}, false); err != nil {
+		t.Fatalf("Failed in creating stream workbook:%s", err.Error())
+	}
+	checkSplitWorkbook(t, streamPath, dataset)
+	if sheetXML := readWorkbookPart(t, streamPath, "xl/worksheets/sheet2.xml"); !strings.Contains(sheetXML,
+		`<autoFilter ref="A1:A3"/>`) {
+		t.Errorf("Expected autofilter on the rows of the second sheet, got:%s", sheetXML)
+	}
+}
@@ -0,0 +1,695 @@
+package common
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tealeg/xlsx"
+	"go.uber.org/zap"
+)
+
+//ExcelWidthSampleRows is the number of rows of a sheet kept in memory to size the columns before the
+//rows are written, the rest of the rows are written as they are added
+var ExcelWidthSampleRows = 1000
+
+//style indexes of the cell formats in the styles part written by the stream workbook
+const (
+	streamStyleDefault = iota
+	streamStyleHeader
+	streamStyleDate
+	streamStyleVolume
+	streamStyleVolumeInt
+	streamStylePercent
+	streamStylePercentInt
+	streamStyleHyperlink
+)
+
+//streamStyles maps the number formats of the data cells to the style index
+var streamStyles = map[string]int{
+	excelDateTimeFormat:   streamStyleDate,
+	excelVolumeFormat:     streamStyleVolume,
+	excelVolumeIntFormat:  streamStyleVolumeInt,
+	excelPercentFormat:    streamStylePercent,
+	excelPercentIntFormat: streamStylePercentInt,
+}
+
+//StreamWorkbook writes an excel workbook row by row, only the rows used to size the columns of each sheet are kept
+//in memory so memory use does not grow with the number of rows. The sheets are written to temporary files until
+//the workbook is closed, so rows can be added to several sheets in turn, e.g. the rows of each report file read
+type StreamWorkbook struct {
+	path     string
+	file     *os.File
+	zip      *zip.Writer
+	sheets   []excelSheetPart
+	data     []*streamSheet //the data of the sheets, in the order of sheets
+	names    *excelSheetNames
+	current  *streamSheet
+	location *time.Location
+}
+
+//streamSheet is the data of a sheet, written to a temporary file
+type streamSheet struct {
+	index   int //index of the sheet in the workbook sheets
+	headers []string
+	schema  []ColumnSchema
+	formats []columnFormat
+	widths  []int
+	sample  []RowData //rows kept until the column widths are known
+	spool   *os.File
+	writer  *bufio.Writer
+	started bool
+	ended   bool
+	rows    int
+}
+
+//NewStreamWorkbook creates the workbook file, sheets are added with StartSheet and the file is completed by Close
+func NewStreamWorkbook(path string) (*StreamWorkbook, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamWorkbook{path: path, file: file, zip: zip.NewWriter(file), names: newExcelSheetNames(),
+		location: Timezone()}, nil
+}
+
+//StartSheet starts a new sheet for the dataset with the headers, rows are added to it until another sheet is
+//started, the sheet is named after the dataset within the limits of excel
+func (w *StreamWorkbook) StartSheet(datasetName string, headers []string) error {
+	return w.startSheetPart(datasetName, 1, 0, headers, nil)
+}
+
+//startSheetPart starts the sheet for the part of the dataset beginning at row first, the schema gives the fixed
+//units and types of the columns if the dataset has one
+func (w *StreamWorkbook) startSheetPart(datasetName string, partNumber int, first int, headers []string,
+	schema []ColumnSchema) error {
+	spool, err := ioutil.TempFile("", "sheet*.xml")
+	if err != nil {
+		return err
+	}
+	w.sheets = append(w.sheets, excelSheetPart{name: w.names.name(datasetName, partNumber), dataset: datasetName,
+		part: partNumber, first: first})
+	sheet := &streamSheet{index: len(w.data), headers: headers, schema: schema,
+		formats: buildColumnFormats(headers, schema), widths: make([]int, len(headers)), spool: spool,
+		writer: bufio.NewWriter(spool)}
+	//the width leaves room for the bold font and the filter button
+	for i := 0; i < len(headers); i++ {
+		sheet.widths[i] = utf8.RuneCountInString(headers[i]) + 3
+	}
+	w.data = append(w.data, sheet)
+	w.current = sheet
+	return nil
+}
+
+//selectSheet makes the last sheet of the dataset the current sheet, false if no sheet has been started for the
+//dataset
+func (w *StreamWorkbook) selectSheet(datasetName string) bool {
+	for i := len(w.data) - 1; i >= 0; i-- {
+		if w.sheets[i].dataset == datasetName {
+			w.current = w.data[i]
+			return true
+		}
+	}
+	return false
+}
+
+//WriteRow adds a row to the current sheet
+func (w *StreamWorkbook) WriteRow(row RowData) error {
+	sheet := w.current
+	if sheet == nil {
+		return errors.New("No sheet started in stream workbook")
+	}
+	//rows above the limit of excel continues on the next sheet of the dataset
+	if sheet.rows == ExcelMaxSheetRows-1 {
+		current := w.sheets[sheet.index]
+		if err := w.startSheetPart(current.dataset, current.part+1, current.first+sheet.rows, sheet.headers,
+			sheet.schema); err != nil {
+			return err
+		}
+		if err := w.endSheet(sheet); err != nil {
+			return err
+		}
+		sheet = w.current
+	}
+	sheet.rows++
+	if !sheet.started {
+		for y := 0; y < len(row.Columns) && y < len(sheet.widths); y++ {
+			var numberFormat string
+			column := typedColumn(row.Columns[y], sheet.formats[y].columnType)
+			if column.IsInt || column.IsFloat {
+				numberFormat = sheet.formats[y].numberFormat(row, column.IsInt)
+			}
+			if width := displayWidth(column, numberFormat); width > sheet.widths[y] {
+				sheet.widths[y] = width
+			}
+		}
+		sheet.sample = append(sheet.sample, row)
+		if len(sheet.sample) < ExcelWidthSampleRows {
+			return nil
+		}
+		return w.startSheetData(sheet)
+	}
+	return w.writeDataRow(sheet, sheet.rows+1, row)
+}
+
+//WriteDataSet writes the dataset as a new sheet named after the dataset, datasets with a schema are validated
+//before written
+func (w *StreamWorkbook) WriteDataSet(dataset DataSet) error {
+	if err := checkDataSet(dataset); err != nil {
+		return err
+	}
+	if err := w.startSheetPart(dataset.Name, 1, 0, dataset.HeadersName, dataset.Schema.Columns); err != nil {
+		return err
+	}
+	return w.writeRows(dataset)
+}
+
+//writeRows adds the rows of the dataset to the current sheet
+func (w *StreamWorkbook) writeRows(dataset DataSet) error {
+	for i := 0; i < len(dataset.Rows); i++ {
+		if err := w.WriteRow(dataset.Rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//startSheetData writes the start of the sheet with the frozen header and column widths, the header row and the
+//rows kept to size the columns
+func (w *StreamWorkbook) startSheetData(sheet *streamSheet) error {
+	sheet.started = true
+	sheet.writer.WriteString(xml.Header)
+	sheet.writer.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	sheet.writer.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`<selection pane="bottomLeft" activeCell="A2" sqref="A2"/></sheetView></sheetViews>`)
+	if len(sheet.widths) > 0 {
+		sheet.writer.WriteString("<cols>")
+		for i := 0; i < len(sheet.widths); i++ {
+			fmt.Fprintf(sheet.writer, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1,
+				clampColumnWidth(sheet.widths[i]))
+		}
+		sheet.writer.WriteString("</cols>")
+	}
+	sheet.writer.WriteString("<sheetData>")
+	sheet.writer.WriteString(`<row r="1">`)
+	for i := 0; i < len(sheet.headers); i++ {
+		writeStreamStringCell(sheet.writer, cellRef(i, 1), sheet.headers[i], streamStyleHeader)
+	}
+	sheet.writer.WriteString("</row>")
+	for i := 0; i < len(sheet.sample); i++ {
+		if err := w.writeDataRow(sheet, i+2, sheet.sample[i]); err != nil {
+			return err
+		}
+	}
+	sheet.sample = nil
+	return nil
+}
+
+//writeDataRow writes the row with the number formats chosen for its values, empty values are left out
+func (w *StreamWorkbook) writeDataRow(sheet *streamSheet, rowNumber int, row RowData) error {
+	fmt.Fprintf(sheet.writer, `<row r="%d">`, rowNumber)
+	for y := 0; y < len(row.Columns); y++ {
+		column := row.Columns[y]
+		if y < len(sheet.formats) {
+			column = typedColumn(column, sheet.formats[y].columnType)
+		}
+		ref := cellRef(y, rowNumber)
+		var numberFormat string
+		if y < len(sheet.formats) && (column.IsInt || column.IsFloat) {
+			numberFormat = sheet.formats[y].numberFormat(row, column.IsInt)
+		}
+		if IsNullValue(column) {
+			//missing values are left out as empty cells
+			continue
+		} else if column.IsStr {
+			writeStreamStringCell(sheet.writer, ref, column.StrVal, streamStyleDefault)
+		} else if column.IsInt {
+			writeStreamNumberCell(sheet.writer, ref, strconv.Itoa(column.IntVal), streamStyles[numberFormat])
+		} else if column.IsFloat {
+			writeStreamNumberCell(sheet.writer, ref, strconv.FormatFloat(column.FloatVal, 'f', -1, 64),
+				streamStyles[numberFormat])
+		} else if column.IsTime {
+			//zero times are written as empty cells
+			if !column.TimeValue.IsZero() {
+				writeStreamNumberCell(sheet.writer, ref, strconv.FormatFloat(w.excelTime(column.TimeValue), 'f', -1, 64),
+					streamStyleDate)
+			}
+		} else if !column.IsEmptyColumn {
+			return errors.New(fmt.Sprintf("Undefined datatype found for sheet:%s, row number:%d,column number:%d",
+				w.sheets[sheet.index].name, rowNumber, y))
+		}
+	}
+	_, err := sheet.writer.WriteString("</row>")
+	return err
+}
+
+//excelTime returns the excel serial date for the time as shown in the time zone set by SetTimezone, as done by
+//the in memory workbook
+func (w *StreamWorkbook) excelTime(t time.Time) float64 {
+	local := t.In(w.location)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0,
+		time.UTC)
+	return xlsx.TimeToExcelTime(wall, false)
+}
+
+//endSheet writes the end of the sheet with the autofilter on the header
+func (w *StreamWorkbook) endSheet(sheet *streamSheet) error {
+	sheet.ended = true
+	if !sheet.started {
+		if err := w.startSheetData(sheet); err != nil {
+			return err
+		}
+	}
+	sheet.writer.WriteString("</sheetData>")
+	if len(sheet.headers) > 0 {
+		fmt.Fprintf(sheet.writer, `<autoFilter ref="A1:%s"/>`, cellRef(len(sheet.headers)-1, sheet.rows+1))
+	}
+	sheet.writer.WriteString(`<pageMargins left="0.7" right="0.7" top="0.75" bottom="0.75" header="0.3" footer="0.3"/>`)
+	sheet.writer.WriteString("</worksheet>")
+	w.sheets[sheet.index].rows = sheet.rows
+	return sheet.writer.Flush()
+}
+
+//Close ends the sheets, copies them to the workbook, writes the index sheet and the parts describing
+//the workbook, then closes the file
+func (w *StreamWorkbook) Close() error {
+	err := w.close()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	for i := 0; i < len(w.data); i++ {
+		w.data[i].spool.Close()
+		os.Remove(w.data[i].spool.Name())
+	}
+	return err
+}
+
+func (w *StreamWorkbook) close() error {
+	var err error
+	w.current = nil
+	if len(w.sheets) == 0 {
+		return errors.New("No sheets written to workbook:" + w.path)
+	}
+	w.orderSheets()
+	for i := 0; i < len(w.data); i++ {
+		var part io.Writer
+		if !w.data[i].ended {
+			if err = w.endSheet(w.data[i]); err != nil {
+				return err
+			}
+		}
+		if part, err = w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)); err != nil {
+			return err
+		}
+		if _, err = w.data[i].spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, w.data[i].spool); err != nil {
+			return err
+		}
+	}
+	//the index sheet is written last as the row counts are needed, but listed first in the workbook
+	indexName := w.names.name(IndexSheetName, 1)
+	if err = w.writeIndexSheet(indexName); err != nil {
+		return err
+	}
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", w.workbook(indexName)},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", streamStylesXML},
+	}
+	for i := 0; i < len(parts); i++ {
+		var part io.Writer
+		if part, err = w.zip.Create(parts[i].name); err != nil {
+			return err
+		}
+		if _, err = io.WriteString(part, parts[i].content); err != nil {
+			return err
+		}
+	}
+	return w.zip.Close()
+}
+
+//orderSheets puts the sheets continuing a dataset above the row limit of excel after the first sheet of the
+//dataset, the datasets are kept in the order they were started
+func (w *StreamWorkbook) orderSheets() {
+	datasets := make(map[string]int)
+	for i := 0; i < len(w.sheets); i++ {
+		if _, found := datasets[w.sheets[i].dataset]; !found {
+			datasets[w.sheets[i].dataset] = i
+		}
+	}
+	order := make([]int, len(w.sheets))
+	for i := 0; i < len(order); i++ {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sheetA, sheetB := w.sheets[order[a]], w.sheets[order[b]]
+		if datasets[sheetA.dataset] != datasets[sheetB.dataset] {
+			return datasets[sheetA.dataset] < datasets[sheetB.dataset]
+		}
+		return sheetA.part < sheetB.part
+	})
+	sheets := make([]excelSheetPart, len(order))
+	data := make([]*streamSheet, len(order))
+	for i := 0; i < len(order); i++ {
+		sheets[i], data[i] = w.sheets[order[i]], w.data[order[i]]
+		data[i].index = i
+	}
+	w.sheets, w.data = sheets, data
+}
+
+//writeIndexSheet writes the sheet listing the sheets with their row count, a link to each sheet and the dataset
+//and part of the dataset on the sheet
+func (w *StreamWorkbook) writeIndexSheet(name string) error {
+	part, err := w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)+1))
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(part)
+	widths := make([]int, len(indexSheetHeaders))
+	for i := 0; i < len(indexSheetHeaders); i++ {
+		widths[i] = len(indexSheetHeaders[i]) + 3
+	}
+	for i := 0; i < len(w.sheets); i++ {
+		if width := utf8.RuneCountInString(w.sheets[i].name); width > widths[0] {
+			widths[0] = width
+		}
+		if width := utf8.RuneCountInString(w.sheets[i].dataset); width > widths[2] {
+			widths[2] = width
+		}
+	}
+	writer.WriteString(xml.Header)
+	writer.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	//the index sheet is listed first and shown when the workbook is opened
+	writer.WriteString(`<sheetViews><sheetView tabSelected="1" workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	writer.WriteString("<cols>")
+	for i := 0; i < len(widths); i++ {
+		fmt.Fprintf(writer, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, clampColumnWidth(widths[i]))
+	}
+	writer.WriteString(`</cols><sheetData><row r="1">`)
+	for i := 0; i < len(indexSheetHeaders); i++ {
+		writeStreamStringCell(writer, cellRef(i, 1), indexSheetHeaders[i], streamStyleHeader)
+	}
+	writer.WriteString("</row>")
+	for i := 0; i < len(w.sheets); i++ {
+		fmt.Fprintf(writer, `<row r="%d">`, i+2)
+		target := "#'" + strings.ReplaceAll(w.sheets[i].name, "'", "''") + "'!A1"
+		formula := "HYPERLINK(\"" + strings.ReplaceAll(target, "\"", "\"\"") + "\",\"" +
+			strings.ReplaceAll(w.sheets[i].name, "\"", "\"\"") + "\")"
+		fmt.Fprintf(writer, `<c r="%s" s="%d" t="str"><f>%s</f><v>%s</v></c>`, cellRef(0, i+2), streamStyleHyperlink,
+			escapeXML(formula), escapeXML(w.sheets[i].name))
+		writeStreamNumberCell(writer, cellRef(1, i+2), strconv.Itoa(w.sheets[i].rows), streamStyleDefault)
+		writeStreamStringCell(writer, cellRef(2, i+2), w.sheets[i].dataset, streamStyleDefault)
+		writeStreamNumberCell(writer, cellRef(3, i+2), strconv.Itoa(w.sheets[i].part), streamStyleDefault)
+		writer.WriteString("</row>")
+	}
+	fmt.Fprintf(writer, `</sheetData><autoFilter ref="A1:%s"/></worksheet>`,
+		cellRef(len(indexSheetHeaders)-1, len(w.sheets)+1))
+	return writer.Flush()
+}
+
+//workbook returns the workbook part listing the sheets, the index sheet first
+func (w *StreamWorkbook) workbook(indexName string) string {
+	var sheets strings.Builder
+	fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(indexName), len(w.sheets)+1,
+		len(w.sheets)+1)
+	for i := 0; i < len(w.sheets); i++ {
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(w.sheets[i].name), i+1, i+1)
+	}
+	return xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<bookViews><workbookView/></bookViews><sheets>` + sheets.String() + `</sheets></workbook>`
+}
+
+//workbookRels returns the relationships from the workbook to the sheets and the styles
+func (w *StreamWorkbook) workbookRels() string {
+	var rels strings.Builder
+	//the index sheet is written after the dataset sheets
+	count := len(w.sheets) + 1
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, count+1)
+	return xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		rels.String() + `</Relationships>`
+}
+
+//contentTypes returns the content types of the parts in the workbook
+func (w *StreamWorkbook) contentTypes() string {
+	var overrides strings.Builder
+	//the index sheet is written after the dataset sheets
+	count := len(w.sheets) + 1
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	return xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		overrides.String() + `</Types>`
+}
+
+//streamStylesXML holds the fonts, number formats and cell formats in the order of the stream style indexes
+var streamStylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="3"><numFmt numFmtId="164" formatCode="` + escapeXML(excelDateTimeFormat) + `"/>` +
+	`<numFmt numFmtId="165" formatCode="` + escapeXML(excelPercentFormat) + `"/>` +
+	`<numFmt numFmtId="166" formatCode="` + escapeXML(excelPercentIntFormat) + `"/></numFmts>` +
+	`<fonts count="3"><font><sz val="12"/><name val="Verdana"/></font><font><b/><sz val="12"/><name val="Verdana"/></font>` +
+	`<font><u/><sz val="12"/><color rgb="FF0563C1"/><name val="Verdana"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="8"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment horizontal="center"/></xf>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+
+//cellRef returns the reference of the cell, e.g. A1, for the zero based column index and the row number
+func cellRef(column, rowNumber int) string {
+	return xlsx.ColIndexToLetters(column) + strconv.Itoa(rowNumber)
+}
+
+//escapeXML escapes the text for use in xml, characters not allowed in xml are replaced
+func escapeXML(text string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+func writeStreamStringCell(w *bufio.Writer, ref, value string, style int) {
+	fmt.Fprintf(w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style,
+		escapeXML(value))
+}
+
+func writeStreamNumberCell(w *bufio.Writer, ref, value string, style int) {
+	fmt.Fprintf(w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, value)
+}
+
+//clampColumnWidth returns the column width with padding within the min and max widths
+func clampColumnWidth(width int) int {
+	width += 2
+	if width < excelMinColumnWidth {
+		return excelMinColumnWidth
+	} else if width > excelMaxColumnWidth {
+		return excelMaxColumnWidth
+	}
+	return width
+}
+
+//CreateStreamWorkbookFromDataSet writes the datasets with the stream workbook, either as sheets in one file or
+//one file per dataset named after the file and the dataset
+func CreateStreamWorkbookFromDataSet(filepath string, datasets []DataSet, oneFilePerSheet bool) error {
+	writer, err := NewDataSetWorkbookWriter(filepath, oneFilePerSheet, StreamTransforms{})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(datasets); i++ {
+		if err = writer.Write(datasets[i]); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+//DataSetWorkbookWriter writes datasets to stream workbooks as they are built, the rows of a dataset written in
+//parts, e.g. one part per report file read, are added to the sheet of the dataset so only one part needs to be kept
+//in memory. Datasets with a dataset transform, e.g. sorting or pivoting, are kept in memory until the writer is
+//closed
+type DataSetWorkbookWriter struct {
+	filepath        string
+	oneFilePerSheet bool
+	transforms      StreamTransforms
+	workbook        *StreamWorkbook
+	files           []string                   //the datasets written to one file each, in the order started
+	workbooks       map[string]*StreamWorkbook //the workbook of each dataset written to one file each
+	buffered        []DataSet                  //the datasets kept for the dataset transform, in the order started
+}
+
+//NewDataSetWorkbookWriter creates a writer of datasets to one workbook or one workbook per dataset, the row
+//transforms are applied to each part written and the dataset transforms to each dataset when the writer is closed
+func NewDataSetWorkbookWriter(filepath string, oneFilePerSheet bool,
+	transforms StreamTransforms) (*DataSetWorkbookWriter, error) {
+	writer := &DataSetWorkbookWriter{filepath: filepath, oneFilePerSheet: oneFilePerSheet, transforms: transforms,
+		workbooks: make(map[string]*StreamWorkbook)}
+	if !oneFilePerSheet {
+		var err error
+		if writer.workbook, err = NewStreamWorkbook(filepath); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+//Write writes the dataset as a sheet, or adds its rows to the sheet of a dataset with the name already written
+func (writer *DataSetWorkbookWriter) Write(dataset DataSet) error {
+	return writer.WriteParts(1, func(i int) DataSet {
+		return dataset
+	})
+}
+
+//WriteParts writes a dataset built in count parts, e.g. one part per report, to the sheet of the dataset. The part
+//function returns the dataset with the rows of part i, all parts must have the headers of the first. The row
+//transforms are applied to each part as it is built, the rows are then written or kept for the dataset transforms
+func (writer *DataSetWorkbookWriter) WriteParts(count int, part func(i int) DataSet) error {
+	for i := 0; i < count; i++ {
+		datasets := []DataSet{part(i)}
+		if writer.transforms.Rows != nil {
+			var err error
+			if datasets, err = writer.transforms.Rows(datasets); err != nil {
+				return err
+			}
+		}
+		for x := 0; x < len(datasets); x++ {
+			if err := writer.add(datasets[x]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//add writes the rows of the dataset, or keeps them until the writer is closed if there is a dataset transform
+func (writer *DataSetWorkbookWriter) add(dataset DataSet) error {
+	if writer.transforms.DataSet == nil {
+		return writer.write(dataset)
+	}
+	for i := 0; i < len(writer.buffered); i++ {
+		if writer.buffered[i].Name == dataset.Name {
+			if err := checkPartHeaders(writer.buffered[i], dataset); err != nil {
+				return err
+			}
+			writer.buffered[i].Rows = append(writer.buffered[i].Rows, dataset.Rows...)
+			return nil
+		}
+	}
+	zap.S().Warnf("Keeping all rows of dataset:%s in memory for sorting or pivoting before written to excel",
+		dataset.Name)
+	dataset.Rows = append([]RowData{}, dataset.Rows...)
+	writer.buffered = append(writer.buffered, dataset)
+	return nil
+}
+
+//write adds the rows of the dataset to the sheet of the dataset, the sheet is started by the first rows written
+func (writer *DataSetWorkbookWriter) write(dataset DataSet) error {
+	if err := checkDataSet(dataset); err != nil {
+		return err
+	}
+	workbook := writer.workbook
+	if writer.oneFilePerSheet {
+		if workbook = writer.workbooks[dataset.Name]; workbook == nil {
+			var err error
+			if workbook, err = NewStreamWorkbook(writer.datasetFile(dataset.Name)); err != nil {
+				return err
+			}
+			writer.workbooks[dataset.Name] = workbook
+			writer.files = append(writer.files, dataset.Name)
+		}
+	}
+	if workbook.selectSheet(dataset.Name) {
+		if err := checkPartHeaders(DataSet{Name: dataset.Name, HeadersName: workbook.current.headers},
+			dataset); err != nil {
+			return err
+		}
+	} else if err := workbook.startSheetPart(dataset.Name, 1, 0, dataset.HeadersName,
+		dataset.Schema.Columns); err != nil {
+		return err
+	}
+	return workbook.writeRows(dataset)
+}
+
+//datasetFile returns the file of the dataset written to one file each, named after the file and the dataset
+func (writer *DataSetWorkbookWriter) datasetFile(datasetName string) string {
+	fileNameOnly, ext := GetFileNameAndExtension(writer.filepath)
+	return GetFolderPathForFile(writer.filepath) + string(os.PathSeparator) + fileNameOnly + "_" + datasetName + ext
+}
+
+//checkPartHeaders returns an error if the part of the dataset has other headers than the first part
+func checkPartHeaders(first DataSet, part DataSet) error {
+	if part.Name != first.Name || !sameHeaders(part.HeadersName, first.HeadersName) {
+		return fmt.Errorf("Part of dataset:%s has other headers than the first part, dataset:%s headers:%v",
+			first.Name, part.Name, part.HeadersName)
+	}
+	return nil
+}
+
+//Close transforms and writes the datasets kept for the dataset transform one at a time, then completes the
+//workbooks written to. Close is also called after a failed write to remove the sheets written to temporary files
+func (writer *DataSetWorkbookWriter) Close() error {
+	var err error
+	for i := 0; i < len(writer.buffered) && err == nil; i++ {
+		var datasets []DataSet
+		datasets, err = writer.transforms.DataSet(writer.buffered[i : i+1])
+		//the rows of the dataset are transformed, let them be collected when written
+		writer.buffered[i].Rows = nil
+		for x := 0; x < len(datasets) && err == nil; x++ {
+			err = writer.write(datasets[x])
+		}
+	}
+	writer.buffered = nil
+	for i := 0; i < len(writer.files); i++ {
+		if closeErr := writer.workbooks[writer.files[i]].Close(); err == nil {
+			err = closeErr
+			if err == nil {
+				zap.S().Infof("Wrote excel data for dataset name:%s to location:%s", writer.files[i],
+					writer.datasetFile(writer.files[i]))
+			}
+		}
+	}
+	if writer.workbook != nil {
+		if closeErr := writer.workbook.Close(); err == nil {
+			err = closeErr
+			if err == nil {
+				zap.S().Infof("Wrote excel data to location:%s", writer.filepath)
+			}
+		}
+	}
+	return err
+}
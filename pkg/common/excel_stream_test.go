@@ -0,0 +1,270 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestStreamWorkbookFormatting(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	//a small sample makes the rows after the first be written as they are added
+	defer func(sample int) { ExcelWidthSampleRows = sample }(ExcelWidthSampleRows)
+	ExcelWidthSampleRows = 1
+	datasets := testDataSets(excelFixtures...)
+	datasets[0].Rows = append(datasets[0].Rows, RowData{Columns: []ColumnData{{StrVal: "B & <C>", IsStr: true},
+		{IntVal: 1500, IsInt: true}, {StrVal: "Sm3", IsStr: true}, {IsEmptyColumn: true}, {IsEmptyColumn: true},
+		{IsEmptyColumn: true}}})
+	path := filepath.Join(folder, "production.xlsx")
+	if err = CreateStreamWorkbookFromDataSet(path, datasets, false); err != nil {
+		t.Fatalf("Failed in creating workbook:%s", err.Error())
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 3 || file.Sheets[0].Name != IndexSheetName || file.Sheets[1].Name != "VOLUMES" {
+		t.Fatalf("Expected index sheet first followed by the dataset sheets, got %d sheets", len(file.Sheets))
+	}
+	link := file.Sheets[0].Cell(1, 0)
+	if link.Formula() != `HYPERLINK("#'VOLUMES'!A1","VOLUMES")` || link.Value != "VOLUMES" {
+		t.Errorf("Unexpected index link, formula:%s value:%s", link.Formula(), link.Value)
+	}
+	if rows, _ := file.Sheets[0].Cell(1, 1).Int(); rows != 2 {
+		t.Errorf("Expected row count 2 on index sheet, got:%d", rows)
+	}
+	volumes := file.Sheets[1]
+	if value := volumes.Cell(2, 0).Value; value != "B & <C>" {
+		t.Errorf("Expected escaped string to be read back, got:%s", value)
+	}
+	if value, _ := volumes.Cell(1, 1).Float(); value != 1234567.5 {
+		t.Errorf("Expected volume value, got:%v", value)
+	}
+	if format := volumes.Cell(1, 1).GetNumberFormat(); format != excelVolumeFormat {
+		t.Errorf("Expected volume format, got:%s", format)
+	}
+	if format := volumes.Cell(2, 1).GetNumberFormat(); format != excelVolumeIntFormat {
+		t.Errorf("Expected volume int format, got:%s", format)
+	}
+	if format := volumes.Cell(1, 3).GetNumberFormat(); format != excelPercentFormat {
+		t.Errorf("Expected percent format, got:%s", format)
+	}
+	if value, _ := volumes.Cell(1, 5).GetTime(false); value.Year() != 2020 {
+		t.Errorf("Expected date value, got:%v", value)
+	}
+	if volumes.Cols[0].Width <= volumes.Cols[2].Width {
+		t.Errorf("Expected long facility column to be wider, got:%v and %v", volumes.Cols[0].Width, volumes.Cols[2].Width)
+	}
+	sheetXML := readWorkbookPart(t, path, "xl/worksheets/sheet1.xml")
+	if !strings.Contains(sheetXML, `state="frozen"`) || !strings.Contains(sheetXML, `<autoFilter ref="A1:F3"/>`) {
+		t.Errorf("Expected frozen header and autofilter, got:%s", sheetXML)
+	}
+}
+
+func TestStreamWorkbookDuplicatedSheet(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	path := filepath.Join(folder, "production.xlsx")
+	workbook, err := NewStreamWorkbook(path)
+	if err != nil {
+		t.Fatalf("Failed in creating workbook:%s", err.Error())
+	}
+	//sheet names are unique without regard to case in excel
+	for _, name := range []string{"INFO", "info"} {
+		if err = workbook.StartSheet(name, []string{"Name"}); err != nil {
+			t.Fatalf("Failed in starting sheet:%s", err.Error())
+		}
+	}
+	if err = workbook.Close(); err != nil {
+		t.Fatalf("Failed in closing workbook:%s", err.Error())
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 3 || file.Sheets[2].Name != "info (2)" || file.Sheets[0].Cell(2, 2).Value != "info" {
+		t.Errorf("Expected duplicated sheet name to be made unique and mapped on the index sheet")
+	}
+}
+
+//volumesPart returns a part of a dataset with 2000 rows of 100 characters of text
+func volumesPart(part int) DataSet {
+	fixture := testFixture{name: "VOLUMES", headers: []string{"Part", "Row", "Comment"}}
+	for i := 0; i < 2000; i++ {
+		fixture.rows = append(fixture.rows, testRow{part, i, strings.Repeat("x", 100)})
+	}
+	return fixture.dataSet()
+}
+
+//heapInUse returns the bytes of the heap in use after a garbage collection
+func heapInUse() int64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
+
+func TestWritePartsMemoryBounded(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	//the text alone of a part is more than this
+	const partSize = 2000 * 100
+	writer, err := NewDataSetWorkbookWriter(filepath.Join(folder, "production.xlsx"), false, StreamTransforms{})
+	if err != nil {
+		t.Fatalf("Failed in creating writer:%s", err.Error())
+	}
+	//the heap is measured while building a part, it does not grow with the parts already written
+	const parts = 30
+	var early, late int64
+	err = writer.WriteParts(parts, func(i int) DataSet {
+		if i == 5 {
+			early = heapInUse()
+		} else if i == parts-1 {
+			late = heapInUse()
+		}
+		return volumesPart(i)
+	})
+	if err != nil {
+		t.Fatalf("Failed in writing parts:%s", err.Error())
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Failed in closing writer:%s", err.Error())
+	}
+	if growth := late - early; growth > 2*partSize {
+		t.Errorf("Expected memory bounded by a part of:%d bytes, grew:%d bytes over %d parts", partSize, growth,
+			parts-6)
+	}
+	file, err := xlsx.OpenFile(filepath.Join(folder, "production.xlsx"))
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 2 || file.Sheets[1].MaxRow != parts*2000+1 {
+		t.Errorf("Expected the index sheet and all rows of the parts on one sheet, got %d sheets", len(file.Sheets))
+	}
+}
+
+func TestWritePartsWithOtherHeaders(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	writer, err := NewDataSetWorkbookWriter(filepath.Join(folder, "production.xlsx"), true, StreamTransforms{})
+	if err != nil {
+		t.Fatalf("Failed in creating writer:%s", err.Error())
+	}
+	//the writer is closed to remove the sheets written to temporary files
+	defer writer.Close()
+	err = writer.WriteParts(2, func(i int) DataSet {
+		dataset := DataSet{Name: "VOLUMES", HeadersName: []string{"Oil"}}
+		if i > 0 {
+			dataset.HeadersName = append(dataset.HeadersName, "Gas")
+		}
+		return dataset
+	})
+	if err == nil || !strings.Contains(err.Error(), "other headers") {
+		t.Errorf("Expected error for part with other headers, got:%v", err)
+	}
+}
+
+func TestWritePartsOfDatasetsInTurn(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	query, err := ParseDataSetQuery("", "Row < 3", "Part,Row", "")
+	if err != nil {
+		t.Fatalf("Failed in parsing query:%s", err.Error())
+	}
+	path := filepath.Join(folder, "production.xlsx")
+	writer, err := NewDataSetWorkbookWriter(path, false, StreamTransforms{Rows: query.Transform()})
+	if err != nil {
+		t.Fatalf("Failed in creating writer:%s", err.Error())
+	}
+	//the datasets of each file read are written in turn, the rows of a dataset end up on its sheet
+	for part := 0; part < 3; part++ {
+		for _, name := range []string{"VOLUMES", "COMMENTS"} {
+			dataset := volumesPart(part)
+			dataset.Name = name
+			if err = writer.Write(dataset); err != nil {
+				t.Fatalf("Failed in writing part:%s", err.Error())
+			}
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Failed in closing writer:%s", err.Error())
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	for _, name := range []string{"VOLUMES", "COMMENTS"} {
+		sheet := file.Sheet[name]
+		if sheet == nil {
+			t.Fatalf("Expected a sheet for dataset:%s", name)
+		}
+		if sheet.MaxRow != 3*3+1 || sheet.MaxCol != 2 {
+			t.Errorf("Expected the filtered rows and selected columns of all parts on sheet:%s, got %d rows %d columns",
+				sheet.Name, sheet.MaxRow, sheet.MaxCol)
+		}
+		if sheet.Cell(4, 0).Value != "1" || sheet.Cell(9, 1).Value != "2" {
+			t.Errorf("Expected the parts in the order written on sheet:%s", sheet.Name)
+		}
+	}
+}
+
+func TestWritePartsSorted(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	query, err := ParseDataSetQuery("", "Row < 2", "Part,Row", "Row,Part desc")
+	if err != nil {
+		t.Fatalf("Failed in parsing query:%s", err.Error())
+	}
+	rows, sort := query.Split()
+	path := filepath.Join(folder, "production.xlsx")
+	writer, err := NewDataSetWorkbookWriter(path, false,
+		StreamTransforms{Rows: rows.Transform(), DataSet: sort.Transform()})
+	if err != nil {
+		t.Fatalf("Failed in creating writer:%s", err.Error())
+	}
+	if err = writer.WriteParts(3, volumesPart); err != nil {
+		t.Fatalf("Failed in writing parts:%s", err.Error())
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Failed in closing writer:%s", err.Error())
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	sheet := file.Sheet["VOLUMES"]
+	if sheet == nil {
+		t.Fatalf("Expected a sheet for dataset:VOLUMES")
+	}
+	var parts []string
+	for i := 1; i < sheet.MaxRow; i++ {
+		parts = append(parts, sheet.Cell(i, 0).Value+"/"+sheet.Cell(i, 1).Value)
+	}
+	if strings.Join(parts, ",") != "2/0,1/0,0/0,2/1,1/1,0/1" || sheet.MaxCol != 2 {
+		t.Errorf("Expected the rows of all parts sorted, got:%v", parts)
+	}
+}
@@ -11,7 +11,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/tealeg/xlsx"
 	"go.uber.org/zap"
@@ -54,57 +57,236 @@ func CreateWorkbookFromDataSet(filepath string, datasets []DataSet, oneFilePerSh
 	return err
 }
 
+//number formats used for the data cells, percentages are shown with a percent sign without scaling the value
+const (
+	excelDateTimeFormat   = "yyyy-mm-dd hh:mm:ss"
+	excelVolumeFormat     = "#,##0.00"
+	excelVolumeIntFormat  = "#,##0"
+	excelPercentFormat    = "0.00\"%\""
+	excelPercentIntFormat = "0\"%\""
+)
+
+//IndexSheetName is the name of the front sheet listing the dataset sheets of a workbook
+const IndexSheetName = "INDEX"
+
+//limits in characters for the automatic column widths
+const (
+	excelMinColumnWidth = 8
+	excelMaxColumnWidth = 60
+)
+
+//volumeUnits are the units of measure, in lower case, of values formatted as volumes with thousands separators
+var volumeUnits = map[string]bool{"m3": true, "sm3": true, "ksm3": true, "msm3": true, "gsm3": true,
+	"bbl": true, "stb": true, "ft3": true, "scf": true, "mscf": true, "l": true}
+
+//columnFormat holds what is needed to choose the number format of the cells of a column
+type columnFormat struct {
+	unitIndex  int        //index of the unit of measure column for the values, -1 if none
+	unit       string     //unit of measure of all values in the column as declared in the schema
+	isVolume   bool       //the column name says it holds volumes
+	columnType ColumnType //type declared in the schema, ColumnTypeString if there is no schema
+}
+
+//buildColumnFormats finds the unit of measure column, named <column>_UoM or <column>UoM, for each column and
+//takes the fixed units and types of the columns from the schema, if any
+func buildColumnFormats(headers []string, schema []ColumnSchema) []columnFormat {
+	formats := make([]columnFormat, len(headers))
+	for i := 0; i < len(headers); i++ {
+		formats[i].unitIndex = -1
+		if i < len(schema) {
+			formats[i].unit = strings.ToLower(strings.TrimSpace(schema[i].Unit))
+			formats[i].columnType = schema[i].Type
+		}
+		formats[i].isVolume = strings.Contains(strings.ToLower(headers[i]), "volume") &&
+			!strings.HasSuffix(strings.ToLower(headers[i]), "uom")
+		for x := 0; x < len(headers); x++ {
+			if strings.EqualFold(headers[x], headers[i]+"_UoM") || strings.EqualFold(headers[x], headers[i]+"UoM") {
+				formats[i].unitIndex = x
+				break
+			}
+		}
+	}
+	return formats
+}
+
+//numberFormat returns the number format for the value in the column of the row, percent for % units, thousands
+//separators for volumes, empty for the general format
+func (format columnFormat) numberFormat(row RowData, isInt bool) string {
+	unit := format.unit
+	if format.unitIndex >= 0 && format.unitIndex < len(row.Columns) && row.Columns[format.unitIndex].IsStr {
+		unit = strings.ToLower(strings.TrimSpace(row.Columns[format.unitIndex].StrVal))
+	}
+	if unit == "%" {
+		if isInt {
+			return excelPercentIntFormat
+		}
+		return excelPercentFormat
+	} else if format.isVolume || volumeUnits[unit] {
+		if isInt {
+			return excelVolumeIntFormat
+		}
+		return excelVolumeFormat
+	}
+	return ""
+}
+
+//displayWidth returns the approximate number of characters shown for the value in excel
+func displayWidth(column ColumnData, numberFormat string) int {
+	if column.IsTime {
+		return len(datelayout_out_csv)
+	} else if column.IsFloat && numberFormat != "" {
+		value := strconv.FormatFloat(column.FloatVal, 'f', 2, 64)
+		//thousands separators and the percent sign
+		return len(value) + len(value)/4 + 1
+	}
+	return utf8.RuneCountInString(csvValue(column))
+}
+
+//formatSheet freezes the header row, adds an autofilter on the header and sets the column widths
+func formatSheet(sheet *xlsx.Sheet, columns, rows int, widths []int) {
+	sheet.SheetViews = []xlsx.SheetView{{Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+		State: "frozen"}}}
+	if columns > 0 {
+		sheet.AutoFilter = &xlsx.AutoFilter{TopLeftCell: "A1",
+			BottomRightCell: xlsx.GetCellIDStringFromCoords(columns-1, rows)}
+	}
+	for i := 0; i < len(widths); i++ {
+		width := widths[i] + 2
+		if width < excelMinColumnWidth {
+			width = excelMinColumnWidth
+		} else if width > excelMaxColumnWidth {
+			width = excelMaxColumnWidth
+		}
+		sheet.SetColWidth(i, i, float64(width))
+	}
+}
+
+//addIndexSheet adds the front sheet listing the sheets with their row count, a link to each sheet and the dataset
+//and part of the dataset on the sheet
+func addIndexSheet(file *xlsx.File, name string, parts []excelSheetPart) error {
+	sheet, err := file.AddSheet(name)
+	if err != nil {
+		return err
+	}
+	buildObjSheetHeaders(sheet.AddRow(), indexSheetHeaders)
+	widths := make([]int, len(indexSheetHeaders))
+	for i := 0; i < len(indexSheetHeaders); i++ {
+		widths[i] = len(indexSheetHeaders[i]) + 3
+	}
+	for i := 0; i < len(parts); i++ {
+		row := sheet.AddRow()
+		addHyperlinkCell(row, parts[i].name, parts[i].name)
+		addCellDataInt(row, parts[i].rows)
+		addCellData(row, parts[i].dataset)
+		addCellDataInt(row, parts[i].part)
+		if width := utf8.RuneCountInString(parts[i].name); width > widths[0] {
+			widths[0] = width
+		}
+		if width := utf8.RuneCountInString(parts[i].dataset); width > widths[2] {
+			widths[2] = width
+		}
+	}
+	formatSheet(sheet, len(indexSheetHeaders), len(parts), widths)
+	return nil
+}
+
 //Creates a new excel file where each dataset that is feed into this function is
-//represented as a worksheet
+//represented as a worksheet, datasets above the row limit of excel continues on more sheets.
+//An index sheet listing the dataset sheets is added first
 func createWorkbookFromDataSet(filepath string, datasets []DataSet) error {
 	var file *xlsx.File
 	var err error
-	var sheet *xlsx.Sheet
 	var dTimOptions xlsx.DateTimeOptions
-	loc := time.Now().Location()
-	dTimOptions.Location = loc
-	dTimOptions.ExcelTimeFormat = xlsx.DefaultDateTimeFormat
-	file = xlsx.NewFile()
+	dTimOptions.Location = Timezone()
+	dTimOptions.ExcelTimeFormat = excelDateTimeFormat
 	for i := 0; i < len(datasets); i++ {
-		//create the sheet
-		if sheet, err = file.AddSheet(datasets[i].Name); err != nil {
+		if err = checkDataSet(datasets[i]); err != nil {
 			return err
 		}
-		//add the headers
-		row := sheet.AddRow()
-		for s := 0; s < len(datasets[i].HeadersName); s++ {
-			addHeaderCellData(row, datasets[i].HeadersName[s])
+	}
+	file = xlsx.NewFile()
+	names := newExcelSheetNames()
+	parts := planExcelSheets(datasets, names)
+	if err = addIndexSheet(file, names.name(IndexSheetName, 1), parts); err != nil {
+		return err
+	}
+	i := 0
+	for x := 0; x < len(parts); x++ {
+		if x > 0 && parts[x].part == 1 {
+			i++
+		}
+		rows := datasets[i].Rows[parts[x].first : parts[x].first+parts[x].rows]
+		if err = addDataSetSheet(file, parts[x], datasets[i].HeadersName, datasets[i].Schema.Columns, rows,
+			dTimOptions); err != nil {
+			return err
 		}
-		//process the rowdata
-		for x := 0; x < len(datasets[i].Rows); x++ {
-			//add the new row
-			row := sheet.AddRow()
-			for y := 0; y < len(datasets[i].Rows[x].Columns); y++ {
-				if datasets[i].Rows[x].Columns[y].IsStr {
-					addCellData(row, datasets[i].Rows[x].Columns[y].StrVal)
-				} else if datasets[i].Rows[x].Columns[y].IsInt {
-					addCellDataInt(row, datasets[i].Rows[x].Columns[y].IntVal)
-				} else if datasets[i].Rows[x].Columns[y].IsFloat {
-					addCellDataFloat(row, datasets[i].Rows[x].Columns[y].FloatVal)
-				} else if datasets[i].Rows[x].Columns[y].IsTime {
-					//just add the cell data if it is not null otherwise add empty column
-					timeValue := datasets[i].Rows[x].Columns[y].TimeValue
-					if timeValue.IsZero() {
-						//add the empty column
-						addEmptyCell(row)
-					} else {
-						addCellDataTime(row, datasets[i].Rows[x].Columns[y].TimeValue, dTimOptions)
-					}
-
-				} else if datasets[i].Rows[x].Columns[y].IsEmptyColumn {
+	}
+	return file.Save(filepath)
+}
+
+//addDataSetSheet adds a sheet with the headers and the rows of the part of the dataset, ints in float columns
+//of the schema are written as floats
+func addDataSetSheet(file *xlsx.File, part excelSheetPart, headers []string, schema []ColumnSchema, rows []RowData,
+	dTimOptions xlsx.DateTimeOptions) error {
+	sheet, err := file.AddSheet(part.name)
+	if err != nil {
+		return err
+	}
+	formats := buildColumnFormats(headers, schema)
+	widths := make([]int, len(headers))
+	//add the headers, the width leaves room for the bold font and the filter button
+	row := sheet.AddRow()
+	for s := 0; s < len(headers); s++ {
+		addHeaderCellData(row, headers[s])
+		widths[s] = utf8.RuneCountInString(headers[s]) + 3
+	}
+	//process the rowdata
+	for x := 0; x < len(rows); x++ {
+		//add the new row
+		row := sheet.AddRow()
+		rowData := rows[x]
+		for y := 0; y < len(rowData.Columns); y++ {
+			var numberFormat string
+			column := rowData.Columns[y]
+			if y < len(formats) {
+				column = typedColumn(column, formats[y].columnType)
+			}
+			if y < len(formats) && (column.IsInt || column.IsFloat) {
+				numberFormat = formats[y].numberFormat(rowData, column.IsInt)
+			}
+			if IsNullValue(column) {
+				//missing values are left as empty cells
+				addEmptyCell(row)
+			} else if column.IsStr {
+				addCellData(row, column.StrVal)
+			} else if column.IsInt {
+				addCellDataIntWithFormat(row, column.IntVal, numberFormat)
+			} else if column.IsFloat {
+				addCellDataFloatWithFormat(row, column.FloatVal, numberFormat)
+			} else if column.IsTime {
+				//just add the cell data if it is not null otherwise add empty column
+				timeValue := column.TimeValue
+				if timeValue.IsZero() {
+					//add the empty column
 					addEmptyCell(row)
 				} else {
-					return errors.New(fmt.Sprintf("Undefined datatype found for data set with name: %s, row number:%d,column number:%d", datasets[i].Name, x, y))
+					addCellDataTime(row, column.TimeValue, dTimOptions)
+				}
+
+			} else if column.IsEmptyColumn {
+				addEmptyCell(row)
+			} else {
+				return errors.New(fmt.Sprintf("Undefined datatype found for data set with name: %s, row number:%d,column number:%d", part.dataset, part.first+x, y))
+			}
+			if y < len(widths) {
+				if width := displayWidth(column, numberFormat); width > widths[y] {
+					widths[y] = width
 				}
 			}
 		}
 	}
-	return file.Save(filepath)
+	formatSheet(sheet, len(headers), len(rows), widths)
+	return nil
 }
 
 //adds a cell formatted with center adjustement and bold txt
@@ -164,6 +346,42 @@ func addCellDataFloat(row *xlsx.Row, data float64) {
 	cell.SetFloat(data)
 }
 
+//adds a cell with the type of float to a row using the number format, general format if empty
+func addCellDataFloatWithFormat(row *xlsx.Row, data float64, numberFormat string) {
+	if numberFormat == "" {
+		addCellDataFloat(row, data)
+		return
+	}
+	cell := row.AddCell()
+	cell.SetFloatWithFormat(data, numberFormat)
+}
+
+//adds a cell with the type of int to a row using the number format, general format if empty
+func addCellDataIntWithFormat(row *xlsx.Row, data int, numberFormat string) {
+	cell := row.AddCell()
+	cell.SetInt(data)
+	if numberFormat != "" {
+		cell.SetFormat(numberFormat)
+	}
+}
+
+//adds a cell linking to cell A1 of the sheet with the text shown
+func addHyperlinkCell(row *xlsx.Row, sheetName string, text string) {
+	cell := row.AddCell()
+	target := "#'" + strings.ReplaceAll(sheetName, "'", "''") + "'!A1"
+	cell.SetStringFormula("HYPERLINK(\"" + strings.ReplaceAll(target, "\"", "\"\"") + "\",\"" +
+		strings.ReplaceAll(text, "\"", "\"\"") + "\")")
+	//cached value shown until the workbook is recalculated
+	cell.Value = text
+	style := xlsx.NewStyle()
+	font := *xlsx.NewFont(11, "Calibri")
+	font.Color = "FF0563C1"
+	font.Underline = true
+	style.Font = font
+	style.ApplyFont = true
+	cell.SetStyle(style)
+}
+
 //adds a cell with the type of string and set the style of it
 func addCellDataWithStyle(row *xlsx.Row, data string, style *xlsx.Style) {
 	cell := row.AddCell()
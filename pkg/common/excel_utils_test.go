@@ -0,0 +1,141 @@
+package common
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+//readWorkbookPart returns the content of a file in the xlsx zip archive
+func readWorkbookPart(t *testing.T, path, name string) string {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	defer reader.Close()
+	for i := 0; i < len(reader.File); i++ {
+		if reader.File[i].Name != name {
+			continue
+		}
+		file, err := reader.File[i].Open()
+		if err != nil {
+			t.Fatalf("Failed in opening workbook part:%s", err.Error())
+		}
+		defer file.Close()
+		data, _ := ioutil.ReadAll(file)
+		return string(data)
+	}
+	t.Fatalf("Workbook part not found:%s", name)
+	return ""
+}
+
+//excelFixtures has volumes with a long name, a volume, a percentage and a date to format and an info dataset
+var excelFixtures = []testFixture{
+	{name: "VOLUMES", headers: []string{"Facility", "Volume", "VolumeUoM", "Share", "ShareUoM", "Start"},
+		rows: []testRow{{"A very long facility name", NewMeasure(1234567.5, "Sm3"), NewMeasure(45.5, "%"),
+			time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC)}}},
+	{name: "INFO", headers: []string{"Name"}, rows: []testRow{{"info"}}},
+}
+
+func TestCreateWorkbookFormatting(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	path := filepath.Join(folder, "production.xlsx")
+	if err = CreateWorkbookFromDataSet(path, testDataSets(excelFixtures...), false, false); err != nil {
+		t.Fatalf("Failed in creating workbook:%s", err.Error())
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 3 || file.Sheets[0].Name != IndexSheetName {
+		t.Fatalf("Expected index sheet first followed by the dataset sheets, got %d sheets", len(file.Sheets))
+	}
+	link := file.Sheets[0].Cell(1, 0)
+	if link.Formula() != `HYPERLINK("#'VOLUMES'!A1","VOLUMES")` || link.Value != "VOLUMES" {
+		t.Errorf("Unexpected index link, formula:%s value:%s", link.Formula(), link.Value)
+	}
+	if rows, _ := file.Sheets[0].Cell(1, 1).Int(); rows != 1 {
+		t.Errorf("Expected row count 1 on index sheet, got:%d", rows)
+	}
+	volumes := file.Sheets[1]
+	if format := volumes.Cell(1, 1).GetNumberFormat(); format != excelVolumeFormat {
+		t.Errorf("Expected volume format, got:%s", format)
+	}
+	if format := volumes.Cell(1, 3).GetNumberFormat(); format != excelPercentFormat {
+		t.Errorf("Expected percent format, got:%s", format)
+	}
+	if format := volumes.Cell(1, 5).GetNumberFormat(); format != excelDateTimeFormat {
+		t.Errorf("Expected iso date format, got:%s", format)
+	}
+	if volumes.Cols[0].Width <= volumes.Cols[2].Width {
+		t.Errorf("Expected long facility column to be wider, got:%v and %v", volumes.Cols[0].Width, volumes.Cols[2].Width)
+	}
+	sheetXML := readWorkbookPart(t, path, "xl/worksheets/sheet2.xml")
+	if !strings.Contains(sheetXML, `state="frozen"`) || !strings.Contains(sheetXML, `<autoFilter ref="A1:F2">`) {
+		t.Errorf("Expected frozen header and autofilter, got:%s", sheetXML)
+	}
+}
+
+func TestCreateWorkbookOneFilePerSheet(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	if err = CreateWorkbookFromDataSet(filepath.Join(folder, "production.xlsx"), testDataSets(excelFixtures...), true, false); err != nil {
+		t.Fatalf("Failed in creating workbooks:%s", err.Error())
+	}
+	//a workbook with a single dataset also starts with the index sheet
+	file, err := xlsx.OpenFile(filepath.Join(folder, "production_INFO.xlsx"))
+	if err != nil {
+		t.Fatalf("Failed in opening workbook:%s", err.Error())
+	}
+	if len(file.Sheets) != 2 || file.Sheets[0].Name != IndexSheetName || file.Sheets[1].Name != "INFO" {
+		t.Errorf("Expected the index sheet and the dataset sheet, got %d sheets", len(file.Sheets))
+	}
+}
+
+func TestCreateWorkbookNulls(t *testing.T) {
+	folder, err := ioutil.TempDir("", "excel")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	dataset := nullFixture.dataSet()
+	//a null between values must not move the values after it
+	dataset.Rows[0].AddStrValue("after")
+	dataset.HeadersName = append(dataset.HeadersName, "After")
+	path := filepath.Join(folder, "nulls.xlsx")
+	streamPath := filepath.Join(folder, "nulls_stream.xlsx")
+	if err = CreateWorkbookFromDataSet(path, []DataSet{dataset}, false, false); err != nil {
+		t.Fatalf("Failed in creating workbook:%s", err.Error())
+	}
+	if err = CreateStreamWorkbookFromDataSet(streamPath, []DataSet{dataset}, false); err != nil {
+		t.Fatalf("Failed in creating stream workbook:%s", err.Error())
+	}
+	for _, workbook := range []string{path, streamPath} {
+		file, err := xlsx.OpenFile(workbook)
+		if err != nil {
+			t.Fatalf("Failed in opening workbook:%s", err.Error())
+		}
+		sheet := file.Sheets[1]
+		for y := 0; y < 7; y++ {
+			if value := sheet.Cell(1, y).Value; value != "" {
+				t.Errorf("Expected empty cell for null in column:%d of %s, got:%s", y, workbook, value)
+			}
+		}
+		if value := sheet.Cell(1, 7).Value; value != "after" {
+			t.Errorf("Expected value after the nulls in %s, got:%s", workbook, value)
+		}
+	}
+}
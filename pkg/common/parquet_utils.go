@@ -0,0 +1,154 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"go.uber.org/zap"
+)
+
+//ParquetExtension is the extension of parquet files written for datasets
+const ParquetExtension = ".parquet"
+
+//ParquetSourceFilesKey is the key of the file key/value metadata holding the source files as a json list
+const ParquetSourceFilesKey = "source_files"
+
+//parquetCreatedBy is written as created_by in the parquet file metadata
+const parquetCreatedBy = "subsurfaceTools"
+
+//DatasetsToParquet writes each dataset to its own parquet file named after the output file and the dataset name,
+//the source files are stored as a json list in the key/value metadata of each file
+func DatasetsToParquet(datasets []DataSet, outputFile string, sourceFiles []string) error {
+	var err error
+	var outputFolder, fileNameOnly, dataOutFile string
+	for i := 0; i < len(datasets); i++ {
+		//get the outputfolder to store in
+		outputFolder = GetFolderPathForFile(outputFile)
+		//get the file name without extension, parquet files always get the parquet extension
+		fileNameOnly, _ = GetFileNameAndExtension(outputFile)
+		dataOutFile = outputFolder + string(os.PathSeparator) + fileNameOnly + "_" + datasets[i].Name + ParquetExtension
+		if err = DatasetToParquet(datasets[i], dataOutFile, sourceFiles); err != nil {
+			return err
+		}
+		zap.S().Infof("Wrote parquet data for dataset name:%s to location:%s", datasets[i].Name, dataOutFile)
+	}
+	return nil
+}
+
+//DatasetToParquet writes the dataset to a parquet file
+func DatasetToParquet(dataset DataSet, outputFile string, sourceFiles []string) error {
+	var err error
+	var f *os.File
+	if f, err = os.Create(outputFile); err != nil {
+		return err
+	}
+	if err = WriteDatasetParquet(f, dataset, sourceFiles); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//WriteDatasetParquet writes the dataset as a parquet file using parquet-go. Columns are typed from the schema,
+//or the column data for datasets without a schema, as int64, double, timestamp (milliseconds, utc) or utf8 string,
+//null and empty columns and NullFloatValue are written as nulls
+func WriteDatasetParquet(w io.Writer, dataset DataSet, sourceFiles []string) error {
+	var err error
+	var sourceList []byte
+	var pw *writer.ParquetWriter
+	if err = checkDataSet(dataset); err != nil {
+		return err
+	}
+	if sourceFiles == nil {
+		sourceFiles = []string{}
+	}
+	if sourceList, err = json.Marshal(sourceFiles); err != nil {
+		return err
+	}
+	schema := dataset.ColumnSchemas()
+	if pw, err = writer.NewParquetWriterFromWriter(w, buildParquetSchema(dataset.HeadersName, schema), 1); err != nil {
+		return err
+	}
+	//the rows are written as lists of values in column order, as by the csv writer of parquet-go
+	pw.MarshalFunc = marshal.MarshalCSV
+	createdBy := parquetCreatedBy
+	pw.Footer.CreatedBy = &createdBy
+	for x := 0; x < len(dataset.Rows); x++ {
+		record := make([]interface{}, len(schema))
+		for y := 0; y < len(schema) && y < len(dataset.Rows[x].Columns); y++ {
+			record[y] = parquetValue(dataset.Rows[x].Columns[y], schema[y].Type)
+		}
+		if err = pw.Write(record); err != nil {
+			return err
+		}
+	}
+	pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata,
+		parquetKeyValue("dataset", dataset.Name), parquetKeyValue(ParquetSourceFilesKey, string(sourceList)))
+	return pw.WriteStop()
+}
+
+//buildParquetSchema returns the parquet schema elements for the columns, the root element followed by one optional
+//element per column, column names are made unique as parquet readers do not accept duplicated names
+func buildParquetSchema(headers []string, schema []ColumnSchema) []*parquet.SchemaElement {
+	names := uniqueColumnNames(headers)
+	root := parquet.NewSchemaElement()
+	root.Name = "schema"
+	root.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+	root.NumChildren = int32Ptr(int32(len(schema)))
+	elements := []*parquet.SchemaElement{root}
+	for i := 0; i < len(schema); i++ {
+		element := parquet.NewSchemaElement()
+		element.Name = names[i]
+		element.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		switch schema[i].Type {
+		case ColumnTypeTime:
+			element.Type = parquet.TypePtr(parquet.Type_INT64)
+			element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)
+		case ColumnTypeFloat:
+			element.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+		case ColumnTypeInt:
+			element.Type = parquet.TypePtr(parquet.Type_INT64)
+		default:
+			element.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+			element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+//parquetValue returns the value of the column data as the go type parquet-go writes for the column type,
+//nil for missing values
+func parquetValue(column ColumnData, columnType ColumnType) interface{} {
+	if IsNullValue(column) {
+		return nil
+	}
+	switch columnType {
+	case ColumnTypeTime:
+		return column.TimeValue.UnixNano() / int64(time.Millisecond)
+	case ColumnTypeFloat:
+		if column.IsInt {
+			return float64(column.IntVal)
+		}
+		return column.FloatVal
+	case ColumnTypeInt:
+		return int64(column.IntVal)
+	}
+	return csvValue(column)
+}
+
+func parquetKeyValue(key, value string) *parquet.KeyValue {
+	keyValue := parquet.NewKeyValue()
+	keyValue.Key = key
+	keyValue.Value = &value
+	return keyValue
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
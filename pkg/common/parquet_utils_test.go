@@ -0,0 +1,180 @@
+package common
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+//parquetFixture has a duplicated header, a null volume, an empty column and a row with a missing column
+var parquetFixture = testFixture{name: "volumes", headers: []string{"Well", "Oil", "Count", "Start", "Count"},
+	rows: []testRow{{"A-1", 10.5, 3, time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC), 1},
+		{"A-2", NullFloatValue, nil, time.Date(2020, 3, 1, 7, 0, 0, 0, time.UTC)}}}
+
+func TestWriteDatasetParquet(t *testing.T) {
+	folder, err := ioutil.TempDir("", "parquet")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	outputFile := filepath.Join(folder, "volumes.parquet")
+	if err = DatasetToParquet(parquetFixture.dataSet(), outputFile, []string{"/data/report.xml"}); err != nil {
+		t.Fatalf("Failed in writing parquet:%s", err.Error())
+	}
+	file, err := local.NewLocalFileReader(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		t.Fatalf("Failed in opening parquet file:%s", err.Error())
+	}
+	defer pr.ReadStop()
+	if pr.GetNumRows() != 2 || pr.Footer.GetCreatedBy() != parquetCreatedBy {
+		t.Errorf("Expected 2 rows created by:%s, got:%d created by:%s", parquetCreatedBy, pr.GetNumRows(),
+			pr.Footer.GetCreatedBy())
+	}
+	//schema has the root followed by the optional columns typed from the dataset schema
+	schema := pr.Footer.GetSchema()
+	expected := []struct {
+		name          string
+		physicalType  parquet.Type
+		convertedType *parquet.ConvertedType
+	}{
+		{"Well", parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)},
+		{"Oil", parquet.Type_DOUBLE, nil},
+		{"Count", parquet.Type_INT64, nil},
+		{"Start", parquet.Type_INT64, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)},
+		{"Count_2", parquet.Type_INT64, nil},
+	}
+	if len(schema) != len(expected)+1 || schema[0].GetNumChildren() != int32(len(expected)) {
+		t.Fatalf("Unexpected schema:%v", schema)
+	}
+	for i := 0; i < len(expected); i++ {
+		element := schema[i+1]
+		if element.GetName() != expected[i].name || element.GetType() != expected[i].physicalType ||
+			element.GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL ||
+			!reflect.DeepEqual(element.ConvertedType, expected[i].convertedType) {
+			t.Errorf("Unexpected schema element:%v, expected:%v", element, expected[i])
+		}
+	}
+	//the missing column of the second row and NullFloatValue are nulls
+	values, _, _, err := pr.ReadColumnByPath(pr.SchemaHandler.ValueColumns[4], 2)
+	if err != nil || !reflect.DeepEqual(values, []interface{}{int64(1), nil}) {
+		t.Errorf("Expected missing column to be null, got:%v, %v", values, err)
+	}
+	if values, _, _, err = pr.ReadColumnByPath(pr.SchemaHandler.ValueColumns[1], 2); err != nil ||
+		!reflect.DeepEqual(values, []interface{}{10.5, nil}) {
+		t.Errorf("Expected NullFloatValue to be null, got:%v, %v", values, err)
+	}
+}
+
+//TestReadParquetWithParquetGo will test that the rows and the key value metadata are read back by parquet-go
+func TestReadParquetWithParquetGo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDatasetParquet(&buf, parquetFixture.dataSet(), []string{"/data/report.xml"}); err != nil {
+		t.Fatalf("Failed in writing parquet:%s", err.Error())
+	}
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(file, nil, 1)
+	if err != nil {
+		t.Fatalf("Failed in opening parquet file with parquet-go:%s", err.Error())
+	}
+	defer pr.ReadStop()
+	if pr.GetNumRows() != 2 {
+		t.Fatalf("Expected 2 rows, got:%d", pr.GetNumRows())
+	}
+	schema := pr.Footer.GetSchema()
+	names := []string{}
+	for i := 1; i < len(schema); i++ {
+		names = append(names, schema[i].GetName())
+	}
+	if !reflect.DeepEqual(names, []string{"Well", "Oil", "Count", "Start", "Count_2"}) ||
+		schema[1].GetConvertedType() != parquet.ConvertedType_UTF8 ||
+		schema[4].GetConvertedType() != parquet.ConvertedType_TIMESTAMP_MILLIS {
+		t.Errorf("Unexpected schema read by parquet-go:%v", schema)
+	}
+	sourceFiles := ""
+	for _, keyValue := range pr.Footer.GetKeyValueMetadata() {
+		if keyValue.GetKey() == ParquetSourceFilesKey {
+			sourceFiles = keyValue.GetValue()
+		}
+	}
+	if sourceFiles != `["/data/report.xml"]` {
+		t.Errorf("Expected source files in key value metadata, got:%s", sourceFiles)
+	}
+	rows, err := pr.ReadByNumber(2)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("Failed in reading rows with parquet-go:%v, rows:%d", err, len(rows))
+	}
+	//optional columns are read as pointers, nil for nulls
+	field := func(row interface{}, name string) interface{} {
+		value := reflect.ValueOf(row).FieldByName(name)
+		if !value.IsValid() {
+			t.Fatalf("Column:%s not found in row read by parquet-go:%v", name, row)
+		}
+		if value.IsNil() {
+			return nil
+		}
+		return value.Elem().Interface()
+	}
+	start := time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC)
+	expected := [][]interface{}{
+		{"A-1", 10.5, int64(3), start.UnixNano() / int64(time.Millisecond), int64(1)},
+		{"A-2", nil, nil, start.Add(time.Hour).UnixNano() / int64(time.Millisecond), nil},
+	}
+	for i := 0; i < len(rows); i++ {
+		for x, name := range []string{"Well", "Oil", "Count", "Start", "Count_2"} {
+			if value := field(rows[i], name); value != expected[i][x] {
+				t.Errorf("Expected row:%d column:%s read as:%v, got:%v", i, name, expected[i][x], value)
+			}
+		}
+	}
+}
+
+//TestReadLargeParquetWithParquetGo will test that longer runs of nulls and values are read back by parquet-go
+func TestReadLargeParquetWithParquetGo(t *testing.T) {
+	fixture := testFixture{name: "volumes", headers: []string{"Well", "Oil"}}
+	for i := 0; i < 1000; i++ {
+		var oil interface{} = float64(i)
+		if i%3 == 0 || (i > 500 && i < 700) {
+			oil = testNullFloat
+		}
+		fixture.rows = append(fixture.rows, testRow{"A-" + string(rune('A'+i%26)), oil})
+	}
+	dataset := fixture.dataSet()
+	var buf bytes.Buffer
+	if err := WriteDatasetParquet(&buf, dataset, nil); err != nil {
+		t.Fatalf("Failed in writing parquet:%s", err.Error())
+	}
+	file, _ := buffer.NewBufferFile(buf.Bytes())
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		t.Fatalf("Failed in opening parquet file with parquet-go:%s", err.Error())
+	}
+	defer pr.ReadStop()
+	//the value columns are the paths of the columns below the root in the schema read from the file
+	values, _, levels, err := pr.ReadColumnByPath(pr.SchemaHandler.ValueColumns[1], 1000)
+	if err != nil || len(levels) != 1000 {
+		t.Fatalf("Failed in reading column with parquet-go:%v, values:%d", err, len(levels))
+	}
+	for i := 0; i < 1000; i++ {
+		null := i%3 == 0 || (i > 500 && i < 700)
+		if null != (values[i] == nil) || (!null && values[i].(float64) != float64(i)) {
+			t.Fatalf("Unexpected value at row:%d, got:%v", i, values[i])
+		}
+	}
+}
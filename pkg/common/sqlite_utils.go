@@ -0,0 +1,282 @@
+package common
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	//pure go sqlite driver without cgo, registered as sqlite
+	_ "modernc.org/sqlite"
+)
+
+//columns added to every table, the report key and row number are the primary key rows are upserted on and the
+//report version is the creation time of the version of the report the row was written from
+const (
+	SqliteReportKeyColumn     = "ReportKey"
+	SqliteRowNoColumn         = "RowNo"
+	SqliteReportVersionColumn = "ReportVersion"
+)
+
+//ReportKey links the DataUUID of the rows read from a report to the natural identity of the report, the key is the
+//same for every version of the report. The version is the creation time of the report, zero if not known
+type ReportKey struct {
+	DataUUID string
+	Key      string
+	Version  time.Time
+}
+
+//sqliteReports holds the report key of each DataUUID and the newest version of each report
+type sqliteReports struct {
+	keys   map[string]string
+	newest map[string]ReportKey
+}
+
+//newSqliteReports returns the report keys by DataUUID, when several versions of a report are in the list the one
+//created last is the newest, of versions created at the same time or without a creation time the one added last
+func newSqliteReports(reportKeys []ReportKey) sqliteReports {
+	reports := sqliteReports{keys: make(map[string]string), newest: make(map[string]ReportKey)}
+	for i := 0; i < len(reportKeys); i++ {
+		reports.keys[reportKeys[i].DataUUID] = reportKeys[i].Key
+		if newest, found := reports.newest[reportKeys[i].Key]; !found || !reportKeys[i].Version.Before(newest.Version) {
+			reports.newest[reportKeys[i].Key] = reportKeys[i]
+		}
+	}
+	return reports
+}
+
+//sqliteVersionValue returns the report version to store, nil if the creation time of the report is not known
+func sqliteVersionValue(version time.Time) interface{} {
+	if version.IsZero() {
+		return nil
+	}
+	return version.UTC()
+}
+
+//DatasetsToSqlite writes each dataset as a table in the sqlite database file, creating the file, tables and
+//columns if needed. Rows are upserted on the natural identity of the report they come from, looked up in
+//reportKeys on the DataUUID of the row, and their position within the report so that converting a report again
+//replaces its rows. Of several versions of a report converted together only the rows of the newest one are
+//written, and a version older than the one already stored in a table is skipped. Rows without a DataUUID found in
+//reportKeys are keyed on their values
+func DatasetsToSqlite(datasets []DataSet, outputFile string, reportKeys []ReportKey) error {
+	var err error
+	var db *sql.DB
+	var tx *sql.Tx
+	if db, err = sql.Open("sqlite", outputFile); err != nil {
+		return fmt.Errorf("Failed in opening sqlite database:%s, error:%s", outputFile, err.Error())
+	}
+	defer db.Close()
+	if tx, err = db.Begin(); err != nil {
+		return fmt.Errorf("Failed in starting sqlite transaction:%s, error:%s", outputFile, err.Error())
+	}
+	reports := newSqliteReports(reportKeys)
+	for i := 0; i < len(datasets); i++ {
+		if err = writeDatasetSqlite(tx, datasets[i], reports); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed in writing dataset:%s to sqlite database:%s, error:%s",
+				datasets[i].Name, outputFile, err.Error())
+		}
+		zap.S().Infof("Wrote sqlite data for dataset name:%s to location:%s", datasets[i].Name, outputFile)
+	}
+	return tx.Commit()
+}
+
+//sqliteColumnType returns the declared sqlite type for the column type
+func sqliteColumnType(columnType ColumnType) string {
+	switch columnType {
+	case ColumnTypeInt:
+		return "INTEGER"
+	case ColumnTypeFloat:
+		return "REAL"
+	case ColumnTypeTime:
+		return "DATETIME"
+	}
+	return "TEXT"
+}
+
+//sqliteValue returns the value to store for the column, nil for missing values
+func sqliteValue(column ColumnData) interface{} {
+	if IsNullValue(column) {
+		return nil
+	} else if column.IsFloat {
+		return column.FloatVal
+	} else if column.IsInt {
+		return int64(column.IntVal)
+	} else if column.IsTime {
+		//stored as utc so the text of the stored times orders as the times, also across daylight saving changes
+		return column.TimeValue.UTC()
+	}
+	return column.StrVal
+}
+
+//quoteIdentifier quotes a table or column name
+func quoteIdentifier(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+//createSqliteTable creates the table for the dataset if it does not exist and adds columns missing in an
+//existing table, e.g. columns added by a newer version of a report. Columns are typed from the dataset schema
+func createSqliteTable(tx *sql.Tx, dataset DataSet, columnNames []string) error {
+	var err error
+	var rows *sql.Rows
+	schema := dataset.ColumnSchemas()
+	table := quoteIdentifier(dataset.Name)
+	definitions := []string{quoteIdentifier(SqliteReportKeyColumn) + " TEXT NOT NULL",
+		quoteIdentifier(SqliteRowNoColumn) + " INTEGER NOT NULL", quoteIdentifier(SqliteReportVersionColumn) + " DATETIME"}
+	for i := 0; i < len(columnNames); i++ {
+		definitions = append(definitions, quoteIdentifier(columnNames[i])+" "+sqliteColumnType(schema[i].Type))
+	}
+	definitions = append(definitions, "PRIMARY KEY ("+quoteIdentifier(SqliteReportKeyColumn)+", "+
+		quoteIdentifier(SqliteRowNoColumn)+")")
+	if _, err = tx.Exec("CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") + ")"); err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	if rows, err = tx.Query("SELECT name FROM pragma_table_info(?)", dataset.Name); err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[strings.ToLower(name)] = true
+	}
+	rows.Close()
+	//tables written before the report version was stored
+	if !existing[strings.ToLower(SqliteReportVersionColumn)] {
+		if _, err = tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + quoteIdentifier(SqliteReportVersionColumn) +
+			" DATETIME"); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < len(columnNames); i++ {
+		if existing[strings.ToLower(columnNames[i])] {
+			continue
+		}
+		zap.S().Infof("Adding column:%s to sqlite table:%s", columnNames[i], dataset.Name)
+		if _, err = tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + quoteIdentifier(columnNames[i]) + " " +
+			sqliteColumnType(schema[i].Type)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//rowContentKey returns a key built from the values of the row, used for rows not belonging to a known report
+func rowContentKey(row RowData) string {
+	var values []string
+	for i := 0; i < len(row.Columns); i++ {
+		values = append(values, csvValue(row.Columns[i]))
+	}
+	hash := sha1.Sum([]byte(strings.Join(values, "\x1f")))
+	return "row:" + hex.EncodeToString(hash[:])
+}
+
+//writeDatasetSqlite upserts the rows of the dataset into its table and deletes rows left from earlier versions
+//of the reports that now have fewer rows, rows of versions of a report older than the newest one converted are skipped
+func writeDatasetSqlite(tx *sql.Tx, dataset DataSet, reports sqliteReports) error {
+	var err error
+	var stmt *sql.Stmt
+	if err = checkDataSet(dataset); err != nil {
+		return err
+	}
+	columnNames := uniqueColumnNames(dataset.HeadersName, SqliteReportKeyColumn, SqliteRowNoColumn,
+		SqliteReportVersionColumn)
+	if err = createSqliteTable(tx, dataset, columnNames); err != nil {
+		return err
+	}
+	table := quoteIdentifier(dataset.Name)
+	columns := []string{quoteIdentifier(SqliteReportKeyColumn), quoteIdentifier(SqliteRowNoColumn),
+		quoteIdentifier(SqliteReportVersionColumn)}
+	updates := []string{columns[2] + " = excluded." + columns[2]}
+	for i := 0; i < len(columnNames); i++ {
+		columns = append(columns, quoteIdentifier(columnNames[i]))
+		updates = append(updates, quoteIdentifier(columnNames[i])+" = excluded."+quoteIdentifier(columnNames[i]))
+	}
+	upsert := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ") ON CONFLICT (" + columns[0] + ", " +
+		columns[1] + ") DO UPDATE SET " + strings.Join(updates, ", ")
+	if stmt, err = tx.Prepare(upsert); err != nil {
+		return err
+	}
+	defer stmt.Close()
+	uuidIndex := -1
+	for i := 0; i < len(dataset.HeadersName); i++ {
+		if dataset.HeadersName[i] == "DataUUID" {
+			uuidIndex = i
+			break
+		}
+	}
+	//reports with a newer version already stored are left as they are
+	stored := make(map[string]bool)
+	for key, report := range reports.newest {
+		if report.Version.IsZero() {
+			continue
+		}
+		var count int
+		if err = tx.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE "+columns[0]+" = ? AND "+columns[2]+" > ?",
+			key, sqliteVersionValue(report.Version)).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			zap.S().Infof("Skipping report:%s in dataset:%s, a version newer than:%s is stored", key, dataset.Name,
+				report.Version.Format(time.RFC3339))
+			stored[key] = true
+		}
+	}
+	rowCounts := make(map[string]int64)
+	skipped := 0
+	for x := 0; x < len(dataset.Rows); x++ {
+		var key string
+		var rowNo int64
+		row := dataset.Rows[x]
+		if uuidIndex >= 0 && uuidIndex < len(row.Columns) {
+			uuid := row.Columns[uuidIndex].StrVal
+			key = reports.keys[uuid]
+			if key != "" && (reports.newest[key].DataUUID != uuid || stored[key]) {
+				skipped++
+				continue
+			}
+		}
+		var version interface{}
+		if key != "" {
+			rowNo = rowCounts[key]
+			rowCounts[key]++
+			version = sqliteVersionValue(reports.newest[key].Version)
+		} else {
+			key = rowContentKey(row)
+		}
+		values := []interface{}{key, rowNo, version}
+		for y := 0; y < len(columnNames); y++ {
+			if y < len(row.Columns) {
+				values = append(values, sqliteValue(row.Columns[y]))
+			} else {
+				values = append(values, nil)
+			}
+		}
+		if _, err = stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	if skipped > 0 {
+		zap.S().Infof("Skipped %d rows of older versions of reports in dataset:%s", skipped, dataset.Name)
+	}
+	//rows of the reports written beyond the rows they now have are left from an earlier version of the report
+	for key := range reports.newest {
+		if stored[key] {
+			continue
+		}
+		if _, err = tx.Exec("DELETE FROM "+table+" WHERE "+columns[0]+" = ? AND "+columns[1]+" >= ?",
+			key, rowCounts[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package common
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//oilDayReport returns the rows of a report read with the uuid, a row for each of the oil volumes
+func oilDayReport(uuid string, volumes []float64) DataSet {
+	fixture := testFixture{name: "OIL_DAY", headers: []string{"DataUUID", "Well", "Oil", "Start"}}
+	for i := 0; i < len(volumes); i++ {
+		fixture.rows = append(fixture.rows, testRow{uuid, "A-1", volumes[i], time.Date(2020, 3, 1, 6, 0, 0, 0, time.UTC)})
+	}
+	return fixture.dataSet()
+}
+
+func countSqliteRows(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	var count int
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		t.Fatalf("Failed in query:%s, error:%s", query, err.Error())
+	}
+	return count
+}
+
+func TestDatasetsToSqliteUpsert(t *testing.T) {
+	folder, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	dbFile := filepath.Join(folder, "production.db")
+	//first run with two rows for the report, the uuid is new for every read of a file
+	reportKeys := []ReportKey{{DataUUID: "uuid-1", Key: "DPR_1|2020-03-01"}}
+	if err = DatasetsToSqlite([]DataSet{oilDayReport("uuid-1", []float64{10.5, NullFloatValue})}, dbFile,
+		reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite:%s", err.Error())
+	}
+	//second run of the same report with one row and an added column
+	dataset := oilDayReport("uuid-2", []float64{12})
+	dataset.HeadersName = append(dataset.HeadersName, "Water")
+	dataset.Rows[0].AddFloatValue(3)
+	reportKeys = []ReportKey{{DataUUID: "uuid-2", Key: "DPR_1|2020-03-01"}}
+	if err = DatasetsToSqlite([]DataSet{dataset}, dbFile, reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite again:%s", err.Error())
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite:%s", err.Error())
+	}
+	defer db.Close()
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY"); count != 1 {
+		t.Errorf("Expected report rows to be replaced, got %d rows", count)
+	}
+	var oil, water float64
+	var uuid string
+	if err = db.QueryRow("SELECT Oil, Water, DataUUID FROM OIL_DAY WHERE ReportKey = ? AND RowNo = 0",
+		"DPR_1|2020-03-01").Scan(&oil, &water, &uuid); err != nil {
+		t.Fatalf("Failed in reading upserted row:%s", err.Error())
+	}
+	if oil != 12 || water != 3 || uuid != "uuid-2" {
+		t.Errorf("Unexpected upserted row, oil:%v water:%v uuid:%s", oil, water, uuid)
+	}
+	//columns are typed from the column data
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM pragma_table_info('OIL_DAY') WHERE "+
+		"(name = 'Oil' AND type = 'REAL') OR (name = 'Start' AND type = 'DATETIME') OR (name = 'Well' AND type = 'TEXT')"); count != 3 {
+		t.Errorf("Expected typed columns, got %d matching", count)
+	}
+}
+
+func TestDatasetsToSqliteVersionsInOneBatch(t *testing.T) {
+	folder, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	dbFile := filepath.Join(folder, "production.db")
+	//a resent report converted together with the original, the version read last replaces the other
+	dataset := oilDayReport("uuid-1", []float64{10, 11})
+	dataset.Rows = append(dataset.Rows, oilDayReport("uuid-2", []float64{12, 13}).Rows...)
+	dataset.Rows = append(dataset.Rows, oilDayReport("uuid-3", []float64{20}).Rows...)
+	reportKeys := []ReportKey{{DataUUID: "uuid-1", Key: "DPR_1|2020-03-01"},
+		{DataUUID: "uuid-2", Key: "DPR_1|2020-03-01"}, {DataUUID: "uuid-3", Key: "DPR_2|2020-03-01"}}
+	if err = DatasetsToSqlite([]DataSet{dataset}, dbFile, reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite:%s", err.Error())
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite:%s", err.Error())
+	}
+	defer db.Close()
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY"); count != 3 {
+		t.Errorf("Expected the rows of the newest version and the other report, got %d rows", count)
+	}
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY WHERE ReportKey = ? AND DataUUID = ? AND "+
+		"Oil IN (12, 13)", "DPR_1|2020-03-01", "uuid-2"); count != 2 {
+		t.Errorf("Expected the two rows of the newest version of the report, got:%d", count)
+	}
+}
+
+func TestDatasetsToSqliteVersionsByCreationTime(t *testing.T) {
+	folder, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	dbFile := filepath.Join(folder, "production.db")
+	created := time.Date(2020, 3, 2, 8, 0, 0, 0, time.UTC)
+	//the resent report is read first, the original created before it is read last and skipped
+	dataset := oilDayReport("uuid-2", []float64{12})
+	dataset.Rows = append(dataset.Rows, oilDayReport("uuid-1", []float64{10, 11}).Rows...)
+	reportKeys := []ReportKey{{DataUUID: "uuid-2", Key: "DPR_1|2020-03-01", Version: created},
+		{DataUUID: "uuid-1", Key: "DPR_1|2020-03-01", Version: created.Add(-time.Hour)}}
+	if err = DatasetsToSqlite([]DataSet{dataset}, dbFile, reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite:%s", err.Error())
+	}
+	//a later run of the original does not replace the newer version stored
+	reportKeys = []ReportKey{{DataUUID: "uuid-3", Key: "DPR_1|2020-03-01", Version: created.Add(-time.Hour)}}
+	if err = DatasetsToSqlite([]DataSet{oilDayReport("uuid-3", []float64{10, 11})}, dbFile,
+		reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite again:%s", err.Error())
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite:%s", err.Error())
+	}
+	defer db.Close()
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY WHERE DataUUID = ? AND Oil = 12",
+		"uuid-2"); count != 1 || countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY") != 1 {
+		t.Errorf("Expected only the row of the newest version, got:%d", count)
+	}
+	//a newer version replaces it
+	reportKeys = []ReportKey{{DataUUID: "uuid-4", Key: "DPR_1|2020-03-01", Version: created.Add(time.Hour)}}
+	if err = DatasetsToSqlite([]DataSet{oilDayReport("uuid-4", []float64{14, 15})}, dbFile,
+		reportKeys); err != nil {
+		t.Fatalf("Failed in writing sqlite newer version:%s", err.Error())
+	}
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY WHERE DataUUID = ? AND ReportVersion IS NOT NULL",
+		"uuid-4"); count != 2 || countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY") != 2 {
+		t.Errorf("Expected the rows of the newer version, got:%d", count)
+	}
+}
+
+func TestDatasetsToSqliteNulls(t *testing.T) {
+	folder, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	dbFile := filepath.Join(folder, "production.db")
+	dataset := oilDayReport("uuid-1", []float64{NullFloatValue})
+	dataset.Rows[0].Columns[1] = ColumnData{IsEmptyColumn: true}
+	//rows without a known report are keyed on their values so writing them twice does not duplicate them
+	for i := 0; i < 2; i++ {
+		if err = DatasetsToSqlite([]DataSet{dataset}, dbFile, nil); err != nil {
+			t.Fatalf("Failed in writing sqlite:%s", err.Error())
+		}
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite:%s", err.Error())
+	}
+	defer db.Close()
+	if count := countSqliteRows(t, db, "SELECT COUNT(*) FROM OIL_DAY WHERE Oil IS NULL AND Well IS NULL"); count != 1 {
+		t.Errorf("Expected one row with null values, got:%d", count)
+	}
+}
+
+func TestDatasetsToSqliteTimesInUTC(t *testing.T) {
+	folder, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	defer setTestTimezone(t, "Europe/Oslo")()
+	dbFile := filepath.Join(folder, "production.db")
+	//02:30 summer time is before 02:15 winter time on the day of the autumn transition in Oslo
+	dataset := oilDayReport("uuid-1", []float64{1, 2})
+	dataset.Rows[0].Columns[3] = ColumnData{TimeValue: InTimezone(time.Date(2020, 10, 25, 0, 30, 0, 0, time.UTC)), IsTime: true}
+	dataset.Rows[1].Columns[3] = ColumnData{TimeValue: InTimezone(time.Date(2020, 10, 25, 1, 15, 0, 0, time.UTC)), IsTime: true}
+	if err = DatasetsToSqlite([]DataSet{dataset}, dbFile, []ReportKey{{DataUUID: "uuid-1", Key: "DPR_1|2020-10-25"}}); err != nil {
+		t.Fatalf("Failed in writing sqlite:%s", err.Error())
+	}
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatalf("Failed in opening sqlite:%s", err.Error())
+	}
+	defer db.Close()
+	var oil float64
+	var start string
+	if err = db.QueryRow("SELECT Oil, CAST(Start AS TEXT) FROM OIL_DAY ORDER BY CAST(Start AS TEXT) LIMIT 1").Scan(&oil,
+		&start); err != nil {
+		t.Fatalf("Failed in reading times:%s", err.Error())
+	}
+	if oil != 1 || start[:19] != "2020-10-25 00:30:00" {
+		t.Errorf("Expected times stored in UTC and ordered as times, got oil:%v start:%s", oil, start)
+	}
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	//embeds the time zone database so that IANA time zones can be loaded on machines without it, e.g. windows
+	_ "time/tzdata"
+)
+
+var (
+	timezoneLock sync.RWMutex
+	timezone     = time.UTC
+)
+
+//LoadTimezone returns the location of a time zone given as UTC or an IANA name, e.g. Europe/Oslo, UTC for an
+//empty name. Local is not accepted as the output would depend on the machine running the conversion
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "Local") {
+		return nil, fmt.Errorf("Time zone Local is not supported, use UTC or an IANA name, e.g. Europe/Oslo")
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown time zone:%s, use UTC or an IANA name, e.g. Europe/Oslo, error:%s", name, err.Error())
+	}
+	return location, nil
+}
+
+//SetTimezone sets the time zone that xml times without a time zone are parsed in and that times are written in,
+//given as UTC or an IANA name, UTC is used until set
+func SetTimezone(name string) error {
+	location, err := LoadTimezone(name)
+	if err != nil {
+		return err
+	}
+	timezoneLock.Lock()
+	defer timezoneLock.Unlock()
+	timezone = location
+	return nil
+}
+
+//Timezone returns the time zone times are parsed and written in
+func Timezone() *time.Location {
+	timezoneLock.RLock()
+	defer timezoneLock.RUnlock()
+	return timezone
+}
+
+//InTimezone returns the time in the time zone times are written in, the zero time is returned as it is
+func InTimezone(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(Timezone())
+}
+
+//ParseXsdDateTime parses a xsd date time, e.g. 2017-05-12T09:18:06+02:00, a date time without a time zone is in
+//the time zone set by SetTimezone. The time is returned in that time zone
+func ParseXsdDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	parse, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		if parse, err = time.ParseInLocation("2006-01-02T15:04:05.999999999", value, Timezone()); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return parse.In(Timezone()), nil
+}
+
+//ParseXsdDate parses a xsd date, e.g. 2017-05-12, as the start of the day in the time zone set by SetTimezone.
+//A date is a calendar day, so the time zone of a date with one, e.g. 2017-05-12+02:00 or 2017-05-12Z, is
+//ignored and the date is the start of the same day in the time zone set
+func ParseXsdDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	parse, err := time.ParseInLocation(xsdDateLayout, value, Timezone())
+	if err != nil {
+		if parse, err = time.Parse(xsdDateLayout+"Z07:00", value); err != nil {
+			return time.Time{}, err
+		}
+		parse = time.Date(parse.Year(), parse.Month(), parse.Day(), 0, 0, 0, 0, Timezone())
+	}
+	return parse, nil
+}
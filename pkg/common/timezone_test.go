@@ -0,0 +1,128 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	for _, name := range []string{"", " utc ", "UTC"} {
+		if location, err := LoadTimezone(name); err != nil || location != time.UTC {
+			t.Errorf("Expected UTC for time zone:%s, got:%v, %v", name, location, err)
+		}
+	}
+	for _, name := range []string{"Local", "Mars/Olympus_Mons", "+02:00"} {
+		if _, err := LoadTimezone(name); err == nil {
+			t.Errorf("Expected error for time zone:%s", name)
+		}
+	}
+	if err := SetTimezone("Europe/Nowhere"); err == nil || Timezone() != time.UTC {
+		t.Errorf("Expected error and time zone left as UTC for unknown time zone")
+	}
+}
+
+func TestParseXsdTimesAcrossDST(t *testing.T) {
+	//times without time zone are UTC by default, whatever the time zone of the machine
+	if parse, _ := ParseXsdDateTime("2020-03-29T01:30:00"); parse.Format(time.RFC3339) != "2020-03-29T01:30:00Z" {
+		t.Errorf("Expected time without time zone in UTC, got:%s", parse.Format(time.RFC3339))
+	}
+	defer setTestTimezone(t, "Europe/Oslo")()
+	tests := []struct {
+		value    string
+		parse    func(value string) (time.Time, error)
+		expected string
+	}{
+		//the clocks go from 02:00 to 03:00 on the 29th of March 2020 and from 03:00 to 02:00 on the 25th of October
+		{"2020-03-29T01:59:59", ParseXsdDateTime, "2020-03-29T01:59:59+01:00"},
+		{"2020-03-29T03:00:00", ParseXsdDateTime, "2020-03-29T03:00:00+02:00"},
+		{"2020-10-25T01:30:00", ParseXsdDateTime, "2020-10-25T01:30:00+02:00"},
+		{"2020-10-25T03:30:00", ParseXsdDateTime, "2020-10-25T03:30:00+01:00"},
+		{"2020-10-25T02:30:00+01:00", ParseXsdDateTime, "2020-10-25T02:30:00+01:00"},
+		{"2020-10-25T00:30:00Z", ParseXsdDateTime, "2020-10-25T02:30:00+02:00"},
+		{"2020-10-25T01:30:00.5Z", ParseXsdDateTime, "2020-10-25T02:30:00+01:00"},
+		{"2020-03-29", ParseXsdDate, "2020-03-29T00:00:00+01:00"},
+		{"2020-03-30", ParseXsdDate, "2020-03-30T00:00:00+02:00"},
+		//dates are calendar days, a date with a time zone is the same day in the time zone set
+		{"2020-10-25Z", ParseXsdDate, "2020-10-25T00:00:00+02:00"},
+		{"2020-10-25+14:00", ParseXsdDate, "2020-10-25T00:00:00+02:00"},
+		{"2020-03-30-12:00", ParseXsdDate, "2020-03-30T00:00:00+02:00"},
+	}
+	for _, test := range tests {
+		parse, err := test.parse(test.value)
+		if err != nil {
+			t.Errorf("Failed in parsing:%s, error:%s", test.value, err.Error())
+		} else if parse.Format(time.RFC3339) != test.expected {
+			t.Errorf("Expected:%s parsed as:%s, got:%s", test.value, test.expected, parse.Format(time.RFC3339))
+		}
+	}
+	if _, err := ParseXsdDateTime("2020-13-01T00:00:00"); err == nil {
+		t.Errorf("Expected error for invalid time")
+	}
+	//the day of the spring transition has 23 hours
+	start, _ := ParseXsdDate("2020-03-29")
+	end, _ := ParseXsdDate("2020-03-30")
+	if hours := end.Sub(start).Hours(); hours != 23 {
+		t.Errorf("Expected 23 hours in the day of the spring transition, got:%v", hours)
+	}
+}
+
+func TestWriteTimesInTimezone(t *testing.T) {
+	//the two instants an hour apart are both shown as 02:30 in Oslo on the day of the autumn transition
+	first := time.Date(2020, 10, 25, 0, 30, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	row := RowData{}
+	row.AddTimeValue(first)
+	row.AddTimeValue(second)
+	if value := csvValue(row.Columns[0]); value != "2020-10-25 00:30:00" {
+		t.Errorf("Expected csv time in UTC, got:%s", value)
+	}
+	defer setTestTimezone(t, "Europe/Oslo")()
+	for i := 0; i < len(row.Columns); i++ {
+		if value := csvValue(row.Columns[i]); value != "2020-10-25 02:30:00" {
+			t.Errorf("Expected csv time:%d in Oslo time, got:%s", i, value)
+		}
+	}
+	row = RowData{}
+	row.AddTimeValue(second)
+	row.AddTimeValue(time.Time{})
+	if row.Columns[0].TimeValue.Location() != Timezone() || !row.Columns[1].IsNull {
+		t.Errorf("Expected time added in the time zone and zero time added as null")
+	}
+	workbook := &StreamWorkbook{location: Timezone()}
+	expected := xlsx.TimeToExcelTime(time.Date(2020, 3, 29, 3, 0, 0, 0, time.UTC), false)
+	if value := workbook.excelTime(time.Date(2020, 3, 29, 1, 0, 0, 0, time.UTC)); value != expected {
+		t.Errorf("Expected 01:00 UTC written as 03:00 in excel in Oslo summer time, got:%v", value)
+	}
+	filterTime, err := ParseFilterTime("2020-03-29 03:00")
+	if err != nil || !filterTime.Equal(time.Date(2020, 3, 29, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected filter time in Oslo time, got:%v, %v", filterTime, err)
+	}
+}
+
+func TestPivotDaysAcrossDST(t *testing.T) {
+	defer setTestTimezone(t, "Europe/Oslo")()
+	oslo := Timezone()
+	dataset := DataSet{Name: "WELLBORE", HeadersName: []string{"FacilityName", "FlowKind", "ProductKind", "dateStart",
+		"VolumeStd"}}
+	for _, day := range []int{28, 29, 31} {
+		row := RowData{}
+		row.AddStrValue("G-1")
+		row.AddStrValue("production")
+		row.AddStrValue("oil")
+		row.AddTimeValue(time.Date(2020, 3, day, 0, 0, 0, 0, oslo).UTC())
+		row.AddFloatValue(float64(day))
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	pivoted, err := DefaultPivot().Apply(dataset)
+	if err != nil {
+		t.Fatalf("Failed in pivoting:%s", err.Error())
+	}
+	//the days of 23 hours are still daily steps and the missing 30th is added at midnight in Oslo
+	expectValues(t, "days", []string{"2020-03-28 00:00:00", "2020-03-29 00:00:00", "2020-03-30 00:00:00",
+		"2020-03-31 00:00:00"}, pivotValues(t, pivoted, "dateStart"))
+	if !pivoted.Rows[2].Columns[0].TimeValue.Equal(time.Date(2020, 3, 30, 0, 0, 0, 0, oslo)) {
+		t.Errorf("Expected the added day at midnight in Oslo, got:%v", pivoted.Rows[2].Columns[0].TimeValue)
+	}
+}
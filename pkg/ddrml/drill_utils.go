@@ -10,8 +10,8 @@ package ddrml
 import (
 	"encoding/xml"
 	"io"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
@@ -20,35 +20,137 @@ import (
 )
 
 //Builds an excel output file for a given list of mprml objects
-func BuildXLSFileForDrilling(path string, dReports []DrillReports, oneFilePerSheet bool, appendTimeInName bool) error {
+func BuildXLSFileForDrilling(path string, dReports []DrillReports, oneFilePerSheet bool, appendTimeInName bool, transform common.DataSetTransform) error {
 	//just need to join everything
 	var drillReports []DrillReport
 	for i := 0; i < len(dReports); i++ {
 		drillReports = append(drillReports, dReports[i].DrillReports...)
 	}
-	dataSets := BuildDDRDataset(drillReports)
+	dataSets, err := common.TransformDataSets(BuildDDRDataset(drillReports), transform)
+	if err != nil {
+		return err
+	}
 
 	return common.CreateWorkbookFromDataSet(path, dataSets, oneFilePerSheet, appendTimeInName)
 }
 
+//BuildStreamXLSFileForDrilling writes an excel output file with the streaming writer, the files are read one at a
+//time and the rows of each dataset are built and written one report at a time, so memory use does not grow with
+//the number of files and reports. The row transforms are applied as the rows are written, with dataset transforms,
+//e.g. sorting, the rows of the datasets are kept in memory until all files are read
+func BuildStreamXLSFileForDrilling(path string, files []string, oneFilePerSheet bool, transforms common.StreamTransforms) error {
+	writer, err := common.NewDataSetWorkbookWriter(path, oneFilePerSheet, transforms)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(files) && err == nil; i++ {
+		var dReports DrillReports
+		zap.S().Info("Processing ddr xml file:", files[i])
+		if dReports, err = ParseDDRFile(files[i]); err != nil {
+			zap.S().Errorf("Failed in parsing ddr xml file:%s\n", err.Error())
+		} else {
+			err = WriteDDRDatasets(dReports.DrillReports, writer)
+		}
+	}
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
 func BuildCsvFileForDrilling(path string, dReports []DrillReports) error {
+	return BuildCsvFileForDrillingWithOptions(path, dReports, common.DefaultCsvOptions(), nil)
+}
+
+//BuildCsvFileForDrillingWithOptions writes one csv file per dataset using the given delimiter, bom and line endings
+func BuildCsvFileForDrillingWithOptions(path string, dReports []DrillReports, options common.CsvOptions, transform common.DataSetTransform) error {
 	var drillReports []DrillReport
 	for i := 0; i < len(dReports); i++ {
 		drillReports = append(drillReports, dReports[i].DrillReports...)
 	}
-	dataSets := BuildDDRDataset(drillReports)
-	return common.DatasetsToCsv(dataSets, path, ";")
+	dataSets, err := common.TransformDataSets(BuildDDRDataset(drillReports), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToCsvWithOptions(dataSets, path, options)
+}
+
+//BuildParquetFileForDrilling writes one parquet file per dataset with the xml files read as source files
+//in the metadata
+func BuildParquetFileForDrilling(path string, dReports []DrillReports, transform common.DataSetTransform) error {
+	var drillReports []DrillReport
+	var sourceFiles []string
+	for i := 0; i < len(dReports); i++ {
+		drillReports = append(drillReports, dReports[i].DrillReports...)
+		sourceFiles = append(sourceFiles, dReports[i].DataIdentification.FilePath)
+	}
+	dataSets, err := common.TransformDataSets(BuildDDRDataset(drillReports), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToParquet(dataSets, path, sourceFiles)
+}
+
+//BuildSqliteFileForDrilling writes each dataset as a table in the sqlite database, rows are upserted on the
+//uid of the drill reports so that converting a report again replaces its rows. Of several versions of a report
+//the one with the latest create date is kept, also when converted in an earlier run
+func BuildSqliteFileForDrilling(path string, dReports []DrillReports, transform common.DataSetTransform) error {
+	var drillReports []DrillReport
+	var reportKeys []common.ReportKey
+	for i := 0; i < len(dReports); i++ {
+		drillReports = append(drillReports, dReports[i].DrillReports...)
+		reportKeys = append(reportKeys, common.ReportKey{DataUUID: dReports[i].DataIdentification.UUid,
+			Key: reportKey(dReports[i]), Version: reportVersion(dReports[i])})
+	}
+	dataSets, err := common.TransformDataSets(BuildDDRDataset(drillReports), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToSqlite(dataSets, path, reportKeys)
+}
+
+//reportKey returns the natural identity of the drill reports in a file, the wellbore and uid of each report or
+//the wellbore name and start time if the report has no uid
+func reportKey(dReports DrillReports) string {
+	var keys []string
+	for i := 0; i < len(dReports.DrillReports); i++ {
+		report := dReports.DrillReports[i]
+		if report.Uid != "" {
+			keys = append(keys, report.UidWellbore+"/"+report.Uid)
+		} else {
+			keys = append(keys, report.NameWellbore+"/"+report.DTimStart.Format(time.RFC3339))
+		}
+	}
+	if len(keys) == 0 {
+		return dReports.DataIdentification.FileName
+	}
+	return strings.Join(keys, ",")
+}
+
+//reportVersion returns the creation time of the drill reports in a file, the latest create date of the reports
+func reportVersion(dReports DrillReports) time.Time {
+	var version time.Time
+	for i := 0; i < len(dReports.DrillReports); i++ {
+		if created := dReports.DrillReports[i].CreatedDate.Time; created.After(version) {
+			version = created
+		}
+	}
+	return version
 }
 
 //builds a json output file for drillind data
-func BuildJsonFileForDrilling(path string, dReports []DrillReports) error {
+func BuildJsonFileForDrilling(path string, dReports []DrillReports, transform common.DataSetTransform) error {
 	var err error
 	var data []byte
 	var drillReports []DrillReport
 	for i := 0; i < len(dReports); i++ {
 		drillReports = append(drillReports, dReports[i].DrillReports...)
 	}
-	dataSets := BuildDDRDataset(drillReports)
+	dataSets, err := common.TransformDataSets(BuildDDRDataset(drillReports), transform)
+	if err != nil {
+		return err
+	}
 
 	if data, err = common.DatasetsToJson(dataSets); err != nil {
 		return err
@@ -63,14 +165,22 @@ func ReadDDRXMLFiles2Struct(folderPath string) ([]DrillReports, error) {
 	var err error
 	var dReports []DrillReports
 	var files []string
-	var dReport DrillReports
 	start := time.Now()
 
-	if files, err = common.GetFilesWithExtension(folderPath, "*.xml"); err != nil {
+	if files, err = common.GetXMLFiles(folderPath); err != nil {
 		zap.S().Error("Failed in reading file in folder:", err.Error())
 		return dReports, err
 	}
 	fileSearchTook := time.Since(start)
+	zap.S().Infof("File search took:%s", fileSearchTook)
+	return ReadDDRXMLFileList2Struct(files)
+}
+
+//ReadDDRXMLFileList2Struct parses the given list of ddr xml files into a list of struct objects to be used for further processing
+func ReadDDRXMLFileList2Struct(files []string) ([]DrillReports, error) {
+	var err error
+	var dReports []DrillReports
+	var dReport DrillReports
 	xmlStart := time.Now()
 	for i := 0; i < len(files); i++ {
 		zap.S().Info("Processing ddr xml file:", files[i])
@@ -82,7 +192,6 @@ func ReadDDRXMLFiles2Struct(folderPath string) ([]DrillReports, error) {
 	}
 	xmlParseTook := time.Since(xmlStart)
 
-	zap.S().Infof("File search took:%s", fileSearchTook)
 	zap.S().Infof("XML parse took:%s", xmlParseTook)
 	return dReports, nil
 }
@@ -91,14 +200,16 @@ func ParseDDRFile(inputFile string) (DrillReports, error) {
 	var drillReports DrillReports
 	var err error
 	//var data []byte
-	var data io.Reader
+	var data io.ReadCloser
 
 	/*if data, err = common.ReadFile(inputFile); err != nil {
 		return drillReports, err
 	}*/
-	if data, err = os.Open(inputFile); err != nil {
+	//gzip compressed .xml.gz files are decompressed transparently
+	if data, err = common.OpenXMLFile(inputFile); err != nil {
 		return drillReports, err
 	}
+	defer data.Close()
 
 	//make sure we can read iso-8859-1
 	decoder := xml.NewDecoder(data)
@@ -132,37 +243,66 @@ func createDataId(inputFile string) ProcessingData {
 
 func BuildDDRDataset(dReports []DrillReport) []common.DataSet {
 	var dSets []common.DataSet
-	dSets = append(dSets, buildDDRReportFileInfo(dReports, "REPORT_FILE_INFO"))
-	dSets = append(dSets, buildDDRDrillReportInfo(dReports, "REPORT_INFO"))
-	dSets = append(dSets, buildDDRWellboreInfo(dReports, "WELLBORE_INFO"))
-	dSets = append(dSets, buildDDRStatusInfo(dReports, "STATUS_INFO"))
-	dSets = append(dSets, buildDDRBitRecords(dReports, "BIT_RECORDS"))
-	dSets = append(dSets, buildDDRCasingLinerTubings(dReports, "CASING_LINER_TUBING"))
-	dSets = append(dSets, buildDDRCementStages(dReports, "CEMENT_STAGES"))
-	dSets = append(dSets, buildDDRFluid(dReports, "FLUIDS"))
-	dSets = append(dSets, buildDDRPorePressure(dReports, "PORE_PRESSURES"))
-	dSets = append(dSets, buildDDRSurveyStation(dReports, "SURVEY_STATIONS"))
-	dSets = append(dSets, buildDDRActivities(dReports, "ACTIVITIES"))
-	dSets = append(dSets, buildDDRLoginfo(dReports, "LOG_INFO"))
-	dSets = append(dSets, buildDDRCoreInfo(dReports, "CORE_INFO"))
-	dSets = append(dSets, buildDDRWellTestInfo(dReports, "WELLTEST_INFO"))
-	dSets = append(dSets, buildDDRFormTestInfo(dReports, "FORMTEST_INFO"))
-	dSets = append(dSets, buildDDRLithShowInfo(dReports, "LITHSHOW_INFO"))
-	dSets = append(dSets, buildEquipFailureInfo(dReports, "EQUIPFAILURE_INFO"))
-	dSets = append(dSets, buildControlIncidentInfo(dReports, "CONTROLINCIDENT_INFO"))
-	dSets = append(dSets, buildStratInfo(dReports, "STRAT_INFO"))
-	dSets = append(dSets, buildPerfInfo(dReports, "PERF_INFO"))
-	dSets = append(dSets, buildGasReadingInfo(dReports, "GASREADING_INFO"))
-	dSets = append(dSets, buildWeather(dReports, "WEATHER"))
+	for i := 0; i < len(ddrDatasetBuilders); i++ {
+		dSets = append(dSets, ddrDatasetBuilders[i].build(dReports, ddrDatasetBuilders[i].name))
+	}
 	return dSets
 }
 
+//ddrDatasetBuilders lists the datasets built from drill reports in the order they are output
+var ddrDatasetBuilders = []struct {
+	name  string
+	build func(dReports []DrillReport, datasetName string) common.DataSet
+}{
+	{"REPORT_FILE_INFO", buildDDRReportFileInfo},
+	{"REPORT_INFO", buildDDRDrillReportInfo},
+	{"WELLBORE_INFO", buildDDRWellboreInfo},
+	{"STATUS_INFO", buildDDRStatusInfo},
+	{"BIT_RECORDS", buildDDRBitRecords},
+	{"CASING_LINER_TUBING", buildDDRCasingLinerTubings},
+	{"CEMENT_STAGES", buildDDRCementStages},
+	{"FLUIDS", buildDDRFluid},
+	{"PORE_PRESSURES", buildDDRPorePressure},
+	{"SURVEY_STATIONS", buildDDRSurveyStation},
+	{"ACTIVITIES", buildDDRActivities},
+	{"LOG_INFO", buildDDRLoginfo},
+	{"CORE_INFO", buildDDRCoreInfo},
+	{"WELLTEST_INFO", buildDDRWellTestInfo},
+	{"FORMTEST_INFO", buildDDRFormTestInfo},
+	{"LITHSHOW_INFO", buildDDRLithShowInfo},
+	{"EQUIPFAILURE_INFO", buildEquipFailureInfo},
+	{"CONTROLINCIDENT_INFO", buildControlIncidentInfo},
+	{"STRAT_INFO", buildStratInfo},
+	{"PERF_INFO", buildPerfInfo},
+	{"GASREADING_INFO", buildGasReadingInfo},
+	{"WEATHER", buildWeather},
+}
+
+//WriteDDRDatasets writes the datasets with the writer, the rows of each dataset are built one report at a time
+//and written before the next report is built. The report file information is written first, so a join transform
+//of the writer has the context of the reports when the other datasets are written
+func WriteDDRDatasets(dReports []DrillReport, writer *common.DataSetWorkbookWriter) error {
+	for i := 0; i < len(ddrDatasetBuilders); i++ {
+		builder := ddrDatasetBuilders[i]
+		if err := writer.WriteParts(len(dReports), func(x int) common.DataSet {
+			return builder.build(dReports[x:x+1], builder.name)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ddrReportFileInfoSchema declares the columns of the drilling report file information
+var ddrReportFileInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+}}
+
 func buildDDRReportFileInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	headers = []string{"DataUUID", "FileName", "FilePath"}
+	dSet := common.NewDataSet(datasetName, ddrReportFileInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		row := common.RowData{}
@@ -171,33 +311,95 @@ func buildDDRReportFileInfo(dReports []DrillReport, datasetName string) common.D
 		row.AddStrValue(dReports[i].DataIdentification.FilePath)
 		rows = append(rows, row)
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrFluidsSchema declares the columns of the drilling fluids of the drilling reports, one row per rheometer reading
+var ddrFluidsSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.StrColumn("Type", "Type of the fluid"),
+	common.StrColumn("LocationSample", "Location of the sample"),
+	common.TimeColumn("DTim", "Time of the sample"),
+	common.FloatColumn("MD", "Measured depth of the sample"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("TVD", "True vertical depth of the sample"),
+	common.UoMColumn("TVD_UoM", "TVD"),
+	common.FloatColumn("PresBopRating", "Pressure rating of the blowout preventer"),
+	common.UoMColumn("PresBopRating_UoM", "PresBopRating"),
+	common.StrColumn("MudClass", "Class of the mud"),
+	common.FloatColumn("Density", "Density of the fluid"),
+	common.UoMColumn("Density_UoM", "Density"),
+	common.FloatColumn("VisFunnel", "Funnel viscosity"),
+	common.UoMColumn("VisFunnel_UoM", "VisFunnel"),
+	common.FloatColumn("PV", "Plastic viscosity"),
+	common.UoMColumn("PV_UoM", "PV"),
+	common.FloatColumn("YP", "Yield point"),
+	common.UoMColumn("YP_UoM", "YP"),
+	common.FloatColumn("Gel10Sec", "Gel strength after 10 seconds"),
+	common.UoMColumn("Gel10Sec_UoM", "Gel10Sec"),
+	common.FloatColumn("Gel10Min", "Gel strength after 10 minutes"),
+	common.UoMColumn("Gel10Min_UoM", "Gel10Min"),
+	common.FloatColumn("Gel30Min", "Gel strength after 30 minutes"),
+	common.UoMColumn("Gel30Min_UoM", "Gel30Min"),
+	common.FloatColumn("FilterCakeLtlp", "Filter cake thickness at low temperature and pressure"),
+	common.UoMColumn("FilterCakeLtlp_UoM", "FilterCakeLtlp"),
+	common.FloatColumn("FiltrateLtlp", "Filtrate volume at low temperature and pressure"),
+	common.UoMColumn("FiltrateLtlp_UoM", "FiltrateLtlp"),
+	common.FloatColumn("TempHthp", "Temperature of the high temperature high pressure test"),
+	common.UoMColumn("TempHthp_UoM", "TempHthp"),
+	common.FloatColumn("FiltrateHthp", "Filtrate volume at high temperature and pressure"),
+	common.UoMColumn("FiltrateHthp_UoM", "FiltrateHthp"),
+	common.FloatColumn("FilterCakeHthp", "Filter cake thickness at high temperature and pressure"),
+	common.UoMColumn("FilterCakegthp_UoM", "FilterCakeHthp"),
+	common.FloatColumn("SolidsPc", "Solids percentage"),
+	common.UoMColumn("SolidsPc_UoM", "SolidsPc"),
+	common.FloatColumn("WaterPc", "Water percentage"),
+	common.UoMColumn("WaterPc_UoM", "WaterPc"),
+	common.FloatColumn("OilPc", "Oil percentage"),
+	common.UoMColumn("OilPc_UoM", "OilPc"),
+	common.FloatColumn("SandPc", "Sand percentage"),
+	common.UoMColumn("SandPc_UoM", "SandPc"),
+	common.FloatColumn("SolidsLowGravPc", "Low gravity solids percentage"),
+	common.UoMColumn("SolidsLowGravPc_UoM", "SolidsLowGravPc"),
+	common.FloatColumn("PH", "pH of the fluid"),
+	common.FloatColumn("PM", "Phenolphthalein alkalinity of the mud"),
+	common.UoMColumn("PM_UoM", "PM"),
+	common.FloatColumn("PMFiltrate", "Phenolphthalein alkalinity of the filtrate"),
+	common.UoMColumn("PMFiltrate_UoM", "PMFiltrate"),
+	common.FloatColumn("MF", "Methyl orange alkalinity of the filtrate"),
+	common.UoMColumn("MF_UoM", "MF"),
+	common.FloatColumn("Chloride", "Chloride concentration"),
+	common.UoMColumn("Chloride_UoM", "Chloride"),
+	common.FloatColumn("Calcium", "Calcium concentration"),
+	common.UoMColumn("Calcium_UoM", "Calcium"),
+	common.FloatColumn("Magnesium", "Magnesium concentration"),
+	common.UoMColumn("Magnesium_UoM", "Magnesium"),
+	common.FloatColumn("TempRheom", "Temperature of the rheometer reading"),
+	common.UoMColumn("TempRheom_UoM", "TempRheom"),
+	common.FloatColumn("PresRheom", "Pressure of the rheometer reading"),
+	common.UoMColumn("PresRheom_UoM", "PresRheom"),
+	common.FloatColumn("Vis3Rpm", "Viscosity at 3 rpm"),
+	common.FloatColumn("Vis6Rpm", "Viscosity at 6 rpm"),
+	common.FloatColumn("Vis30Rpm", "Viscosity at 30 rpm"),
+	common.FloatColumn("Vis60Rpm", "Viscosity at 60 rpm"),
+	common.FloatColumn("Vis100Rpm", "Viscosity at 100 rpm"),
+	common.FloatColumn("Vis200Rpm", "Viscosity at 200 rpm"),
+	common.FloatColumn("Vis300Rpm", "Viscosity at 300 rpm"),
+	common.FloatColumn("Vis600Rpm", "Viscosity at 600 rpm"),
+	common.FloatColumn("Lime", "Lime concentration"),
+	common.UoMColumn("Lime_UoM", "Lime"),
+	common.FloatColumn("SolidsHiGravPc", "High gravity solids percentage"),
+	common.UoMColumn("SolidsHiGravPc_UoM", "SolidsHiGravPc"),
+	common.FloatColumn("SolCorPc", "Corrected solids percentage"),
+	common.UoMColumn("SolCorPc_UoM", "SolCorPc"),
+	common.StrColumn("Comments", "Comments to the fluid"),
+)
+
 func buildDDRFluid(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "Type", "LocationSample",
-		"DTim", "MD", "MD_UoM", "TVD", "TVD_UoM", "PresBopRating", "PresBopRating_UoM",
-		"MudClass", "Density", "Density_UoM", "VisFunnel", "VisFunnel_UoM", "PV", "PV_UoM",
-		"YP", "YP_UoM", "Gel10Sec", "Gel10Sec_UoM", "Gel10Min", "Gel10Min_UoM",
-		"Gel30Min", "Gel30Min_UoM", "FilterCakeLtlp", "FilterCakeLtlp_UoM",
-		"FiltrateLtlp", "FiltrateLtlp_UoM", "TempHthp", "TempHthp_UoM",
-		"FiltrateHthp", "FiltrateHthp_UoM", "FilterCakeHthp", "FilterCakegthp_UoM",
-		"SolidsPc", "SolidsPc_UoM", "WaterPc", "WaterPc_UoM", "OilPc", "OilPc_UoM",
-		"SandPc", "SandPc_UoM", "SolidsLowGravPc", "SolidsLowGravPc_UoM",
-		"PH", "PM", "PM_UoM", "PMFiltrate", "PMFiltrate_UoM", "MF", "MF_UoM", "Chloride", "Chloride_UoM",
-		"Calcium", "Calcium_UoM", "Magnesium", "Magnesium_UoM",
-		"TempRheom", "TempRheom_UoM", "PresRheom", "PresRheom_UoM",
-		"Vis3Rpm", "Vis6Rpm", "Vis30Rpm", "Vis60Rpm", "Vis100Rpm", "Vis200Rpm",
-		"Vis300Rpm", "Vis600Rpm", "Lime", "Lime_UoM", "SolidsHiGravPc", "SolidsHiGravPc_UoM",
-		"SolCorPc", "SolCorPc_UoM", "Comments"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrFluidsSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -212,105 +414,106 @@ func buildDDRFluid(dReports []DrillReport, datasetName string) common.DataSet {
 				row.AddStrValue(fluid.Type)
 				row.AddStrValue(fluid.LocationSample)
 				row.AddTimeValue(fluid.DTim.Time)
-				row.AddFloatValue(fluid.Md.Value)
-				row.AddStrValue(fluid.Md.Uom)
-				row.AddFloatValue(fluid.Tvd.Value)
-				row.AddStrValue(fluid.Tvd.Uom)
-				row.AddFloatValue(fluid.PresBopRating.Value)
-				row.AddStrValue(fluid.PresBopRating.Uom)
+				row.AddMeasureValue(fluid.Md)
+				row.AddMeasureValue(fluid.Tvd)
+				row.AddMeasureValue(fluid.PresBopRating)
 				row.AddStrValue(fluid.MudClass)
-				row.AddFloatValue(fluid.Density.Value)
-				row.AddStrValue(fluid.Density.Uom)
-				row.AddFloatValue(fluid.VisFunnel.Value)
-				row.AddStrValue(fluid.VisFunnel.Uom)
-				row.AddFloatValue(fluid.PV.Value)
-				row.AddStrValue(fluid.PV.Uom)
-				row.AddFloatValue(fluid.YP.Value)
-				row.AddStrValue(fluid.YP.Uom)
-				row.AddFloatValue(fluid.Gel10Sec.Value)
-				row.AddStrValue(fluid.Gel10Sec.Uom)
-				row.AddFloatValue(fluid.Gel10Min.Value)
-				row.AddStrValue(fluid.Gel10Min.Uom)
-				row.AddFloatValue(fluid.Gel30Min.Value)
-				row.AddStrValue(fluid.Gel30Min.Uom)
-				row.AddFloatValue(fluid.FilterCakeLtlp.Value)
-				row.AddStrValue(fluid.FilterCakeLtlp.Uom)
-				row.AddFloatValue(fluid.FiltrateLtlp.Value)
-				row.AddStrValue(fluid.FiltrateLtlp.Uom)
-				row.AddFloatValue(fluid.TempHtHp.Value)
-				row.AddStrValue(fluid.TempHtHp.Uom)
-				row.AddFloatValue(fluid.FiltrateHtHp.Value)
-				row.AddStrValue(fluid.FiltrateHtHp.Uom)
-				row.AddFloatValue(fluid.FilterCakeHtHp.Value)
-				row.AddStrValue(fluid.FilterCakeHtHp.Uom)
-				row.AddFloatValue(fluid.SolidsPc.Value)
-				row.AddStrValue(fluid.SolidsPc.Uom)
-				row.AddFloatValue(fluid.WaterPc.Value)
-				row.AddStrValue(fluid.WaterPc.Uom)
-				row.AddFloatValue(fluid.OilPc.Value)
-				row.AddStrValue(fluid.OilPc.Uom)
-				row.AddFloatValue(fluid.SandPc.Value)
-				row.AddStrValue(fluid.SandPc.Uom)
-				row.AddFloatValue(fluid.SolidsLowGravPc.Value)
-				row.AddStrValue(fluid.SolidsLowGravPc.Uom)
-				row.AddFloatValue(fluid.PH)
-				row.AddFloatValue(fluid.PM.Value)
-				row.AddStrValue(fluid.PM.Uom)
-				row.AddFloatValue(fluid.PMFiltrate.Value)
-				row.AddStrValue(fluid.PMFiltrate.Uom)
-				row.AddFloatValue(fluid.MF.Value)
-				row.AddStrValue(fluid.MF.Uom)
-				row.AddFloatValue(fluid.Chloride.Value)
-				row.AddStrValue(fluid.Chloride.Uom)
-				row.AddFloatValue(fluid.Calcium.Value)
-				row.AddStrValue(fluid.Calcium.Uom)
-				row.AddFloatValue(fluid.Magnesium.Value)
-				row.AddStrValue(fluid.Magnesium.Uom)
-				row.AddFloatValue(rheom.TempRheom.Value)
-				row.AddStrValue(rheom.TempRheom.Uom)
-				row.AddFloatValue(rheom.PressRheom.Value)
-				row.AddStrValue(rheom.PressRheom.Uom)
-				row.AddFloatValue(rheom.Vis3Rpm)
-				row.AddFloatValue(rheom.Vis6Rpm)
-				row.AddFloatValue(rheom.Vis30Rpm)
-				row.AddFloatValue(rheom.Vis60Rpm)
-				row.AddFloatValue(rheom.Vis100Rpm)
-				row.AddFloatValue(rheom.Vis200Rpm)
-				row.AddFloatValue(rheom.Vis300Rpm)
-				row.AddFloatValue(rheom.Vis600Rpm)
-				row.AddFloatValue(fluid.Lime.Value)
-				row.AddStrValue(fluid.Lime.Uom)
-				row.AddFloatValue(fluid.SolidsHiGravPc.Value)
-				row.AddStrValue(fluid.SolidsHiGravPc.Uom)
-				row.AddFloatValue(fluid.SolCorPc.Value)
-				row.AddStrValue(fluid.SolCorPc.Uom)
+				row.AddMeasureValue(fluid.Density)
+				row.AddMeasureValue(fluid.VisFunnel)
+				row.AddMeasureValue(fluid.PV)
+				row.AddMeasureValue(fluid.YP)
+				row.AddMeasureValue(fluid.Gel10Sec)
+				row.AddMeasureValue(fluid.Gel10Min)
+				row.AddMeasureValue(fluid.Gel30Min)
+				row.AddMeasureValue(fluid.FilterCakeLtlp)
+				row.AddMeasureValue(fluid.FiltrateLtlp)
+				row.AddMeasureValue(fluid.TempHtHp)
+				row.AddMeasureValue(fluid.FiltrateHtHp)
+				row.AddMeasureValue(fluid.FilterCakeHtHp)
+				row.AddMeasureValue(fluid.SolidsPc)
+				row.AddMeasureValue(fluid.WaterPc)
+				row.AddMeasureValue(fluid.OilPc)
+				row.AddMeasureValue(fluid.SandPc)
+				row.AddMeasureValue(fluid.SolidsLowGravPc)
+				addDoubleValue(&row, fluid.PH)
+				row.AddMeasureValue(fluid.PM)
+				row.AddMeasureValue(fluid.PMFiltrate)
+				row.AddMeasureValue(fluid.MF)
+				row.AddMeasureValue(fluid.Chloride)
+				row.AddMeasureValue(fluid.Calcium)
+				row.AddMeasureValue(fluid.Magnesium)
+				row.AddMeasureValue(rheom.TempRheom)
+				row.AddMeasureValue(rheom.PressRheom)
+				addDoubleValue(&row, rheom.Vis3Rpm)
+				addDoubleValue(&row, rheom.Vis6Rpm)
+				addDoubleValue(&row, rheom.Vis30Rpm)
+				addDoubleValue(&row, rheom.Vis60Rpm)
+				addDoubleValue(&row, rheom.Vis100Rpm)
+				addDoubleValue(&row, rheom.Vis200Rpm)
+				addDoubleValue(&row, rheom.Vis300Rpm)
+				addDoubleValue(&row, rheom.Vis600Rpm)
+				row.AddMeasureValue(fluid.Lime)
+				row.AddMeasureValue(fluid.SolidsHiGravPc)
+				row.AddMeasureValue(fluid.SolCorPc)
 				row.AddStrValue(fluid.Comments)
 
 				rows = append(rows, row)
 			}
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrWeatherSchema declares the columns of the weather observations of the drilling reports
+var ddrWeatherSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the observation"),
+	common.StrColumn("Agency", "Agency of the observation"),
+	common.FloatColumn("BarometricPressure", "Barometric pressure"),
+	common.UoMColumn("BarometricPressure_UoM", "BarometricPressure"),
+	common.IntColumn("BeaufortScaleNumber", "Wind force on the Beaufort scale"),
+	common.FloatColumn("TempSurfaceMn", "Minimum surface temperature"),
+	common.UoMColumn("TempSurfaceMn_UoM", "TempSurfaceMn"),
+	common.FloatColumn("TempSurfaceMx", "Maximum surface temperature"),
+	common.UoMColumn("TempSurfaceMx_UoM", "TempSurfaceMx"),
+	common.FloatColumn("TempWindChill", "Wind chill temperature"),
+	common.UoMColumn("TempWindChill_UoM", "TempWindChill"),
+	common.FloatColumn("TempSea", "Sea temperature"),
+	common.UoMColumn("TempSea_UoM", "TempSea"),
+	common.FloatColumn("Visibility", "Visibility"),
+	common.UoMColumn("Visibility_UoM", "Visibility"),
+	common.FloatColumn("AziWave", "Azimuth of the waves"),
+	common.UoMColumn("AziWave_UoM", "AziWave"),
+	common.FloatColumn("HtWave", "Height of the waves"),
+	common.UoMColumn("HtWave_UoM", "HtWave"),
+	common.FloatColumn("SignificantWave", "Significant wave height"),
+	common.UoMColumn("SignificantWave_UoM", "SignificantWave"),
+	common.FloatColumn("MaxWave", "Maximum wave height"),
+	common.UoMColumn("MaxWave_UoM", "MaxWave"),
+	common.FloatColumn("PeriodWave", "Period of the waves"),
+	common.UoMColumn("PeriodWave_UoM", "PeriodWave"),
+	common.FloatColumn("AziWind", "Azimuth of the wind"),
+	common.UoMColumn("AziWind_UoM", "AziWind"),
+	common.FloatColumn("VelWind", "Velocity of the wind"),
+	common.UoMColumn("VelWind_UoM", "VelWind"),
+	common.StrColumn("TypePrecip", "Type of precipitation"),
+	common.FloatColumn("AmtPrecip", "Amount of precipitation"),
+	common.UoMColumn("AmtPrecip_UoM", "AmtPrecip"),
+	common.StrColumn("CoverCloud", "Cloud cover"),
+	common.FloatColumn("CeilingCloud", "Cloud ceiling"),
+	common.UoMColumn("CeilingCloud_UoM", "CeilingCloud"),
+	common.FloatColumn("CurrentSea", "Velocity of the sea current"),
+	common.UoMColumn("CurrentSea_UoM", "CurrentSea"),
+	common.FloatColumn("AziCurrentSea", "Azimuth of the sea current"),
+	common.UoMColumn("AziCurrentSea_UoM", "AziCurrentSea"),
+	common.StrColumn("Comments", "Comments"),
+)
+
 func buildWeather(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "Agency", "BarometricPressure",
-		"BarometricPressure_UoM", "BeaufortScaleNumber", "TempSurfaceMn", "TempSurfaceMn_UoM",
-		"TempSurfaceMx", "TempSurfaceMx_UoM", "TempWindChill", "TempWindChill_UoM",
-		"TempSea", "TempSea_UoM", "Visibility", "Visibility_UoM", "AziWave",
-		"AziWave_UoM", "HtWave", "HtWave_UoM",
-		"SignificantWave", "SignificantWave_UoM", "MaxWave", "MaxWave_UoM", "PeriodWave",
-		"PeriodWave_UoM", "AziWind", "AziWind_UoM", "VelWind", "VelWind_UoM", "TypePrecip",
-		"AmtPrecip", "AmtPrecip_UoM", "CoverCloud", "CeilingCloud", "CeilingCloud_UoM",
-		"CurrentSea", "CurrentSea_UoM", "AziCurrentSea", "AziCurrentSea_UoM", "Comments"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrWeatherSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -321,68 +524,73 @@ func buildWeather(dReports []DrillReport, datasetName string) common.DataSet {
 			row.AddStrValue(recordId)
 			row.AddTimeValue(wInfo.DTim.Time)
 			row.AddStrValue(wInfo.Agency)
-			row.AddFloatValue(wInfo.BarometricPressure.Value)
-			row.AddStrValue(wInfo.BarometricPressure.Uom)
+			row.AddMeasureValue(wInfo.BarometricPressure)
 			row.AddIntValue(wInfo.BeaufortScaleNumber)
-			row.AddFloatValue(wInfo.TempSurfaceMn.Value)
-			row.AddStrValue(wInfo.TempSurfaceMn.Uom)
-			row.AddFloatValue(wInfo.TempSurfaceMx.Value)
-			row.AddStrValue(wInfo.TempSurfaceMx.Uom)
-			row.AddFloatValue(wInfo.TempWindChill.Value)
-			row.AddStrValue(wInfo.TempWindChill.Uom)
-			row.AddFloatValue(wInfo.TempSea.Value)
-			row.AddStrValue(wInfo.TempSea.Uom)
-			row.AddFloatValue(wInfo.Visibility.Value)
-			row.AddStrValue(wInfo.Visibility.Uom)
-			row.AddFloatValue(wInfo.AziWave.Value)
-			row.AddStrValue(wInfo.AziWave.Uom)
-			row.AddFloatValue(wInfo.HtWave.Value)
-			row.AddStrValue(wInfo.HtWave.Uom)
-			row.AddFloatValue(wInfo.SignificantWave.Value)
-			row.AddStrValue(wInfo.SignificantWave.Uom)
-			row.AddFloatValue(wInfo.SignificantWave.Value)
-			row.AddStrValue(wInfo.SignificantWave.Uom)
-			row.AddFloatValue(wInfo.MaxWave.Value)
-			row.AddStrValue(wInfo.MaxWave.Uom)
-			row.AddFloatValue(wInfo.PeriodWave.Value)
-			row.AddStrValue(wInfo.PeriodWave.Uom)
-			row.AddFloatValue(wInfo.AziWind.Value)
-			row.AddStrValue(wInfo.AziWind.Uom)
-			row.AddFloatValue(wInfo.VelWind.Value)
-			row.AddStrValue(wInfo.VelWind.Uom)
+			row.AddMeasureValue(wInfo.TempSurfaceMn)
+			row.AddMeasureValue(wInfo.TempSurfaceMx)
+			row.AddMeasureValue(wInfo.TempWindChill)
+			row.AddMeasureValue(wInfo.TempSea)
+			row.AddMeasureValue(wInfo.Visibility)
+			row.AddMeasureValue(wInfo.AziWave)
+			row.AddMeasureValue(wInfo.HtWave)
+			row.AddMeasureValue(wInfo.SignificantWave)
+			row.AddMeasureValue(wInfo.MaxWave)
+			row.AddMeasureValue(wInfo.PeriodWave)
+			row.AddMeasureValue(wInfo.AziWind)
+			row.AddMeasureValue(wInfo.VelWind)
 			row.AddStrValue(wInfo.TypePrecip)
-			row.AddFloatValue(wInfo.AmtPrecip.Value)
-			row.AddStrValue(wInfo.AmtPrecip.Uom)
+			row.AddMeasureValue(wInfo.AmtPrecip)
 			row.AddStrValue(wInfo.CoverCloud)
-			row.AddFloatValue(wInfo.CeilingCloud.Value)
-			row.AddStrValue(wInfo.CeilingCloud.Uom)
-			row.AddFloatValue(wInfo.CurrentSea.Value)
-			row.AddStrValue(wInfo.CurrentSea.Uom)
-			row.AddFloatValue(wInfo.AziCurrentSea.Value)
-			row.AddStrValue(wInfo.AziCurrentSea.Uom)
+			row.AddMeasureValue(wInfo.CeilingCloud)
+			row.AddMeasureValue(wInfo.CurrentSea)
+			row.AddMeasureValue(wInfo.AziCurrentSea)
 			row.AddStrValue(wInfo.Comments)
 
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrGasReadingInfoSchema declares the columns of the gas readings of the drilling reports
+var ddrGasReadingInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the reading"),
+	common.StrColumn("ReadingType", "Type of the reading"),
+	common.FloatColumn("MdTop", "Measured depth of the top of the interval"),
+	common.UoMColumn("MdTop_UoM", "MdTop"),
+	common.FloatColumn("MdBottom", "Measured depth of the bottom of the interval"),
+	common.UoMColumn("MdBottom_UoM", "MdBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the interval"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the interval"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.FloatColumn("GasHigh", "Highest gas reading"),
+	common.UoMColumn("GasHigh_UoM", "GasHigh"),
+	common.FloatColumn("GasLow", "Lowest gas reading"),
+	common.UoMColumn("GasLow_UoM", "GasLow"),
+	common.FloatColumn("Meth", "Methane concentration"),
+	common.UoMColumn("Meth_UoM", "Meth"),
+	common.FloatColumn("Eth", "Ethane concentration"),
+	common.UoMColumn("Eth_UoM", "Eth"),
+	common.FloatColumn("Prop", "Propane concentration"),
+	common.UoMColumn("Prop_UoM", "Prop"),
+	common.FloatColumn("IBut", "Iso-butane concentration"),
+	common.UoMColumn("IBut_UoM", "IBut"),
+	common.FloatColumn("NBut", "Normal butane concentration"),
+	common.UoMColumn("NBut_UoM", "NBut"),
+	common.FloatColumn("IPent", "Iso-pentane concentration"),
+	common.UoMColumn("IPent_UoM", "IPent"),
+	common.FloatColumn("NPent", "Normal pentane concentration"),
+	common.UoMColumn("NPent_UoM", "NPent"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildGasReadingInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "ReadingType", "MdTop",
-		"MdTop_UoM", "MdBottom", "MdBottom_UoM", "TvdTop", "TvdTop_UoM",
-		"TvdBottom", "TvdBottom_UoM", "GasHigh", "GasHigh_UoM", "GasLow",
-		"GasLow_UoM", "Meth", "Meth_UoM", "Eth", "Eth_UoM", "Prop", "Prop_UoM",
-		"IBut", "IBut_UoM", "NBut", "NBut_UoM", "IPent", "IPent_UoM", "NPent",
-		"NPent_UoM", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrGasReadingInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -393,51 +601,47 @@ func buildGasReadingInfo(dReports []DrillReport, datasetName string) common.Data
 			row.AddStrValue(recordId)
 			row.AddTimeValue(gInfo.DTim.Time)
 			row.AddStrValue(gInfo.ReadingType)
-			row.AddFloatValue(gInfo.MdTop.Value)
-			row.AddStrValue(gInfo.MdTop.Uom)
-			row.AddFloatValue(gInfo.MdBottom.Value)
-			row.AddStrValue(gInfo.MdBottom.Uom)
-			row.AddFloatValue(gInfo.TvdTop.Value)
-			row.AddStrValue(gInfo.TvdTop.Uom)
-			row.AddFloatValue(gInfo.TvdBottom.Value)
-			row.AddStrValue(gInfo.TvdBottom.Uom)
-			row.AddFloatValue(gInfo.GasHigh.Value)
-			row.AddStrValue(gInfo.GasHigh.Uom)
-			row.AddFloatValue(gInfo.GasLow.Value)
-			row.AddStrValue(gInfo.GasLow.Uom)
-			row.AddFloatValue(gInfo.Meth.Value)
-			row.AddStrValue(gInfo.Meth.Uom)
-			row.AddFloatValue(gInfo.Eth.Value)
-			row.AddStrValue(gInfo.Eth.Uom)
-			row.AddFloatValue(gInfo.Prop.Value)
-			row.AddStrValue(gInfo.Prop.Uom)
-			row.AddFloatValue(gInfo.Ibut.Value)
-			row.AddStrValue(gInfo.Ibut.Uom)
-			row.AddFloatValue(gInfo.NBut.Value)
-			row.AddStrValue(gInfo.NBut.Uom)
-			row.AddFloatValue(gInfo.IPent.Value)
-			row.AddStrValue(gInfo.IPent.Uom)
-			row.AddFloatValue(gInfo.NPent.Value)
-			row.AddStrValue(gInfo.NPent.Uom)
+			row.AddMeasureValue(gInfo.MdTop)
+			row.AddMeasureValue(gInfo.MdBottom)
+			row.AddMeasureValue(gInfo.TvdTop)
+			row.AddMeasureValue(gInfo.TvdBottom)
+			row.AddMeasureValue(gInfo.GasHigh)
+			row.AddMeasureValue(gInfo.GasLow)
+			row.AddMeasureValue(gInfo.Meth)
+			row.AddMeasureValue(gInfo.Eth)
+			row.AddMeasureValue(gInfo.Prop)
+			row.AddMeasureValue(gInfo.Ibut)
+			row.AddMeasureValue(gInfo.NBut)
+			row.AddMeasureValue(gInfo.IPent)
+			row.AddMeasureValue(gInfo.NPent)
 			row.AddStrValue(gInfo.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrPerfInfoSchema declares the columns of the perforations of the drilling reports
+var ddrPerfInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTimOpen", "Time the perforation was opened"),
+	common.TimeColumn("DTimClose", "Time the perforation was closed"),
+	common.FloatColumn("MdTop", "Measured depth of the top of the perforation"),
+	common.UoMColumn("MdTop_UoM", "MdTop"),
+	common.FloatColumn("MdBottom", "Measured depth of the bottom of the perforation"),
+	common.UoMColumn("MdBottom_UoM", "MdBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the perforation"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the perforation"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildPerfInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTimOpen", "DTimClose", "MdTop", "MdTop_UoM",
-		"MdBottom", "MdBottom_UoM", "TvdTop", "TvdTop_UoM", "TvdBottom",
-		"TvdBottom_UoM", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrPerfInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -448,32 +652,37 @@ func buildPerfInfo(dReports []DrillReport, datasetName string) common.DataSet {
 			row.AddStrValue(recordId)
 			row.AddTimeValue(pInfo.DTimOpen.Time)
 			row.AddTimeValue(pInfo.DTimClose.Time)
-			row.AddFloatValue(pInfo.MdTop.Value)
-			row.AddStrValue(pInfo.MdTop.Uom)
-			row.AddFloatValue(pInfo.MdBottom.Value)
-			row.AddStrValue(pInfo.MdBottom.Uom)
-			row.AddFloatValue(pInfo.TvdTop.Value)
-			row.AddStrValue(pInfo.TvdTop.Uom)
-			row.AddFloatValue(pInfo.TvdBottom.Value)
-			row.AddStrValue(pInfo.TvdBottom.Uom)
+			row.AddMeasureValue(pInfo.MdTop)
+			row.AddMeasureValue(pInfo.MdBottom)
+			row.AddMeasureValue(pInfo.TvdTop)
+			row.AddMeasureValue(pInfo.TvdBottom)
 			row.AddStrValue(pInfo.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrStratInfoSchema declares the columns of the stratigraphy of the drilling reports
+var ddrStratInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the description"),
+	common.FloatColumn("MDTopPlanned", "Planned measured depth of the top"),
+	common.UoMColumn("MDTopPlanned_UoM", "MDTopPlanned"),
+	common.FloatColumn("TvdTopPlanned", "Planned true vertical depth of the top"),
+	common.UoMColumn("TvdTopPlanned_UoM", "TvdTopPlanned"),
+	common.FloatColumn("MdTop", "Measured depth of the top"),
+	common.UoMColumn("MdTop_UoM", "MdTop"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.StrColumn("Description", "Description"),
+)
+
 func buildStratInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "MDTopPlanned", "MDTopPlanned_UoM",
-		"TvdTopPlanned", "TvdTopPlanned_UoM", "MdTop", "MdTop_UoM", "TvdTop", "TvdTop_UoM", "Description"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrStratInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -483,37 +692,63 @@ func buildStratInfo(dReports []DrillReport, datasetName string) common.DataSet {
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddTimeValue(sInfo.DTim.Time)
-			row.AddFloatValue(sInfo.MdTopPlanned.Value)
-			row.AddStrValue(sInfo.MdTopPlanned.Uom)
-			row.AddFloatValue(sInfo.TvdTopPlanned.Value)
-			row.AddStrValue(sInfo.TvdTopPlanned.Uom)
-			row.AddFloatValue(sInfo.MdTop.Value)
-			row.AddStrValue(sInfo.MdTop.Uom)
-			row.AddFloatValue(sInfo.TvdTop.Value)
-			row.AddStrValue(sInfo.TvdTop.Uom)
+			row.AddMeasureValue(sInfo.MdTopPlanned)
+			row.AddMeasureValue(sInfo.TvdTopPlanned)
+			row.AddMeasureValue(sInfo.MdTop)
+			row.AddMeasureValue(sInfo.TvdTop)
 			row.AddStrValue(sInfo.Description)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrControlIncidentInfoSchema declares the columns of the well control incidents of the drilling reports
+var ddrControlIncidentInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the incident"),
+	common.FloatColumn("MdInflow", "Measured depth of the inflow"),
+	common.UoMColumn("MdInflow_UoM", "MdInflow"),
+	common.FloatColumn("TvdInflow", "True vertical depth of the inflow"),
+	common.UoMColumn("TvdInflow_UoM", "TvdInflow"),
+	common.StrColumn("Phase", "Phase of the operations"),
+	common.StrColumn("ProprietaryCode", "Proprietary code of the incident"),
+	common.FloatColumn("ETimLost", "Time lost"),
+	common.UoMColumn("ETimLost_UoM", "ETimLost"),
+	common.TimeColumn("DTimRegained", "Time the control was regained"),
+	common.FloatColumn("DiaBit", "Diameter of the bit"),
+	common.UoMColumn("DiaBit_UoM", "DiaBit"),
+	common.FloatColumn("MdBit", "Measured depth of the bit"),
+	common.UoMColumn("MdBit_UoM", "MdBit"),
+	common.FloatColumn("WTMud", "Mud weight"),
+	common.UoMColumn("WTMud_UoM", "WTMud"),
+	common.FloatColumn("PorePressure", "Pore pressure"),
+	common.UoMColumn("PorePressure_UoM", "PorePressure"),
+	common.FloatColumn("DiaCsgLast", "Diameter of the last casing"),
+	common.UoMColumn("DiaCsgLast_UoM", "DiaCsgLast"),
+	common.FloatColumn("MDCsgLast", "Measured depth of the last casing"),
+	common.UoMColumn("MdCsgLast_UoM", "MDCsgLast"),
+	common.FloatColumn("VolMudGained", "Volume of mud gained"),
+	common.UoMColumn("VolMudGained_UoM", "VolMudGained"),
+	common.FloatColumn("PresShutinCasing", "Shut in casing pressure"),
+	common.UoMColumn("PresShutinCasing_UoM", "PresShutinCasing"),
+	common.FloatColumn("PresShutInDrill", "Shut in drill pipe pressure"),
+	common.UoMColumn("PresShutinDrill_UoM", "PresShutInDrill"),
+	common.StrColumn("IncidentType", "Type of the incident"),
+	common.StrColumn("KillingType", "Type of the well kill"),
+	common.StrColumn("Formation", "Formation of the inflow"),
+	common.FloatColumn("TempBottom", "Bottom hole temperature"),
+	common.UoMColumn("TempBottom_UoM", "TempBottom"),
+	common.FloatColumn("PresMaxChoke", "Maximum choke pressure"),
+	common.UoMColumn("PresMaxChoke_UoM", "PresMaxChoke"),
+	common.StrColumn("Description", "Description"),
+)
+
 func buildControlIncidentInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "MdInflow", "MdInflow_UoM",
-		"TvdInflow", "TvdInflow_UoM", "Phase", "ProprietaryCode", "ETimLost", "ETimLost_UoM",
-		"DTimRegained", "DiaBit", "DiaBit_UoM", "MdBit", "MdBit_UoM", "WTMud", "WTMud_UoM",
-		"PorePressure", "PorePressure_UoM", "DiaCsgLast", "DiaCsgLast_UoM", "MDCsgLast", "MdCsgLast_UoM",
-		"VolMudGained", "VolMudGained_UoM", "PresShutinCasing", "PresShutinCasing_UoM",
-		"PresShutInDrill", "PresShutinDrill_UoM", "IncidentType", "KillingType", "Formation",
-		"TempBottom", "TempBottom_UoM", "PresMaxChoke", "PresMaxChoke_UoM", "Description"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrControlIncidentInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -523,58 +758,53 @@ func buildControlIncidentInfo(dReports []DrillReport, datasetName string) common
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddTimeValue(cInfo.DTim.Time)
-			row.AddFloatValue(cInfo.MdInflow.Value)
-			row.AddStrValue(cInfo.MdInflow.Uom)
-			row.AddFloatValue(cInfo.TvdInflow.Value)
-			row.AddStrValue(cInfo.TvdInflow.Uom)
+			row.AddMeasureValue(cInfo.MdInflow)
+			row.AddMeasureValue(cInfo.TvdInflow)
 			row.AddStrValue(cInfo.Phase)
 			row.AddStrValue(cInfo.ProprietaryCode)
-			row.AddFloatValue(cInfo.ETimLost.Value)
-			row.AddStrValue(cInfo.ETimLost.Uom)
+			row.AddMeasureValue(cInfo.ETimLost)
 			row.AddTimeValue(cInfo.DTimRegained.Time)
-			row.AddFloatValue(cInfo.DiaBit.Value)
-			row.AddStrValue(cInfo.DiaBit.Uom)
-			row.AddFloatValue(cInfo.MdBit.Value)
-			row.AddStrValue(cInfo.MdBit.Uom)
-			row.AddFloatValue(cInfo.WtMud.Value)
-			row.AddStrValue(cInfo.WtMud.Uom)
-			row.AddFloatValue(cInfo.PorePressure.Value)
-			row.AddStrValue(cInfo.PorePressure.Uom)
-			row.AddFloatValue(cInfo.DiaCsgLast.Value)
-			row.AddStrValue(cInfo.DiaCsgLast.Uom)
-			row.AddFloatValue(cInfo.MdCsgLast.Value)
-			row.AddStrValue(cInfo.MdCsgLast.Uom)
-			row.AddFloatValue(cInfo.VolMudGained.Value)
-			row.AddStrValue(cInfo.VolMudGained.Uom)
-			row.AddFloatValue(cInfo.PresShutinCasing.Value)
-			row.AddStrValue(cInfo.PresShutinCasing.Uom)
-			row.AddFloatValue(cInfo.PresShutInDrill.Value)
-			row.AddStrValue(cInfo.PresShutInDrill.Uom)
+			row.AddMeasureValue(cInfo.DiaBit)
+			row.AddMeasureValue(cInfo.MdBit)
+			row.AddMeasureValue(cInfo.WtMud)
+			row.AddMeasureValue(cInfo.PorePressure)
+			row.AddMeasureValue(cInfo.DiaCsgLast)
+			row.AddMeasureValue(cInfo.MdCsgLast)
+			row.AddMeasureValue(cInfo.VolMudGained)
+			row.AddMeasureValue(cInfo.PresShutinCasing)
+			row.AddMeasureValue(cInfo.PresShutInDrill)
 			row.AddStrValue(cInfo.IncidentType)
 			row.AddStrValue(cInfo.KillingType)
 			row.AddStrValue(cInfo.Formation)
-			row.AddFloatValue(cInfo.TempBottom.Value)
-			row.AddStrValue(cInfo.TempBottom.Uom)
-			row.AddFloatValue(cInfo.PresMaxChoke.Value)
-			row.AddStrValue(cInfo.PresMaxChoke.Uom)
+			row.AddMeasureValue(cInfo.TempBottom)
+			row.AddMeasureValue(cInfo.PresMaxChoke)
 			row.AddStrValue(cInfo.Description)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
+
+//ddrEquipFailureInfoSchema declares the columns of the equipment failures of the drilling reports
+var ddrEquipFailureInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the failure"),
+	common.FloatColumn("Md", "Measured depth of the failure"),
+	common.UoMColumn("Md_UoM", "Md"),
+	common.FloatColumn("Tvd", "True vertical depth of the failure"),
+	common.UoMColumn("Tvd_UoM", "Tvd"),
+	common.StrColumn("EquipClass", "Class of the equipment"),
+	common.FloatColumn("ETimMissProduction", "Production time lost"),
+	common.UoMColumn("ETimMissProduction_UoM", "ETimMissProduction"),
+	common.TimeColumn("DTimRepair", "Time of the repair"),
+	common.StrColumn("Description", "Description"),
+)
+
 func buildEquipFailureInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "Md", "Md_UoM",
-		"Tvd", "Tvd_UoM", "EquipClass", "ETimMissProduction", "ETimMissProduction_UoM",
-		"DTimRepair", "Description"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrEquipFailureInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -584,33 +814,39 @@ func buildEquipFailureInfo(dReports []DrillReport, datasetName string) common.Da
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddTimeValue(eInfo.DTim.Time)
-			row.AddFloatValue(eInfo.Md.Value)
-			row.AddStrValue(eInfo.Md.Uom)
-			row.AddFloatValue(eInfo.Tvd.Value)
-			row.AddStrValue(eInfo.Tvd.Uom)
+			row.AddMeasureValue(eInfo.Md)
+			row.AddMeasureValue(eInfo.Tvd)
 			row.AddStrValue(eInfo.EquipClass)
-			row.AddFloatValue(eInfo.ETimMissProduction.Value)
-			row.AddStrValue(eInfo.ETimMissProduction.Uom)
+			row.AddMeasureValue(eInfo.ETimMissProduction)
 			row.AddTimeValue(eInfo.DTimRepair.Time)
 			row.AddStrValue(eInfo.Description)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrLithShowInfoSchema declares the columns of the lithology and shows of the drilling reports
+var ddrLithShowInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the description"),
+	common.FloatColumn("MdTop", "Measured depth of the top of the interval"),
+	common.UoMColumn("MdTop_UoM", "MdTop"),
+	common.FloatColumn("MdBottom", "Measured depth of the bottom of the interval"),
+	common.UoMColumn("MdBottom_UoM", "MdBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the interval"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the interval"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.StrColumn("Show", "Description of the shows"),
+	common.StrColumn("Lithology", "Description of the lithology"),
+)
+
 func buildDDRLithShowInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "MdTop", "MdTop_UoM",
-		"MdBottom", "MdBottom_UoM", "TvdTop", "TvdTop_UoM", "TvdBottom", "TvdBottom_UoM",
-		"Show", "Lithology"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrLithShowInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -620,36 +856,52 @@ func buildDDRLithShowInfo(dReports []DrillReport, datasetName string) common.Dat
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddTimeValue(lInfo.DTim.Time)
-			row.AddFloatValue(lInfo.MdTop.Value)
-			row.AddStrValue(lInfo.MdTop.Uom)
-			row.AddFloatValue(lInfo.MdBottom.Value)
-			row.AddStrValue(lInfo.MdBottom.Uom)
-			row.AddFloatValue(lInfo.TvdTop.Value)
-			row.AddStrValue(lInfo.TvdTop.Uom)
-			row.AddFloatValue(lInfo.TvdBottom.Value)
-			row.AddStrValue(lInfo.TvdBottom.Uom)
+			row.AddMeasureValue(lInfo.MdTop)
+			row.AddMeasureValue(lInfo.MdBottom)
+			row.AddMeasureValue(lInfo.TvdTop)
+			row.AddMeasureValue(lInfo.TvdBottom)
 			row.AddStrValue(lInfo.Show)
 			row.AddStrValue(lInfo.Lithology)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrFormTestInfoSchema declares the columns of the formation tests of the drilling reports
+var ddrFormTestInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the test"),
+	common.StrColumn("RunNumber", "Number of the run"),
+	common.IntColumn("TestNumber", "Number of the test"),
+	common.FloatColumn("MD", "Measured depth of the test"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("TVD", "True vertical depth of the test"),
+	common.UoMColumn("TVD_UoM", "TVD"),
+	common.FloatColumn("PresPore", "Pore pressure"),
+	common.UoMColumn("PresPore_UoM", "PresPore"),
+	common.FloatColumn("FluidDensity", "Density of the fluid"),
+	common.UoMColumn("FluidDensity_UoM", "FluidDensity"),
+	common.FloatColumn("HydrostaticPresBefore", "Hydrostatic pressure before the test"),
+	common.UoMColumn("HydrostaticPresBefore_UoM", "HydrostaticPresBefore"),
+	common.FloatColumn("LeakOffPressure", "Leak off pressure"),
+	common.UoMColumn("LeakOffPressure_UoM", "LeakOffPressure"),
+	common.StrColumn("GoodSeal", "True for a good seal"),
+	common.FloatColumn("MdSample", "Measured depth of the sample"),
+	common.UoMColumn("MdSample_UoM", "MdSample"),
+	common.StrColumn("DominateComponent", "Dominant component of the sample"),
+	common.FloatColumn("DensityHC", "Density of the hydrocarbons"),
+	common.UoMColumn("DensityHC_UoM", "DensityHC"),
+	common.FloatColumn("VolumeSample", "Volume of the sample"),
+	common.UoMColumn("VolumeSample_UoM", "VolumeSample"),
+	common.StrColumn("Description", "Description"),
+)
+
 func buildDDRFormTestInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "RunNumber",
-		"TestNumber", "MD", "MD_UoM", "TVD", "TVD_UoM", "PresPore", "PresPore_UoM",
-		"FluidDensity", "FluidDensity_UoM", "HydrostaticPresBefore", "HydrostaticPresBefore_UoM",
-		"LeakOffPressure", "LeakOffPressure_UoM", "GoodSeal", "MdSample", "MdSample_UoM",
-		"DominateComponent", "DensityHC", "DensityHC_UoM", "VolumeSample", "VolumeSample_UoM", "Description"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrFormTestInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -661,53 +913,84 @@ func buildDDRFormTestInfo(dReports []DrillReport, datasetName string) common.Dat
 			row.AddTimeValue(fInfo.DTim.Time)
 			row.AddStrValue(fInfo.RunNumber)
 			row.AddIntValue(fInfo.TestNumber)
-			row.AddFloatValue(fInfo.Md.Value)
-			row.AddStrValue(fInfo.Md.Uom)
-			row.AddFloatValue(fInfo.Tvd.Value)
-			row.AddStrValue(fInfo.Tvd.Uom)
-			row.AddFloatValue(fInfo.PresPore.Value)
-			row.AddStrValue(fInfo.PresPore.Uom)
-			row.AddFloatValue(fInfo.FluidDensity.Value)
-			row.AddStrValue(fInfo.FluidDensity.Uom)
-			row.AddFloatValue(fInfo.HydrostaticPresBefore.Value)
-			row.AddStrValue(fInfo.HydrostaticPresBefore.Uom)
-			row.AddFloatValue(fInfo.LeakOffPressure.Value)
-			row.AddStrValue(fInfo.LeakOffPressure.Uom)
+			row.AddMeasureValue(fInfo.Md)
+			row.AddMeasureValue(fInfo.Tvd)
+			row.AddMeasureValue(fInfo.PresPore)
+			row.AddMeasureValue(fInfo.FluidDensity)
+			row.AddMeasureValue(fInfo.HydrostaticPresBefore)
+			row.AddMeasureValue(fInfo.LeakOffPressure)
 			row.AddStrValue(strconv.FormatBool(fInfo.GoodSeal))
-			row.AddFloatValue(fInfo.MdSample.Value)
-			row.AddStrValue(fInfo.MdSample.Uom)
+			row.AddMeasureValue(fInfo.MdSample)
 			row.AddStrValue(fInfo.DominateComponent)
-			row.AddFloatValue(fInfo.DensityHC.Value)
-			row.AddStrValue(fInfo.DensityHC.Uom)
-			row.AddFloatValue(fInfo.VolumeSample.Value)
-			row.AddStrValue(fInfo.VolumeSample.Uom)
+			row.AddMeasureValue(fInfo.DensityHC)
+			row.AddMeasureValue(fInfo.VolumeSample)
 			row.AddStrValue(fInfo.Description)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrWellTestInfoSchema declares the columns of the well tests of the drilling reports
+var ddrWellTestInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the test"),
+	common.StrColumn("TestType", "Type of the test"),
+	common.IntColumn("TestNumber", "Number of the test"),
+	common.FloatColumn("MDTop", "Measured depth of the top of the tested interval"),
+	common.UoMColumn("MDTop_UoM", "MDTop"),
+	common.FloatColumn("MDBottom", "Measured depth of the bottom of the tested interval"),
+	common.UoMColumn("MDBottom_UoM", "MDBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the tested interval"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the tested interval"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.FloatColumn("ChokeSize", "Size of the choke"),
+	common.UoMColumn("ChokeSize_UomM", "ChokeSize"),
+	common.FloatColumn("DensityOil", "Density of the oil"),
+	common.UoMColumn("DensityOil_UoM", "DensityOil"),
+	common.FloatColumn("DensityWater", "Density of the water"),
+	common.UoMColumn("DensityWater_UoM", "DensityWater"),
+	common.FloatColumn("DensityGas", "Density of the gas"),
+	common.UoMColumn("DensityGas_UoM", "DensityGas"),
+	common.FloatColumn("FlowrateOil", "Flow rate of oil"),
+	common.UoMColumn("FlowrateOil_UoM", "FlowrateOil"),
+	common.FloatColumn("FlowrateWater", "Flow rate of water"),
+	common.UoMColumn("FlowrateWater_UoM", "FlowrateWater"),
+	common.FloatColumn("FlowrateGas", "Flow rate of gas"),
+	common.UoMColumn("FlowrateGas_UoM", "FlowrateGas"),
+	common.FloatColumn("PresShutin", "Shut in pressure"),
+	common.UoMColumn("PresShutin_UoM", "PresShutin"),
+	common.FloatColumn("PresFlowing", "Flowing pressure"),
+	common.UoMColumn("PresFlowing_UoM", "PresFlowing"),
+	common.FloatColumn("PresBottom", "Bottom hole pressure"),
+	common.UoMColumn("PresBottom_UoM", "PresBottom"),
+	common.FloatColumn("GasOilRatio", "Gas oil ratio"),
+	common.UoMColumn("GasOilRatio_UoM", "GasOilRatio"),
+	common.FloatColumn("WaterOilRatio", "Water oil ratio"),
+	common.UoMColumn("WaterOilRatio_UoM", "WaterOilRatio"),
+	common.FloatColumn("Chloride", "Chloride concentration"),
+	common.UoMColumn("Chloride_UoM", "Chloride"),
+	common.FloatColumn("CarbonDioxide", "Carbon dioxide concentration"),
+	common.UoMColumn("CarbonDioxide_UoM", "CarbonDioxide"),
+	common.FloatColumn("HydrogenSulfide", "Hydrogen sulfide concentration"),
+	common.UoMColumn("HydrogenSulfide_UoM", "HydrogenSulfide"),
+	common.FloatColumn("VolOilTotal", "Total volume of oil"),
+	common.UoMColumn("VolOilTotal_UoM", "VolOilTotal"),
+	common.FloatColumn("VolGasTotal", "Total volume of gas"),
+	common.UoMColumn("VolGasTotal_UoM", "VolGasTotal"),
+	common.FloatColumn("VolWaterTotal", "Total volume of water"),
+	common.UoMColumn("VolWaterTotal_UoM", "VolWaterTotal"),
+	common.FloatColumn("VolOilStored", "Volume of oil stored"),
+	common.UoMColumn("VolOilStored_UoM", "VolOilStored"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRWellTestInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "TestType", "TestNumber",
-		"MDTop", "MDTop_UoM", "MDBottom", "MDBottom_UoM", "TvdTop", "TvdTop_UoM",
-		"TvdBottom", "TvdBottom_UoM", "ChokeSize", "ChokeSize_UomM",
-		"DensityOil", "DensityOil_UoM", "DensityWater", "DensityWater_UoM",
-		"DensityGas", "DensityGas_UoM", "FlowrateOil", "FlowrateOil_UoM",
-		"FlowrateWater", "FlowrateWater_UoM", "FlowrateGas", "FlowrateGas_UoM", "PresShutin",
-		"PresShutin_UoM", "PresFlowing", "PresFlowing_UoM", "PresBottom", "PresBottom_UoM",
-		"GasOilRatio", "GasOilRatio_UoM", "WaterOilRatio", "WaterOilRatio_UoM",
-		"Chloride", "Chloride_UoM", "CarbonDioxide", "CarbonDioxide_UoM", "HydrogenSulfide",
-		"HydrogenSulfide_UoM", "VolOilTotal", "VolOilTotal_UoM", "VolGasTotal", "VolGasTotal_UoM",
-		"VolWaterTotal", "VolWaterTotal_UoM", "VolOilStored", "VolOilStored_UoM", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrWellTestInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -719,73 +1002,64 @@ func buildDDRWellTestInfo(dReports []DrillReport, datasetName string) common.Dat
 			row.AddTimeValue(wInfo.DTim.Time)
 			row.AddStrValue(wInfo.TestType)
 			row.AddIntValue(wInfo.TestNumber)
-			row.AddFloatValue(wInfo.MdTop.Value)
-			row.AddStrValue(wInfo.MdTop.Uom)
-			row.AddFloatValue(wInfo.MdBottom.Value)
-			row.AddStrValue(wInfo.MdBottom.Uom)
-			row.AddFloatValue(wInfo.TvdTop.Value)
-			row.AddStrValue(wInfo.TvdTop.Uom)
-			row.AddFloatValue(wInfo.TvdBottom.Value)
-			row.AddStrValue(wInfo.TvdBottom.Uom)
-			row.AddFloatValue(wInfo.ChokeSize.Value)
-			row.AddStrValue(wInfo.ChokeSize.Uom)
-			row.AddFloatValue(wInfo.DensityOil.Value)
-			row.AddStrValue(wInfo.DensityOil.Uom)
-			row.AddFloatValue(wInfo.DensityWater.Value)
-			row.AddStrValue(wInfo.DensityWater.Uom)
-			row.AddFloatValue(wInfo.DensityGas.Value)
-			row.AddStrValue(wInfo.DensityGas.Uom)
-			row.AddFloatValue(wInfo.FlowRateOil.Value)
-			row.AddStrValue(wInfo.FlowRateOil.Uom)
-			row.AddFloatValue(wInfo.FlowRateWater.Value)
-			row.AddStrValue(wInfo.FlowRateWater.Uom)
-			row.AddFloatValue(wInfo.FlowRateGas.Value)
-			row.AddStrValue(wInfo.FlowRateGas.Uom)
-			row.AddFloatValue(wInfo.PresShutIn.Value)
-			row.AddStrValue(wInfo.PresShutIn.Uom)
-			row.AddFloatValue(wInfo.PresFlowing.Value)
-			row.AddStrValue(wInfo.PresFlowing.Uom)
-			row.AddFloatValue(wInfo.PresBottom.Value)
-			row.AddStrValue(wInfo.PresBottom.Uom)
-			row.AddFloatValue(wInfo.GoR.Value)
-			row.AddStrValue(wInfo.GoR.Uom)
-			row.AddFloatValue(wInfo.WaterOilRatio.Value)
-			row.AddStrValue(wInfo.WaterOilRatio.Uom)
-			row.AddFloatValue(wInfo.Chloride.Value)
-			row.AddStrValue(wInfo.Chloride.Uom)
-			row.AddFloatValue(wInfo.CarbonDioxide.Value)
-			row.AddStrValue(wInfo.CarbonDioxide.Uom)
-			row.AddFloatValue(wInfo.HydrogenSulfide.Value)
-			row.AddStrValue(wInfo.HydrogenSulfide.Uom)
-			row.AddFloatValue(wInfo.VolOilTotal.Value)
-			row.AddStrValue(wInfo.VolOilTotal.Uom)
-			row.AddFloatValue(wInfo.VolGasTotal.Value)
-			row.AddStrValue(wInfo.VolGasTotal.Uom)
-			row.AddFloatValue(wInfo.VolWaterTotal.Value)
-			row.AddStrValue(wInfo.VolWaterTotal.Uom)
-			row.AddFloatValue(wInfo.VolOilStored.Value)
-			row.AddStrValue(wInfo.VolOilStored.Uom)
+			row.AddMeasureValue(wInfo.MdTop)
+			row.AddMeasureValue(wInfo.MdBottom)
+			row.AddMeasureValue(wInfo.TvdTop)
+			row.AddMeasureValue(wInfo.TvdBottom)
+			row.AddMeasureValue(wInfo.ChokeSize)
+			row.AddMeasureValue(wInfo.DensityOil)
+			row.AddMeasureValue(wInfo.DensityWater)
+			row.AddMeasureValue(wInfo.DensityGas)
+			row.AddMeasureValue(wInfo.FlowRateOil)
+			row.AddMeasureValue(wInfo.FlowRateWater)
+			row.AddMeasureValue(wInfo.FlowRateGas)
+			row.AddMeasureValue(wInfo.PresShutIn)
+			row.AddMeasureValue(wInfo.PresFlowing)
+			row.AddMeasureValue(wInfo.PresBottom)
+			row.AddMeasureValue(wInfo.GoR)
+			row.AddMeasureValue(wInfo.WaterOilRatio)
+			row.AddMeasureValue(wInfo.Chloride)
+			row.AddMeasureValue(wInfo.CarbonDioxide)
+			row.AddMeasureValue(wInfo.HydrogenSulfide)
+			row.AddMeasureValue(wInfo.VolOilTotal)
+			row.AddMeasureValue(wInfo.VolGasTotal)
+			row.AddMeasureValue(wInfo.VolWaterTotal)
+			row.AddMeasureValue(wInfo.VolOilStored)
 			row.AddStrValue(wInfo.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrCoreInfoSchema declares the columns of the cores of the drilling reports
+var ddrCoreInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the coring"),
+	common.StrColumn("CoreNumber", "Number of the core"),
+	common.FloatColumn("MDTop", "Measured depth of the top of the core"),
+	common.UoMColumn("MDTop_UoM", "MDTop"),
+	common.FloatColumn("MDBottom", "Measured depth of the bottom of the core"),
+	common.UoMColumn("MDBottom_UoM", "MDBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the core"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the core"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.FloatColumn("LenRecovered", "Length of core recovered"),
+	common.UoMColumn("LenRecovered_UoM", "LenRecovered"),
+	common.FloatColumn("RecoverPc", "Percentage of the core recovered"),
+	common.UoMColumn("RecoverPc_UoM", "RecoverPc"),
+	common.FloatColumn("LenBarrel", "Length of the core barrel"),
+	common.UoMColumn("LenBarrel_UoM", "LenBarrel"),
+	common.StrColumn("InnerBarrelType", "Type of the inner barrel"),
+	common.StrColumn("CoreDescription", "Description of the core"),
+)
+
 func buildDDRCoreInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim",
-		"CoreNumber", "MDTop", "MDTop_UoM", "MDBottom", "MDBottom_UoM",
-		"TvdTop", "TvdTop_UoM", "TvdBottom", "TvdBottom_UoM", "LenRecovered",
-		"LenRecovered_UoM", "RecoverPc", "RecoverPc_UoM",
-		"LenBarrel", "LenBarrel_UoM", "InnerBarrelType", "CoreDescription"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrCoreInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -796,42 +1070,55 @@ func buildDDRCoreInfo(dReports []DrillReport, datasetName string) common.DataSet
 			row.AddStrValue(recordId)
 			row.AddTimeValue(cInfo.DTim.Time)
 			row.AddStrValue(cInfo.CoreNumber)
-			row.AddFloatValue(cInfo.MDTop.Value)
-			row.AddStrValue(cInfo.MDTop.Uom)
-			row.AddFloatValue(cInfo.MDBottom.Value)
-			row.AddStrValue(cInfo.MDBottom.Uom)
-			row.AddFloatValue(cInfo.TvdTop.Value)
-			row.AddStrValue(cInfo.TvdTop.Uom)
-			row.AddFloatValue(cInfo.TvdBottom.Value)
-			row.AddStrValue(cInfo.TvdBottom.Uom)
-			row.AddFloatValue(cInfo.LenRecovered.Value)
-			row.AddStrValue(cInfo.LenRecovered.Uom)
-			row.AddFloatValue(cInfo.RecoverPC.Value)
-			row.AddStrValue(cInfo.RecoverPC.Uom)
-			row.AddFloatValue(cInfo.LenBarrel.Value)
-			row.AddStrValue(cInfo.LenBarrel.Uom)
+			row.AddMeasureValue(cInfo.MDTop)
+			row.AddMeasureValue(cInfo.MDBottom)
+			row.AddMeasureValue(cInfo.TvdTop)
+			row.AddMeasureValue(cInfo.TvdBottom)
+			row.AddMeasureValue(cInfo.LenRecovered)
+			row.AddMeasureValue(cInfo.RecoverPC)
+			row.AddMeasureValue(cInfo.LenBarrel)
 			row.AddStrValue(cInfo.InnerBarrelType)
 			row.AddStrValue(cInfo.CoreDescription)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrLogInfoSchema declares the columns of the logs of the drilling reports
+var ddrLogInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the log"),
+	common.StrColumn("RunNumber", "Number of the log run"),
+	common.StrColumn("ServiceCompany", "Logging company"),
+	common.StrColumn("Service", "Logging service"),
+	common.FloatColumn("MDTop", "Measured depth of the top of the log"),
+	common.UoMColumn("MDTop_UoM", "MDTop"),
+	common.FloatColumn("MDBottom", "Measured depth of the bottom of the log"),
+	common.UoMColumn("MDBottom_UoM", "MDBottom"),
+	common.FloatColumn("TvdTop", "True vertical depth of the top of the log"),
+	common.UoMColumn("TvdTop_UoM", "TvdTop"),
+	common.FloatColumn("TvdBottom", "True vertical depth of the bottom of the log"),
+	common.UoMColumn("TvdBottom_UoM", "TvdBottom"),
+	common.StrColumn("Tool", "Logging tool"),
+	common.FloatColumn("TempBHCT", "Bottom hole circulating temperature"),
+	common.UoMColumn("TempBHCT_UoM", "TempBHCT"),
+	common.FloatColumn("TempBHST", "Bottom hole static temperature"),
+	common.UoMColumn("TempBHST_UoM", "TempBHST"),
+	common.FloatColumn("ETimStatic", "Time static before the temperature reading"),
+	common.UoMColumn("ETimStatic_UoM", "ETimStatic"),
+	common.FloatColumn("MDTempTool", "Measured depth of the temperature tool"),
+	common.UoMColumn("MDTempTool_UoM", "MDTempTool"),
+	common.FloatColumn("TvdTempTool", "True vertical depth of the temperature tool"),
+	common.UoMColumn("TvdTempTool_UoM", "TvdTempTool"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRLoginfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim",
-		"RunNumber", "ServiceCompany", "Service", "MDTop", "MDTop_UoM", "MDBottom",
-		"MDBottom_UoM", "TvdTop", "TvdTop_UoM", "TvdBottom", "TvdBottom_UoM", "Tool",
-		"TempBHCT", "TempBHCT_UoM", "TempBHST", "TempBHST_UoM", "ETimStatic", "ETimStatic_UoM",
-		"MDTempTool", "MDTempTool_UoM", "TvdTempTool", "TvdTempTool_UoM", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrLogInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -844,44 +1131,47 @@ func buildDDRLoginfo(dReports []DrillReport, datasetName string) common.DataSet
 			row.AddStrValue(lInfo.RunNumber)
 			row.AddStrValue(lInfo.ServiceCompany)
 			row.AddStrValue(lInfo.Service)
-			row.AddFloatValue(lInfo.MdTop.Value)
-			row.AddStrValue(lInfo.MdTop.Uom)
-			row.AddFloatValue(lInfo.MdBottom.Value)
-			row.AddStrValue(lInfo.MdBottom.Uom)
-			row.AddFloatValue(lInfo.TvdTop.Value)
-			row.AddStrValue(lInfo.TvdTop.Uom)
-			row.AddFloatValue(lInfo.TvdBottom.Value)
-			row.AddStrValue(lInfo.TvdBottom.Uom)
+			row.AddMeasureValue(lInfo.MdTop)
+			row.AddMeasureValue(lInfo.MdBottom)
+			row.AddMeasureValue(lInfo.TvdTop)
+			row.AddMeasureValue(lInfo.TvdBottom)
 			row.AddStrValue(lInfo.Tool)
-			row.AddFloatValue(lInfo.TempBHCt.Value)
-			row.AddStrValue(lInfo.TempBHCt.Uom)
-			row.AddFloatValue(lInfo.TempBHST.Value)
-			row.AddStrValue(lInfo.TempBHST.Uom)
-			row.AddFloatValue(lInfo.ETimStatic.Value)
-			row.AddStrValue(lInfo.ETimStatic.Uom)
-			row.AddFloatValue(lInfo.MdTempTool.Value)
-			row.AddStrValue(lInfo.MdTempTool.Uom)
-			row.AddFloatValue(lInfo.TvdTempTool.Value)
-			row.AddStrValue(lInfo.TvdTempTool.Uom)
+			row.AddMeasureValue(lInfo.TempBHCt)
+			row.AddMeasureValue(lInfo.TempBHST)
+			row.AddMeasureValue(lInfo.ETimStatic)
+			row.AddMeasureValue(lInfo.MdTempTool)
+			row.AddMeasureValue(lInfo.TvdTempTool)
 			row.AddStrValue(lInfo.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrActivitiesSchema declares the columns of the activities of the drilling reports
+var ddrActivitiesSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTimStart", "Start of the activity"),
+	common.TimeColumn("DTimEnd", "End of the activity"),
+	common.FloatColumn("MD", "Measured depth at the end of the activity"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("Tvd", "True vertical depth at the end of the activity"),
+	common.UoMColumn("Tvd_UoM", "Tvd"),
+	common.StrColumn("Phase", "Phase of the activity"),
+	common.StrColumn("ProprietaryCode", "Proprietary code of the activity"),
+	common.StrColumn("Conveyance", "Conveyance of the activity"),
+	common.FloatColumn("MdHoleStart", "Measured depth of the hole at the start of the activity"),
+	common.UoMColumn("MdHoleStart_UoM", "MdHoleStart"),
+	common.StrColumn("State", "State of the activity"),
+	common.StrColumn("StateDetailActivity", "Detailed state of the activity"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRActivities(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTimStart", "DTimEnd",
-		"MD", "MD_UoM", "Tvd", "Tvd_UoM", "Phase", "ProprietaryCode",
-		"Conveyance", "MdHoleStart", "MdHoleStart_UoM", "State", "StateDetailActivity", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrActivitiesSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -892,36 +1182,41 @@ func buildDDRActivities(dReports []DrillReport, datasetName string) common.DataS
 			row.AddStrValue(recordId)
 			row.AddTimeValue(act.DTimStart.Time)
 			row.AddTimeValue(act.DTimeEnd.Time)
-			row.AddFloatValue(act.Md.Value)
-			row.AddStrValue(act.Md.Uom)
-			row.AddFloatValue(act.Tvd.Value)
-			row.AddStrValue(act.Tvd.Uom)
+			row.AddMeasureValue(act.Md)
+			row.AddMeasureValue(act.Tvd)
 			row.AddStrValue(act.Phase)
 			row.AddStrValue(act.ProprietaryCode)
 			row.AddStrValue(act.Conveyance)
-			row.AddFloatValue(act.MdHoleStart.Value)
-			row.AddStrValue(act.MdHoleStart.Uom)
+			row.AddMeasureValue(act.MdHoleStart)
 			row.AddStrValue(act.State)
 			row.AddStrValue(act.StateDetailActivity)
 			row.AddStrValue(act.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrSurveyStationsSchema declares the columns of the survey stations of the drilling reports
+var ddrSurveyStationsSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTim", "Time of the survey"),
+	common.FloatColumn("MD", "Measured depth of the station"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("Tvd", "True vertical depth of the station"),
+	common.UoMColumn("Tvd_UoM", "Tvd"),
+	common.FloatColumn("Incl", "Inclination"),
+	common.UoMColumn("Incl_UoM", "Incl"),
+	common.FloatColumn("Azi", "Azimuth"),
+	common.UoMColumn("Azi_UoM", "Azi"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRSurveyStation(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTim", "MD", "MD_UoM", "Tvd", "Tvd_UoM",
-		"Incl", "Incl_UoM", "Azi", "Azi_UoM",
-		"Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrSurveyStationsSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -931,32 +1226,36 @@ func buildDDRSurveyStation(dReports []DrillReport, datasetName string) common.Da
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddTimeValue(sStation.DTim.Time)
-			row.AddFloatValue(sStation.Md.Value)
-			row.AddStrValue(sStation.Md.Uom)
-			row.AddFloatValue(sStation.Tvd.Value)
-			row.AddStrValue(sStation.Tvd.Uom)
-			row.AddFloatValue(sStation.Incl.Value)
-			row.AddStrValue(sStation.Incl.Uom)
-			row.AddFloatValue(sStation.Azi.Value)
-			row.AddStrValue(sStation.Azi.Uom)
+			row.AddMeasureValue(sStation.Md)
+			row.AddMeasureValue(sStation.Tvd)
+			row.AddMeasureValue(sStation.Incl)
+			row.AddMeasureValue(sStation.Azi)
 			row.AddStrValue(sStation.Comment)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrPorePressuresSchema declares the columns of the pore pressures of the drilling reports
+var ddrPorePressuresSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.StrColumn("ReadingKind", "Kind of the reading, e.g. estimated or measured"),
+	common.FloatColumn("EquivalentMudWeight", "Pore pressure as equivalent mud weight"),
+	common.UoMColumn("EquivalentMudWeight_UoM", "EquivalentMudWeight"),
+	common.TimeColumn("DTim", "Time of the reading"),
+	common.FloatColumn("MD", "Measured depth of the reading"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("Tvd", "True vertical depth of the reading"),
+	common.UoMColumn("Tvd_UoM", "Tvd"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRPorePressure(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "ReadingKind", "EquivalentMudWeight",
-		"EquivalentMudWeight_UoM", "DTim", "MD", "MD_UoM", "Tvd", "Tvd_UoM", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrPorePressuresSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -967,36 +1266,42 @@ func buildDDRPorePressure(dReports []DrillReport, datasetName string) common.Dat
 			row.AddStrValue(recordId)
 			porePressure := dReports[i].PorePressures[s]
 			row.AddStrValue(porePressure.ReadingKind)
-			row.AddFloatValue(porePressure.EquivalentMudWeight.Value)
-			row.AddStrValue(porePressure.EquivalentMudWeight.Uom)
+			row.AddMeasureValue(porePressure.EquivalentMudWeight)
 			row.AddTimeValue(porePressure.DTim.Time)
-			row.AddFloatValue(porePressure.Md.Value)
-			row.AddStrValue(porePressure.Md.Uom)
-			row.AddFloatValue(porePressure.Tvd.Value)
-			row.AddStrValue(porePressure.Tvd.Uom)
+			row.AddMeasureValue(porePressure.Md)
+			row.AddMeasureValue(porePressure.Tvd)
 			row.AddStrValue(porePressure.Comment)
 
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrDrillReportInfoSchema declares the columns of the drilling report information, one row per wellbore alias
+var ddrDrillReportInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DrillReportUid", "Unique identifier of the drill report"),
+	common.StrColumn("DrillReportUidWell", "Unique identifier of the well"),
+	common.StrColumn("DrillReportUidWellbore", "Unique identifier of the wellbore"),
+	common.StrColumn("NameWell", "Name of the well"),
+	common.StrColumn("NameWellNamingSystem", "Naming system of the well name"),
+	common.StrColumn("NameWellbore", "Name of the wellbore alias"),
+	common.StrColumn("NameWellboreNamingSystem", "Naming system of the wellbore alias"),
+	common.StrColumn("ReportName", "Name of the drill report"),
+	common.TimeColumn("ReportDTimStart", "Start of the reporting period"),
+	common.TimeColumn("ReportDTimEnd", "End of the reporting period"),
+	common.StrColumn("VersionKind", "Version of the report, e.g. normal or revised"),
+	common.TimeColumn("CreatedDate", "Time the report was created"),
+	common.TimeColumn("ExtendedReportTime", "Time of the extended report"),
+	common.StrColumn("ExtendedReport", "Summary of operations after the report period"),
+	common.StrColumn("Comment", "Comment to the report"),
+}}
+
 func buildDDRDrillReportInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	headers = []string{"DataUUID", "DrillReportUid",
-		"DrillReportUidWell", "DrillReportUidWellbore",
-		"NameWell", "NameWellNamingSystem",
-		"NameWellbore", "NameWellboreNamingSystem", "ReportName", "ReportDTimStart",
-		"ReportDTimEnd", "VersionKind",
-		"CreatedDate",
-		"ExtendedReportTime", "ExtendedReport", "Comment"}
-	dSet.HeadersName = headers
-	dSet.Name = datasetName
+	dSet := common.NewDataSet(datasetName, ddrDrillReportInfoSchema)
 	for i := 0; i < len(dReports); i++ {
 		//need to add one row entry per wellbore alias
 		for s := 0; s < len(dReports[i].WellboreAliases); s++ {
@@ -1025,15 +1330,24 @@ func buildDDRDrillReportInfo(dReports []DrillReport, datasetName string) common.
 	return dSet
 }
 
+//ddrWellboreInfoSchema declares the columns of the wellbore information of the drilling reports, one row per rig alias
+var ddrWellboreInfoSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTimSpud", "Time of the spud of the wellbore"),
+	common.TimeColumn("DTimPreSpud", "Time of the pre spud of the wellbore"),
+	common.TimeColumn("DateDrillComplete", "Date the drilling was completed"),
+	common.FloatColumn("DaysAhead", "Days ahead of the plan"),
+	common.StrColumn("Operator", "Operator of the wellbore"),
+	common.StrColumn("DrillContractor", "Drilling contractor"),
+	common.StrColumn("RigAlias", "Name of the rig"),
+	common.StrColumn("RigAliasNamingSystem", "Naming system of the rig name"),
+	common.FloatColumn("DaysBehind", "Days behind the plan"),
+)
+
 func buildDDRWellboreInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTimSpud", "DTimPreSpud", "DateDrillComplete",
-		"DaysAhead", "Operator", "DrillContractor", "RigAlias", "RigAliasNamingSystem", "DaysBehind"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrWellboreInfoSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -1044,34 +1358,61 @@ func buildDDRWellboreInfo(dReports []DrillReport, datasetName string) common.Dat
 			row.AddTimeValue(dReports[i].WellboreInfo.DTimSpud.Time)
 			row.AddTimeValue(dReports[i].WellboreInfo.DTimPreSpud.Time)
 			row.AddTimeValue(dReports[i].WellboreInfo.DateDrillComplete.Time)
-			row.AddFloatValue(dReports[i].WellboreInfo.DaysAhead.float64)
+			addDoubleValue(&row, dReports[i].WellboreInfo.DaysAhead)
 			row.AddStrValue(dReports[i].WellboreInfo.Operator)
 			row.AddStrValue(dReports[i].WellboreInfo.DrillContractor)
 			row.AddStrValue(dReports[i].WellboreInfo.RigAliases[x].Name)
 			row.AddStrValue(dReports[i].WellboreInfo.RigAliases[x].NamingSystem)
-			row.AddFloatValue(dReports[i].WellboreInfo.DaysBehind.float64)
+			addDoubleValue(&row, dReports[i].WellboreInfo.DaysBehind)
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrCementStagesSchema declares the columns of the cement stages of the drilling reports, one row per cementing fluid
+var ddrCementStagesSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.TimeColumn("DTimPumpStart", "Start of the pumping"),
+	common.TimeColumn("DTimPumpEnd", "End of the pumping"),
+	common.StrColumn("JobType", "Type of the cement job"),
+	common.FloatColumn("CasingStrDia", "Diameter of the casing string"),
+	common.UoMColumn("CasingStrDia_UoM", "CasingStrDia"),
+	common.StrColumn("Comments", "Comments to the stage"),
+	common.FloatColumn("VolReturns", "Volume of returns"),
+	common.UoMColumn("VolReturns_UoM", "VolReturns"),
+	common.StrColumn("TypeFluid", "Type of the cementing fluid"),
+	common.StrColumn("DescFluid", "Description of the cementing fluid"),
+	common.FloatColumn("RatioMixWater", "Mix water ratio"),
+	common.UoMColumn("RatioMixWater_UoM", "RatioMixWater"),
+	common.FloatColumn("Density", "Density of the fluid"),
+	common.UoMColumn("Density_UoM", "Density"),
+	common.FloatColumn("VolPumped", "Volume pumped"),
+	common.UoMColumn("VolPumped_UoM", "VolPumped"),
+	common.FloatColumn("YP", "Yield point"),
+	common.UoMColumn("YP_UoM", "YP"),
+	common.FloatColumn("ETimThickening", "Thickening time"),
+	common.UoMColumn("ETimThickening_UoM", "ETimThickening"),
+	common.FloatColumn("PCFreeWater", "Free water percentage"),
+	common.UoMColumn("PCFreeWater_UoM", "PCFreeWater"),
+	common.StrColumn("FluidComments", "Comments to the cementing fluid"),
+	common.TimeColumn("DTimPresReleased", "Time the pressure was released"),
+	common.StrColumn("AnnFlowAfter", "True for annular flow after the job"),
+	common.StrColumn("TopPlug", "True if a top plug was used"),
+	common.StrColumn("BotPlug", "True if a bottom plug was used"),
+	common.StrColumn("PlugBumped", "True if the plug was bumped"),
+	common.FloatColumn("PresBump", "Bump pressure"),
+	common.UoMColumn("PresBump_UoM", "PresBump"),
+	common.StrColumn("FloatHeld", "True if the float held"),
+	common.StrColumn("Reciprocated", "True if the pipe was reciprocated"),
+	common.StrColumn("Rotated", "True if the pipe was rotated"),
+)
+
 func buildDDRCementStages(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "DTimPumpStart", "DTimPumpEnd",
-		"JobType", "CasingStrDia", "CasingStrDia_UoM", "Comments", "VolReturns", "VolReturns_UoM",
-		"TypeFluid", "DescFluid", "RatioMixWater", "RatioMixWater_UoM", "Density", "Density_UoM",
-		"VolPumped", "VolPumped_UoM", "YP", "YP_UoM", "ETimThickening", "ETimThickening_UoM",
-		"PCFreeWater", "PCFreeWater_UoM", "FluidComments", "DTimPresReleased",
-		"AnnFlowAfter", "TopPlug", "BotPlug", "PlugBumped", "PresBump", "PresBump_UoM", "FloatHeld",
-		"Reciprocated", "Rotated"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrCementStagesSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -1087,33 +1428,24 @@ func buildDDRCementStages(dReports []DrillReport, datasetName string) common.Dat
 				row.AddTimeValue(cementStage.DTimPumpStart.Time)
 				row.AddTimeValue(cementStage.DTimPumpEnd.Time)
 				row.AddStrValue(cementStage.JobType)
-				row.AddFloatValue(cementStage.CasingStrDia.Value)
-				row.AddStrValue(cementStage.CasingStrDia.Uom)
+				row.AddMeasureValue(cementStage.CasingStrDia)
 				row.AddStrValue(cementStage.Comments)
-				row.AddFloatValue(cementStage.VolReturns.Value)
-				row.AddStrValue(cementStage.VolReturns.Uom)
+				row.AddMeasureValue(cementStage.VolReturns)
 				row.AddStrValue(cementingFluid.TypeFluid)
 				row.AddStrValue(cementingFluid.DescFluid)
-				row.AddFloatValue(cementingFluid.RatioMixWater.Value)
-				row.AddStrValue(cementingFluid.RatioMixWater.Uom)
-				row.AddFloatValue(cementingFluid.Density.Value)
-				row.AddStrValue(cementingFluid.Density.Uom)
-				row.AddFloatValue(cementingFluid.VolPumped.Value)
-				row.AddStrValue(cementingFluid.VolPumped.Uom)
-				row.AddFloatValue(cementingFluid.Yp.Value)
-				row.AddStrValue(cementingFluid.Yp.Uom)
-				row.AddFloatValue(cementingFluid.ETimThickening.Value)
-				row.AddStrValue(cementingFluid.ETimThickening.Uom)
-				row.AddFloatValue(cementingFluid.PCFreeWater.Value)
-				row.AddStrValue(cementingFluid.PCFreeWater.Uom)
+				row.AddMeasureValue(cementingFluid.RatioMixWater)
+				row.AddMeasureValue(cementingFluid.Density)
+				row.AddMeasureValue(cementingFluid.VolPumped)
+				row.AddMeasureValue(cementingFluid.Yp)
+				row.AddMeasureValue(cementingFluid.ETimThickening)
+				row.AddMeasureValue(cementingFluid.PCFreeWater)
 				row.AddStrValue(cementingFluid.Comments)
 				row.AddTimeValue(cementStage.DTimPresReleased.Time)
 				row.AddStrValue(strconv.FormatBool(cementStage.AnnFlowAfter))
 				row.AddStrValue(strconv.FormatBool(cementStage.TopPlug))
 				row.AddStrValue(strconv.FormatBool(cementStage.BotPlug))
 				row.AddStrValue(strconv.FormatBool(cementStage.PlugBumped))
-				row.AddFloatValue(cementStage.PresBump.Value)
-				row.AddStrValue(cementStage.PresBump.Uom)
+				row.AddMeasureValue(cementStage.PresBump)
 				row.AddStrValue(strconv.FormatBool(cementStage.FloatHeld))
 				row.AddStrValue(strconv.FormatBool(cementStage.Reciprocated))
 				row.AddStrValue(strconv.FormatBool(cementStage.Rotated))
@@ -1121,23 +1453,39 @@ func buildDDRCementStages(dReports []DrillReport, datasetName string) common.Dat
 			}
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrCasingLinerTubingSchema declares the columns of the casing, liner and tubing of the drilling reports
+var ddrCasingLinerTubingSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.StrColumn("Type", "Type of the casing, liner or tubing"),
+	common.FloatColumn("ID", "Inner diameter"),
+	common.UoMColumn("ID_UoM", "ID"),
+	common.FloatColumn("OD", "Outer diameter"),
+	common.UoMColumn("OD_UoM", "OD"),
+	common.FloatColumn("Weight", "Weight per length"),
+	common.UoMColumn("Weight_UoM", "Weight"),
+	common.StrColumn("Grade", "Grade of the material"),
+	common.StrColumn("Connection", "Type of the connection"),
+	common.FloatColumn("Length", "Length"),
+	common.UoMColumn("Length_UoM", "Length"),
+	common.FloatColumn("MdTop", "Measured depth of the top"),
+	common.UoMColumn("MdTop_UoM", "MdTop"),
+	common.FloatColumn("MdBottom", "Measured depth of the bottom"),
+	common.UoMColumn("MdBottom_UoM", "MdBottom"),
+	common.StrColumn("CasingType", "Type of the casing of the run"),
+	common.StrColumn("Description", "Description of the run"),
+	common.TimeColumn("DTimStart", "Start of the run"),
+	common.TimeColumn("DTimEnd", "End of the run"),
+	common.StrColumn("Comment", "Comment"),
+)
+
 func buildDDRCasingLinerTubings(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "Type", "ID", "ID_UoM",
-		"OD", "OD_UoM", "Weight", "Weight_UoM", "Grade",
-		"Connection", "Length", "Length_UoM", "MdTop", "MdTop_UoM",
-		"MdBottom", "MdBottom_UoM", "CasingType", "Description",
-		"DTimStart", "DTimEnd", "Comment"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrCasingLinerTubingSchema)
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
 		idSet = buildDDRRowIdentification(dReports[i], datasetName)
@@ -1147,20 +1495,14 @@ func buildDDRCasingLinerTubings(dReports []DrillReport, datasetName string) comm
 			row := idSet.Rows[0]
 			row.AddStrValue(recordId)
 			row.AddStrValue(casingL.Type)
-			row.AddFloatValue(casingL.Id.Value)
-			row.AddStrValue(casingL.Id.Uom)
-			row.AddFloatValue(casingL.Od.Value)
-			row.AddStrValue(casingL.Od.Uom)
-			row.AddFloatValue(casingL.Weight.Value)
-			row.AddStrValue(casingL.Weight.Uom)
+			row.AddMeasureValue(casingL.Id)
+			row.AddMeasureValue(casingL.Od)
+			row.AddMeasureValue(casingL.Weight)
 			row.AddStrValue(casingL.Grade)
 			row.AddStrValue(casingL.Connection)
-			row.AddFloatValue(casingL.Length.Value)
-			row.AddStrValue(casingL.Length.Uom)
-			row.AddFloatValue(casingL.MdTop.Value)
-			row.AddStrValue(casingL.MdTop.Uom)
-			row.AddFloatValue(casingL.MdBottom.Value)
-			row.AddStrValue(casingL.MdBottom.Uom)
+			row.AddMeasureValue(casingL.Length)
+			row.AddMeasureValue(casingL.MdTop)
+			row.AddMeasureValue(casingL.MdBottom)
 			row.AddStrValue(casingL.CasingLinerTubingRun.CasingType)
 			row.AddStrValue(casingL.CasingLinerTubingRun.Description)
 			row.AddTimeValue(casingL.CasingLinerTubingRun.DTimStart.Time)
@@ -1169,28 +1511,58 @@ func buildDDRCasingLinerTubings(dReports []DrillReport, datasetName string) comm
 			rows = append(rows, row)
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrBitRecordsSchema declares the columns of the bit records of the drilling reports, one row per nozzle
+var ddrBitRecordsSchema = ddrRowIdentificationSchema.Append(
+	common.StrColumn("RecordId", "Identifier of the rows of one report"),
+	common.StrColumn("NumBitRun", "Number of the bit run"),
+	common.StrColumn("Numbit", "Number of the bit"),
+	common.FloatColumn("DiaBit", "Diameter of the bit"),
+	common.UoMColumn("DiaBit_UoM", "DiaBit"),
+	common.StrColumn("Manufacturer", "Manufacturer of the bit"),
+	common.StrColumn("CodeMfg", "Manufacturer code of the bit"),
+	common.StrColumn("DullGrade", "Dull grade of the bit"),
+	common.StrColumn("CodelADC", "IADC code of the bit"),
+	common.IntColumn("CondFinalInner", "Final condition of the inner cutters"),
+	common.IntColumn("CondFinalOuter", "Final condition of the outer cutters"),
+	common.StrColumn("CondFinalDull", "Final dull condition"),
+	common.StrColumn("CondFinalLocation", "Location of the final dull condition"),
+	common.StrColumn("CondFinalBearing", "Final condition of the bearings"),
+	common.StrColumn("CondFinalGauge", "Final condition of the gauge"),
+	common.StrColumn("CondFinalOther", "Other final conditions"),
+	common.StrColumn("CondFinalReason", "Reason for pulling the bit"),
+	common.FloatColumn("BitRun_ETimOpBit", "Operating time of the bit in the run"),
+	common.UoMColumn("BitRun_ETimOpBit_UoM", "BitRun_ETimOpBit"),
+	common.FloatColumn("MdHoleStart", "Measured depth at the start of the run"),
+	common.UoMColumn("MdHoleStart_UoM", "MdHoleStart"),
+	common.FloatColumn("MdHoleStop", "Measured depth at the end of the run"),
+	common.UoMColumn("MdHoleStop_UoM", "MdHoleStop"),
+	common.FloatColumn("RopAv", "Average rate of penetration of the run"),
+	common.UoMColumn("RopAV_UoM", "RopAv"),
+	common.FloatColumn("MdHoleMadeRun", "Hole made in the run"),
+	common.UoMColumn("MdHoleMadeRun_UoM", "MdHoleMadeRun"),
+	common.FloatColumn("HrsDrilled", "Hours drilled in the period"),
+	common.UoMColumn("HrsDrilled_UoM", "HrsDrilled"),
+	common.FloatColumn("HrsDrilledRun", "Hours drilled in the run"),
+	common.UoMColumn("HrsDrilledRun_UoM", "HrsDrilledRun"),
+	common.FloatColumn("MdTotHoleMade", "Total hole made by the bit"),
+	common.UoMColumn("MdTotHoleMade_UoM", "MdTotHoleMade"),
+	common.FloatColumn("TotHrsDrilled", "Total hours drilled by the bit"),
+	common.UoMColumn("TotHrsDrilled_UoM", "TotHrsDrilled"),
+	common.FloatColumn("TotRop", "Total rate of penetration of the bit"),
+	common.UoMColumn("TotRop_UoM", "TotRop"),
+	common.IntColumn("NumNozzle", "Number of the nozzle"),
+	common.FloatColumn("DiaNozzle", "Diameter of the nozzle"),
+	common.UoMColumn("DiaNozzle_UoM", "DiaNozzle"),
+)
+
 func buildDDRBitRecords(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"RecordId", "NumBitRun", "Numbit",
-		"DiaBit", "DiaBit_UoM", "Manufacturer", "CodeMfg", "DullGrade",
-		"CodelADC", "CondFinalInner", "CondFinalOuter", "CondFinalDull",
-		"CondFinalLocation", "CondFinalBearing", "CondFinalGauge", "CondFinalOther",
-		"CondFinalReason", "BitRun_ETimOpBit", "BitRun_ETimOpBit_UoM",
-		"MdHoleStart", "MdHoleStart_UoM", "MdHoleStop", "MdHoleStop_UoM",
-		"RopAv", "RopAV_UoM", "MdHoleMadeRun", "MdHoleMadeRun_UoM", "HrsDrilled",
-		"HrsDrilled_UoM", "HrsDrilledRun", "HrsDrilledRun_UoM", "MdTotHoleMade", "MdTotHoleMade_UoM",
-		"TotHrsDrilled", "TotHrsDrilled_UoM", "TotRop", "TotRop_UoM",
-		"NumNozzle", "DiaNozzle", "DiaNozzle_UoM"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrBitRecordsSchema)
 
 	for i := 0; i < len(dReports); i++ {
 		recordId := common.CreateUUID()
@@ -1204,8 +1576,7 @@ func buildDDRBitRecords(dReports []DrillReport, datasetName string) common.DataS
 				row.AddStrValue(recordId)
 				row.AddStrValue(bitRecord.NumBitRun)
 				row.AddStrValue(bitRecord.NumBit)
-				row.AddFloatValue(bitRecord.DiaBit.Value)
-				row.AddStrValue(bitRecord.DiaBit.Uom)
+				row.AddMeasureValue(bitRecord.DiaBit)
 				row.AddStrValue(bitRecord.Manufacturer)
 				row.AddStrValue(bitRecord.CodeMfg)
 				row.AddStrValue(bitRecord.DullGrade)
@@ -1218,158 +1589,157 @@ func buildDDRBitRecords(dReports []DrillReport, datasetName string) common.DataS
 				row.AddStrValue(bitRecord.CondFinalGauge)
 				row.AddStrValue(bitRecord.CondFinalOther)
 				row.AddStrValue(bitRecord.CondFinalReason)
-				row.AddFloatValue(bitRecord.BitRun.ETimOpBit.Value)
-				row.AddStrValue(bitRecord.BitRun.ETimOpBit.Uom)
-				row.AddFloatValue(bitRecord.BitRun.MDHoleStart.Value)
-				row.AddStrValue(bitRecord.BitRun.MDHoleStart.Uom)
-				row.AddFloatValue(bitRecord.BitRun.MDHoleStop.Value)
-				row.AddStrValue(bitRecord.BitRun.MDHoleStop.Uom)
-				row.AddFloatValue(bitRecord.BitRun.RopAv.Value)
-				row.AddStrValue(bitRecord.BitRun.RopAv.Uom)
-				row.AddFloatValue(bitRecord.BitRun.MDHoleMadeRun.Value)
-				row.AddStrValue(bitRecord.BitRun.MDHoleMadeRun.Uom)
-				row.AddFloatValue(bitRecord.BitRun.HrsDrilled.Value)
-				row.AddStrValue(bitRecord.BitRun.HrsDrilled.Uom)
-				row.AddFloatValue(bitRecord.BitRun.HrsDrilledRun.Value)
-				row.AddStrValue(bitRecord.BitRun.HrsDrilledRun.Uom)
-				row.AddFloatValue(bitRecord.BitRun.MdTotalHoleMade.Value)
-				row.AddStrValue(bitRecord.BitRun.MdTotalHoleMade.Uom)
-				row.AddFloatValue(bitRecord.BitRun.TotHrsDrilled.Value)
-				row.AddStrValue(bitRecord.BitRun.TotHrsDrilled.Uom)
-				row.AddFloatValue(bitRecord.BitRun.TotRop.Value)
-				row.AddStrValue(bitRecord.BitRun.TotRop.Uom)
+				row.AddMeasureValue(bitRecord.BitRun.ETimOpBit)
+				row.AddMeasureValue(bitRecord.BitRun.MDHoleStart)
+				row.AddMeasureValue(bitRecord.BitRun.MDHoleStop)
+				row.AddMeasureValue(bitRecord.BitRun.RopAv)
+				row.AddMeasureValue(bitRecord.BitRun.MDHoleMadeRun)
+				row.AddMeasureValue(bitRecord.BitRun.HrsDrilled)
+				row.AddMeasureValue(bitRecord.BitRun.HrsDrilledRun)
+				row.AddMeasureValue(bitRecord.BitRun.MdTotalHoleMade)
+				row.AddMeasureValue(bitRecord.BitRun.TotHrsDrilled)
+				row.AddMeasureValue(bitRecord.BitRun.TotRop)
 				row.AddIntValue(bitRecord.Nozzles[s].NumNozzle)
-				row.AddFloatValue(bitRecord.Nozzles[s].DiaNozzle.Value)
-				row.AddStrValue(bitRecord.Nozzles[s].DiaNozzle.Uom)
+				row.AddMeasureValue(bitRecord.Nozzles[s].DiaNozzle)
 				rows = append(rows, row)
 			}
 		}
 	}
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrStatusInfoSchema declares the columns of the status of the drilling at the end of the report period
+var ddrStatusInfoSchema = ddrRowIdentificationSchema.Append(
+	common.IntColumn("ReportNo", "Number of the report"),
+	common.TimeColumn("DTim", "Time of the status"),
+	common.FloatColumn("MD", "Measured depth at the end of the period"),
+	common.UoMColumn("MD_UoM", "MD"),
+	common.FloatColumn("TVD", "True vertical depth at the end of the period"),
+	common.UoMColumn("TVD_UoM", "TVD"),
+	common.FloatColumn("MDPlugTop", "Measured depth of the top of the plug"),
+	common.UoMColumn("MDPlugTop_UoM", "MDPlugTop"),
+	common.FloatColumn("DiaHole", "Diameter of the hole"),
+	common.UoMColumn("DiaHole_UoM", "DiaHole"),
+	common.TimeColumn("DTimDiaHoleStart", "Time the hole diameter was started"),
+	common.FloatColumn("MDDiaHoleStart", "Measured depth the hole diameter was started"),
+	common.UoMColumn("MDDiaHoleStart_UoM", "MDDiaHoleStart"),
+	common.FloatColumn("DiaPilot", "Diameter of the pilot hole"),
+	common.UoMColumn("DiaPilot_UoM", "DiaPilot"),
+	common.FloatColumn("MDDiaPilotPlan", "Planned measured depth of the pilot hole"),
+	common.UoMColumn("MDDiaPilotPlan_UoM", "MDDiaPilotPlan"),
+	common.FloatColumn("TVDDiaPilotPlan", "Planned true vertical depth of the pilot hole"),
+	common.UoMColumn("TVDDiaPilotPlan_Uom", "TVDDiaPilotPlan"),
+	common.StrColumn("TypeWellbore", "Type of the wellbore"),
+	common.StrColumn("PrimaryConveyance", "Primary conveyance of the operations"),
+	common.FloatColumn("MDKickOff", "Measured depth of the kick off"),
+	common.UoMColumn("MDKickOff_UoM", "MDKickOff"),
+	common.FloatColumn("TVDKickOff", "True vertical depth of the kick off"),
+	common.UoMColumn("TVDKickOff_UoM", "TVDKickOff"),
+	common.FloatColumn("StrengthForm", "Formation strength"),
+	common.UoMColumn("StrengthForm_UoM", "StrengthForm"),
+	common.FloatColumn("MDStrengthForm", "Measured depth of the formation strength"),
+	common.UoMColumn("MDStrengthForm_UoM", "MDStrengthForm"),
+	common.FloatColumn("TVDStrengthForm", "True vertical depth of the formation strength"),
+	common.UoMColumn("TVDStrengthForm_UoM", "TVDStrengthForm"),
+	common.FloatColumn("DiaCsgLast", "Diameter of the last casing"),
+	common.UoMColumn("DiaCsgLast_UoM", "DiaCsgLast"),
+	common.FloatColumn("MDCsgLast", "Measured depth of the last casing"),
+	common.UoMColumn("MDCsgLast_UoM", "MDCsgLast"),
+	common.FloatColumn("TVDCsgLast", "True vertical depth of the last casing"),
+	common.UoMColumn("TVDCsgLast_UoM", "TVDCsgLast"),
+	common.StrColumn("PresTestType", "Type of the pressure test"),
+	common.FloatColumn("MDPlanned", "Planned measured depth"),
+	common.UoMColumn("MDPlanned_UoM", "MDPlanned"),
+	common.FloatColumn("DistDrill", "Distance drilled in the period"),
+	common.UoMColumn("DistDrill_UoM", "DistDrill"),
+	common.FloatColumn("ElevKelly", "Elevation of the kelly bushing"),
+	common.UoMColumn("ElevKelly_UoM", "ElevKelly"),
+	common.FloatColumn("WellheadElevation", "Elevation of the wellhead"),
+	common.UoMColumn("WellheadElevation_UoM", "WellheadElevation"),
+	common.FloatColumn("WaterDepth", "Water depth"),
+	common.UoMColumn("WaterDepth_UoM", "WaterDepth"),
+	common.StrColumn("Sum24Hr", "Summary of the last 24 hours"),
+	common.StrColumn("Forecast24Hr", "Forecast of the next 24 hours"),
+	common.FloatColumn("RopCurrent", "Current rate of penetration"),
+	common.UoMColumn("RopCurrent_UoM", "RopCurrent"),
+	common.StrColumn("TightWell", "True for a tight well"),
+	common.StrColumn("HPHT", "True for a high pressure high temperature well"),
+	common.FloatColumn("AvgPresBH", "Average bottom hole pressure"),
+	common.UoMColumn("AvgPresBH_UoM", "AvgPresBH"),
+	common.FloatColumn("AvgTempBH", "Average bottom hole temperature"),
+	common.UoMColumn("AvgTempBH_UoM", "AvgTempBH"),
+	common.StrColumn("FixedRig", "True for a fixed rig"),
+)
+
 func buildDDRStatusInfo(dReports []DrillReport, datasetName string) common.DataSet {
-	var dSet common.DataSet
-	var headers []string
 	var rows []common.RowData
-	dSet.Name = datasetName
-	idSet := buildDDRRowIdentification(dReports[0], datasetName)
-	headers = []string{"ReportNo", "DTim", "MD", "MD_UoM",
-		"TVD", "TVD_UoM",
-		"MDPlugTop", "MDPlugTop_UoM",
-		"DiaHole", "DiaHole_UoM",
-		"DTimDiaHoleStart",
-		"MDDiaHoleStart", "MDDiaHoleStart_UoM",
-		"DiaPilot", "DiaPilot_UoM",
-		"MDDiaPilotPlan", "MDDiaPilotPlan_UoM",
-		"TVDDiaPilotPlan", "TVDDiaPilotPlan_Uom",
-		"TypeWellbore", "PrimaryConveyance",
-		"MDKickOff", "MDKickOff_UoM",
-		"TVDKickOff", "TVDKickOff_UoM",
-		"StrengthForm", "StrengthForm_UoM",
-		"MDStrengthForm", "MDStrengthForm_UoM",
-		"TVDStrengthForm", "TVDStrengthForm_UoM",
-		"DiaCsgLast", "DiaCsgLast_UoM",
-		"MDCsgLast", "MDCsgLast_UoM",
-		"TVDCsgLast", "TVDCsgLast_UoM",
-		"PresTestType",
-		"MDPlanned", "MDPlanned_UoM",
-		"DistDrill", "DistDrill_UoM",
-		"ElevKelly", "ElevKelly_UoM",
-		"WellheadElevation", "WellheadElevation_UoM",
-		"WaterDepth", "WaterDepth_UoM",
-		"Sum24Hr",
-		"Forecast24Hr",
-		"RopCurrent", "RopCurrent_UoM",
-		"TightWell", "HPHT",
-		"AvgPresBH", "AvgPresBH_UoM",
-		"AvgTempBH", "AvgTempBH_UoM",
-		"FixedRig"}
-	headers = append(idSet.HeadersName, headers...)
+	var idSet common.DataSet
+	dSet := common.NewDataSet(datasetName, ddrStatusInfoSchema)
 	for i := 0; i < len(dReports); i++ {
 		idSet = buildDDRRowIdentification(dReports[i], datasetName)
 		row := idSet.Rows[0]
 		sInfo := dReports[i].StatusInfo
 		row.AddIntValue(sInfo.Reportnumber)
 		row.AddTimeValue(sInfo.DTim.Time)
-		row.AddFloatValue(sInfo.Md.Value)
-		row.AddStrValue(sInfo.Md.Uom)
-		row.AddFloatValue(sInfo.Tvd.Value)
-		row.AddStrValue(sInfo.Tvd.Uom)
-		row.AddFloatValue(sInfo.MdPlugTop.Value)
-		row.AddStrValue(sInfo.MdPlugTop.Uom)
-		row.AddFloatValue(sInfo.DiaHole.Value)
-		row.AddStrValue(sInfo.DiaHole.Uom)
+		row.AddMeasureValue(sInfo.Md)
+		row.AddMeasureValue(sInfo.Tvd)
+		row.AddMeasureValue(sInfo.MdPlugTop)
+		row.AddMeasureValue(sInfo.DiaHole)
 		row.AddTimeValue(sInfo.DTimDiaHoleStart.Time)
-		row.AddFloatValue(sInfo.MdDiaHoleStart.Value)
-		row.AddStrValue(sInfo.MdDiaHoleStart.Uom)
-		row.AddFloatValue(sInfo.DiaPilot.Value)
-		row.AddStrValue(sInfo.DiaPilot.Uom)
-		row.AddFloatValue(sInfo.MdDiaPilotPlan.Value)
-		row.AddStrValue(sInfo.MdDiaPilotPlan.Uom)
-		row.AddFloatValue(sInfo.TVDDiaPilotPlan.Value)
-		row.AddStrValue(sInfo.TVDDiaPilotPlan.Uom)
+		row.AddMeasureValue(sInfo.MdDiaHoleStart)
+		row.AddMeasureValue(sInfo.DiaPilot)
+		row.AddMeasureValue(sInfo.MdDiaPilotPlan)
+		row.AddMeasureValue(sInfo.TVDDiaPilotPlan)
 		row.AddStrValue(sInfo.TypeWellBore)
 		row.AddStrValue(sInfo.PrimaryConveyance)
-		row.AddFloatValue(sInfo.MdKickoff.Value)
-		row.AddStrValue(sInfo.MdKickoff.Uom)
-		row.AddFloatValue(sInfo.TvdKickoff.Value)
-		row.AddStrValue(sInfo.TvdKickoff.Uom)
-		row.AddFloatValue(sInfo.StrengthForm.Value)
-		row.AddStrValue(sInfo.StrengthForm.Uom)
-		row.AddFloatValue(sInfo.MdStrengthForm.Value)
-		row.AddStrValue(sInfo.MdStrengthForm.Uom)
-		row.AddFloatValue(sInfo.TvdStrengthForm.Value)
-		row.AddStrValue(sInfo.TvdStrengthForm.Uom)
-		row.AddFloatValue(sInfo.DiaCasingLast.Value)
-		row.AddStrValue(sInfo.DiaCasingLast.Uom)
-		row.AddFloatValue(sInfo.MdCasingLast.Value)
-		row.AddStrValue(sInfo.MdCasingLast.Uom)
-		row.AddFloatValue(sInfo.TvdCasingLast.Value)
-		row.AddStrValue(sInfo.TvdCasingLast.Uom)
+		row.AddMeasureValue(sInfo.MdKickoff)
+		row.AddMeasureValue(sInfo.TvdKickoff)
+		row.AddMeasureValue(sInfo.StrengthForm)
+		row.AddMeasureValue(sInfo.MdStrengthForm)
+		row.AddMeasureValue(sInfo.TvdStrengthForm)
+		row.AddMeasureValue(sInfo.DiaCasingLast)
+		row.AddMeasureValue(sInfo.MdCasingLast)
+		row.AddMeasureValue(sInfo.TvdCasingLast)
 		row.AddStrValue(sInfo.PressTestType)
-		row.AddFloatValue(sInfo.MdPlanned.Value)
-		row.AddStrValue(sInfo.MdPlanned.Uom)
-		row.AddFloatValue(sInfo.DistDrilled.Value)
-		row.AddStrValue(sInfo.DistDrilled.Uom)
-		row.AddFloatValue(sInfo.ElevKelly.Value)
-		row.AddStrValue(sInfo.ElevKelly.Uom)
-		row.AddFloatValue(sInfo.WellheadElevation.Value)
-		row.AddStrValue(sInfo.WellheadElevation.Uom)
-		row.AddFloatValue(sInfo.WaterDepth.Value)
-		row.AddStrValue(sInfo.WaterDepth.Uom)
+		row.AddMeasureValue(sInfo.MdPlanned)
+		row.AddMeasureValue(sInfo.DistDrilled)
+		row.AddMeasureValue(sInfo.ElevKelly)
+		row.AddMeasureValue(sInfo.WellheadElevation)
+		row.AddMeasureValue(sInfo.WaterDepth)
 		row.AddStrValue(sInfo.Sum24Hr)
 		row.AddStrValue(sInfo.Forecast24Hr)
-		row.AddFloatValue(sInfo.RopCurrent.Value)
-		row.AddStrValue(sInfo.RopCurrent.Uom)
+		row.AddMeasureValue(sInfo.RopCurrent)
 		row.AddStrValue(strconv.FormatBool(sInfo.TightWell))
 		row.AddStrValue(strconv.FormatBool(sInfo.HPHT))
-		row.AddFloatValue(sInfo.AvgPresBH.Value)
-		row.AddStrValue(sInfo.AvgPresBH.Uom)
-		row.AddFloatValue(sInfo.AvgTempBH.Value)
-		row.AddStrValue(sInfo.AvgTempBH.Uom)
+		row.AddMeasureValue(sInfo.AvgPresBH)
+		row.AddMeasureValue(sInfo.AvgTempBH)
 		row.AddStrValue(strconv.FormatBool(sInfo.FixedRig))
 		rows = append(rows, row)
 	}
 	//start off with the data that is generic
-	dSet.HeadersName = headers
 	dSet.Rows = rows
 	return dSet
 }
 
+//ddrRowIdentificationSchema declares the columns identifying the report of the rows in the drilling datasets
+var ddrRowIdentificationSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DrillReportUid", "Unique identifier of the drill report"),
+	common.StrColumn("DrillReportUidWell", "Unique identifier of the well"),
+	common.StrColumn("DrillReportUidWellbore", "Unique identifier of the wellbore"),
+	common.StrColumn("NameWell", "Name of the well"),
+	common.StrColumn("NameWellbore", "Name of the wellbore"),
+	common.StrColumn("ReportName", "Name of the drill report"),
+	common.TimeColumn("ReportDTimStart", "Start of the reporting period"),
+	common.TimeColumn("ReportDTimEnd", "End of the reporting period"),
+}}
+
 //Function will build a base dataset extrating the essential information from a drillreport such as
 //wellbore name, uid, times and so on. Other dataset functions can then use this base dataset to append additional
 //data, hence this will be used as indentification columns
 func buildDDRRowIdentification(dReport DrillReport, dataSetName string) common.DataSet {
-	var dSet common.DataSet
-	dSet = common.DataSet{}
-	dSet.Name = dataSetName
-	dSet.HeadersName = []string{"DataUUID", "DrillReportUid",
-		"DrillReportUidWell", "DrillReportUidWellbore", "NameWell",
-		"NameWellbore", "ReportName", "ReportDTimStart",
-		"ReportDTimEnd"}
+	dSet := common.NewDataSet(dataSetName, ddrRowIdentificationSchema)
 	row := common.RowData{}
 	row.AddStrValue(dReport.DataIdentification.UUid)
 	row.AddStrValue(dReport.Uid)
@@ -1383,3 +1753,12 @@ func buildDDRRowIdentification(dReport DrillReport, dataSetName string) common.D
 	dSet.Rows = append(dSet.Rows, row)
 	return dSet
 }
+
+//addDoubleValue adds the value as a float column, null if the element is missing in the xml
+func addDoubleValue(row *common.RowData, value xsdDouble) {
+	if !value.present {
+		row.AddNullFloatValue()
+		return
+	}
+	row.AddFloatValue(value.float64)
+}
@@ -0,0 +1,69 @@
+package ddrml
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+//TestParseDDRFileWithAbsentElements will test that elements missing in the xml give null cells, while
+//elements with a value, also zero, give the value
+func TestParseDDRFileWithAbsentElements(t *testing.T) {
+	folder, err := ioutil.TempDir("", "ddrml")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	xmlFile := filepath.Join(folder, "ddr.xml")
+	ddr := `<?xml version="1.0" encoding="utf-8"?>
+<drillReports><drillReport uid="1" uidWell="w1" uidWellbore="wb1"><nameWell>NO 1/1-1</nameWell><nameWellbore>NO 1/1-1</nameWellbore>
+<wellboreInfo><daysBehind>0</daysBehind><operator>Operator</operator><rigAlias><name>Rig</name></rigAlias></wellboreInfo>
+<statusInfo><reportNo>1</reportNo><md uom="m">1250.5</md><diaHole uom="in"></diaHole></statusInfo>
+</drillReport></drillReports>`
+	if err = ioutil.WriteFile(xmlFile, []byte(ddr), 0644); err != nil {
+		t.Fatalf("Failed in writing test file:%s", err.Error())
+	}
+	dReports, err := ParseDDRFile(xmlFile)
+	if err != nil || len(dReports.DrillReports) != 1 {
+		t.Fatalf("Failed in parsing ddr file:%v, reports:%d", err, len(dReports.DrillReports))
+	}
+	wellbore := buildDDRWellboreInfo(dReports.DrillReports, "WELLBORE_INFO")
+	if len(wellbore.Rows) != 1 {
+		t.Fatalf("Expected one wellbore info row, got:%d", len(wellbore.Rows))
+	}
+	if cell := columnByName(t, wellbore, 0, "DaysAhead"); !cell.IsNull || !cell.IsFloat {
+		t.Errorf("Expected null float for absent daysAhead, got:%+v", cell)
+	}
+	if cell := columnByName(t, wellbore, 0, "DaysBehind"); cell.IsNull || cell.FloatVal != 0 {
+		t.Errorf("Expected zero for daysBehind, got:%+v", cell)
+	}
+	status := buildDDRStatusInfo(dReports.DrillReports, "STATUS_INFO")
+	if cell := columnByName(t, status, 0, "MD"); cell.IsNull || cell.FloatVal != 1250.5 {
+		t.Errorf("Expected md value, got:%+v", cell)
+	}
+	if cell := columnByName(t, status, 0, "MD_UoM"); cell.StrVal != "m" {
+		t.Errorf("Expected md uom, got:%+v", cell)
+	}
+	for _, name := range []string{"TVD", "TVD_UoM", "DiaHole"} {
+		if cell := columnByName(t, status, 0, name); !cell.IsNull {
+			t.Errorf("Expected null for absent or empty element:%s, got:%+v", name, cell)
+		}
+	}
+	if cell := columnByName(t, status, 0, "DiaHole_UoM"); cell.StrVal != "in" {
+		t.Errorf("Expected uom of empty diaHole, got:%+v", cell)
+	}
+}
+
+//columnByName returns the column of the row with the header name
+func columnByName(t *testing.T, dataset common.DataSet, row int, name string) common.ColumnData {
+	for i := 0; i < len(dataset.HeadersName); i++ {
+		if dataset.HeadersName[i] == name {
+			return dataset.Rows[row].Columns[i]
+		}
+	}
+	t.Fatalf("Column:%s not found in dataset:%s", name, dataset.Name)
+	return common.ColumnData{}
+}
@@ -5,20 +5,17 @@ import (
 	"strconv"
 	"strings"
 	"time"
-)
 
-const xsdDateTimeLayoutNoTimeZone = "2006-01-02T15:04:05"
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
 
 type drillTimestamp struct {
 	time.Time
 }
 
-//const xsdDateTimeLayout = "2006-01-02T15:04:05"
-const xsdDateTimeLayout = time.RFC3339
-const xsdDateLayout = "2006-01-02"
-
 type xsdDouble struct {
 	float64
+	present bool //set if the element is in the xml
 }
 
 //unmarshals a xsd date element in the form of 2006-01-02 (YYYY-MM-DD)
@@ -43,20 +40,16 @@ func (c *xsdDouble) UnmarshalText(text []byte) error {
 	if number, err = strconv.ParseFloat(parse, 64); err != nil {
 		return err
 	}
-	*c = xsdDouble{number}
+	*c = xsdDouble{number, true}
 	return err
 }
 
 //unmarshal function to handle xsd date time parsing
 func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
-	var parse time.Time
-	var err error
 	d.DecodeElement(&v, &start)
-	if parse, err = time.Parse(xsdDateTimeLayout, v); err != nil {
-		//try to parse it without timezone..
-		parse, err = time.ParseInLocation(xsdDateTimeLayoutNoTimeZone, v, time.Now().Location())
-	}
+	//a time without time zone is read in the time zone set for the conversion, UTC by default
+	parse, _ := common.ParseXsdDateTime(v)
 	*c = xsdDateTime{parse}
 	return nil
 }
@@ -64,15 +57,13 @@ func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 func (c *xsdDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	parse, _ := time.ParseInLocation(xsdDateLayout, v, time.Now().Location())
+	parse, _ := common.ParseXsdDate(v)
 	*c = xsdDate{parse}
 	return nil
 }
 
-type Value struct {
-	Value float64 `xml:",chardata"`
-	Uom   string  `xml:"uom,attr"`
-}
+//Value is a measure element, missing if the element has no value
+type Value = common.Measure
 
 type ProcessingData struct {
 	UUid         string //uniqueue identifier for this dataset from a given report
@@ -86,7 +77,7 @@ type ProcessingData struct {
 func (c *drillTimestamp) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	parse, _ := time.Parse(time.RFC3339, v)
+	parse, _ := common.ParseXsdDateTime(v)
 	*c = drillTimestamp{parse}
 	return nil
 }
@@ -320,7 +311,7 @@ type Fluid struct {
 	OilPc           Value          `xml:"oilPc"`
 	SandPc          Value          `xml:"sandPc"`
 	SolidsLowGravPc Value          `xml:"solidsLowGravPc"`
-	PH              float64        `xml:"ph"`
+	PH              xsdDouble      `xml:"ph"`
 	PM              Value          `xml:"pm"`
 	PMFiltrate      Value          `xml:"pmFiltrate"`
 	MF              Value          `xml:"mf"`
@@ -335,16 +326,16 @@ type Fluid struct {
 }
 
 type Rheometer struct {
-	TempRheom  Value   `xml:"tempRheom"`
-	PressRheom Value   `xml:"presRheom"`
-	Vis3Rpm    float64 `xml:"vis3Rpm"`
-	Vis6Rpm    float64 `xml:"vis6Rpm"`
-	Vis30Rpm   float64 `xml:"vis30Rpm"`
-	Vis60Rpm   float64 `xml:"vis60Rpm"`
-	Vis100Rpm  float64 `xml:"vis100Rpm"`
-	Vis200Rpm  float64 `xml:"vis200Rpm"`
-	Vis300Rpm  float64 `xml:"vis300Rpm"`
-	Vis600Rpm  float64 `xml:"vis600Rpm"`
+	TempRheom  Value     `xml:"tempRheom"`
+	PressRheom Value     `xml:"presRheom"`
+	Vis3Rpm    xsdDouble `xml:"vis3Rpm"`
+	Vis6Rpm    xsdDouble `xml:"vis6Rpm"`
+	Vis30Rpm   xsdDouble `xml:"vis30Rpm"`
+	Vis60Rpm   xsdDouble `xml:"vis60Rpm"`
+	Vis100Rpm  xsdDouble `xml:"vis100Rpm"`
+	Vis200Rpm  xsdDouble `xml:"vis200Rpm"`
+	Vis300Rpm  xsdDouble `xml:"vis300Rpm"`
+	Vis600Rpm  xsdDouble `xml:"vis600Rpm"`
 }
 
 type PorePressure struct {
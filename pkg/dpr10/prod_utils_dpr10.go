@@ -8,6 +8,7 @@ package dpr10
 
 import (
 	"encoding/xml"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,7 +21,7 @@ func ParseProdXMLFile(inputFile string) (WITSMLComposite, error) {
 	var witsmlComposite WITSMLComposite
 	var err error
 	var data []byte
-	if data, err = common.ReadFile(inputFile); err != nil {
+	if data, err = common.ReadXMLFile(inputFile); err != nil {
 		return witsmlComposite, err
 	}
 	if err = xml.Unmarshal(data, &witsmlComposite); err != nil {
@@ -53,14 +54,22 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]WITSMLComposite, error) {
 	var err error
 	var objects []WITSMLComposite
 	var files []string
-	var data WITSMLComposite
 	start := time.Now()
 
-	if files, err = common.GetFilesWithExtension(folderPath, "*.xml"); err != nil {
+	if files, err = common.GetXMLFiles(folderPath); err != nil {
 		zap.S().Error("Failed in reading file in folder:", err.Error())
 		return objects, err
 	}
 	fileSearchTook := time.Since(start)
+	zap.S().Infof("File search took:%s", fileSearchTook)
+	return ReadProdXMLFileList2Struct(files)
+}
+
+//ReadProdXMLFileList2Struct parses the given list of xml files into a list of struct objects to be used for further processing
+func ReadProdXMLFileList2Struct(files []string) ([]WITSMLComposite, error) {
+	var err error
+	var objects []WITSMLComposite
+	var data WITSMLComposite
 	xmlStart := time.Now()
 	for i := 0; i < len(files); i++ {
 		zap.S().Info("Processing xml file:", files[i])
@@ -72,7 +81,6 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]WITSMLComposite, error) {
 	}
 	xmlParseTook := time.Since(xmlStart)
 
-	zap.S().Infof("File search took:%s", fileSearchTook)
 	zap.S().Infof("XML parse took:%s", xmlParseTook)
 	return objects, nil
 }
@@ -110,12 +118,19 @@ func OrganiseFacilitiesByKind_DPR10(objects []WITSMLComposite) map[string][]Faci
 	return facilities
 }
 
+//fileInformationSchema declares the columns of the report file information
+var fileInformationSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.TimeColumn("ReadTime", "Time the report file was read"),
+	common.StrColumn("DocumentName", "Name of the report document"),
+}}
+
 //Extracts data from the report files as read from the disk
 func extractFileInformation(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath", "ReadTime", "DocumentName"}
+	dataSet := common.NewDataSet(dataSetName, fileInformationSchema)
 	for i := 0; i < len(objects); i++ {
 		row := common.RowData{}
 		row.AddStrValue(objects[i].DataIdentification.UUid)
@@ -132,16 +147,95 @@ func extractFileInformation(dataSetName string, objects []WITSMLComposite) commo
 }
 
 func BuildCSVFileForProduction(path string, objects []WITSMLComposite, discriminator string) error {
-	dataSets := BuildDataSetsDPR_10(objects)
-	return common.DatasetsToCsv(dataSets, path, discriminator)
+	options := common.DefaultCsvOptions()
+	options.Delimiter = discriminator
+	return BuildCSVFileForProductionWithOptions(path, objects, options, nil)
+}
+
+//BuildCSVFileForProductionWithOptions writes one csv file per dataset using the given delimiter, bom and line endings
+func BuildCSVFileForProductionWithOptions(path string, objects []WITSMLComposite, options common.CsvOptions, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSetsDPR_10(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToCsvWithOptions(dataSets, path, options)
+}
+
+//BuildParquetFileForProduction writes one parquet file per dataset with the xml files read as source files
+//in the metadata
+func BuildParquetFileForProduction(path string, objects []WITSMLComposite, transform common.DataSetTransform) error {
+	var sourceFiles []string
+	for i := 0; i < len(objects); i++ {
+		sourceFiles = append(sourceFiles, objects[i].DataIdentification.FilePath)
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSetsDPR_10(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToParquet(dataSets, path, sourceFiles)
+}
+
+//BuildSqliteFileForProduction writes each dataset as a table in the sqlite database, rows are upserted on the
+//report identity so that converting a report again replaces its rows. Of several versions of a report the one
+//with the latest file creation date is kept, also when converted in an earlier run
+func BuildSqliteFileForProduction(path string, objects []WITSMLComposite, transform common.DataSetTransform) error {
+	var reportKeys []common.ReportKey
+	for i := 0; i < len(objects); i++ {
+		reportKeys = append(reportKeys, common.ReportKey{DataUUID: objects[i].DataIdentification.UUid,
+			Key: reportKey(objects[i]), Version: objects[i].DocumentInfo.FileCreationInfo.FileCreationDate.Time})
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSetsDPR_10(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToSqlite(dataSets, path, reportKeys)
+}
+
+//reportKey returns the natural identity of the report, the document name and the period it covers
+func reportKey(object WITSMLComposite) string {
+	name := object.DocumentInfo.DocumentName.Txt
+	if name == "" {
+		name = object.DataIdentification.FileName
+	}
+	return name + "|" + object.DataIdentification.ReportStart.Format(time.RFC3339) + "|" +
+		object.DataIdentification.ReportEnd.Format(time.RFC3339)
 }
 
 //Builds an excel output file for a given list of mprml objects
-func BuildXLSFileForProduction(path string, objects []WITSMLComposite, oneFilePerSheet bool, appendTimeInName bool) error {
-	dataSets := BuildDataSetsDPR_10(objects)
+func BuildXLSFileForProduction(path string, objects []WITSMLComposite, oneFilePerSheet bool, appendTimeInName bool, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSetsDPR_10(objects), transform)
+	if err != nil {
+		return err
+	}
 	return common.CreateWorkbookFromDataSet(path, dataSets, oneFilePerSheet, appendTimeInName)
 }
 
+//BuildStreamXLSFileForProduction writes an excel output file with the streaming writer, the files are read one at
+//a time and the rows of each dataset are built and written one report or facility at a time, so memory use does not
+//grow with the number of files and reports. Datasets first found in a file are added after the datasets of the
+//files before. The row transforms are applied as the rows are written, with dataset transforms, e.g. sorting or
+//pivoting, the rows of the datasets are kept in memory until all files are read
+func BuildStreamXLSFileForProduction(path string, files []string, oneFilePerSheet bool, transforms common.StreamTransforms) error {
+	writer, err := common.NewDataSetWorkbookWriter(path, oneFilePerSheet, transforms)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(files) && err == nil; i++ {
+		var object WITSMLComposite
+		zap.S().Info("Processing xml file:", files[i])
+		if object, err = ParseProdXMLFile(files[i]); err != nil {
+			zap.S().Errorf("Failed in parsing xml file:%s\n", err.Error())
+		} else {
+			err = WriteDataSetsDPR_10([]WITSMLComposite{object}, writer)
+		}
+	}
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
 func BuildDataSetsDPR_10(objects []WITSMLComposite) []common.DataSet {
 	var datasets []common.DataSet
 	var groupedFacilities map[string][]Facility
@@ -150,10 +244,10 @@ func BuildDataSetsDPR_10(objects []WITSMLComposite) []common.DataSet {
 
 	//build the report file info
 	datasets = append(datasets, extractFileInformation("REPORT_FILE_INFO", objects))
-	datasets = append(datasets, extractFacilityInfo("FACILITIES", groupedFacilities))
-	datasets = append(datasets, extractFlowInformation("FLOW_INFO", objects))
 	//add the document info data
 	datasets = append(datasets, extractDocumentInfo_DPR10("DOCUMENT_INFO", objects))
+	datasets = append(datasets, extractFacilityInfo("FACILITIES", groupedFacilities))
+	datasets = append(datasets, extractFlowInformation("FLOW_INFO", objects))
 	datasets = append(datasets, extractOpHSE_DPR10("OP_HSE", objects))
 	datasets = append(datasets, extractPersonell_DPR10("CREW", objects))
 	datasets = append(datasets, extract_OpComments_DPR10("OP_COMMENTS", objects))
@@ -177,10 +271,97 @@ func BuildDataSetsDPR_10(objects []WITSMLComposite) []common.DataSet {
 
 }
 
-func BuildJsonFileForProduction(path string, objects []WITSMLComposite) error {
+//WriteDataSetsDPR_10 writes the datasets of BuildDataSetsDPR_10 with the writer, the rows of each dataset are
+//built and written one report or facility at a time. The report datasets are written before the others, so a join
+//transform of the writer has the context of the reports when the other datasets are written
+func WriteDataSetsDPR_10(objects []WITSMLComposite, writer *common.DataSetWorkbookWriter) error {
+	groupedFacilities := OrganiseFacilitiesByKind_DPR10(objects)
+	var kinds []string
+	for kind := range groupedFacilities {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	reportDatasets := []struct {
+		name    string
+		extract func(dataSetName string, objects []WITSMLComposite) common.DataSet
+	}{
+		{"REPORT_FILE_INFO", extractFileInformation},
+		{"DOCUMENT_INFO", extractDocumentInfo_DPR10},
+		{"FACILITIES", nil},
+		{"FLOW_INFO", extractFlowInformation},
+		{"OP_HSE", extractOpHSE_DPR10},
+		{"CREW", extractPersonell_DPR10},
+		{"OP_COMMENTS", extract_OpComments_DPR10},
+		{"LOST_PRODUCTION", extractLostProduction_DPR10},
+		{"WATER_CLEANING", extractWaterCleaning_DPR10},
+		{"WELLTESTS", extractWellTests_DPR10},
+	}
+	for i := 0; i < len(reportDatasets); i++ {
+		var err error
+		dataset := reportDatasets[i]
+		if dataset.extract == nil {
+			//the facilities are listed one facility kind at a time
+			err = writer.WriteParts(len(kinds), func(x int) common.DataSet {
+				return extractFacilityInfo(dataset.name, map[string][]Facility{kinds[x]: groupedFacilities[kinds[x]]})
+			})
+		} else {
+			err = writer.WriteParts(len(objects), func(x int) common.DataSet {
+				return dataset.extract(dataset.name, objects[x:x+1])
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, kind := range kinds {
+		zap.S().Infof("Building sheet for facility kind:%s", kind)
+		extract := extractFacilityProdVolumes
+		if kind == "wellhead" || kind == "bottomhole" {
+			extract = extractFacilityProdVolumesBHPAndWHP
+		}
+		if err := writeFacilityDataSets(writer, strings.ToUpper(kind), groupedFacilities[kind], extract); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//writeFacilityDataSets writes the datasets extracted from the facilities of a kind one facility at a time, the
+//period kinds of all facilities are found first as each gives a dataset
+func writeFacilityDataSets(writer *common.DataSetWorkbookWriter, kind string, facilities []Facility,
+	extract func(dataSetName string, facilities []Facility) map[string]*common.DataSet) error {
+	var keys []string
+	empty := make(map[string]common.DataSet)
+	for i := 0; i < len(facilities); i++ {
+		for key, dataset := range extract(kind, facilities[i:i+1]) {
+			if _, found := empty[key]; !found {
+				keys = append(keys, key)
+				empty[key] = common.DataSet{Name: dataset.Name, HeadersName: dataset.HeadersName, Schema: dataset.Schema}
+			}
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		zap.S().Infof("Adding dataset with key:%s", key)
+		if err := writer.WriteParts(len(facilities), func(i int) common.DataSet {
+			if dataset, found := extract(kind, facilities[i:i+1])[key]; found {
+				return *dataset
+			}
+			return empty[key]
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BuildJsonFileForProduction(path string, objects []WITSMLComposite, transform common.DataSetTransform) error {
 	var err error
 	var data []byte
-	dataSets := BuildDataSetsDPR_10(objects)
+	dataSets, err := common.TransformDataSets(BuildDataSetsDPR_10(objects), transform)
+	if err != nil {
+		return err
+	}
 	if data, err = common.DatasetsToJson(dataSets); err != nil {
 		return err
 	}
@@ -188,15 +369,24 @@ func BuildJsonFileForProduction(path string, objects []WITSMLComposite) error {
 	return common.Write2File(path, data)
 }
 
+//facilityInfoSchema declares the columns of the facilities of the reports by facility kind
+var facilityInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("UidRef", "Unique identifier of the facility"),
+	common.StrColumn("NameKind", "Kind given with the facility name"),
+	common.StrColumn("FileName", "Name of the report file"),
+}}
+
 func extractFacilityInfo(dataSetName string, groupedFacilities map[string][]Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"FacilityKind", "FacilityName", "UidRef", "NameKind", "FileName"}
+	dataSet := common.NewDataSet(dataSetName, facilityInfoSchema)
 	for kind, facilities := range groupedFacilities {
 		for i := 0; i < len(facilities); i++ {
 			row := common.RowData{}
 			//add the top identification information so that it can be bound back to the original file
+			row.AddStrValue(facilities[i].DataIdentification.DataIdentification.UUid)
 			row.AddStrValue(kind)
 			row.AddStrValue(facilities[i].Name.Name)
 			row.AddStrValue(facilities[i].Name.UidRef)
@@ -209,21 +399,30 @@ func extractFacilityInfo(dataSetName string, groupedFacilities map[string][]Faci
 	return dataSet
 }
 
+//flowInformationSchema declares the columns of the flows of the facilities
+var flowInformationSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+}}
+
 func extractFlowInformation(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath",
-		"FacilityName", "FacilityKind", "FlowName", "FlowKind", "FlowQualifier"}
+	dataSet := common.NewDataSet(dataSetName, flowInformationSchema)
 	for i := 0; i < len(objects); i++ {
 		for x := 0; x < len(objects[i].ProdVolumeSet.ProdVolumes); x++ {
 			for s := 0; s < len(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities); s++ {
 				for y := 0; y < len(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].Flow); y++ {
 					row := common.RowData{}
 					//add the top identification information so that it can be bound back to the original file
-					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].DataIdentification.DataIdentification.UUid)
-					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].DataIdentification.DataIdentification.FileName)
-					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].DataIdentification.DataIdentification.FilePath)
+					row.AddStrValue(objects[i].DataIdentification.UUid)
+					row.AddStrValue(objects[i].DataIdentification.FileName)
+					row.AddStrValue(objects[i].DataIdentification.FilePath)
 					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].Name.Name)
 					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].Name.Kind)
 					row.AddStrValue(objects[i].ProdVolumeSet.ProdVolumes[x].Facilities[s].Flow[y].Name)
@@ -238,17 +437,45 @@ func extractFlowInformation(dataSetName string, objects []WITSMLComposite) commo
 	return dataSet
 }
 
+//wellTestsSchema declares the columns of the well tests of the wellbores
+var wellTestsSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("WellUid", "Unique identifier of the well"),
+	common.StrColumn("WellName", "Name of the well"),
+	common.StrColumn("WellboreName", "Name of the wellbore"),
+	common.StrColumn("WellTestName", "Name of the well test"),
+	common.TimeColumn("TestDate", "Date of the test"),
+	common.StrColumn("TestType", "Type of the test"),
+	common.FloatColumn("ChokeSize", "Choke size of the well test"),
+	common.UoMColumn("ChokeSizeUoM", "ChokeSize"),
+	common.StrColumn("StandardTempPres", "Standard temperature and pressure of the test"),
+	common.FloatColumn("TestDuration", "Duration of the production test"),
+	common.UoMColumn("TestDurationUoM", "TestDuration"),
+	common.FloatColumn("WHT", "Wellhead temperature"),
+	common.UoMColumn("WHTUoM", "WHT"),
+	common.FloatColumn("WHP", "Wellhead pressure"),
+	common.UoMColumn("WHPUoM", "WHP"),
+	common.FloatColumn("ChokeSize", "Choke size of the production test"),
+	common.UoMColumn("ChokeSizeUoM", "ChokeSize"),
+	common.FloatColumn("SepPress", "Separator pressure"),
+	common.UoMColumn("SepPressUoM", "SepPress"),
+	common.FloatColumn("SepTemp", "Separator temperature"),
+	common.UoMColumn("SepTempUoM", "SepTemp"),
+	common.FloatColumn("OilRate", "Oil rate"),
+	common.UoMColumn("OilRateUoM", "OilRate"),
+	common.FloatColumn("GasRate", "Gas rate"),
+	common.UoMColumn("GasRateUoM", "GasRate"),
+	common.FloatColumn("WatRate", "Water rate"),
+	common.UoMColumn("WatRateUoM", "WatRate"),
+	common.FloatColumn("GoR", "Gas oil ratio"),
+	common.UoMColumn("GoRUoM", "GoR"),
+}}
+
 func extractWellTests_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath",
-		"WellUid", "WellName", "WellboreName", "WellTestName",
-		"TestDate", "TestType", "ChokeSize", "ChokeSizeUoM",
-		"StandardTempPres", "TestDuration", "TestDurationUoM", "WHT", "WHTUoM",
-		"WHP", "WHPUoM", "ChokeSize", "ChokeSizeUoM", "SepPress", "SepPressUoM",
-		"SepTemp", "SepTempUoM", "OilRate", "OilRateUoM", "GasRate", "GasRateUoM",
-		"WatRate", "WatRateUoM", "GoR", "GoRUoM"}
+	dataSet := common.NewDataSet(dataSetName, wellTestsSchema)
 	for i := 0; i < len(objects); i++ {
 		dIdentification := objects[i].DataIdentification
 		wSet := objects[i].Wellset
@@ -267,29 +494,18 @@ func extractWellTests_DPR10(dataSetName string, objects []WITSMLComposite) commo
 					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].Name)
 					row.AddTimeValue(wSet.Wells[s].Wellbores[y].WellTests[x].TestDate.Time)
 					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].TestType)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ChokeSize.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ChokeSize.Uom)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ChokeSize)
 					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].StandardTempPres)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.TestDuration.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.TestDuration.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHT.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHT.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHP.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHP.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.ChokeSize.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.ChokeSize.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepPress.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepPress.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepTemp.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepTemp.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.OilRate.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.OilRate.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GasRate.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GasRate.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WatRate.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WatRate.Uom)
-					row.AddFloatValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GoR.Value)
-					row.AddStrValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GoR.Uom)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.TestDuration)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHT)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WHP)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.ChokeSize)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepPress)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.SepTemp)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.OilRate)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GasRate)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.WatRate)
+					row.AddMeasureValue(wSet.Wells[s].Wellbores[y].WellTests[x].ProductionTest.GoR)
 					rows = append(rows, row)
 
 				}
@@ -302,21 +518,46 @@ func extractWellTests_DPR10(dataSetName string, objects []WITSMLComposite) commo
 	return dataSet
 }
 
+//wellheadBottomholeSchema declares the columns of the wellhead and bottomhole flows of the facilities, one dataset per facility kind
+var wellheadBottomholeSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FacilityParent1", "Name of the first parent facility"),
+	common.StrColumn("FacilityParent1Kind", "Kind of the first parent facility"),
+	common.StrColumn("FacilityParent1UID", "Unique identifier of the first parent facility"),
+	common.StrColumn("FacilityParent2", "Name of the second parent facility"),
+	common.StrColumn("FacilityParent2Kind", "Kind of the second parent facility"),
+	common.StrColumn("FacilityParent2UID", "Unique identifier of the second parent facility"),
+	common.StrColumn("ContextFacility", "Name of the context facility"),
+	common.StrColumn("ContextFacilityKind", "Kind of the context facility"),
+	common.StrColumn("ContextFacilityUID", "Unique identifier of the context facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("InteralFlowUid", "Identifier given to the flow when read"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.TimeColumn("ReportStart", "Start of the reporting period"),
+	common.TimeColumn("ReportEnd", "End of the reporting period"),
+	common.FloatColumn("chokeRelative", "Relative choke opening"),
+	common.UoMColumn("chokeUoM", "chokeRelative"),
+	common.FloatColumn("Pressure", "Pressure of the flow"),
+	common.UoMColumn("PressureUoM", "Pressure"),
+	common.FloatColumn("Temp", "Temperature of the flow"),
+	common.UoMColumn("TempUoM", "Temp"),
+}}
+
 func extractFacilityProdVolumesBHPAndWHP(dataSetName string, facilities []Facility) map[string]*common.DataSet {
 	returnData := make(map[string]*common.DataSet)
 	//dataSet := common.DataSet{}
 	//dataSet.Name = dataSetName
-	zap.S().Infof("Building dataset with name:%s", dataSetName)
-	headersName := []string{"DataUUID", "FileName", "FilePath", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FacilityParent1", "FacilityParent1Kind", "FacilityParent1UID",
-		"FacilityParent2", "FacilityParent2Kind", "FacilityParent2UID",
-		"ContextFacility", "ContextFacilityKind", "ContextFacilityUID",
-		"FlowUid", "InteralFlowUid", "FlowKind", "FlowQualifier", "FlowName",
-		"ReportStart", "ReportEnd",
-		"chokeRelative", "chokeUoM",
-		"Pressure", "PressureUoM", "Temp", "TempUoM"}
+	zap.S().Debugf("Building dataset with name:%s", dataSetName)
 	//operationTime, wellProducing, wellInjecting, chokeRelative reserved for well/wellbores
 
 	for i := 0; i < len(facilities); i++ {
@@ -355,33 +596,9 @@ func extractFacilityProdVolumesBHPAndWHP(dataSetName string, facilities []Facili
 			//add the dates
 			row.AddTimeValue(facilities[i].DataIdentification.DataIdentification.ReportStart)
 			row.AddTimeValue(facilities[i].DataIdentification.DataIdentification.ReportEnd)
-			if facilities[i].Flow[x].PortDiff.ChokeRelative.Uom != "" {
-				//add the choke
-				row.AddFloatValue(facilities[i].Flow[x].PortDiff.ChokeRelative.Value)
-				row.AddStrValue(facilities[i].Flow[x].PortDiff.ChokeRelative.Uom)
-			} else {
-				//add empty columns
-				row.AddFloatValue(common.NullFloatValue)
-				row.AddStrValue("")
-			}
-			if facilities[i].Flow[x].Pres.Uom != "" {
-				//add wellhead pressure
-				row.AddFloatValue(facilities[i].Flow[x].Pres.Value)
-				row.AddStrValue(facilities[i].Flow[x].Pres.Uom)
-			} else {
-				//add empty columns
-				row.AddFloatValue(common.NullFloatValue)
-				row.AddStrValue("")
-			}
-			if facilities[i].Flow[x].Temp.Uom != "" {
-				//add wellhead pressure
-				row.AddFloatValue(facilities[i].Flow[x].Temp.Value)
-				row.AddStrValue(facilities[i].Flow[x].Temp.Uom)
-			} else {
-				//add empty columns
-				row.AddFloatValue(common.NullFloatValue)
-				row.AddStrValue("")
-			}
+			row.AddMeasureValue(facilities[i].Flow[x].PortDiff.ChokeRelative)
+			row.AddMeasureValue(facilities[i].Flow[x].Pres)
+			row.AddMeasureValue(facilities[i].Flow[x].Temp)
 
 			key := strings.ToUpper(facilities[i].Name.Kind)
 			if _, ok := returnData[key]; ok {
@@ -391,40 +608,91 @@ func extractFacilityProdVolumesBHPAndWHP(dataSetName string, facilities []Facili
 				//not there add it
 				rows := []common.RowData{}
 				rows = append(rows, row)
-				returnData[key] = &common.DataSet{HeadersName: headersName, Name: key, Rows: rows}
+				returnData[key] = &common.DataSet{HeadersName: wellheadBottomholeSchema.Headers(), Name: key, Rows: rows, Schema: wellheadBottomholeSchema}
 			}
 		}
 	}
 	return returnData
 }
 
+//prodVolumesSchema declares the columns of the product volumes of the facilities, one dataset per facility kind and period kind
+var prodVolumesSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FacilityParent1", "Name of the first parent facility"),
+	common.StrColumn("FacilityParent1Kind", "Kind of the first parent facility"),
+	common.StrColumn("FacilityParent1UID", "Unique identifier of the first parent facility"),
+	common.StrColumn("FacilityParent2", "Name of the second parent facility"),
+	common.StrColumn("FacilityParent2Kind", "Kind of the second parent facility"),
+	common.StrColumn("FacilityParent2UID", "Unique identifier of the second parent facility"),
+	common.StrColumn("ContextFacility", "Name of the context facility"),
+	common.StrColumn("ContextFacilityKind", "Kind of the context facility"),
+	common.StrColumn("ContextFacilityUID", "Unique identifier of the context facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("InteralFlowUid", "Identifier given to the flow when read"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("ProductKind", "Kind of the product, e.g. oil or gas"),
+	common.StrColumn("ProductName", "Name of the product"),
+	common.StrColumn("PeriodKind", "Kind of the period, e.g. day or month"),
+	common.TimeColumn("dateStart", "Start date of the period"),
+	common.TimeColumn("dateEnd", "End date of the period"),
+	common.TimeColumn("dTimStart", "Start time of the period"),
+	common.TimeColumn("dTimEnd", "End time of the period"),
+	common.FloatColumn("volume", "Volume"),
+	common.UoMColumn("volumeUoM", "volume"),
+	common.FloatColumn("volumeStd", "Volume at standard conditions"),
+	common.UoMColumn("volumeStdUoM", "volumeStd"),
+	common.FloatColumn("volumeValue", "Volume at the given temperature and pressure"),
+	common.UoMColumn("volumeValueUoM", "volumeValue"),
+	common.FloatColumn("volumeValueTempCondValue", "Temperature of the volume"),
+	common.UoMColumn("volumeValueTempCondUom", "volumeValueTempCondValue"),
+	common.FloatColumn("volumeValuePresCondValue", "Pressure of the volume"),
+	common.UoMColumn("volumeValuePresCondUoM", "volumeValuePresCondValue"),
+	common.FloatColumn("densityValue", "Density at the given temperature and pressure"),
+	common.UoMColumn("densityValueUoM", "densityValue"),
+	common.FloatColumn("densityValueTempCondValue", "Temperature of the density"),
+	common.UoMColumn("densityValueTempCondUom", "densityValueTempCondValue"),
+	common.FloatColumn("densityValuePresCondValue", "Pressure of the density"),
+	common.UoMColumn("densityValuePresCondUoM", "densityValuePresCondValue"),
+	common.FloatColumn("densityStd", "Density at standard conditions"),
+	common.UoMColumn("densityStdUoM", "densityStd"),
+	common.FloatColumn("bsw", "Basic sediment and water"),
+	common.UoMColumn("bswUoM", "bsw"),
+	common.FloatColumn("rvp", "Reid vapour pressure"),
+	common.UoMColumn("rvpUoM", "rvp"),
+	common.FloatColumn("mass", "Mass"),
+	common.UoMColumn("massUoM", "mass"),
+	common.FloatColumn("operationTime", "Operating time of the well"),
+	common.UoMColumn("operationTimeUoM", "operationTime"),
+	common.StrColumn("wellProducing", "True if the well is producing"),
+	common.StrColumn("wellInjecting", "True if the well is injecting"),
+	common.FloatColumn("chokeRelative", "Relative choke opening of the well"),
+	common.UoMColumn("chokeUoM", "chokeRelative"),
+	common.FloatColumn("WellheadPressure", "Wellhead pressure"),
+	common.UoMColumn("WellheadPressureUoM", "WellheadPressure"),
+	common.FloatColumn("WellheadTemp", "Wellhead temperature"),
+	common.UoMColumn("WellheadTempUoM", "WellheadTemp"),
+	common.FloatColumn("BottomholePressure", "Bottomhole pressure"),
+	common.UoMColumn("BottomholePressureUoM", "BottomholePressure"),
+	common.FloatColumn("BottomholeTemp", "Bottomhole temperature"),
+	common.UoMColumn("BottomholeTempUoM", "BottomholeTemp"),
+}}
+
 func extractFacilityProdVolumes(dataSetName string, facilities []Facility) map[string]*common.DataSet {
 	//var rows []common.RowData
 	returnData := make(map[string]*common.DataSet)
 	//dataSet := common.DataSet{}
 	//dataSet.Name = dataSetName
-	zap.S().Infof("Building dataset with name:%s", dataSetName)
-	headersName := []string{"DataUUID", "FileName", "FilePath", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FacilityParent1", "FacilityParent1Kind", "FacilityParent1UID",
-		"FacilityParent2", "FacilityParent2Kind", "FacilityParent2UID",
-		"ContextFacility", "ContextFacilityKind", "ContextFacilityUID",
-		"FlowUid", "InteralFlowUid", "FlowKind", "FlowQualifier", "FlowName", "ProductKind", "ProductName",
-		"PeriodKind", "dateStart", "dateEnd", "dTimStart", "dTimEnd",
-		"volume", "volumeUoM", "volumeStd", "volumeStdUoM", "volumeValue", "volumeValueUoM",
-		"volumeValueTempCondValue", "volumeValueTempCondUom", "volumeValuePresCondValue",
-		"volumeValuePresCondUoM", "densityValue", "densityValueUoM",
-		"densityValueTempCondValue", "densityValueTempCondUom",
-		"densityValuePresCondValue", "densityValuePresCondUoM",
-		"densityStd", "densityStdUoM", "bsw", "bswUoM",
-		"rvp", "rvpUoM",
-		"mass", "massUoM",
-		//"GoR", "GoRUoM", "WaterConcVol", "WaterConcVolUoM",
-		"operationTime", "operationTimeUoM", "wellProducing", "wellInjecting",
-		"chokeRelative", "chokeUoM",
-		"WellheadPressure", "WellheadPressureUoM", "WellheadTemp", "WellheadTempUoM",
-		"BottomholePressure", "BottomholePressureUoM", "BottomholeTemp", "BottomholeTempUoM"}
+	zap.S().Debugf("Building dataset with name:%s", dataSetName)
 	//operationTime, wellProducing, wellInjecting, chokeRelative reserved for well/wellbores
 
 	for i := 0; i < len(facilities); i++ {
@@ -474,111 +742,53 @@ func extractFacilityProdVolumes(dataSetName string, facilities []Facility) map[s
 					row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)
 					row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DTimStart.Time) //add dtim start and end
 					row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DTimEnd.Time)   //add dtime end
-					if facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Uom != "" {
-						//just add it if there is data
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Value) //add volume only value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Uom)
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly)  //add volume only value
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd)   //add volume std value
 
+					if facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.IsPresent() {
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume)   //add volume value
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp)     //add volume value temp cond
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure) //add volume value pres cond
 					} else {
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-					}
-					if facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Value) //add volume std value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Uom)
-					} else {
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
+						row.AddNullFloatValue()
+						row.AddNullStrValue()
+						row.AddNullFloatValue()
+						row.AddNullStrValue()
+						row.AddNullFloatValue()
+						row.AddNullStrValue() //add volume value pres uom
 					}
+					if facilities[i].Flow[x].Product[z].Period[s].Density.Density.IsPresent() {
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density)  //add density value
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Temp)     //add density temp cond value
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure) //add density pressure cond value
 
-					if facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Value) //add volume value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Uom)
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Value)     //add volume value temp cond
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Uom)         //add volume value temp uom
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Value) //add volume value pres cond
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Uom)     //add volume value pres uom
 					} else {
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("") //add volume value pres uom
-					}
-					if facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Value)  //add density value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom)      //add density uom
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Value)     //add density temp cond value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Uom)         //add density temp cond uom
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Value) //add density pressure cond value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Uom)     //add density pressure cond uom
-
-					} else {
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
+						row.AddNullFloatValue()
+						row.AddNullStrValue()
+						row.AddNullFloatValue()
+						row.AddNullStrValue()
+						row.AddNullFloatValue()
+						row.AddNullStrValue()
 					}
 					//need to add density std element if evident
-					if facilities[i].Flow[x].Product[z].DensityStd.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].DensityStd.Value)
-						row.AddStrValue(facilities[i].Flow[x].Product[z].DensityStd.Uom)
-					} else {
-						//add null values
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-
-					}
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].DensityStd)
 					//add bsw if evident
-					if facilities[i].Flow[x].Product[z].BsW.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].BsW.Value)
-						row.AddStrValue(facilities[i].Flow[x].Product[z].BsW.Uom)
-					} else {
-						//add null values
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-
-					}
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].BsW)
 					//add rvp if evident
-					if facilities[i].Flow[x].Product[z].Rvp.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Rvp.Value)
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Rvp.Uom)
-					} else {
-						//add null values
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-
-					}
-					if facilities[i].Flow[x].Product[z].Period[s].Mass.Uom != "" {
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Value) //add mass value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Uom)
-					} else {
-						row.AddFloatValue(common.NullFloatValue)
-						row.AddStrValue("")
-					}
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].Rvp)
+					row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Mass) //add mass value
 
 					//need to process additional well/wellbore data if it is of that kind...
 					if facilities[i].Name.Kind == "well" || facilities[i].Name.Kind == "wellbore" {
-						row.AddFloatValue(facilities[i].OperationTime.Value)
-						row.AddStrValue(facilities[i].OperationTime.Uom)
+						row.AddMeasureValue(facilities[i].OperationTime)
 						row.AddStrValue(facilities[i].WellProducing)
 						row.AddStrValue(facilities[i].WellInjecting)
-						if facilities[i].Flow[x].PortDiff.ChokeRelative.Uom != "" {
-							row.AddFloatValue(facilities[i].Flow[x].PortDiff.ChokeRelative.Value)
-							row.AddStrValue(facilities[i].Flow[x].PortDiff.ChokeRelative.Uom)
-						} else if facilities[i].Flow[x].PortDiff.ChokeRelative.Uom == "" {
-							//just see if we can locate the choke in the flow
-							chokeValue := getChokeData(facilities[i].Flow)
-							if chokeValue.Uom != "" {
-								row.AddFloatValue(chokeValue.Value)
-								row.AddStrValue(chokeValue.Uom)
-							}
+						if facilities[i].Flow[x].PortDiff.ChokeRelative.IsPresent() {
+							row.AddMeasureValue(facilities[i].Flow[x].PortDiff.ChokeRelative)
 						} else {
-							row.AddFloatValue(common.NullFloatValue)
-							row.AddStrValue("")
+							//just see if we can locate the choke in the flow, null if there is none
+							chokeValue := getChokeData(facilities[i].Flow)
+							row.AddMeasureValue(chokeValue)
 						}
 
 					} else {
@@ -592,16 +802,14 @@ func extractFacilityProdVolumes(dataSetName string, facilities []Facility) map[s
 					}
 					if facilities[i].Name.Kind == "wellhead" {
 						//need to add whp and wht
-						if facilities[i].Flow[x].Pres.Uom != "" {
-							row.AddFloatValue(facilities[i].Flow[x].Pres.Value)
-							row.AddStrValue(facilities[i].Flow[x].Pres.Uom)
+						if facilities[i].Flow[x].Pres.IsPresent() {
+							row.AddMeasureValue(facilities[i].Flow[x].Pres)
 						} else {
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
 						}
-						if facilities[i].Flow[x].Temp.Uom != "" {
-							row.AddFloatValue(facilities[i].Flow[x].Temp.Value)
-							row.AddStrValue(facilities[i].Flow[x].Temp.Uom)
+						if facilities[i].Flow[x].Temp.IsPresent() {
+							row.AddMeasureValue(facilities[i].Flow[x].Temp)
 						} else {
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
@@ -616,16 +824,14 @@ func extractFacilityProdVolumes(dataSetName string, facilities []Facility) map[s
 					}
 					if facilities[i].Name.Kind == "bottomhole" {
 						//need to add whp and wht
-						if facilities[i].Flow[x].Pres.Uom != "" {
-							row.AddFloatValue(facilities[i].Flow[x].Pres.Value)
-							row.AddStrValue(facilities[i].Flow[x].Pres.Uom)
+						if facilities[i].Flow[x].Pres.IsPresent() {
+							row.AddMeasureValue(facilities[i].Flow[x].Pres)
 						} else {
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
 						}
-						if facilities[i].Flow[x].Temp.Uom != "" {
-							row.AddFloatValue(facilities[i].Flow[x].Temp.Value)
-							row.AddStrValue(facilities[i].Flow[x].Temp.Uom)
+						if facilities[i].Flow[x].Temp.IsPresent() {
+							row.AddMeasureValue(facilities[i].Flow[x].Temp)
 						} else {
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
@@ -647,7 +853,7 @@ func extractFacilityProdVolumes(dataSetName string, facilities []Facility) map[s
 						//not there add it
 						rows := []common.RowData{}
 						rows = append(rows, row)
-						returnData[key] = &common.DataSet{HeadersName: headersName, Name: key, Rows: rows}
+						returnData[key] = &common.DataSet{HeadersName: prodVolumesSchema.Headers(), Name: key, Rows: rows, Schema: prodVolumesSchema}
 					}
 					//rows = append(rows, row)
 
@@ -669,18 +875,10 @@ func getWellheadOrBottomHoleFlow(uid string, facilities []Facility, facilityKind
 		if facilities[i].Name.UidRef == uid && facilities[i].Name.Kind == facilityKind {
 			//get the flow that contains temp and pres
 			for s := 0; s < len(facilities[i].Flow) && !breakOut; s++ {
-				if facilities[i].Flow[s].Temp.Uom != "" || facilities[i].Flow[s].Pres.Uom != "" {
+				if facilities[i].Flow[s].Temp.IsPresent() || facilities[i].Flow[s].Pres.IsPresent() {
 					zap.S().Infof("LOcated facilitykind:%s, with uidref:%s", facilityKind, uid)
 					breakOut = true
 					returnFlow = facilities[i].Flow[s]
-					if facilities[i].Flow[s].Temp.Uom == "" {
-						//just blank out the temp with default null value
-						returnFlow.Temp.Value = common.NullFloatValue
-					}
-					if facilities[i].Flow[s].Pres.Uom == "" {
-						//just blank out the temp with default null value
-						returnFlow.Pres.Value = common.NullFloatValue
-					}
 				}
 			}
 		}
@@ -689,25 +887,33 @@ func getWellheadOrBottomHoleFlow(uid string, facilities []Facility, facilityKind
 }
 
 func getChokeData(flows []Flow) Value {
-	returnValue := Value{Uom: "", Value: common.NullFloatValue}
+	returnValue := Value{}
 	breakOut := false
 	for i := 0; i < len(flows) && !breakOut; i++ {
-		if flows[i].PortDiff.ChokeRelative.Uom != "" {
-			returnValue = Value{Uom: flows[i].PortDiff.ChokeRelative.Uom,
-				Value: flows[i].PortDiff.ChokeRelative.Value}
+		if flows[i].PortDiff.ChokeRelative.IsPresent() {
+			returnValue = flows[i].PortDiff.ChokeRelative
 			breakOut = true
 		}
 	}
 	return returnValue
 }
 
+//documentInfoSchema declares the columns of the document information and audit trail of the reports
+var documentInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.TimeColumn("DocumentDate", "Date of the report document"),
+	common.TimeColumn("AuditEventDate", "Date of the audit event"),
+	common.StrColumn("AuditEventRespParty", "Responsible party of the audit event"),
+	common.StrColumn("AuditEventComment", "Comment to the audit event"),
+}}
+
 //Extracts data from the document info in the mprml struct objects
 func extractDocumentInfo_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath", "DocumentName", "DocumentDate",
-		"AuditEventDate", "AuditEventRespParty", "AuditEventComment"}
+	dataSet := common.NewDataSet(dataSetName, documentInfoSchema)
 
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
@@ -734,14 +940,29 @@ func extractDocumentInfo_DPR10(dataSetName string, objects []WITSMLComposite) co
 	return dataSet
 }
 
+//crewSchema declares the columns of the crew counts of the installations
+var crewSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.IntColumn("BedsAvailable", "Number of beds available"),
+	common.StrColumn("CrewType", "Type of the crew"),
+	common.IntColumn("CrewCount", "Number of crew"),
+	common.FloatColumn("Work", "Work hours"),
+	common.UoMColumn("WorkUoM", "Work"),
+}}
+
 func extractPersonell_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind",
-		"BedsAvailable", "CrewType", "CrewCount", "Work", "WorkUoM"}
+	dataSet := common.NewDataSet(dataSetName, crewSchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -756,8 +977,7 @@ func extractPersonell_DPR10(dataSetName string, objects []WITSMLComposite) commo
 			periodKind := objects[i].ProdOperationSet.ProdOperation[x].Kind
 			for y := 0; y < len(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport); y++ {
 				bedsAvailable := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].BedsAvailable
-				work := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].Work.Value
-				workUoM := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].Work.Uom
+				work := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].Work
 				for z := 0; z < len(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].CrewCounts); z++ {
 
 					row := common.RowData{}
@@ -775,8 +995,7 @@ func extractPersonell_DPR10(dataSetName string, objects []WITSMLComposite) commo
 					row.AddIntValue(bedsAvailable)
 					row.AddStrValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].CrewCounts[z].Type)
 					row.AddIntValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].CrewCounts[z].Count)
-					row.AddFloatValue(work.float64)
-					row.AddStrValue(workUoM)
+					row.AddMeasureValue(work)
 					rows = append(rows, row)
 
 				}
@@ -790,13 +1009,28 @@ func extractPersonell_DPR10(dataSetName string, objects []WITSMLComposite) commo
 	return dataSet
 }
 
+//opCommentsSchema declares the columns of the operational comments of the installations
+var opCommentsSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.StrColumn("CommentType", "Type of the comment"),
+	common.TimeColumn("CommentDTimStart", "Start of the period commented"),
+	common.TimeColumn("CommentDTimEnd", "End of the period commented"),
+	common.StrColumn("Comment", "Comment"),
+}}
+
 func extract_OpComments_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind", "CommentType", "CommentDTimStart", "CommentDTimEnd", "Comment"}
+	dataSet := common.NewDataSet(dataSetName, opCommentsSchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -840,14 +1074,27 @@ func extract_OpComments_DPR10(dataSetName string, objects []WITSMLComposite) com
 	return dataSet
 }
 
+//lostProductionSchema declares the columns of the lost production of the installations by reason
+var lostProductionSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.StrColumn("ReasonLost", "Reason the production was lost"),
+	common.FloatColumn("Volume", "Volume lost"),
+	common.UoMColumn("VolumeUoM", "Volume"),
+}}
+
 func extractLostProduction_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind",
-		"ReasonLost", "Volume", "VolumeUoM"}
+	dataSet := common.NewDataSet(dataSetName, lostProductionSchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -887,14 +1134,28 @@ func extractLostProduction_DPR10(dataSetName string, objects []WITSMLComposite)
 	return dataSet
 }
 
+//waterCleaningSchema declares the columns of the water cleaning samples of the installations
+var waterCleaningSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.StrColumn("WaterCleaningUid", "Unique identifier of the water cleaning"),
+	common.StrColumn("SamplePoint", "Sample point"),
+	common.FloatColumn("OilInWaterProduced", "Oil in the produced water"),
+	common.UoMColumn("OilInWaterProducedUoM", "OilInWaterProduced"),
+}}
+
 func extractWaterCleaning_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind",
-		"WaterCleaningUid", "SamplePoint", "OilInWaterProduced", "OilInWaterProducedUoM"}
+	dataSet := common.NewDataSet(dataSetName, waterCleaningSchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -924,8 +1185,7 @@ func extractWaterCleaning_DPR10(dataSetName string, objects []WITSMLComposite) c
 					row.AddStrValue(objects[i].ProdOperationSet.ProdOperation[x].Installation.Kind)
 					row.AddStrValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].ProductionActivity.WaterCleaning[z].Uid)
 					row.AddStrValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].ProductionActivity.WaterCleaning[z].SamplePoint)
-					row.AddFloatValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].ProductionActivity.WaterCleaning[z].OilInWaterProduced.Value)
-					row.AddStrValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].ProductionActivity.WaterCleaning[z].OilInWaterProduced.Uom)
+					row.AddMeasureValue(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].ProductionActivity.WaterCleaning[z].OilInWaterProduced)
 					rows = append(rows, row)
 				}
 			}
@@ -935,15 +1195,31 @@ func extractWaterCleaning_DPR10(dataSetName string, objects []WITSMLComposite) c
 	return dataSet
 }
 
+//opHSESchema declares the columns of the health, safety and environment counts of the installations
+var opHSESchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.IntColumn("IncidentCount", "Number of incidents"),
+	common.StrColumn("SafetyCountType", "Type of the safety count"),
+	common.StrColumn("SafetyCountPeriodType", "Period of the safety count"),
+	common.IntColumn("SafetyCount", "Safety count"),
+	common.IntColumn("SafetyIntroCount", "Number of safety introductions"),
+	common.FloatColumn("SinceLostTime", "Time since the last lost time incident"),
+	common.UoMColumn("SinceLostTimeUoM", "SinceLostTime"),
+}}
+
 func extractOpHSE_DPR10(dataSetName string, objects []WITSMLComposite) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind",
-		"IncidentCount", "SafetyCountType", "SafetyCountPeriodType", "SafetyCount",
-		"SafetyIntroCount", "SinceLostTime", "SinceLostTimeUoM"}
+	dataSet := common.NewDataSet(dataSetName, opHSESchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -960,8 +1236,7 @@ func extractOpHSE_DPR10(dataSetName string, objects []WITSMLComposite) common.Da
 				for z := 0; z < len(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE); z++ {
 					incidentCount := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].IncidentCount
 					safetyIntroCount := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].SafetyIntroCount
-					sinceLostTimeValue := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].SinceLostTime.Value
-					sinceLostTimeUoM := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].SinceLostTime.Uom
+					sinceLostTime := objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].SinceLostTime
 					for w := 0; w < len(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].Safety); w++ {
 						//safetyCount:=objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].Safety[w].SafetyCount
 						for r := 0; r < len(objects[i].ProdOperationSet.ProdOperation[x].InstallationReport[y].OperationalHSE[z].Safety[w].SafetyCount); r++ {
@@ -985,8 +1260,7 @@ func extractOpHSE_DPR10(dataSetName string, objects []WITSMLComposite) common.Da
 							row.AddStrValue(safetyPeriod)
 							row.AddIntValue(safetyCount)
 							row.AddIntValue(safetyIntroCount)
-							row.AddFloatValue(sinceLostTimeValue)
-							row.AddStrValue(sinceLostTimeUoM)
+							row.AddMeasureValue(sinceLostTime)
 							rows = append(rows, row)
 						}
 					}
@@ -9,6 +9,7 @@ package dpr20
 import (
 	"encoding/json"
 	"encoding/xml"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -24,7 +25,7 @@ func ParseProdXMLFile(inputFile string) (Objects, error) {
 	var objects Objects
 	var err error
 	var data []byte
-	if data, err = common.ReadFile(inputFile); err != nil {
+	if data, err = common.ReadXMLFile(inputFile); err != nil {
 		return objects, err
 	}
 	if err = xml.Unmarshal(data, &objects); err != nil {
@@ -69,20 +70,102 @@ func OrganiseProdFacilitiesByKind(objects []Objects) map[string][]Facility {
 }
 
 //Builds an excel output file for a given list of mprml objects
-func BuildXLSFileForProduction(path string, objects []Objects, oneFilePerSheet bool, appendTimeInName bool) error {
-	dataSets := BuildDataSets(objects)
+func BuildXLSFileForProduction(path string, objects []Objects, oneFilePerSheet bool, appendTimeInName bool, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
 	return common.CreateWorkbookFromDataSet(path, dataSets, oneFilePerSheet, appendTimeInName)
 }
 
+//BuildStreamXLSFileForProduction writes an excel output file with the streaming writer, the files are read one at
+//a time and the rows of each dataset are built and written one report or facility at a time, so memory use does not
+//grow with the number of files and reports. Datasets first found in a file are added after the datasets of the
+//files before. The row transforms are applied as the rows are written, with dataset transforms, e.g. sorting or
+//pivoting, the rows of the datasets are kept in memory until all files are read
+func BuildStreamXLSFileForProduction(path string, files []string, oneFilePerSheet bool, transforms common.StreamTransforms) error {
+	writer, err := common.NewDataSetWorkbookWriter(path, oneFilePerSheet, transforms)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(files) && err == nil; i++ {
+		var objects Objects
+		zap.S().Info("Processing xml file:", files[i])
+		if objects, err = ParseProdXMLFile(files[i]); err != nil {
+			zap.S().Errorf("Failed in parsing xml file:%s\n", err.Error())
+		} else {
+			err = WriteDataSets([]Objects{objects}, writer)
+		}
+	}
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
 func BuildCSVFileForProduction(path string, objects []Objects, discriminator string) error {
-	dataSets := BuildDataSets(objects)
-	return common.DatasetsToCsv(dataSets, path, discriminator)
+	options := common.DefaultCsvOptions()
+	options.Delimiter = discriminator
+	return BuildCSVFileForProductionWithOptions(path, objects, options, nil)
+}
+
+//BuildCSVFileForProductionWithOptions writes one csv file per dataset using the given delimiter, bom and line endings
+func BuildCSVFileForProductionWithOptions(path string, objects []Objects, options common.CsvOptions, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToCsvWithOptions(dataSets, path, options)
 }
 
-func BuildJsonFileForProduction(path string, objects []Objects) error {
+//BuildParquetFileForProduction writes one parquet file per dataset with the xml files read as source files
+//in the metadata
+func BuildParquetFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
+	var sourceFiles []string
+	for i := 0; i < len(objects); i++ {
+		sourceFiles = append(sourceFiles, objects[i].DataIdentification.FilePath)
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToParquet(dataSets, path, sourceFiles)
+}
+
+//BuildSqliteFileForProduction writes each dataset as a table in the sqlite database, rows are upserted on the
+//report identity so that converting a report again replaces its rows. Of several versions of a report the one
+//with the latest file creation date is kept, also when converted in an earlier run
+func BuildSqliteFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
+	var reportKeys []common.ReportKey
+	for i := 0; i < len(objects); i++ {
+		reportKeys = append(reportKeys, common.ReportKey{DataUUID: objects[i].DataIdentification.UUid,
+			Key: reportKey(objects[i]), Version: objects[i].DocumentInfo.FileCreationInfo.FileCreationDate.Time})
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToSqlite(dataSets, path, reportKeys)
+}
+
+//reportKey returns the natural identity of the report, the document name and the period it covers
+func reportKey(object Objects) string {
+	name := object.DocumentInfo.DocumentName.Txt
+	if name == "" {
+		name = object.DataIdentification.FileName
+	}
+	return name + "|" + object.Context.StartDate.Format(time.RFC3339) + "|" +
+		object.Context.EndDate.Format(time.RFC3339)
+}
+
+func BuildJsonFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
 	var err error
 	var data []byte
-	dataSets := BuildDataSets(objects)
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
 	if data, err = common.DatasetsToJson(dataSets); err != nil {
 		return err
 	}
@@ -107,14 +190,22 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]Objects, error) {
 	var err error
 	var objects []Objects
 	var files []string
-	var data Objects
 	start := time.Now()
 
-	if files, err = common.GetFilesWithExtension(folderPath, "*.xml"); err != nil {
+	if files, err = common.GetXMLFiles(folderPath); err != nil {
 		zap.S().Error("Failed in reading file in folder:", err.Error())
 		return objects, err
 	}
 	fileSearchTook := time.Since(start)
+	zap.S().Infof("File search took:%s", fileSearchTook)
+	return ReadProdXMLFileList2Struct(files)
+}
+
+//ReadProdXMLFileList2Struct parses the given list of xml files into a list of struct objects to be used for further processing
+func ReadProdXMLFileList2Struct(files []string) ([]Objects, error) {
+	var err error
+	var objects []Objects
+	var data Objects
 	xmlStart := time.Now()
 	for i := 0; i < len(files); i++ {
 		zap.S().Info("Processing xml file:", files[i])
@@ -126,7 +217,6 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]Objects, error) {
 	}
 	xmlParseTook := time.Since(xmlStart)
 
-	zap.S().Infof("File search took:%s", fileSearchTook)
 	zap.S().Infof("XML parse took:%s", xmlParseTook)
 	return objects, nil
 }
@@ -142,12 +232,12 @@ func BuildDataSets(objects []Objects) []common.DataSet {
 	groupedProdFacilities = OrganiseProdFacilitiesByKind(objects)
 	//build the report file info
 	datasets = append(datasets, extractFileInformation("REPORT_FILE_INFO", objects))
-	datasets = append(datasets, extractFacilityInfo("FACILITIES", groupedProdFacilities))
 	//add the document info data
 	datasets = append(datasets, extractDocumentInfo("DOCUMENT_INFO", objects))
 	//add the reportcontext
 	rContext := extractReportContext("REPORT_CONTEXT", objects)
 	datasets = append(datasets, rContext)
+	datasets = append(datasets, extractFacilityInfo("FACILITIES", groupedProdFacilities))
 	//add the actual prod volume data
 	for kind, facilities := range groupedProdFacilities {
 		zap.S().Infof("Building sheet for facility kind:%s", kind)
@@ -169,20 +259,88 @@ func BuildDataSets(objects []Objects) []common.DataSet {
 
 }
 
+//WriteDataSets writes the datasets of BuildDataSets with the writer, the rows of each dataset are built and
+//written one report or facility at a time with the report context added. The operational comments are put
+//together in one part as when built in memory. The report datasets are written before the others, so a join
+//transform of the writer has the context of the reports when the other datasets are written
+func WriteDataSets(objects []Objects, writer *common.DataSetWorkbookWriter) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	groupedProdFacilities := OrganiseProdFacilitiesByKind(objects)
+	var kinds []string
+	for kind := range groupedProdFacilities {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	//the report context is small, one row per report, and kept for adding to the rows of the other datasets
+	rContext := extractReportContext("REPORT_CONTEXT", objects)
+	withContext := func(dataset common.DataSet) common.DataSet {
+		return common.JoinDataSets(dataset, rContext, common.JoinKey)
+	}
+	var err error
+	write := func(count int, part func(i int) common.DataSet) {
+		if err == nil {
+			err = writer.WriteParts(count, part)
+		}
+	}
+	write(len(objects), func(i int) common.DataSet {
+		return withContext(extractFileInformation("REPORT_FILE_INFO", objects[i:i+1]))
+	})
+	write(len(objects), func(i int) common.DataSet {
+		return withContext(extractDocumentInfo("DOCUMENT_INFO", objects[i:i+1]))
+	})
+	write(len(objects), func(i int) common.DataSet {
+		return extractReportContext("REPORT_CONTEXT", objects[i:i+1])
+	})
+	write(len(kinds), func(i int) common.DataSet {
+		return withContext(extractFacilityInfo("FACILITIES", map[string][]Facility{kinds[i]: groupedProdFacilities[kinds[i]]}))
+	})
+	for _, kind := range kinds {
+		facilities := groupedProdFacilities[kind]
+		name := strings.ToUpper(kind)
+		write(len(facilities), func(i int) common.DataSet {
+			return withContext(extractFacilityProdData(name, facilities[i:i+1]))
+		})
+	}
+	for _, kind := range kinds {
+		facilities := groupedProdFacilities[kind]
+		name := strings.ToUpper(kind) + "_PARAMS"
+		write(len(facilities), func(i int) common.DataSet {
+			return withContext(extractFacilityParamsData(name, facilities[i:i+1]))
+		})
+	}
+	write(1, func(i int) common.DataSet {
+		return withContext(extract_OpComments_DPR20("OP_COMMENTS", extractOperationalObjects(objects)))
+	})
+	return err
+}
+
+//facilityInfoSchema declares the columns of the facilities of the reports by facility kind
+var facilityInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("UidRef", "Unique identifier of the facility"),
+	common.StrColumn("NameKind", "Kind given with the facility name"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("Installation", "Name of the installation reporting the facility"),
+}}
+
 func extractFacilityInfo(dataSetName string, groupedFacilities map[string][]Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"FacilityKind", "FacilityName", "UidRef", "NameKind", "FileName", "Installation"}
+	dataSet := common.NewDataSet(dataSetName, facilityInfoSchema)
 	for kind, facilities := range groupedFacilities {
 		for i := 0; i < len(facilities); i++ {
 			row := common.RowData{}
 			//add the top identification information so that it can be bound back to the original file
+			row.AddStrValue(facilities[i].DataIdentification.DataIdentification.UUid)
 			row.AddStrValue(kind)
 			row.AddStrValue(facilities[i].Name.Name)
 			row.AddStrValue(facilities[i].Name.UidRef)
 			row.AddStrValue(facilities[i].Name.Kind)
 			row.AddStrValue(facilities[i].DataIdentification.DataIdentification.FileName)
+			row.AddStrValue(facilities[i].DataIdentification.Installation.Name)
 			rows = append(rows, row)
 		}
 	}
@@ -190,13 +348,12 @@ func extractFacilityInfo(dataSetName string, groupedFacilities map[string][]Faci
 	return dataSet
 }
 
+//addReportContextToAllInstances adds the report context columns to the rows of the other datasets from the same
+//report, joined on DataUUID
 func addReportContextToAllInstances(rContext common.DataSet, dataSets []common.DataSet) {
 	for i := 0; i < len(dataSets); i++ {
-		if dataSets[i].Name != "REPORT_CONTEXT" {
-			dataSets[i].HeadersName = append(dataSets[i].HeadersName, rContext.HeadersName...)
-			for x := 0; x < len(dataSets[i].Rows); x++ {
-				dataSets[i].Rows[x].Columns = append(dataSets[i].Rows[x].Columns, rContext.Rows[0].Columns...)
-			}
+		if dataSets[i].Name != rContext.Name {
+			dataSets[i] = common.JoinDataSets(dataSets[i], rContext, common.JoinKey)
 		}
 	}
 }
@@ -219,13 +376,28 @@ func extractOperationalObjects(objects []Objects) []Objects {
 	return retObjects
 }
 
+//opCommentsSchema declares the columns of the operational comments of the installations
+var opCommentsSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.StrColumn("PeriodKind", "Kind of the reporting period, e.g. day or month"),
+	common.TimeColumn("DateStart", "Start date of the reporting period"),
+	common.TimeColumn("DateEnd", "End date of the reporting period"),
+	common.TimeColumn("DTimStart", "Start time of the reporting period"),
+	common.TimeColumn("DTimEnd", "End time of the reporting period"),
+	common.StrColumn("Installation_Name", "Name of the installation"),
+	common.StrColumn("Installation_Kind", "Kind of the installation"),
+	common.StrColumn("CommentType", "Type of the comment"),
+	common.TimeColumn("CommentDTimStart", "Start of the period commented"),
+	common.TimeColumn("CommentDTimEnd", "End of the period commented"),
+	common.StrColumn("Comment", "Comment"),
+}}
+
 func extract_OpComments_DPR20(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "FileName", "FilePath",
-		"PeriodKind", "DateStart", "DateEnd", "DTimStart", "DTimEnd",
-		"Installation_Name", "Installation_Kind", "CommentType", "CommentDTimStart", "CommentDTimEnd", "Comment"}
+	dataSet := common.NewDataSet(dataSetName, opCommentsSchema)
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
 		documentName := objects[i].DataIdentification.DocumentName
@@ -271,15 +443,26 @@ func extract_OpComments_DPR20(dataSetName string, objects []Objects) common.Data
 	return dataSet
 }
 
+//reportContextSchema declares the columns of the report context, one row per report
+var reportContextSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("ReportKind", "Kind of the report"),
+	common.StrColumn("ReportTitle", "Title of the report"),
+	common.StrColumn("ReportMonth", "Month reported"),
+	common.StrColumn("ReportYear", "Year reported"),
+	common.FloatColumn("ReportVersion", "Version of the report"),
+	common.StrColumn("ReportStatus", "Status of the report"),
+	common.StrColumn("ReportInstallationKind", "Kind of the installation reporting"),
+	common.StrColumn("ReportInstallationUidRef", "Unique identifier of the installation reporting"),
+	common.StrColumn("ReportInstallationName", "Name of the installation reporting"),
+	common.TimeColumn("ReportStartDate", "Start date of the report"),
+	common.TimeColumn("ReportEndDate", "End date of the report"),
+}}
+
 //Extracts data from the report context in the mprml struct objects
 func extractReportContext(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "ReportKind",
-		"ReportTitle", "ReportMonth", "ReportYear",
-		"ReportVersion", "ReportStatus", "ReportInstallationKind",
-		"ReportInstallationUidRef", "ReportInstallationName", "ReportStartDate", "ReportEndDate"}
+	dataSet := common.NewDataSet(dataSetName, reportContextSchema)
 	for i := 0; i < len(objects); i++ {
 
 		dataUUID := objects[i].DataIdentification.UUid
@@ -314,13 +497,20 @@ func extractReportContext(dataSetName string, objects []Objects) common.DataSet
 	return dataSet
 }
 
+//documentInfoSchema declares the columns of the document information and audit trail of the reports
+var documentInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.TimeColumn("DocumentDate", "Date of the report document"),
+	common.TimeColumn("AuditEventDate", "Date of the audit event"),
+	common.StrColumn("AuditEventRespParty", "Responsible party of the audit event"),
+	common.StrColumn("AuditEventComment", "Comment to the audit event"),
+}}
+
 //Extracts data from the document info in the mprml struct objects
 func extractDocumentInfo(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "DocumentDate",
-		"AuditEventDate", "AuditEventRespParty", "AuditEventComment"}
+	dataSet := common.NewDataSet(dataSetName, documentInfoSchema)
 
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
@@ -345,12 +535,19 @@ func extractDocumentInfo(dataSetName string, objects []Objects) common.DataSet {
 	return dataSet
 }
 
+//fileInformationSchema declares the columns of the report file information
+var fileInformationSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.TimeColumn("ReadTime", "Time the report file was read"),
+	common.StrColumn("DocumentName", "Name of the report document"),
+}}
+
 //Extracts data from the report files as read from the disk
 func extractFileInformation(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath", "ReadTime", "DocumentName"}
+	dataSet := common.NewDataSet(dataSetName, fileInformationSchema)
 	for i := 0; i < len(objects); i++ {
 		row := common.RowData{}
 		row.AddStrValue(objects[i].DataIdentification.UUid)
@@ -366,21 +563,51 @@ func extractFileInformation(dataSetName string, objects []Objects) common.DataSe
 
 }
 
+//facilityProdDataSchema declares the columns of the product volumes of the facilities, one dataset per facility kind
+var facilityProdDataSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("ProductKind", "Kind of the product, e.g. oil or gas"),
+	common.StrColumn("ProductName", "Name of the product"),
+	common.StrColumn("PeriodKind", "Kind of the period, e.g. day or month"),
+	common.TimeColumn("dateStart", "Start date of the period"),
+	common.TimeColumn("dateEnd", "End date of the period"),
+	common.FloatColumn("Volume", "Volume"),
+	common.UoMColumn("VolumeUoM", "Volume"),
+	common.FloatColumn("VolumeStd", "Volume at standard conditions"),
+	common.UoMColumn("VolumeStdUoM", "VolumeStd"),
+	common.FloatColumn("VolumeValue", "Volume at the given temperature and pressure"),
+	common.UoMColumn("VolumeValueUoM", "VolumeValue"),
+	common.StrColumn("VolumeValueConditions", "Pressure and temperature of the volume"),
+	common.FloatColumn("Mass", "Mass"),
+	common.UoMColumn("MassUoM", "Mass"),
+	common.FloatColumn("Density", "Density"),
+	common.UoMColumn("DensityUoM", "Density"),
+	common.StrColumn("DensityConditions", "Temperature and pressure of the density"),
+}}
+
+//wellProdDataSchema declares the columns of the product volumes of wells and wellbores, with their operating time
+var wellProdDataSchema = facilityProdDataSchema.Append(
+	common.FloatColumn("OperationTime", "Operating time of the well"),
+	common.UoMColumn("OperationTimeUoM", "OperationTime"),
+)
+
 //Extracts data from the prod volume facility setup in the MPRML files, note that this
 //function will extract entries that includes a balanceset as this will be processed by the cargo/installation processing
 func extractFacilityProdData(dataSetName string, facilities []Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FlowUid", "FlowKind", "FlowQualifier", "FlowName", "ProductKind", "ProductName", "PeriodKind", "dateStart",
-		"dateEnd", "Volume", "VolumeUoM", "VolumeStd", "VolumeStdUoM", "VolumeValue", "VolumeValueUoM", "VolumeValueConditions",
-		"Mass", "MassUoM", "Density", "DensityUoM", "DensityConditions"}
+	dataSet := common.NewDataSet(dataSetName, facilityProdDataSchema)
 	if strings.ToLower(dataSetName) == "wellbore" || strings.ToLower(dataSetName) == "well" {
-		dataSet.HeadersName = append(dataSet.HeadersName, []string{"OperationTime", "OperationTimeUoM"}...)
-
+		dataSet = common.NewDataSet(dataSetName, wellProdDataSchema)
 	}
 	for i := 0; i < len(facilities); i++ {
 
@@ -408,18 +635,15 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 						row.AddStrValue(facilities[i].Flow[x].Qualifier) //add flow qualifier
 						row.AddStrValue(facilities[i].Flow[x].Name)      //add flow name
 						//extract period and product data
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Kind)                         // add product kind
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Name)                         //add product kind
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Kind)               //add period kind
-						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateStart.Time)    //add date start
-						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)      //add date end
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Value) //add volume only value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Uom)     //add volume uom
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Value)  //add volumestd value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Uom)      //add volumestd uom
-
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Value) //add volume value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Uom)     //add volume uom
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Kind)                      // add product kind
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Name)                      //add product kind
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Kind)            //add period kind
+						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateStart.Time) //add date start
+						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)   //add date end
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly)  //add volume only value
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd)   //add volumestd value
+
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume) //add volume value
 						row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Value, 'f', 0, 64) +
 							facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Uom + "/" +
 							strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Value, 'f', 0, 64) +
@@ -427,17 +651,15 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 						//just exlude data here if it is a wellbore
 						if facilities[i].Name.Kind != "wellbore" && facilities[i].Name.Kind != "well" {
 							//just include non empty mass elements
-							if facilities[i].Flow[x].Product[z].Period[s].Mass.Uom != "" {
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Value) //add mass
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Uom)     //add mass uom
+							if facilities[i].Flow[x].Product[z].Period[s].Mass.IsPresent() {
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Mass) //add mass
 							} else {
 								row.AddEmptyColumn()
 								row.AddEmptyColumn()
 							}
 							//just include non empty density elements
-							if facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom != "" {
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Value.float64) //add density value
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom)             //add density uom
+							if facilities[i].Flow[x].Product[z].Period[s].Density.Density.IsPresent() {
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density) //add density value
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Uom + "/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Value, 'f', 0, 64) +
@@ -454,8 +676,7 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
 							//add the operation time
-							row.AddFloatValue(facilities[i].OperationTime.Value.float64)
-							row.AddStrValue(facilities[i].OperationTime.Uom)
+							row.AddMeasureValue(facilities[i].OperationTime)
 						}
 
 						rows = append(rows, row)
@@ -469,15 +690,26 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 	return dataSet
 }
 
+//facilityParamsSchema declares the columns of the parameters of the facilities, one dataset per facility kind
+var facilityParamsSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("ParameterName", "Name of the parameter set"),
+	common.TimeColumn("DTimStart", "Start of the parameter value"),
+	common.TimeColumn("DTimEnd", "End of the parameter value"),
+	common.FloatColumn("MeasureValue", "Value of the parameter"),
+	common.UoMColumn("MeasureValueUoM", "MeasureValue"),
+}}
+
 //extractFacilityParams extracts facility parameters
 func extractFacilityParamsData(dataSetName string, facilities []Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"ParameterName", "DTimStart", "DTimEnd", "MeasureValue", "MeasureValueUoM"}
+	dataSet := common.NewDataSet(dataSetName, facilityParamsSchema)
 	for i := 0; i < len(facilities); i++ {
 		for x := 0; x < len(facilities[i].ParameterSets); x++ {
 			for y := 0; y < len(facilities[i].ParameterSets[x].Parameters); y++ {
@@ -495,8 +727,7 @@ func extractFacilityParamsData(dataSetName string, facilities []Facility) common
 				row.AddStrValue(facilities[i].ParameterSets[x].Name)
 				row.AddTimeValue(facilities[i].ParameterSets[x].Parameters[y].StartDate.Time)
 				row.AddTimeValue(facilities[i].ParameterSets[x].Parameters[y].EndDate.Time)
-				row.AddFloatValue(facilities[i].ParameterSets[x].Parameters[y].MeasureValue.Value)
-				row.AddStrValue(facilities[i].ParameterSets[x].Parameters[y].MeasureValue.Uom)
+				row.AddMeasureValue(facilities[i].ParameterSets[x].Parameters[y].MeasureValue)
 				rows = append(rows, row)
 			}
 		}
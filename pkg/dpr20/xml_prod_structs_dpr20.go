@@ -3,18 +3,11 @@ package dpr20
 import (
 	"encoding/xml"
 
-	"strconv"
 	"strings"
 	"time"
-)
-
-const xsdDateTimeLayout = time.RFC3339
-const xsdDateLayout = "2006-01-02"
-const xsdDateTimeLayoutNoTimeZone = "2006-01-02T15:04:05"
 
-type xsdDouble struct {
-	float64
-}
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
 
 //unmarshals a xsd date element in the form of 2006-01-02 (YYYY-MM-DD)
 type xsdDate struct {
@@ -26,33 +19,12 @@ type xsdDateTime struct {
 	time.Time
 }
 
-//unmarshal function to handle xsd double parsing,
-// xsd double e.g. according to IEEE standard allows for lexical space that needs to be handled
-func (c *xsdDouble) UnmarshalText(text []byte) error {
-	var v string
-	var err error
-	var number float64
-	v = string(text)
-
-	parse := strings.Trim(v, " ")
-	//fmt.Printf("Handled value in:%s,parsed to:%s", v, parse)
-	if number, err = strconv.ParseFloat(parse, 64); err != nil {
-		return err
-	}
-	*c = xsdDouble{number}
-	return err
-}
-
 //unmarshal function to handle xsd date time parsing
 func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
-	var parse time.Time
-	var err error
 	d.DecodeElement(&v, &start)
-	if parse, err = time.Parse(xsdDateTimeLayout, v); err != nil {
-		//try to parse it without timezone..
-		parse, err = time.ParseInLocation(xsdDateTimeLayoutNoTimeZone, v, time.Now().Location())
-	}
+	//a time without time zone is read in the time zone set for the conversion, UTC by default
+	parse, _ := common.ParseXsdDateTime(v)
 	*c = xsdDateTime{parse}
 	return nil
 }
@@ -60,7 +32,7 @@ func (c *xsdDateTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 func (c *xsdDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	parse, _ := time.ParseInLocation(xsdDateLayout, v, time.Now().Location())
+	parse, _ := common.ParseXsdDate(v)
 	*c = xsdDate{parse}
 	return nil
 }
@@ -327,15 +299,11 @@ type Event struct {
 	Kind    string   `xml:"kind"`
 }
 
-type Value struct {
-	Value float64 `xml:",chardata"`
-	Uom   string  `xml:"uom,attr"`
-}
+//Value is a measure element, missing if the element has no value
+type Value = common.Measure
 
-type DoubleValue struct {
-	Value xsdDouble `xml:",chardata"`
-	Uom   string    `xml:"uom,attr"`
-}
+//DoubleValue is a measure element read as a xsd double, missing if the element has no value
+type DoubleValue = common.Measure
 
 type NameElement struct {
 	Txt          string `xml:",chardata"`
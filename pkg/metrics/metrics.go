@@ -0,0 +1,216 @@
+//Package metrics registers counters, gauges and histograms with labels in a Prometheus client registry and serves
+//them in the Prometheus text exposition format together with a health check
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+//DefaultBuckets are the histogram buckets in seconds used for latencies
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+//Registry holds a set of metrics in a Prometheus registry
+type Registry struct {
+	registry *prometheus.Registry
+}
+
+//DefaultRegistry is the registry used by the packages in this repository
+var DefaultRegistry = NewRegistry()
+
+//NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{registry: prometheus.NewRegistry()}
+}
+
+//register adds the collector to the registry, a failed registration, e.g. a name used twice, is logged and the
+//metric is still updated but not served
+func (r *Registry) register(name string, collector prometheus.Collector) {
+	if err := r.registry.Register(collector); err != nil {
+		zap.S().Errorf("Failed in registering metric:%s, error:%s", name, err.Error())
+	}
+}
+
+//errorLog logs the errors of the metrics handler through zap
+type errorLog struct{}
+
+func (errorLog) Println(v ...interface{}) {
+	zap.S().Error(append([]interface{}{"Failed in serving metrics:"}, v...)...)
+}
+
+//Handler returns a http handler serving the metrics, errors in collecting a metric are logged and the other
+//metrics are still served
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{ErrorLog: errorLog{},
+		ErrorHandling: promhttp.ContinueOnError})
+}
+
+//NewServeMux returns a mux serving the metrics of the registry on /metrics and a health check on /healthz
+//answering ok as long as the process is running
+func NewServeMux(r *Registry) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+//logLabelError logs a metric update dropped as the label values do not match the label names, metrics must never
+//stop a download
+func logLabelError(name string, labelValues []string, err error) {
+	zap.S().Errorf("Dropped update of metric:%s with label values:%v, error:%s", name, labelValues, err.Error())
+}
+
+//CounterVec is a counter per combination of label values
+type CounterVec struct {
+	name       string
+	labelNames []string
+	vec        *prometheus.CounterVec
+}
+
+//NewCounterVec creates and registers a counter with the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{name: name, labelNames: labelNames,
+		vec: prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames)}
+	r.register(name, c.vec)
+	return c
+}
+
+//Add adds the value, that must not be negative, to the counter for the label values, a negative value or a
+//wrong number of label values is logged and dropped
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		logLabelError(c.name, labelValues, fmt.Errorf("counter can not be decreased by:%v", value))
+		return
+	}
+	counter, err := c.vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		logLabelError(c.name, labelValues, err)
+		return
+	}
+	counter.Add(value)
+}
+
+//Inc increases the counter for the label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+//Value returns the current value of the counter for the label values, 0 for unknown label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	return collectedValue(c.vec, c.labelNames, labelValues).GetCounter().GetValue()
+}
+
+//GaugeVec is a gauge per combination of label values
+type GaugeVec struct {
+	name       string
+	labelNames []string
+	vec        *prometheus.GaugeVec
+}
+
+//NewGaugeVec creates and registers a gauge with the given label names
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{name: name, labelNames: labelNames,
+		vec: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames)}
+	r.register(name, g.vec)
+	return g
+}
+
+//Set sets the gauge for the label values, a wrong number of label values is logged and dropped
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	gauge, err := g.vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		logLabelError(g.name, labelValues, err)
+		return
+	}
+	gauge.Set(value)
+}
+
+//SetToCurrentTime sets the gauge for the label values to the current unix time in seconds
+func (g *GaugeVec) SetToCurrentTime(labelValues ...string) {
+	g.Set(float64(time.Now().UnixNano())/1e9, labelValues...)
+}
+
+//Value returns the current value of the gauge for the label values, 0 for unknown label values
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	return collectedValue(g.vec, g.labelNames, labelValues).GetGauge().GetValue()
+}
+
+//HistogramVec is a histogram per combination of label values
+type HistogramVec struct {
+	name string
+	vec  *prometheus.HistogramVec
+}
+
+//NewHistogramVec creates and registers a histogram with the given upper bucket bounds, in any order, and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{name: name, vec: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help,
+		Buckets: sorted}, labelNames)}
+	r.register(name, h.vec)
+	return h
+}
+
+//Observe adds a value to the histogram for the label values, a wrong number of label values is logged and dropped
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	histogram, err := h.vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		logLabelError(h.name, labelValues, err)
+		return
+	}
+	histogram.Observe(value)
+}
+
+//ObserveSince adds the number of seconds since start to the histogram for the label values
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+//collectedValue returns the current value of the metric of the collector with the label values, an empty value if
+//there is none. The metrics are collected so that reading a value does not add a series for the label values
+func collectedValue(collector prometheus.Collector, labelNames []string, labelValues []string) *dto.Metric {
+	found := &dto.Metric{}
+	if len(labelNames) != len(labelValues) {
+		return found
+	}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	for m := range ch {
+		value := &dto.Metric{}
+		if err := m.Write(value); err != nil {
+			zap.S().Errorf("Failed in reading metric value:%s", err.Error())
+			continue
+		}
+		if hasLabelValues(value, labelNames, labelValues) {
+			found = value
+		}
+	}
+	return found
+}
+
+//hasLabelValues returns true if the metric has the label values for the label names
+func hasLabelValues(m *dto.Metric, labelNames []string, labelValues []string) bool {
+	pairs := make(map[string]string)
+	for _, pair := range m.GetLabel() {
+		pairs[pair.GetName()] = pair.GetValue()
+	}
+	for i := 0; i < len(labelNames); i++ {
+		if value, exists := pairs[labelNames[i]]; !exists || value != labelValues[i] {
+			return false
+		}
+	}
+	return true
+}
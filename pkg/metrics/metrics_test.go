@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//scrape returns the metrics served by the registry
+func scrape(t *testing.T, r *Registry) string {
+	server := httptest.NewServer(NewServeMux(r))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type:%s", resp.Header.Get("Content-Type"))
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestServeMetrics(t *testing.T) {
+	r := NewRegistry()
+	files := r.NewCounterVec("test_files_total", "Files downloaded", "report_type")
+	files.Inc("DPR10")
+	files.Add(2, "DPR20")
+	files.Add(-1, "DPR20")
+	last := r.NewGaugeVec("test_last_success_timestamp_seconds", "Last success", "block")
+	last.Set(1500, "dpr:JOHAN \"SVERDRUP\"")
+	latency := r.NewHistogramVec("test_duration_seconds", "Durations", []float64{1, 0.5}, "result")
+	latency.Observe(0.25, "success")
+	latency.Observe(0.75, "success")
+	latency.Observe(2, "success")
+	body := scrape(t, r)
+	for _, expected := range []string{
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{result="success",le="0.5"} 1`,
+		`test_duration_seconds_bucket{result="success",le="1"} 2`,
+		`test_duration_seconds_bucket{result="success",le="+Inf"} 3`,
+		`test_duration_seconds_sum{result="success"} 3`,
+		`test_duration_seconds_count{result="success"} 3`,
+		"# HELP test_files_total Files downloaded",
+		"# TYPE test_files_total counter",
+		`test_files_total{report_type="DPR10"} 1`,
+		`test_files_total{report_type="DPR20"} 2`,
+		"# TYPE test_last_success_timestamp_seconds gauge",
+		`test_last_success_timestamp_seconds{block="dpr:JOHAN \"SVERDRUP\""} 1500`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected:%s in the metrics, got:\n%s", expected, body)
+		}
+	}
+	if files.Value("DPR20") != 2 || last.Value("dpr:JOHAN \"SVERDRUP\"") != 1500 {
+		t.Errorf("Expected counter value 2 and gauge value 1500, got:%f,%f", files.Value("DPR20"),
+			last.Value("dpr:JOHAN \"SVERDRUP\""))
+	}
+	//reading a value does not add a series
+	if files.Value("DPR99") != 0 || strings.Contains(scrape(t, r), "DPR99") {
+		t.Errorf("Expected no series added by reading the value of unknown label values")
+	}
+}
+
+func TestWrongNumberOfLabelsIsDropped(t *testing.T) {
+	r := NewRegistry()
+	files := r.NewCounterVec("test_files_total", "Files downloaded", "report_type")
+	last := r.NewGaugeVec("test_last_success_timestamp_seconds", "Last success", "block", "report_type")
+	latency := r.NewHistogramVec("test_duration_seconds", "Durations", DefaultBuckets, "result")
+	files.Inc()
+	files.Add(1, "DPR10", "extra")
+	last.SetToCurrentTime("dpr:JOHAN SVERDRUP")
+	latency.Observe(1)
+	files.Inc("DPR10")
+	if files.Value("DPR10") != 1 {
+		t.Errorf("Expected only the update with the right labels counted, got:%f", files.Value("DPR10"))
+	}
+	if body := scrape(t, r); strings.Contains(body, "test_last_success") || strings.Contains(body, "test_duration") {
+		t.Errorf("Expected the updates with wrong labels dropped, got:\n%s", body)
+	}
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_runs_total", "Runs", "result").Inc("success")
+	duplicate := r.NewCounterVec("test_runs_total", "Runs", "result")
+	duplicate.Inc("failed")
+	if body := scrape(t, r); !strings.Contains(body, `test_runs_total{result="success"} 1`) ||
+		strings.Contains(body, "failed") {
+		t.Errorf("Expected the first registration served, got:\n%s", body)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := httptest.NewServer(NewServeMux(NewRegistry()))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "ok\n" {
+		t.Errorf("Unexpected health check response, status:%d, body:%s", resp.StatusCode, string(body))
+	}
+}
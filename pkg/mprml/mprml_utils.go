@@ -9,6 +9,7 @@ package mprml
 import (
 	"encoding/json"
 	"encoding/xml"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +23,7 @@ func ParseProdXMLFile(inputFile string) (Objects, error) {
 	var objects Objects
 	var err error
 	var data []byte
-	if data, err = common.ReadFile(inputFile); err != nil {
+	if data, err = common.ReadXMLFile(inputFile); err != nil {
 		return objects, err
 	}
 	if err = xml.Unmarshal(data, &objects); err != nil {
@@ -64,20 +65,101 @@ func OrganiseFacilitiesByKind(objects []Objects) map[string][]Facility {
 }
 
 //Builds an excel output file for a given list of mprml objects
-func BuildXLSFileForProduction(path string, objects []Objects, oneFilePerSheet bool, appendTimeInName bool) error {
-	dataSets := BuildDataSets(objects)
+func BuildXLSFileForProduction(path string, objects []Objects, oneFilePerSheet bool, appendTimeInName bool, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
 	return common.CreateWorkbookFromDataSet(path, dataSets, oneFilePerSheet, appendTimeInName)
 }
 
+//BuildStreamXLSFileForProduction writes an excel output file with the streaming writer, the files are read one at
+//a time and the rows of each dataset are built and written one report or facility at a time, so memory use does not
+//grow with the number of files and reports. Datasets first found in a file are added after the datasets of the
+//files before. The row transforms are applied as the rows are written, with dataset transforms, e.g. sorting or
+//pivoting, the rows of the datasets are kept in memory until all files are read
+func BuildStreamXLSFileForProduction(path string, files []string, oneFilePerSheet bool, transforms common.StreamTransforms) error {
+	writer, err := common.NewDataSetWorkbookWriter(path, oneFilePerSheet, transforms)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(files) && err == nil; i++ {
+		var objects Objects
+		zap.S().Info("Processing xml file:", files[i])
+		if objects, err = ParseProdXMLFile(files[i]); err != nil {
+			zap.S().Errorf("Failed in parsing xml file:%s\n", err.Error())
+		} else {
+			err = WriteDataSets([]Objects{objects}, writer)
+		}
+	}
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
 func BuildCSVFileForProduction(path string, objects []Objects, discriminator string) error {
-	dataSets := BuildDataSets(objects)
-	return common.DatasetsToCsv(dataSets, path, discriminator)
+	options := common.DefaultCsvOptions()
+	options.Delimiter = discriminator
+	return BuildCSVFileForProductionWithOptions(path, objects, options, nil)
+}
+
+//BuildCSVFileForProductionWithOptions writes one csv file per dataset using the given delimiter, bom and line endings
+func BuildCSVFileForProductionWithOptions(path string, objects []Objects, options common.CsvOptions, transform common.DataSetTransform) error {
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToCsvWithOptions(dataSets, path, options)
+}
+
+//BuildParquetFileForProduction writes one parquet file per dataset with the xml files read as source files
+//in the metadata
+func BuildParquetFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
+	var sourceFiles []string
+	for i := 0; i < len(objects); i++ {
+		sourceFiles = append(sourceFiles, objects[i].DataIdentification.FilePath)
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToParquet(dataSets, path, sourceFiles)
 }
 
-func BuildJsonFileForProduction(path string, objects []Objects) error {
+//BuildSqliteFileForProduction writes each dataset as a table in the sqlite database, rows are upserted on the
+//report identity so that converting a report again replaces its rows. Of several versions of a report the one
+//with the latest file creation date is kept, also when converted in an earlier run
+func BuildSqliteFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
+	var reportKeys []common.ReportKey
+	for i := 0; i < len(objects); i++ {
+		reportKeys = append(reportKeys, common.ReportKey{DataUUID: objects[i].DataIdentification.UUid,
+			Key: reportKey(objects[i]), Version: objects[i].DocumentInfo.FileCreationInfo.FileCreationDate.Time})
+	}
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
+	return common.DatasetsToSqlite(dataSets, path, reportKeys)
+}
+
+//reportKey returns the natural identity of the report, the document name and the month it covers
+func reportKey(object Objects) string {
+	name := object.DocumentInfo.DocumentName.Txt
+	if name == "" {
+		name = object.DataIdentification.FileName
+	}
+	return name + "|" + object.Context.Year + "-" + object.Context.Month
+}
+
+func BuildJsonFileForProduction(path string, objects []Objects, transform common.DataSetTransform) error {
 	var err error
 	var data []byte
-	dataSets := BuildDataSets(objects)
+	dataSets, err := common.TransformDataSets(BuildDataSets(objects), transform)
+	if err != nil {
+		return err
+	}
 	if data, err = common.DatasetsToJson(dataSets); err != nil {
 		return err
 	}
@@ -102,14 +184,22 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]Objects, error) {
 	var err error
 	var objects []Objects
 	var files []string
-	var data Objects
 	start := time.Now()
 
-	if files, err = common.GetFilesWithExtension(folderPath, "*.xml"); err != nil {
+	if files, err = common.GetXMLFiles(folderPath); err != nil {
 		zap.S().Error("Failed in reading file in folder:", err.Error())
 		return objects, err
 	}
 	fileSearchTook := time.Since(start)
+	zap.S().Infof("File search took:%s", fileSearchTook)
+	return ReadProdXMLFileList2Struct(files)
+}
+
+//ReadProdXMLFileList2Struct parses the given list of xml files into a list of struct objects to be used for further processing
+func ReadProdXMLFileList2Struct(files []string) ([]Objects, error) {
+	var err error
+	var objects []Objects
+	var data Objects
 	xmlStart := time.Now()
 	for i := 0; i < len(files); i++ {
 		zap.S().Info("Processing xml file:", files[i])
@@ -121,7 +211,6 @@ func ReadProdXMLFiles2Struct(folderPath string) ([]Objects, error) {
 	}
 	xmlParseTook := time.Since(xmlStart)
 
-	zap.S().Infof("File search took:%s", fileSearchTook)
 	zap.S().Infof("XML parse took:%s", xmlParseTook)
 	return objects, nil
 }
@@ -158,15 +247,70 @@ func BuildDataSets(objects []Objects) []common.DataSet {
 
 }
 
+//WriteDataSets writes the datasets of BuildDataSets with the writer, the rows of each dataset are built and
+//written one report or facility at a time. The report datasets are written before the others, so a join transform
+//of the writer has the context of the reports when the other datasets are written
+func WriteDataSets(objects []Objects, writer *common.DataSetWorkbookWriter) error {
+	groupedFacilities := OrganiseFacilitiesByKind(objects)
+	var kinds []string
+	var flatFacilities []Facility
+	for kind := range groupedFacilities {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		flatFacilities = append(flatFacilities, groupedFacilities[kind]...)
+	}
+	var err error
+	write := func(count int, part func(i int) common.DataSet) {
+		if err == nil {
+			err = writer.WriteParts(count, part)
+		}
+	}
+	write(len(objects), func(i int) common.DataSet {
+		return extractFileInformation("REPORT_FILE_INFO", objects[i:i+1])
+	})
+	write(len(objects), func(i int) common.DataSet {
+		return extractDocumentInfo("DOCUMENT_INFO", objects[i:i+1])
+	})
+	write(len(objects), func(i int) common.DataSet {
+		return extractReportContext("REPORT_CONTEXT", objects[i:i+1])
+	})
+	for _, kind := range kinds {
+		zap.S().Infof("Building sheet for facility kind:%s", kind)
+		facilities := groupedFacilities[kind]
+		name := strings.ToUpper(kind)
+		write(len(facilities), func(i int) common.DataSet {
+			return extractFacilityProdData(name, facilities[i:i+1])
+		})
+	}
+	write(len(flatFacilities), func(i int) common.DataSet {
+		return extractCargoData("CARGO", flatFacilities[i:i+1])
+	})
+	write(len(flatFacilities), func(i int) common.DataSet {
+		return extractInventoryData("INVENTORY", flatFacilities[i:i+1])
+	})
+	return err
+}
+
+//reportContextSchema declares the columns of the report context, one row per report
+var reportContextSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("ReportKind", "Kind of the report"),
+	common.StrColumn("ReportTitle", "Title of the report"),
+	common.StrColumn("ReportMonth", "Month reported"),
+	common.StrColumn("ReportYear", "Year reported"),
+	common.FloatColumn("ReportVersion", "Version of the report"),
+	common.StrColumn("ReportStatus", "Status of the report"),
+	common.StrColumn("ReportInstallationKind", "Kind of the installation reporting"),
+	common.StrColumn("ReportInstallationUidRef", "Unique identifier of the installation reporting"),
+	common.StrColumn("ReportInstallationName", "Name of the installation reporting"),
+}}
+
 //Extracts data from the report context in the mprml struct objects
 func extractReportContext(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "ReportKind",
-		"ReportTitle", "ReportMonth", "ReportYear",
-		"ReportVersion", "ReportStatus", "ReportInstallationKind",
-		"ReportInstallationUidRef", "ReportInstallationName"}
+	dataSet := common.NewDataSet(dataSetName, reportContextSchema)
 	for i := 0; i < len(objects); i++ {
 
 		dataUUID := objects[i].DataIdentification.UUid
@@ -197,13 +341,20 @@ func extractReportContext(dataSetName string, objects []Objects) common.DataSet
 	return dataSet
 }
 
+//documentInfoSchema declares the columns of the document information and audit trail of the reports
+var documentInfoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("DocumentName", "Name of the report document"),
+	common.TimeColumn("DocumentDate", "Date of the report document"),
+	common.TimeColumn("AuditEventDate", "Date of the audit event"),
+	common.StrColumn("AuditEventRespParty", "Responsible party of the audit event"),
+	common.StrColumn("AuditEventComment", "Comment to the audit event"),
+}}
+
 //Extracts data from the document info in the mprml struct objects
 func extractDocumentInfo(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "DocumentName", "DocumentDate",
-		"AuditEventDate", "AuditEventRespParty", "AuditEventComment"}
+	dataSet := common.NewDataSet(dataSetName, documentInfoSchema)
 
 	for i := 0; i < len(objects); i++ {
 		dataUUID := objects[i].DataIdentification.UUid
@@ -228,12 +379,19 @@ func extractDocumentInfo(dataSetName string, objects []Objects) common.DataSet {
 	return dataSet
 }
 
+//fileInformationSchema declares the columns of the report file information
+var fileInformationSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("FileName", "Name of the report file"),
+	common.StrColumn("FilePath", "Path of the report file"),
+	common.TimeColumn("ReadTime", "Time the report file was read"),
+	common.StrColumn("DocumentName", "Name of the report document"),
+}}
+
 //Extracts data from the report files as read from the disk
 func extractFileInformation(dataSetName string, objects []Objects) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "FileName", "FilePath", "ReadTime", "DocumentName"}
+	dataSet := common.NewDataSet(dataSetName, fileInformationSchema)
 	for i := 0; i < len(objects); i++ {
 		row := common.RowData{}
 		row.AddStrValue(objects[i].DataIdentification.UUid)
@@ -249,21 +407,51 @@ func extractFileInformation(dataSetName string, objects []Objects) common.DataSe
 
 }
 
+//facilityProdDataSchema declares the columns of the product volumes of the facilities, one dataset per facility kind
+var facilityProdDataSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("ProductKind", "Kind of the product, e.g. oil or gas"),
+	common.StrColumn("ProductName", "Name of the product"),
+	common.StrColumn("PeriodKind", "Kind of the period, e.g. day or month"),
+	common.TimeColumn("dateStart", "Start date of the period"),
+	common.TimeColumn("dateEnd", "End date of the period"),
+	common.FloatColumn("Volume", "Volume"),
+	common.UoMColumn("VolumeUoM", "Volume"),
+	common.FloatColumn("VolumeStd", "Volume at standard conditions"),
+	common.UoMColumn("VolumeStdUoM", "VolumeStd"),
+	common.FloatColumn("VolumeValue", "Volume at the given temperature and pressure"),
+	common.UoMColumn("VolumeValueUoM", "VolumeValue"),
+	common.StrColumn("VolumeValueConditions", "Pressure and temperature of the volume"),
+	common.FloatColumn("Mass", "Mass"),
+	common.UoMColumn("MassUoM", "Mass"),
+	common.FloatColumn("Density", "Density"),
+	common.UoMColumn("DensityUoM", "Density"),
+	common.StrColumn("DensityConditions", "Temperature and pressure of the density"),
+}}
+
+//wellboreProdDataSchema declares the columns of the product volumes of wellbores, with their operating time
+var wellboreProdDataSchema = facilityProdDataSchema.Append(
+	common.FloatColumn("OperationTime", "Operating time of the wellbore"),
+	common.UoMColumn("OperationTimeUoM", "OperationTime"),
+)
+
 //Extracts data from the prod volume facility setup in the MPRML files, note that this
 //function will extract entries that includes a balanceset as this will be processed by the cargo/installation processing
 func extractFacilityProdData(dataSetName string, facilities []Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = dataSetName
-	dataSet.HeadersName = []string{"DataUUID", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FlowUid", "FlowKind", "FlowQualifier", "FlowName", "ProductKind", "ProductName", "PeriodKind", "dateStart",
-		"dateEnd", "Volume", "VolumeUoM", "VolumeStd", "VolumeStdUoM", "VolumeValue", "VolumeValueUoM", "VolumeValueConditions",
-		"Mass", "MassUoM", "Density", "DensityUoM", "DensityConditions"}
+	dataSet := common.NewDataSet(dataSetName, facilityProdDataSchema)
 	if strings.ToLower(dataSetName) == "wellbore" {
-		dataSet.HeadersName = append(dataSet.HeadersName, []string{"OperationTime", "OperationTimeUoM"}...)
-
+		dataSet = common.NewDataSet(dataSetName, wellboreProdDataSchema)
 	}
 	for i := 0; i < len(facilities); i++ {
 
@@ -291,18 +479,15 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 						row.AddStrValue(facilities[i].Flow[x].Qualifier) //add flow qualifier
 						row.AddStrValue(facilities[i].Flow[x].Name)      //add flow name
 						//extract period and product data
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Kind)                         // add product kind
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Name)                         //add product kind
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Kind)               //add period kind
-						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateStart.Time)    //add date start
-						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)      //add date end
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Value) //add volume only value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly.Uom)     //add volume uom
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Value)  //add volumestd value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd.Uom)      //add volumestd uom
-
-						row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Value) //add volume value
-						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Uom)     //add volume uom
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Kind)                      // add product kind
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Name)                      //add product kind
+						row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Kind)            //add period kind
+						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateStart.Time) //add date start
+						row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)   //add date end
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeOnly)  //add volume only value
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].VolumeStd)   //add volumestd value
+
+						row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume) //add volume value
 						row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Value, 'f', 0, 64) +
 							facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Uom + "/" +
 							strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Value, 'f', 0, 64) +
@@ -310,17 +495,15 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 						//just exlude data here if it is a wellbore
 						if facilities[i].Name.Kind != "wellbore" {
 							//just include non empty mass elements
-							if facilities[i].Flow[x].Product[z].Period[s].Mass.Uom != "" {
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Value) //add mass
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Uom)     //add mass uom
+							if facilities[i].Flow[x].Product[z].Period[s].Mass.IsPresent() {
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Mass) //add mass
 							} else {
 								row.AddEmptyColumn()
 								row.AddEmptyColumn()
 							}
 							//just include non empty density elements
-							if facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom != "" {
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Value.float64) //add density value
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom)             //add density uom
+							if facilities[i].Flow[x].Product[z].Period[s].Density.Density.IsPresent() {
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density) //add density value
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Uom + "/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Value, 'f', 0, 64) +
@@ -337,8 +520,7 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 							row.AddEmptyColumn()
 							row.AddEmptyColumn()
 							//add the operation time
-							row.AddFloatValue(facilities[i].OperationTime.Value.float64)
-							row.AddStrValue(facilities[i].OperationTime.Uom)
+							row.AddMeasureValue(facilities[i].OperationTime)
 						}
 
 						rows = append(rows, row)
@@ -352,25 +534,54 @@ func extractFacilityProdData(dataSetName string, facilities []Facility) common.D
 	return dataSet
 }
 
+//cargoSchema declares the columns of the cargo lifted from the facilities with the share of each owner
+var cargoSchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("ProductKind", "Kind of the product, e.g. oil or gas"),
+	common.StrColumn("ProductName", "Name of the product"),
+	common.StrColumn("PeriodKind", "Kind of the period, e.g. day or month"),
+	common.TimeColumn("dateStart", "Start date of the period"),
+	common.TimeColumn("dateEnd", "End date of the period"),
+	common.StrColumn("BalanceSetKind", "Kind of the balance set"),
+	common.StrColumn("CargoNumber", "Number of the cargo"),
+	common.StrColumn("Destination", "Destination of the cargo"),
+	common.StrColumn("Country", "Country of the destination"),
+	common.StrColumn("EventKind", "Kind of the event, e.g. bill of lading"),
+	common.TimeColumn("EventDate", "Date of the event"),
+	common.FloatColumn("TotalVolume", "Total volume of the cargo"),
+	common.UoMColumn("TotalVolumeUoM", "TotalVolume"),
+	common.StrColumn("TotalVolumeConditions", "Temperature and pressure of the total volume"),
+	common.FloatColumn("TotalMass", "Total mass of the cargo"),
+	common.UoMColumn("TotalMassUoM", "TotalMass"),
+	common.FloatColumn("TotalDensity", "Density of the cargo"),
+	common.UoMColumn("TotalDensityUoM", "TotalDensity"),
+	common.StrColumn("TotalDensityConditions", "Temperature and pressure of the density"),
+	common.StrColumn("Owner", "Owner of the share"),
+	common.FloatColumn("Share", "Share of the owner"),
+	common.UoMColumn("ShareUoM", "Share"),
+	common.FloatColumn("OwnerVolume", "Volume of the owner"),
+	common.UoMColumn("OwnerVolumeUoM", "OwnerVolume"),
+	common.StrColumn("OwnerVolumeConditions", "Temperature and pressure of the volume of the owner"),
+	common.FloatColumn("OwnerMass", "Mass of the owner"),
+	common.UoMColumn("OwnerMassUoM", "OwnerMass"),
+}}
+
 //Extracts data from the report files under the product volume facility with a flow
 //kind of hydrocarbon accounting and that has associated balancesets. This should
 //be equal to just extracting the cargo information in the given set of files
 func extractCargoData(datasetName string, facilities []Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = datasetName
-	dataSet.HeadersName = []string{"DataUUID", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FlowUid", "FlowKind", "FlowQualifier", "FlowName", "ProductKind",
-		"ProductName", "PeriodKind", "dateStart",
-		"dateEnd", "BalanceSetKind", "CargoNumber", "Destination", "Country",
-		"EventKind", "EventDate",
-		"TotalVolume", "TotalVolumeUoM", "TotalVolumeConditions",
-		"TotalMass", "TotalMassUoM",
-		"TotalDensity", "TotalDensityUoM", "TotalDensityConditions",
-		"Owner", "Share", "ShareUoM", "OwnerVolume", "OwnerVolumeUoM", "OwnerVolumeConditions",
-		"OwnerMass", "OwnerMassUoM"}
+	dataSet := common.NewDataSet(datasetName, cargoSchema)
 	for i := 0; i < len(facilities); i++ {
 
 		for x := 0; x < len(facilities[i].Flow); x++ {
@@ -413,36 +624,30 @@ func extractCargoData(datasetName string, facilities []Facility) common.DataSet
 								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Event.Kind)          //add event kind
 								row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Event.Date.Time)    //add event date
 
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Volume.Value) //add total volume
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Volume.Uom)     //add volume uom
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Volume) //add total volume
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Temp.Value, 'f', 0, 64) +
 
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Volume.Pressure.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Mass.Value)
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Mass.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Density.Value.float64)
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Density.Uom)
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Mass)
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Density)
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Density.Pressure.Uom)
 
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Owner)                 //add the owner
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share.Value)         //add owner share
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share.Uom)             //add share uom
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume.Value) //add share volume
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume.Uom)     //add share volume uom
+								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Owner)             //add the owner
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share)         //add owner share
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume) //add share volume
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Pressure.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass.Value) //add share mass
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass.Uom)     //add share mass
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass) //add share mass
 								rows = append(rows, row)
 							}
 						}
@@ -457,25 +662,52 @@ func extractCargoData(datasetName string, facilities []Facility) common.DataSet
 	return dataSet
 }
 
+//inventorySchema declares the columns of the closing inventory of the facilities with the share of each owner
+var inventorySchema = common.Schema{Columns: []common.ColumnSchema{
+	common.StrColumn("DataUUID", "Identifier given to the report when read").Required(),
+	common.StrColumn("OwningInstallationKind", "Kind of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationUid", "Unique identifier of the installation reporting the facility"),
+	common.StrColumn("OwningInstallationName", "Name of the installation reporting the facility"),
+	common.StrColumn("FacilityKind", "Kind of the facility"),
+	common.StrColumn("FacilityUID", "Unique identifier of the facility"),
+	common.StrColumn("FacilityName", "Name of the facility"),
+	common.StrColumn("FlowUid", "Unique identifier of the flow"),
+	common.StrColumn("FlowKind", "Kind of the flow, e.g. production or injection"),
+	common.StrColumn("FlowQualifier", "Qualifier of the flow, e.g. measured or allocated"),
+	common.StrColumn("FlowName", "Name of the flow"),
+	common.StrColumn("ProductKind", "Kind of the product, e.g. oil or gas"),
+	common.StrColumn("ProductName", "Name of the product"),
+	common.StrColumn("PeriodKind", "Kind of the period, e.g. day or month"),
+	common.TimeColumn("dateStart", "Start date of the period"),
+	common.TimeColumn("dateEnd", "End date of the period"),
+	common.StrColumn("BalanceSetKind", "Kind of the balance set"),
+	common.FloatColumn("TotalVolume", "Total volume of the inventory"),
+	common.UoMColumn("TotalVolumeUoM", "TotalVolume"),
+	common.StrColumn("TotalVolumeConditions", "Temperature and pressure of the total volume"),
+	common.FloatColumn("TotalMass", "Total mass of the inventory"),
+	common.UoMColumn("TotalMassUoM", "TotalMass"),
+	common.FloatColumn("TotalDensity", "Density of the inventory"),
+	common.UoMColumn("TotalDensityUoM", "TotalDensity"),
+	common.StrColumn("TotalDensityConditions", "Temperature and pressure of the density"),
+	common.StrColumn("Owner", "Owner of the share"),
+	common.FloatColumn("Share", "Share of the owner"),
+	common.UoMColumn("ShareUoM", "Share"),
+	common.FloatColumn("OwnerVolume", "Volume of the owner"),
+	common.UoMColumn("OwnerVolumeUoM", "OwnerVolume"),
+	common.StrColumn("OwnerVolumeConditions", "Temperature and pressure of the volume of the owner"),
+	common.FloatColumn("OwnerMass", "Mass of the owner"),
+	common.UoMColumn("OwnerMassUoM", "OwnerMass"),
+	common.FloatColumn("OwnerDensity", "Density of the owner"),
+	common.UoMColumn("OwnerDensityUoM", "OwnerDensity"),
+	common.StrColumn("OwnerDensityConditions", "Temperature and pressure of the density of the owner"),
+}}
+
 //Extracts data from the report files from the prodVolume section with a flow kind type of inventory
 //and where you have an associated balance set. This should be equal to extrating the closing inventory
 //breakdown as associated with each owner of the stock
 func extractInventoryData(datasetName string, facilities []Facility) common.DataSet {
 	var rows []common.RowData
-	dataSet := common.DataSet{}
-	dataSet.Name = datasetName
-	dataSet.HeadersName = []string{"DataUUID", "OwningInstallationKind",
-		"OwningInstallationUid", "OwningInstallationName",
-		"FacilityKind", "FacilityUID", "FacilityName",
-		"FlowUid", "FlowKind", "FlowQualifier", "FlowName", "ProductKind",
-		"ProductName", "PeriodKind", "dateStart",
-		"dateEnd", "BalanceSetKind",
-		"TotalVolume", "TotalVolumeUoM", "TotalVolumeConditions",
-		"TotalMass", "TotalMassUoM",
-		"TotalDensity", "TotalDensityUoM", "TotalDensityConditions",
-		"Owner", "Share", "ShareUoM", "OwnerVolume", "OwnerVolumeUoM", "OwnerVolumeConditions",
-		"OwnerMass", "OwnerMassUoM",
-		"OwnerDensity", "OwnerDensityUoM", "OwnerDensityConditions"}
+	dataSet := common.NewDataSet(datasetName, inventorySchema)
 	for i := 0; i < len(facilities); i++ {
 
 		for x := 0; x < len(facilities[i].Flow); x++ {
@@ -512,39 +744,32 @@ func extractInventoryData(datasetName string, facilities []Facility) common.Data
 								row.AddTimeValue(facilities[i].Flow[x].Product[z].Period[s].DateEnd.Time)       //add date end
 								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].Kind) //add the balanceset kind
 								//add the total volume, mass and density
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Value) //add total volume
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume.Uom)     //add volume uom
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Volume.Volume) //add total volume
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Value, 'f', 0, 64) +
 
 									facilities[i].Flow[x].Product[z].Period[s].Volume.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].Volume.Pressure.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Value)
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Mass.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Value.float64)
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density.Uom)
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Mass)
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].Density.Density)
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].Density.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].Density.Pressure.Uom)
 								//process the owner splits
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Owner)                 //add the owner
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share.Value)         //add owner share
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share.Uom)             //add share uom
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume.Value) //add share volume
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume.Uom)     //add share volume uom
+								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Owner)             //add the owner
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Share)         //add owner share
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Volume) //add share volume
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Temp.Uom +
 									"/" +
 									strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Pressure.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Volume.Pressure.Uom)
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass.Value) //add share mass
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass.Uom)     //add share mass
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Mass) //add share mass
 
-								row.AddFloatValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Density.Density.Value.float64) //add share density
-								row.AddStrValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Density.Density.Uom)             //add share density uom
+								row.AddMeasureValue(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Density.Density) //add share density
 								row.AddStrValue(strconv.FormatFloat(facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Density.Temp.Value, 'f', 0, 64) +
 									facilities[i].Flow[x].Product[z].Period[s].BalanceSets[w].BalanceDetails[y].Density.Temp.Uom +
 									"/" +
@@ -0,0 +1,241 @@
+//Package notify sends notifications of failed downloads and conversions to a webhook, e.g. a Teams or Slack
+//incoming webhook, or by email through a smtp server
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	WebhookFormatJSON  = "json"
+	WebhookFormatTeams = "teams"
+	WebhookFormatSlack = "slack"
+)
+
+//MaxFailures is the max number of failures included in a notification, the rest are summarised as a count
+var MaxFailures = 20
+
+//Config is the notify section of a configuration file, a webhook and/or email can be configured
+type Config struct {
+	Webhook WebhookConfig `xml:"webhook"`
+	Email   EmailConfig   `xml:"email"`
+}
+
+//WebhookConfig holds the webhook url to post failures to and the payload format
+type WebhookConfig struct {
+	URL    string `xml:"url"`
+	Format string `xml:"format"` //json (default), teams or slack
+}
+
+//EmailConfig holds the smtp server and addresses to send failures to
+type EmailConfig struct {
+	Host     string   `xml:"host"`
+	Port     int      `xml:"port"` //defaults to 25
+	Username string   `xml:"username"`
+	Password string   `xml:"password"`
+	From     string   `xml:"from"`
+	To       []string `xml:"to"`
+	Subject  string   `xml:"subject"` //defaults to the title of the notification
+}
+
+//Notification holds what failed, sent as the json payload for the json webhook format
+type Notification struct {
+	Program  string    `json:"program"`
+	Title    string    `json:"title"`
+	Host     string    `json:"host"`
+	Time     time.Time `json:"time"`
+	Details  []string  `json:"details,omitempty"`
+	Failures []string  `json:"failures"`
+}
+
+//NewNotification creates a notification for the program with the host name and current time set
+func NewNotification(program, title string) Notification {
+	host, _ := os.Hostname()
+	return Notification{Program: program, Title: title, Host: host, Time: time.Now()}
+}
+
+//Enabled returns true if a webhook or email is configured
+func (cnfg Config) Enabled() bool {
+	return cnfg.Webhook.URL != "" || cnfg.Email.Host != ""
+}
+
+//Verify checks the notify configuration
+func Verify(cnfg Config) error {
+	switch strings.ToLower(cnfg.Webhook.Format) {
+	case "", WebhookFormatJSON, WebhookFormatTeams, WebhookFormatSlack:
+	default:
+		return fmt.Errorf("Unsupported webhook format:%s, supported formats:json,teams,slack", cnfg.Webhook.Format)
+	}
+	if cnfg.Email.Host != "" {
+		if cnfg.Email.From == "" || len(cnfg.Email.To) == 0 {
+			return errors.New("Email notification requires both from and to addresses")
+		}
+	}
+	return nil
+}
+
+//ReadConfigFile reads the notify section from a xml file, the file can either have notify as its root element
+//or contain a notify element below the root, e.g. the download configuration file
+func ReadConfigFile(path string) (Config, error) {
+	var wrapper struct {
+		Notify Config `xml:"notify"`
+	}
+	var cnfg Config
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cnfg, err
+	}
+	if err = xml.Unmarshal(data, &wrapper); err != nil {
+		return cnfg, err
+	}
+	cnfg = wrapper.Notify
+	if !cnfg.Enabled() {
+		if err = xml.Unmarshal(data, &cnfg); err != nil {
+			return cnfg, err
+		}
+	}
+	if !cnfg.Enabled() {
+		return cnfg, fmt.Errorf("No webhook url or email host found in notify configuration:%s", path)
+	}
+	return cnfg, Verify(cnfg)
+}
+
+//Text returns the notification as plain text, one line per detail and failure
+func (n Notification) Text() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s on %s at %s", n.Program, n.Host, n.Time.Format(time.RFC3339)))
+	lines = append(lines, n.Details...)
+	lines = append(lines, "Failures:")
+	for i := 0; i < len(n.Failures) && i < MaxFailures; i++ {
+		lines = append(lines, "- "+n.Failures[i])
+	}
+	if len(n.Failures) > MaxFailures {
+		lines = append(lines, fmt.Sprintf("- ...and %d more, please check the logs", len(n.Failures)-MaxFailures))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//BuildWebhookPayload builds the payload to post in the given format, teams gives a message card and slack
+//a text message, json gives the notification with a text field so it can also be shown by teams and slack
+func BuildWebhookPayload(format string, n Notification) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case WebhookFormatTeams:
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "http://schema.org/extensions",
+			"themeColor": "D70000",
+			"summary":    n.Title,
+			"title":      n.Title,
+			//teams needs two line breaks for a new line
+			"text": strings.Replace(n.Text(), "\n", "\n\n", -1),
+		})
+	case WebhookFormatSlack:
+		return json.Marshal(map[string]string{"text": "*" + n.Title + "*\n" + n.Text()})
+	case "", WebhookFormatJSON:
+		if len(n.Failures) > MaxFailures {
+			n.Failures = n.Failures[:MaxFailures]
+		}
+		return json.Marshal(struct {
+			Notification
+			Text string `json:"text"`
+		}{n, n.Title + "\n" + n.Text()})
+	default:
+		return nil, fmt.Errorf("Unsupported webhook format:%s", format)
+	}
+}
+
+//SendWebhook posts the notification to the webhook url
+func SendWebhook(ctx context.Context, cnfg WebhookConfig, n Notification) error {
+	var resp *resty.Response
+	payload, err := BuildWebhookPayload(cnfg.Format, n)
+	if err != nil {
+		return err
+	}
+	client := resty.New()
+	client.SetTimeout(time.Duration(30 * time.Second))
+	if resp, err = client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(payload).
+		Post(cnfg.URL); err != nil {
+		return fmt.Errorf("Failed in posting notification to webhook:%s", err.Error())
+	}
+	if resp.IsError() {
+		return fmt.Errorf("Failed in posting notification to webhook, code:%d,status:%s,body:%s",
+			resp.StatusCode(), resp.Status(), string(resp.Body()))
+	}
+	return nil
+}
+
+//buildEmail builds the email message with headers for the notification
+func buildEmail(cnfg EmailConfig, n Notification) []byte {
+	subject := cnfg.Subject
+	if subject == "" {
+		subject = n.Title
+	}
+	headers := []string{
+		"From: " + cnfg.From,
+		"To: " + strings.Join(cnfg.To, ", "),
+		"Subject: " + subject,
+		"Date: " + n.Time.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	body := strings.Replace(n.Text(), "\n", "\r\n", -1)
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body + "\r\n")
+}
+
+//SendEmail sends the notification as an email through the smtp server, authenticating if a username is set
+func SendEmail(cnfg EmailConfig, n Notification) error {
+	var auth smtp.Auth
+	port := cnfg.Port
+	if port == 0 {
+		port = 25
+	}
+	if cnfg.Username != "" {
+		auth = smtp.PlainAuth("", cnfg.Username, cnfg.Password, cnfg.Host)
+	}
+	addr := net.JoinHostPort(cnfg.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, cnfg.From, cnfg.To, buildEmail(cnfg, n)); err != nil {
+		return fmt.Errorf("Failed in sending notification email through:%s,error:%s", addr, err.Error())
+	}
+	return nil
+}
+
+//Send sends the notification to the webhook and email configured, returns the errors of the ones that failed
+func Send(ctx context.Context, cnfg Config, n Notification) []error {
+	var errorList []error
+	if cnfg.Webhook.URL != "" {
+		if err := SendWebhook(ctx, cnfg.Webhook, n); err != nil {
+			errorList = append(errorList, err)
+		}
+	}
+	if cnfg.Email.Host != "" {
+		if err := SendEmail(cnfg.Email, n); err != nil {
+			errorList = append(errorList, err)
+		}
+	}
+	return errorList
+}
+
+//SendWithConfigFile reads the notify configuration from the xml file and sends the notification
+func SendWithConfigFile(ctx context.Context, path string, n Notification) []error {
+	cnfg, err := ReadConfigFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("Failed in reading notify configuration:%s,error:%s", path, err.Error())}
+	}
+	return Send(ctx, cnfg, n)
+}
@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testNotification() Notification {
+	n := NewNotification("test", "Download failed")
+	n.Details = []string{"Files downloaded:1, failed:2"}
+	n.Failures = []string{"DPR20 TEST:Failed in download of file 1", "DPR20 TEST:Failed in download of file 2"}
+	return n
+}
+
+//startSMTPServer starts a minimal smtp server accepting one mail, the message data is sent on the returned channel
+func startSMTPServer(t *testing.T) (string, int, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed in starting smtp listener:%s", err.Error())
+	}
+	messages := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		fmt.Fprintf(conn, "220 localhost test smtp\r\n")
+		var data []string
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if inData {
+				if line == "." {
+					inData = false
+					messages <- strings.Join(data, "\n")
+					fmt.Fprintf(conn, "250 queued\r\n")
+				} else {
+					data = append(data, line)
+				}
+				continue
+			}
+			switch {
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				fmt.Fprintf(conn, "354 go ahead\r\n")
+			case strings.HasPrefix(line, "QUIT"):
+				fmt.Fprintf(conn, "221 bye\r\n")
+				return
+			default:
+				fmt.Fprintf(conn, "250 OK\r\n")
+			}
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNo, _ := strconv.Atoi(port)
+	return host, portNo, messages
+}
+
+func TestSendWebhookFormats(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = nil
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Webhook payload is not json:%s", string(body))
+		}
+	}))
+	defer server.Close()
+	n := testNotification()
+	if err := SendWebhook(context.Background(), WebhookConfig{URL: server.URL}, n); err != nil {
+		t.Fatalf("Failed in sending webhook:%s", err.Error())
+	}
+	if received["program"] != "test" || len(received["failures"].([]interface{})) != 2 {
+		t.Errorf("Unexpected json payload:%v", received)
+	}
+	if !strings.Contains(received["text"].(string), "Failed in download of file 2") {
+		t.Errorf("Expected failures in text of json payload:%v", received["text"])
+	}
+	if err := SendWebhook(context.Background(), WebhookConfig{URL: server.URL, Format: "teams"}, n); err != nil {
+		t.Fatalf("Failed in sending webhook:%s", err.Error())
+	}
+	if received["@type"] != "MessageCard" || received["title"] != "Download failed" {
+		t.Errorf("Unexpected teams payload:%v", received)
+	}
+	if err := SendWebhook(context.Background(), WebhookConfig{URL: server.URL, Format: "slack"}, n); err != nil {
+		t.Fatalf("Failed in sending webhook:%s", err.Error())
+	}
+	if !strings.HasPrefix(received["text"].(string), "*Download failed*") {
+		t.Errorf("Unexpected slack payload:%v", received)
+	}
+}
+
+func TestSendWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid webhook", http.StatusBadRequest)
+	}))
+	defer server.Close()
+	if err := SendWebhook(context.Background(), WebhookConfig{URL: server.URL}, testNotification()); err == nil {
+		t.Errorf("Expected error for webhook answering bad request")
+	}
+}
+
+func TestTextTruncatesFailures(t *testing.T) {
+	n := testNotification()
+	n.Failures = nil
+	for i := 0; i < MaxFailures+5; i++ {
+		n.Failures = append(n.Failures, fmt.Sprintf("failure %d", i))
+	}
+	text := n.Text()
+	if strings.Contains(text, fmt.Sprintf("failure %d", MaxFailures)) || !strings.Contains(text, "...and 5 more") {
+		t.Errorf("Expected failures to be truncated:%s", text)
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	host, port, messages := startSMTPServer(t)
+	cnfg := EmailConfig{Host: host, Port: port, From: "downloader@example.com",
+		To: []string{"ops@example.com", "dev@example.com"}}
+	if err := SendEmail(cnfg, testNotification()); err != nil {
+		t.Fatalf("Failed in sending email:%s", err.Error())
+	}
+	message := <-messages
+	for _, expected := range []string{"Subject: Download failed", "To: ops@example.com, dev@example.com",
+		"- DPR20 TEST:Failed in download of file 1"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected %s in email:%s", expected, message)
+		}
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatalf("Failed in creating temp folder:%s", err.Error())
+	}
+	defer os.RemoveAll(folder)
+	nested := filepath.Join(folder, "download.xml")
+	ioutil.WriteFile(nested, []byte(`<subsurface><notify><webhook><url>http://localhost/hook</url>
+		<format>teams</format></webhook></notify></subsurface>`), 0644)
+	if cnfg, err := ReadConfigFile(nested); err != nil || cnfg.Webhook.Format != "teams" {
+		t.Errorf("Expected notify section to be read from download configuration, got:%v,%v", cnfg, err)
+	}
+	root := filepath.Join(folder, "notify.xml")
+	ioutil.WriteFile(root, []byte(`<notify><email><host>localhost</host><from>a@example.com</from>
+		<to>b@example.com</to><to>c@example.com</to></email></notify>`), 0644)
+	if cnfg, err := ReadConfigFile(root); err != nil || len(cnfg.Email.To) != 2 {
+		t.Errorf("Expected notify root element to be read, got:%v,%v", cnfg, err)
+	}
+	invalid := filepath.Join(folder, "invalid.xml")
+	ioutil.WriteFile(invalid, []byte(`<notify><webhook><url>http://localhost</url><format>xml</format></webhook></notify>`), 0644)
+	if _, err := ReadConfigFile(invalid); err == nil {
+		t.Errorf("Expected error for unsupported webhook format")
+	}
+}
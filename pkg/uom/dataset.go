@@ -0,0 +1,149 @@
+package uom
+
+import (
+	"strings"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+	"go.uber.org/zap"
+)
+
+//unitPair holds the indexes of a value column and its unit of measure column
+type unitPair struct {
+	value, unit int
+}
+
+//unitPairs returns the value and unit of measure column pairs of the headers, a unit column is named as its
+//value column followed by UoM or _UoM, e.g. Volume and VolumeUoM or MD and MD_UoM, and other unit columns,
+//e.g. chokeUoM, are paired with the column before them as the measures are added as a value followed by its unit
+func unitPairs(headers []string) []unitPair {
+	isUnitColumn := func(header string) bool {
+		return strings.HasSuffix(strings.ToLower(header), "uom")
+	}
+	var pairs []unitPair
+	for i := 0; i < len(headers); i++ {
+		if !isUnitColumn(headers[i]) {
+			continue
+		}
+		name := strings.TrimSuffix(headers[i][:len(headers[i])-3], "_")
+		value := -1
+		for x := 0; x < len(headers) && name != ""; x++ {
+			if x != i && strings.EqualFold(headers[x], name) {
+				value = x
+				break
+			}
+		}
+		if value < 0 && i > 0 && !isUnitColumn(headers[i-1]) {
+			value = i - 1
+		}
+		if value >= 0 {
+			pairs = append(pairs, unitPair{value: value, unit: i})
+		}
+	}
+	return pairs
+}
+
+//productColumn returns the index of the ProductKind column giving the product of the values of a row, -1 if
+//the headers have none
+func productColumn(headers []string) int {
+	for i := 0; i < len(headers); i++ {
+		if strings.EqualFold(headers[i], "ProductKind") {
+			return i
+		}
+	}
+	return -1
+}
+
+//Transform returns the transform converting the values of the value and unit of measure column pairs of the
+//datasets to the target units, nil for a nil converter
+func (converter *Converter) Transform() common.DataSetTransform {
+	if converter == nil {
+		return nil
+	}
+	unknown := make(map[string]bool)
+	return func(datasets []common.DataSet) ([]common.DataSet, error) {
+		result := make([]common.DataSet, len(datasets))
+		for i := 0; i < len(datasets); i++ {
+			result[i] = converter.convertDataSet(datasets[i], unknown)
+		}
+		return result, nil
+	}
+}
+
+//ConvertDataSet returns the dataset with the values of its value and unit of measure column pairs converted to
+//the target units and the unit columns set to the target units, values of unknown units are left as they are
+func (converter *Converter) ConvertDataSet(dataset common.DataSet) common.DataSet {
+	return converter.convertDataSet(dataset, make(map[string]bool))
+}
+
+//convertDataSet converts the dataset, logging each unknown unit once by adding it to unknown
+func (converter *Converter) convertDataSet(dataset common.DataSet, unknown map[string]bool) common.DataSet {
+	pairs := unitPairs(dataset.HeadersName)
+	if len(pairs) == 0 {
+		return dataset
+	}
+	converted := dataset
+	converted.Rows = make([]common.RowData, len(dataset.Rows))
+	copy(converted.Rows, dataset.Rows)
+	copied := make([]bool, len(dataset.Rows))
+	convertedUnits := make(map[int]string)
+	product := productColumn(dataset.HeadersName)
+	count := 0
+	for _, pair := range pairs {
+		for x := 0; x < len(converted.Rows); x++ {
+			columns := converted.Rows[x].Columns
+			if pair.unit >= len(columns) || pair.value >= len(columns) || common.IsNullValue(columns[pair.unit]) {
+				continue
+			}
+			symbol := strings.TrimSpace(columns[pair.unit].StrVal)
+			productKind := ""
+			if product >= 0 && product < len(columns) && !common.IsNullValue(columns[product]) {
+				productKind = columns[product].StrVal
+			}
+			target, found := converter.TargetUnitProduct(symbol, productKind)
+			if !found {
+				if _, known := converter.table.Lookup(symbol); !known && symbol != "" && !unknown[symbol] {
+					unknown[symbol] = true
+					zap.S().Warnf("Unknown unit of measure:%s in column:%s of dataset:%s, values are not converted",
+						symbol, dataset.HeadersName[pair.unit], dataset.Name)
+				}
+				continue
+			}
+			value := columns[pair.value]
+			if target == symbol || !(common.IsNullValue(value) || value.IsFloat || value.IsInt) {
+				continue
+			}
+			if !copied[x] {
+				converted.Rows[x].Columns = append([]common.ColumnData{}, columns...)
+				columns = converted.Rows[x].Columns
+				copied[x] = true
+			}
+			if !common.IsNullValue(value) {
+				number := value.FloatVal
+				if value.IsInt {
+					number = float64(value.IntVal)
+				}
+				number, _, _ = converter.ConvertProduct(number, symbol, productKind)
+				columns[pair.value] = common.ColumnData{FloatVal: number, IsFloat: true}
+				count++
+			}
+			columns[pair.unit] = common.ColumnData{StrVal: target, IsStr: true}
+			if unit, found := convertedUnits[pair.value]; found && unit != target {
+				target = ""
+			}
+			convertedUnits[pair.value] = target
+		}
+	}
+	if len(convertedUnits) > 0 && dataset.HasSchema() {
+		converted.Schema = common.Schema{Columns: append([]common.ColumnSchema{}, dataset.Schema.Columns...)}
+		for index, target := range convertedUnits {
+			if index < len(converted.Schema.Columns) {
+				converted.Schema.Columns[index].Type = common.ColumnTypeFloat
+				converted.Schema.Columns[index].Unit = target
+			}
+		}
+	}
+	if count > 0 {
+		zap.S().Debugf("Converted %d values of dataset:%s to the target units", count, dataset.Name)
+	}
+	return converted
+}
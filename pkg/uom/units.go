@@ -0,0 +1,157 @@
+package uom
+
+import "strings"
+
+//Dimensions of the units, values are only converted between units of the same dimension
+const (
+	DimensionLength        = "length"
+	DimensionVolume        = "volume"
+	DimensionStdVolume     = "standard volume"
+	DimensionPressure      = "pressure"
+	DimensionGaugePressure = "gauge pressure"
+	DimensionTemperature   = "temperature"
+	DimensionMass          = "mass"
+	DimensionDensity       = "density"
+	DimensionTime          = "time"
+)
+
+//Product classes, a target unit can be given for the values of the products of a class as the dimension
+//followed by a slash and the class, e.g. standard volume/gas, and is used before the target unit of the dimension
+const (
+	ProductGas    = "gas"
+	ProductLiquid = "liquid"
+)
+
+//ProductClass returns the class of a product kind of the reports, gas for kinds naming gas, e.g. gas or
+//gas - dry, liquid for other kinds, e.g. oil, condensate or water, and empty for an empty kind
+func ProductClass(productKind string) string {
+	productKind = strings.ToLower(strings.TrimSpace(productKind))
+	if productKind == "" {
+		return ""
+	}
+	if strings.Contains(productKind, ProductGas) {
+		return ProductGas
+	}
+	return ProductLiquid
+}
+
+//productTarget returns the target key of a dimension for the products of a class
+func productTarget(dimension string, class string) string {
+	return dimension + "/" + class
+}
+
+const (
+	feetToMetre             = 0.3048
+	barrelToCubicMetre      = 0.158987294928
+	cubicFootToCubicMetre   = 0.028316846592
+	poundToKilogram         = 0.45359237
+	poundForceInchToPascal  = 6894.757293168
+	gallonUSToCubicMetre    = 0.003785411784
+	poundCubicFootToDensity = poundToKilogram / cubicFootToCubicMetre
+)
+
+//units is the conversion table, each unit is converted to the base unit of its dimension as
+//base = (A + B*value) / (C + D*value) as in the Energistics unit of measure dictionary
+var units = []Unit{
+	{Symbol: "m", Dimension: DimensionLength, A: 0, B: 1, C: 1},
+	{Symbol: "km", Dimension: DimensionLength, A: 0, B: 1000, C: 1},
+	{Symbol: "cm", Dimension: DimensionLength, A: 0, B: 0.01, C: 1},
+	{Symbol: "mm", Dimension: DimensionLength, A: 0, B: 0.001, C: 1},
+	{Symbol: "ft", Dimension: DimensionLength, A: 0, B: feetToMetre, C: 1},
+	{Symbol: "ftUS", Dimension: DimensionLength, A: 0, B: 1200, C: 3937},
+	{Symbol: "in", Dimension: DimensionLength, A: 0, B: 0.0254, C: 1},
+	{Symbol: "yd", Dimension: DimensionLength, A: 0, B: 0.9144, C: 1},
+
+	{Symbol: "m3", Dimension: DimensionVolume, A: 0, B: 1, C: 1},
+	{Symbol: "L", Dimension: DimensionVolume, A: 0, B: 0.001, C: 1},
+	{Symbol: "dm3", Dimension: DimensionVolume, A: 0, B: 0.001, C: 1},
+	{Symbol: "cm3", Dimension: DimensionVolume, A: 0, B: 0.000001, C: 1},
+	{Symbol: "bbl", Dimension: DimensionVolume, A: 0, B: barrelToCubicMetre, C: 1},
+	{Symbol: "ft3", Dimension: DimensionVolume, A: 0, B: cubicFootToCubicMetre, C: 1},
+	{Symbol: "galUS", Dimension: DimensionVolume, A: 0, B: gallonUSToCubicMetre, C: 1},
+
+	{Symbol: "Sm3", Dimension: DimensionStdVolume, A: 0, B: 1, C: 1},
+	{Symbol: "kSm3", Dimension: DimensionStdVolume, A: 0, B: 1000, C: 1},
+	{Symbol: "MSm3", Dimension: DimensionStdVolume, A: 0, B: 1000000, C: 1},
+	{Symbol: "GSm3", Dimension: DimensionStdVolume, A: 0, B: 1000000000, C: 1},
+	{Symbol: "stb", Dimension: DimensionStdVolume, A: 0, B: barrelToCubicMetre, C: 1},
+	{Symbol: "Mstb", Dimension: DimensionStdVolume, A: 0, B: 1000 * barrelToCubicMetre, C: 1},
+	{Symbol: "scf", Dimension: DimensionStdVolume, A: 0, B: cubicFootToCubicMetre, C: 1},
+	{Symbol: "Mscf", Dimension: DimensionStdVolume, A: 0, B: 1000 * cubicFootToCubicMetre, C: 1},
+	{Symbol: "MMscf", Dimension: DimensionStdVolume, A: 0, B: 1000000 * cubicFootToCubicMetre, C: 1},
+
+	{Symbol: "Pa", Dimension: DimensionPressure, A: 0, B: 1, C: 1},
+	{Symbol: "kPa", Dimension: DimensionPressure, A: 0, B: 1000, C: 1},
+	{Symbol: "MPa", Dimension: DimensionPressure, A: 0, B: 1000000, C: 1},
+	{Symbol: "bar", Dimension: DimensionPressure, A: 0, B: 100000, C: 1},
+	{Symbol: "psi", Dimension: DimensionPressure, A: 0, B: poundForceInchToPascal, C: 1},
+	{Symbol: "atm", Dimension: DimensionPressure, A: 0, B: 101325, C: 1},
+
+	{Symbol: "kPag", Dimension: DimensionGaugePressure, A: 0, B: 1000, C: 1},
+	{Symbol: "barg", Dimension: DimensionGaugePressure, A: 0, B: 100000, C: 1},
+	{Symbol: "psig", Dimension: DimensionGaugePressure, A: 0, B: poundForceInchToPascal, C: 1},
+
+	{Symbol: "K", Dimension: DimensionTemperature, A: 0, B: 1, C: 1},
+	{Symbol: "degC", Dimension: DimensionTemperature, A: 273.15, B: 1, C: 1},
+	{Symbol: "degF", Dimension: DimensionTemperature, A: 2298.35, B: 5, C: 9},
+	{Symbol: "degR", Dimension: DimensionTemperature, A: 0, B: 5, C: 9},
+
+	{Symbol: "kg", Dimension: DimensionMass, A: 0, B: 1, C: 1},
+	{Symbol: "g", Dimension: DimensionMass, A: 0, B: 0.001, C: 1},
+	{Symbol: "t", Dimension: DimensionMass, A: 0, B: 1000, C: 1},
+	{Symbol: "lbm", Dimension: DimensionMass, A: 0, B: poundToKilogram, C: 1},
+
+	{Symbol: "kg/m3", Dimension: DimensionDensity, A: 0, B: 1, C: 1},
+	{Symbol: "g/cm3", Dimension: DimensionDensity, A: 0, B: 1000, C: 1},
+	{Symbol: "g/L", Dimension: DimensionDensity, A: 0, B: 1, C: 1},
+	{Symbol: "lbm/ft3", Dimension: DimensionDensity, A: 0, B: poundCubicFootToDensity, C: 1},
+	{Symbol: "lbm/galUS", Dimension: DimensionDensity, A: 0, B: poundToKilogram / gallonUSToCubicMetre, C: 1},
+
+	{Symbol: "s", Dimension: DimensionTime, A: 0, B: 1, C: 1},
+	{Symbol: "min", Dimension: DimensionTime, A: 0, B: 60, C: 1},
+	{Symbol: "h", Dimension: DimensionTime, A: 0, B: 3600, C: 1},
+	{Symbol: "d", Dimension: DimensionTime, A: 0, B: 86400, C: 1},
+}
+
+//aliases holds other spellings of the units found in reports by their lower case spelling
+var aliases = map[string]string{
+	"meter": "m",
+	"metre": "m",
+	"feet":  "ft",
+	"bara":  "bar",
+	"psia":  "psi",
+	"°c":    "degC",
+	"°f":    "degF",
+	"gal":   "galUS",
+	"ppg":   "lbm/galUS",
+	"kg/l":  "g/cm3",
+	"hr":    "h",
+	"day":   "d",
+}
+
+//systems holds the target unit of each dimension of the named unit systems, SI uses bar and degC as in the
+//reports on the Norwegian continental shelf rather than Pa and K. Field units give standard volumes of liquids
+//in stb and of gas in Mscf, so standard volumes of an unknown product are not converted to field units
+var systems = map[string]map[string]string{
+	"si": {
+		DimensionLength:        "m",
+		DimensionVolume:        "m3",
+		DimensionStdVolume:     "Sm3",
+		DimensionPressure:      "bar",
+		DimensionGaugePressure: "barg",
+		DimensionTemperature:   "degC",
+		DimensionMass:          "kg",
+		DimensionDensity:       "kg/m3",
+	},
+	"field": {
+		DimensionLength:                          "ft",
+		DimensionVolume:                          "bbl",
+		DimensionStdVolume + "/" + ProductLiquid: "stb",
+		DimensionStdVolume + "/" + ProductGas:    "Mscf",
+		DimensionPressure:                        "psi",
+		DimensionGaugePressure:                   "psig",
+		DimensionTemperature:                     "degF",
+		DimensionMass:                            "lbm",
+		DimensionDensity:                         "lbm/galUS",
+	},
+}
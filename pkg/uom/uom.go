@@ -0,0 +1,244 @@
+//Package uom converts values between units of measure using a conversion table in the style of the Energistics
+//unit of measure dictionary, and normalises the value and unit of measure column pairs of datasets to a target
+//unit system
+package uom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+//Unit is a unit of measure of a dimension, a value of the unit is converted to the base unit of the dimension as
+//(A + B*value) / (C + D*value)
+type Unit struct {
+	Symbol    string  `json:"symbol"`
+	Dimension string  `json:"dimension"`
+	A         float64 `json:"a"`
+	B         float64 `json:"b"`
+	C         float64 `json:"c"`
+	D         float64 `json:"d"`
+}
+
+//ToBase returns the value converted to the base unit of the dimension
+func (unit Unit) ToBase(value float64) float64 {
+	return (unit.A + unit.B*value) / (unit.C + unit.D*value)
+}
+
+//FromBase returns the value in the base unit of the dimension converted to the unit
+func (unit Unit) FromBase(value float64) float64 {
+	return (unit.A - unit.C*value) / (unit.D*value - unit.B)
+}
+
+//verify checks that the unit has a symbol and a dimension and can be converted
+func (unit Unit) verify() error {
+	if strings.TrimSpace(unit.Symbol) == "" || strings.TrimSpace(unit.Dimension) == "" {
+		return fmt.Errorf("Unit requires a symbol and a dimension, got symbol:%s dimension:%s", unit.Symbol, unit.Dimension)
+	}
+	if unit.B == 0 || (unit.C == 0 && unit.D == 0) {
+		return fmt.Errorf("Unit:%s requires b and c or d different from 0 to be converted", unit.Symbol)
+	}
+	return nil
+}
+
+//Table holds units by symbol
+type Table struct {
+	units map[string]Unit
+}
+
+//NewTable returns a table with the default units
+func NewTable() *Table {
+	table := &Table{units: make(map[string]Unit)}
+	for i := 0; i < len(units); i++ {
+		table.units[units[i].Symbol] = units[i]
+	}
+	return table
+}
+
+//Add adds or replaces a unit in the table
+func (table *Table) Add(unit Unit) error {
+	if err := unit.verify(); err != nil {
+		return err
+	}
+	table.units[unit.Symbol] = unit
+	return nil
+}
+
+//Lookup returns the unit of the symbol, the symbol is matched exactly and then as a known alias, other case
+//variants are not matched as e.g. Mm and mm are different units
+func (table *Table) Lookup(symbol string) (Unit, bool) {
+	symbol = strings.TrimSpace(symbol)
+	if unit, found := table.units[symbol]; found {
+		return unit, true
+	}
+	if alias, found := aliases[strings.ToLower(symbol)]; found {
+		if unit, found := table.units[alias]; found {
+			return unit, true
+		}
+	}
+	return Unit{}, false
+}
+
+//Convert returns the value of the from unit converted to the to unit
+func (table *Table) Convert(value float64, from string, to string) (float64, error) {
+	fromUnit, found := table.Lookup(from)
+	if !found {
+		return value, fmt.Errorf("Unknown unit of measure:%s", from)
+	}
+	toUnit, found := table.Lookup(to)
+	if !found {
+		return value, fmt.Errorf("Unknown unit of measure:%s", to)
+	}
+	if fromUnit.Dimension != toUnit.Dimension {
+		return value, fmt.Errorf("Cannot convert from:%s (%s) to:%s (%s)", from, fromUnit.Dimension, to, toUnit.Dimension)
+	}
+	if fromUnit.Symbol == toUnit.Symbol {
+		return value, nil
+	}
+	return toUnit.FromBase(fromUnit.ToBase(value)), nil
+}
+
+//Convert returns the value of the from unit converted to the to unit using the default units
+func Convert(value float64, from string, to string) (float64, error) {
+	return NewTable().Convert(value, from, to)
+}
+
+//Converter converts values to the target unit of their dimension, values of dimensions without a target unit
+//are not converted
+type Converter struct {
+	table   *Table
+	targets map[string]string
+}
+
+//customUnits is the json file of custom target units, based on a unit system and adding units to the table
+type customUnits struct {
+	System  string            `json:"system"`  //optional unit system to start from, SI or field
+	Targets map[string]string `json:"targets"` //target unit by dimension, e.g. {"pressure": "kPa", "standard volume/gas": "MSm3"}
+	Units   []Unit            `json:"units"`   //units added to the conversion table
+}
+
+//NewConverter returns the converter for the target units given as SI, field or the path to a json file with custom
+//target units, nil for empty target units
+func NewConverter(targetUnits string) (*Converter, error) {
+	targetUnits = strings.TrimSpace(targetUnits)
+	if targetUnits == "" {
+		return nil, nil
+	}
+	if strings.HasSuffix(strings.ToLower(targetUnits), ".json") {
+		return readCustomConverter(targetUnits)
+	}
+	targets, found := systems[strings.ToLower(targetUnits)]
+	if !found {
+		return nil, fmt.Errorf("Unknown target units:%s, expected SI, field or a json file", targetUnits)
+	}
+	return NewConverterForTargets(NewTable(), targets)
+}
+
+//NewConverterForTargets returns the converter for the target unit of each dimension, or of a dimension for a
+//product class as e.g. standard volume/gas, the target units must be in the table and of the dimension they are
+//given for
+func NewConverterForTargets(table *Table, targets map[string]string) (*Converter, error) {
+	converter := &Converter{table: table, targets: make(map[string]string)}
+	for key, symbol := range targets {
+		dimension := key
+		if index := strings.Index(key, "/"); index >= 0 {
+			dimension = strings.TrimSpace(key[:index])
+			class := strings.TrimSpace(key[index+1:])
+			if class != ProductGas && class != ProductLiquid {
+				return nil, fmt.Errorf("Unknown product class:%s for dimension:%s, expected %s or %s", class, dimension,
+					ProductGas, ProductLiquid)
+			}
+			key = productTarget(dimension, class)
+		}
+		unit, found := table.Lookup(symbol)
+		if !found {
+			return nil, fmt.Errorf("Unknown target unit:%s for dimension:%s", symbol, key)
+		}
+		if unit.Dimension != dimension {
+			return nil, fmt.Errorf("Target unit:%s is of dimension:%s, not:%s", symbol, unit.Dimension, dimension)
+		}
+		converter.targets[key] = unit.Symbol
+	}
+	return converter, nil
+}
+
+//readCustomConverter reads the converter from a json file, e.g.
+//{"system": "SI", "targets": {"pressure": "kPa"}, "units": [{"symbol": "Mbbl", "dimension": "volume", "b": 158.987294928, "c": 1}]}
+func readCustomConverter(path string) (*Converter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom customUnits
+	if err = json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("Failed in parsing target units file:%s, error:%s", path, err.Error())
+	}
+	table := NewTable()
+	for i := 0; i < len(custom.Units); i++ {
+		if err = table.Add(custom.Units[i]); err != nil {
+			return nil, fmt.Errorf("Invalid unit in target units file:%s, error:%s", path, err.Error())
+		}
+	}
+	targets := make(map[string]string)
+	if custom.System != "" {
+		system, found := systems[strings.ToLower(custom.System)]
+		if !found {
+			return nil, fmt.Errorf("Unknown unit system:%s in target units file:%s, expected SI or field", custom.System, path)
+		}
+		for dimension, symbol := range system {
+			targets[dimension] = symbol
+		}
+	}
+	for dimension, symbol := range custom.Targets {
+		targets[strings.ToLower(dimension)] = symbol
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No target units found in target units file:%s", path)
+	}
+	return NewConverterForTargets(table, targets)
+}
+
+//Convert returns the value converted to the target unit of the dimension of its unit and the target unit, false
+//if the unit is unknown or its dimension has no target unit
+func (converter *Converter) Convert(value float64, symbol string) (float64, string, bool) {
+	return converter.ConvertProduct(value, symbol, "")
+}
+
+//ConvertProduct returns the value of a product kind, e.g. oil or gas, converted to the target unit of its
+//dimension for the class of the product, or else of its dimension, and the target unit, false if the unit is
+//unknown or has no target unit
+func (converter *Converter) ConvertProduct(value float64, symbol string, productKind string) (float64, string, bool) {
+	unit, found := converter.table.Lookup(symbol)
+	if !found {
+		return value, symbol, false
+	}
+	target, found := "", false
+	if class := ProductClass(productKind); class != "" {
+		target, found = converter.targets[productTarget(unit.Dimension, class)]
+	}
+	if !found {
+		target, found = converter.targets[unit.Dimension]
+	}
+	if !found {
+		return value, symbol, false
+	}
+	converted, err := converter.table.Convert(value, unit.Symbol, target)
+	if err != nil {
+		return value, symbol, false
+	}
+	return converted, target, true
+}
+
+//TargetUnit returns the target unit of the dimension of the unit, false if the unit is unknown or its dimension
+//has no target unit
+func (converter *Converter) TargetUnit(symbol string) (string, bool) {
+	return converter.TargetUnitProduct(symbol, "")
+}
+
+//TargetUnitProduct returns the target unit of the unit for the values of a product kind, false if the unit is
+//unknown or has no target unit
+func (converter *Converter) TargetUnitProduct(symbol string, productKind string) (string, bool) {
+	_, target, found := converter.ConvertProduct(0, symbol, productKind)
+	return target, found
+}
@@ -0,0 +1,183 @@
+package uom
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalcollaboration-collabor8/subsurfaceTools/pkg/common"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		expected float64
+	}{
+		{1, "bbl", "m3", 0.158987294928},
+		{1000, "ft", "m", 304.8},
+		{100, "bar", "psi", 1450.377377},
+		{100, "degC", "degF", 212},
+		{-40, "degF", "degC", -40},
+		{300, "K", "degC", 26.85},
+		{1.2, "g/cm3", "ppg", 10.014},
+		{1, "MSm3", "Mscf", 35314.667},
+		{8.5, "in", "mm", 215.9},
+		{2, "°C", "K", 275.15},
+	}
+	for _, test := range tests {
+		value, err := Convert(test.value, test.from, test.to)
+		if err != nil {
+			t.Errorf("Failed in converting %v %s to %s:%s", test.value, test.from, test.to, err.Error())
+		} else if math.Abs(value-test.expected) > 0.001 {
+			t.Errorf("Expected %v %s to be %v %s, got:%v", test.value, test.from, test.expected, test.to, value)
+		}
+	}
+	//symbols differing from a unit only in case are not matched
+	for _, units := range [][]string{{"m", "bar"}, {"Sm3", "m3"}, {"m", "furlong"}, {"MM", "m"}, {"FT", "m"}} {
+		if _, err := Convert(1, units[0], units[1]); err == nil {
+			t.Errorf("Expected error converting %s to %s", units[0], units[1])
+		}
+	}
+}
+
+func TestNewConverter(t *testing.T) {
+	if converter, err := NewConverter(""); converter != nil || err != nil || converter.Transform() != nil {
+		t.Errorf("Expected no converter without target units")
+	}
+	if _, err := NewConverter("imperial"); err == nil {
+		t.Errorf("Expected error for unknown unit system")
+	}
+	converter, err := NewConverter("Field")
+	if err != nil {
+		t.Fatalf("Failed in creating field converter:%s", err.Error())
+	}
+	//field standard volumes depend on the product, stb for liquids and Mscf for gas
+	if value, unit, found := converter.Convert(1, "Sm3"); found || unit != "Sm3" || value != 1 {
+		t.Errorf("Expected Sm3 of an unknown product not converted, got:%v %s", value, unit)
+	}
+	if value, unit, found := converter.ConvertProduct(1, "Sm3", "oil"); !found || unit != "stb" || math.Abs(value-6.2898) > 0.0001 {
+		t.Errorf("Expected Sm3 of oil converted to stb, got:%v %s", value, unit)
+	}
+	if value, unit, found := converter.ConvertProduct(1000, "Sm3", "gas - dry"); !found || unit != "Mscf" || math.Abs(value-35.3147) > 0.0001 {
+		t.Errorf("Expected Sm3 of gas converted to Mscf, got:%v %s", value, unit)
+	}
+	if _, unit, _ := converter.ConvertProduct(1, "m", "gas"); unit != "ft" {
+		t.Errorf("Expected length of gas product converted to ft, got:%s", unit)
+	}
+	if _, _, found := converter.Convert(1, "h"); found {
+		t.Errorf("Expected time not converted by the field system")
+	}
+	folder, err := ioutil.TempDir("", "uom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+	path := filepath.Join(folder, "units.json")
+	custom := `{"system": "SI", "targets": {"Pressure": "kPa", "volume": "Mbbl", "standard volume/gas": "MSm3"},
+		"units": [{"symbol": "Mbbl", "dimension": "volume", "b": 158.987294928, "c": 1}]}`
+	if err = ioutil.WriteFile(path, []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if converter, err = NewConverter(path); err != nil {
+		t.Fatalf("Failed in reading custom units:%s", err.Error())
+	}
+	if value, unit, _ := converter.Convert(2, "bar"); unit != "kPa" || value != 200 {
+		t.Errorf("Expected bar converted to kPa, got:%v %s", value, unit)
+	}
+	if value, unit, _ := converter.Convert(1000, "bbl"); unit != "Mbbl" || math.Abs(value-1) > 0.000001 {
+		t.Errorf("Expected bbl converted to the custom Mbbl, got:%v %s", value, unit)
+	}
+	if _, unit, _ := converter.Convert(1, "ft"); unit != "m" {
+		t.Errorf("Expected length from the SI system, got:%s", unit)
+	}
+	if _, unit, _ := converter.ConvertProduct(1, "kSm3", "gas"); unit != "MSm3" {
+		t.Errorf("Expected standard volume of gas in MSm3, got:%s", unit)
+	}
+	if _, unit, _ := converter.ConvertProduct(1, "kSm3", "oil"); unit != "Sm3" {
+		t.Errorf("Expected standard volume of oil in Sm3 from the SI system, got:%s", unit)
+	}
+	if _, err = NewConverter(filepath.Join("..", "..", "config", "SampleTargetUnits.json")); err != nil {
+		t.Errorf("Failed in reading the sample target units:%s", err.Error())
+	}
+	if err = ioutil.WriteFile(path, []byte(`{"targets": {"pressure": "m"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewConverter(path); err == nil {
+		t.Errorf("Expected error for target unit of another dimension")
+	}
+	if err = ioutil.WriteFile(path, []byte(`{"targets": {"standard volume/water": "stb"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewConverter(path); err == nil {
+		t.Errorf("Expected error for unknown product class")
+	}
+}
+
+func TestConvertDataSetByProduct(t *testing.T) {
+	dataset := common.DataSet{Name: "PRODUCTION", HeadersName: []string{"ProductKind", "Volume", "VolumeUoM"}}
+	for _, product := range []string{"oil", "gas", ""} {
+		row := common.RowData{}
+		row.AddStrValue(product)
+		row.AddMeasureValue(common.NewMeasure(1000, "Sm3"))
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	converter, _ := NewConverter("field")
+	rows := converter.ConvertDataSet(dataset).Rows
+	if rows[0].Columns[2].StrVal != "stb" || math.Abs(rows[0].Columns[1].FloatVal-6289.81) > 0.01 {
+		t.Errorf("Expected oil in stb, got:%v", rows[0].Columns)
+	}
+	if rows[1].Columns[2].StrVal != "Mscf" || math.Abs(rows[1].Columns[1].FloatVal-35.3147) > 0.0001 {
+		t.Errorf("Expected gas in Mscf, got:%v", rows[1].Columns)
+	}
+	if rows[2].Columns[2].StrVal != "Sm3" || rows[2].Columns[1].FloatVal != 1000 {
+		t.Errorf("Expected standard volume of an unknown product left as reported, got:%v", rows[2].Columns)
+	}
+}
+
+func TestConvertDataSet(t *testing.T) {
+	dataset := common.DataSet{Name: "WELLBORE", HeadersName: []string{"MD", "MD_UoM", "VolumeStd", "VolumeStdUoM",
+		"chokeRelative", "chokeUoM", "Comment", "CommentUoM"}}
+	add := func(md common.Measure, volume common.Measure) {
+		row := common.RowData{}
+		row.AddMeasureValue(md)
+		row.AddMeasureValue(volume)
+		row.AddMeasureValue(common.NewMeasure(50, "%"))
+		row.AddStrValue("text")
+		row.AddStrValue("m")
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	add(common.NewMeasure(1000, "ft"), common.NewMeasure(1, "bbl"))
+	add(common.NewMeasure(100, "m"), common.NewMeasure(1, "furlong"))
+	add(common.Measure{}, common.NewMeasure(1000, "kSm3"))
+	pairs := unitPairs(dataset.HeadersName)
+	if len(pairs) != 4 || pairs[2].value != 4 {
+		t.Errorf("Expected value and unit column pairs, got:%v", pairs)
+	}
+	converter, _ := NewConverter("SI")
+	datasets, err := converter.Transform()([]common.DataSet{dataset})
+	if err != nil {
+		t.Fatalf("Failed in converting dataset:%s", err.Error())
+	}
+	rows := datasets[0].Rows
+	if rows[0].Columns[0].FloatVal != 304.8 || rows[0].Columns[1].StrVal != "m" {
+		t.Errorf("Expected ft converted to m, got:%v", rows[0].Columns[:2])
+	}
+	//values are converted after their own unit, bbl is a volume and not a standard volume
+	if math.Abs(rows[0].Columns[2].FloatVal-0.158987) > 0.000001 || rows[0].Columns[3].StrVal != "m3" {
+		t.Errorf("Expected bbl converted to m3, got:%v", rows[0].Columns[2:4])
+	}
+	//unknown units and text values are left as they are
+	if rows[1].Columns[3].StrVal != "furlong" || rows[0].Columns[6].StrVal != "text" || rows[0].Columns[5].StrVal != "%" {
+		t.Errorf("Expected unknown units and text values left as they are, got:%v", rows[1].Columns)
+	}
+	if rows[2].Columns[2].FloatVal != 1000000 || rows[2].Columns[3].StrVal != "Sm3" || !rows[2].Columns[0].IsNull {
+		t.Errorf("Expected kSm3 converted to Sm3 and null kept, got:%v", rows[2].Columns)
+	}
+	//the input dataset is not changed
+	if dataset.Rows[0].Columns[0].FloatVal != 1000 || dataset.Rows[0].Columns[1].StrVal != "ft" {
+		t.Errorf("Expected the input dataset left unchanged, got:%v", dataset.Rows[0].Columns)
+	}
+}